@@ -1,17 +1,29 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 
 	"github.com/joho/godotenv"
+	"go.uber.org/zap"
 
 	"github.com/sudeeya/net-monitor/internal/client/app"
 	"github.com/sudeeya/net-monitor/internal/client/client"
 	"github.com/sudeeya/net-monitor/internal/client/config"
+	"github.com/sudeeya/net-monitor/internal/client/credentials"
+	"github.com/sudeeya/net-monitor/internal/client/credentials/env"
+	"github.com/sudeeya/net-monitor/internal/client/credentials/keystore"
+	"github.com/sudeeya/net-monitor/internal/client/credentials/vault"
+	"github.com/sudeeya/net-monitor/internal/client/inventory"
+	"github.com/sudeeya/net-monitor/internal/client/inventory/file"
+	"github.com/sudeeya/net-monitor/internal/client/inventory/netbox"
+	"github.com/sudeeya/net-monitor/internal/client/metrics"
 	"github.com/sudeeya/net-monitor/internal/client/snapper/snapshots"
 	"github.com/sudeeya/net-monitor/internal/pkg/logging"
+	"github.com/sudeeya/net-monitor/internal/pkg/tracing"
 )
 
 var (
@@ -43,17 +55,121 @@ func main() {
 		log.Fatal(err)
 	}
 
-	snapper, err := snapshots.NewSnapshots(logger, cfg.TargetsFile)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Service:  "net-monitor-client",
+		Exporter: cfg.TracingExporter,
+		Endpoint: cfg.TracingEndpoint,
+		Insecure: cfg.TracingInsecure,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	grpcClient, err := client.NewClient(logger, snapper, cfg.ServerAddr)
+	inventory, err := newInventory(cfg, logger)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	a := app.NewApp(cfg, logger, grpcClient)
+	clientMetrics := metrics.NewMetrics(cfg.ReadyStaleness)
+
+	snapper, err := snapshots.NewSnapshots(logger, inventory, cfg.InventoryRefreshInterval, cfg.CollectRunningConfig, clientMetrics)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	grpcClient, err := client.NewClient(logger, snapper, cfg.ServerAddr, clientMetrics)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var handler http.Handler
+	if cfg.HTTPAddr != "" {
+		handler = clientMetrics.Handler(logger)
+	}
+
+	a := app.NewApp(cfg, logger, grpcClient, snapper, handler, shutdownTracing)
 
 	a.Run()
 }
+
+// newInventory returns the inventory provider selected in the config.
+// Credential references of target devices are resolved by the configured credential providers.
+func newInventory(cfg *config.Config, logger *zap.Logger) (inventory.Provider, error) {
+	provider, err := newTargetsProvider(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, err := newCredentialsResolver(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewInventory(provider, resolver), nil
+}
+
+// newCredentialsResolver returns resolver with every credential provider that is configured.
+func newCredentialsResolver(cfg *config.Config) (*credentials.Resolver, error) {
+	resolver := credentials.NewResolver(cfg.CredentialsProvider)
+
+	resolver.Register(config.EnvCredentials, env.NewEnv(cfg.CredentialsEnvPrefix))
+
+	if cfg.KeystoreFile != "" {
+		k, err := keystore.NewKeystore(cfg.KeystoreFile, cfg.KeystorePassphrase)
+		if err != nil {
+			return nil, err
+		}
+		resolver.Register(config.KeystoreCredentials, k)
+	}
+
+	if cfg.VaultAddr != "" {
+		v, err := vault.NewVault(vault.Config{
+			Addr:       cfg.VaultAddr,
+			Token:      cfg.VaultToken,
+			Mount:      cfg.VaultMount,
+			PathPrefix: cfg.VaultPathPrefix,
+			KVVersion:  cfg.VaultKVVersion,
+		})
+		if err != nil {
+			return nil, err
+		}
+		resolver.Register(config.VaultCredentials, v)
+	}
+
+	return resolver, nil
+}
+
+// newTargetsProvider returns the source of target devices selected in the config.
+func newTargetsProvider(cfg *config.Config, logger *zap.Logger) (inventory.Provider, error) {
+	switch cfg.Inventory {
+	case config.NetBoxInventory:
+		platforms, err := netbox.ParsePlatformMap(cfg.NetBoxPlatformMap)
+		if err != nil {
+			return nil, err
+		}
+		if len(platforms) == 0 {
+			platforms = nil
+		}
+
+		return netbox.NewNetBox(logger, netbox.Config{
+			URL:          cfg.NetBoxURL,
+			Token:        cfg.NetBoxToken,
+			Sites:        cfg.NetBoxSites,
+			Roles:        cfg.NetBoxRoles,
+			Tags:         cfg.NetBoxTags,
+			Platforms:    cfg.NetBoxPlatforms,
+			PlatformMap:  platforms,
+			UsePrimaryIP: cfg.NetBoxUsePrimaryIP,
+			Defaults: inventory.Target{
+				Username:       cfg.TargetUsername,
+				Password:       cfg.TargetPassword,
+				PrivateKeyPath: cfg.TargetPrivateKeyPath,
+				Passphrase:     cfg.TargetPassphrase,
+				NoStrictKey:    cfg.TargetNoStrictKey,
+				Credential:     cfg.TargetCredential,
+			},
+		})
+	default:
+		return file.NewFile(cfg.TargetsFile), nil
+	}
+}
@@ -0,0 +1,49 @@
+// Keystore creates an encrypted credential keystore for the client.
+//
+// The input file is a json object mapping credential names to credentials, for example:
+//
+//	{"core-routers": {"username": "admin", "password": "secret"}}
+//
+// The passphrase is read from the KEYSTORE_PASSPHRASE environment variable.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/sudeeya/net-monitor/internal/client/credentials"
+	"github.com/sudeeya/net-monitor/internal/client/credentials/keystore"
+)
+
+func main() {
+	in := flag.String("in", "", "Path to the json file with plaintext credentials")
+	out := flag.String("out", "keystore.json", "Path to the keystore file to create")
+
+	flag.Parse()
+
+	passphrase := os.Getenv("KEYSTORE_PASSPHRASE")
+	if passphrase == "" {
+		log.Fatal("KEYSTORE_PASSPHRASE is not set")
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	creds := make(map[string]credentials.Credential)
+	if err := json.Unmarshal(data, &creds); err != nil {
+		log.Fatal(err)
+	}
+
+	sealed, err := keystore.Seal(creds, passphrase)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(*out, sealed, 0o600); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/server/services/archive"
+)
+
+const (
+	exportCommand         = "export"
+	importCommand         = "import"
+	archiveLimitInSeconds = 3600
+)
+
+const exportUsage = `Usage: server export [-e env file] [-from time] [-to time] [-o file]
+
+Writes snapshots taken from -from to -to to a gzip-compressed JSON Lines archive.
+Times are in RFC 3339 format; by default, all snapshots taken until now are exported.
+
+Flags:
+`
+
+const importUsage = `Usage: server import [-e env file] <file>
+
+Stores snapshots from an archive written by export. Snapshots already stored with the same timestamp are skipped.
+
+Flags:
+`
+
+// runExport runs the export subcommand with arguments following it.
+func runExport(args []string) error {
+	flags := flag.NewFlagSet(exportCommand, flag.ExitOnError)
+	envFile := flags.String("e", "env/server.env", "Path to the file storing environment variables")
+	fromFlag := flags.String("from", "", "Time of the first exported snapshot")
+	toFlag := flags.String("to", "", "Time of the last exported snapshot")
+	output := flags.String("o", "snapshots.jsonl.gz", "Path to the archive")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), exportUsage)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	from, to := time.Unix(0, 0), time.Now()
+	var err error
+	if *fromFlag != "" {
+		if from, err = time.Parse(time.RFC3339, *fromFlag); err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+	}
+	if *toFlag != "" {
+		if to, err = time.Parse(time.RFC3339, *toFlag); err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+
+	cfg, logger, err := loadConfig(*envFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	repo, err := newRepository(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeRepository(repo, logger)
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), archiveLimitInSeconds*time.Second)
+	defer cancel()
+
+	n, err := archive.NewArchive(logger, repo).Export(ctx, f, from, to)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// A partial archive is not left behind.
+		return errors.Join(err, os.Remove(*output))
+	}
+
+	fmt.Printf("Exported %d snapshots to %s\n", n, *output)
+
+	return nil
+}
+
+// runImport runs the import subcommand with arguments following it.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet(importCommand, flag.ExitOnError)
+	envFile := flags.String("e", "env/server.env", "Path to the file storing environment variables")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), importUsage)
+		flags.PrintDefaults()
+	}
+
+	// Flags are accepted both before and after the file.
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	input := flags.Arg(0)
+	if flags.NArg() > 0 {
+		if err := flags.Parse(flags.Args()[1:]); err != nil {
+			return err
+		}
+	}
+	if input == "" || flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("expected a single archive")
+	}
+
+	cfg, logger, err := loadConfig(*envFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	repo, err := newRepository(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeRepository(repo, logger)
+
+	complianceService, ipConflictsService, err := newAnalyzers(cfg, logger, repo)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), archiveLimitInSeconds*time.Second)
+	defer cancel()
+
+	result, err := archive.NewArchive(logger, repo, complianceService, ipConflictsService).Import(ctx, f)
+	fmt.Printf("Imported %d snapshots, skipped %d already stored\n", result.Imported, result.Skipped)
+
+	return err
+}
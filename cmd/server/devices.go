@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	devicesCommand        = "devices"
+	devicesLimitInSeconds = 60
+)
+
+// Possible devices actions.
+const (
+	listAction   = "list"
+	renameAction = "rename"
+	mergeAction  = "merge"
+)
+
+const devicesUsage = `Usage: server devices [-e env file] <action> [arguments]
+
+Actions:
+  list                    print all stored devices
+  rename <id> <hostname>  change the current hostname of a device
+  merge <from> <into>     move the history of a device into another one and delete it
+
+Flags:
+`
+
+// runDevices runs the devices subcommand with arguments following it.
+func runDevices(args []string) error {
+	flags := flag.NewFlagSet(devicesCommand, flag.ExitOnError)
+	envFile := flags.String("e", "env/server.env", "Path to the file storing environment variables")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), devicesUsage)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	action, actionArgs := flags.Arg(0), flags.Args()
+	if len(actionArgs) > 0 {
+		actionArgs = actionArgs[1:]
+	}
+
+	var wantArgs int
+	switch action {
+	case listAction:
+	case renameAction, mergeAction:
+		wantArgs = 2
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown action: %q", action)
+	}
+	if len(actionArgs) != wantArgs {
+		flags.Usage()
+		return fmt.Errorf("%s expects %d arguments, got %d", action, wantArgs, len(actionArgs))
+	}
+
+	cfg, logger, err := loadConfig(*envFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	repo, err := newRepository(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeRepository(repo, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), devicesLimitInSeconds*time.Second)
+	defer cancel()
+
+	switch action {
+	case renameAction:
+		id, err := strconv.Atoi(actionArgs[0])
+		if err != nil {
+			return fmt.Errorf("invalid device id %q", actionArgs[0])
+		}
+		if actionArgs[1] == "" {
+			return fmt.Errorf("hostname is empty")
+		}
+
+		if err := repo.RenameDevice(ctx, id, actionArgs[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Renamed device %d to %s\n", id, actionArgs[1])
+	case mergeAction:
+		from, err := strconv.Atoi(actionArgs[0])
+		if err != nil {
+			return fmt.Errorf("invalid device id %q", actionArgs[0])
+		}
+		into, err := strconv.Atoi(actionArgs[1])
+		if err != nil {
+			return fmt.Errorf("invalid device id %q", actionArgs[1])
+		}
+
+		if err := repo.MergeDevices(ctx, from, into); err != nil {
+			return err
+		}
+		fmt.Printf("Merged device %d into %d\n", from, into)
+	default:
+		devices, err := repo.ListDevices(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tHOSTNAME\tSERIAL\tVENDOR\tOS\tSNAPSHOTS\tFIRST SEEN\tLAST SEEN")
+		for _, d := range devices {
+			firstSeen, lastSeen := "-", "-"
+			if d.Snapshots > 0 {
+				firstSeen, lastSeen = d.FirstSeen.Format(time.RFC3339), d.LastSeen.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s %s\t%d\t%s\t%s\n",
+				d.ID, d.Hostname, d.Serial, d.Vendor, d.OSName, d.OSVersion, d.Snapshots, firstSeen, lastSeen)
+		}
+
+		return w.Flush()
+	}
+
+	return nil
+}
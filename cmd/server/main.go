@@ -1,18 +1,38 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"time"
 
 	"github.com/joho/godotenv"
+	"go.uber.org/zap"
 
 	"github.com/sudeeya/net-monitor/internal/pkg/logging"
+	"github.com/sudeeya/net-monitor/internal/pkg/tracing"
 	"github.com/sudeeya/net-monitor/internal/server/api"
 	"github.com/sudeeya/net-monitor/internal/server/app"
 	"github.com/sudeeya/net-monitor/internal/server/config"
+	"github.com/sudeeya/net-monitor/internal/server/handlers"
+	"github.com/sudeeya/net-monitor/internal/server/metrics"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/memory"
 	"github.com/sudeeya/net-monitor/internal/server/repository/postgresql"
+	"github.com/sudeeya/net-monitor/internal/server/repository/sqlite"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+	"github.com/sudeeya/net-monitor/internal/server/services/alerting"
+	"github.com/sudeeya/net-monitor/internal/server/services/archive"
+	"github.com/sudeeya/net-monitor/internal/server/services/compliance"
+	"github.com/sudeeya/net-monitor/internal/server/services/history"
+	"github.com/sudeeya/net-monitor/internal/server/services/ipam"
+	"github.com/sudeeya/net-monitor/internal/server/services/ipconflicts"
+	"github.com/sudeeya/net-monitor/internal/server/services/notifications"
+	"github.com/sudeeya/net-monitor/internal/server/services/retention"
 	"github.com/sudeeya/net-monitor/internal/server/services/snapshots"
+	"github.com/sudeeya/net-monitor/internal/server/services/topology"
 )
 
 var (
@@ -26,39 +46,190 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case migrateCommand:
+			if err := runMigrate(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case pruneCommand:
+			if err := runPrune(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case devicesCommand:
+			if err := runDevices(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case exportCommand:
+			if err := runExport(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case importCommand:
+			if err := runImport(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+
 	envFile := flag.String("e", "env/server.env", "Path to the file storing environment variables")
 
 	flag.Parse()
 
-	if err := godotenv.Load(*envFile); err != nil {
+	cfg, logger, err := loadConfig(*envFile)
+	if err != nil {
 		log.Fatal(err)
 	}
 
-	cfg, err := config.NewConfig()
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Service:  "net-monitor-server",
+		Exporter: cfg.TracingExporter,
+		Endpoint: cfg.TracingEndpoint,
+		Insecure: cfg.TracingInsecure,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	logger, err := logging.NewLogger(cfg.LogLevel, cfg.LogFile)
+	repo, err := newRepository(cfg, logger)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	repo, err := postgresql.NewPostgreSQL(logger, cfg.DatabaseDSN)
+	if m, ok := repo.(repository.Migratable); ok && cfg.DatabaseAutoMigrate {
+		ctx, cancel := context.WithTimeout(context.Background(), migrateLimitInSeconds*time.Second)
+		err := m.Migrator().Up(ctx)
+		cancel()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	serverMetrics := metrics.NewMetrics(logger, repo)
+	repo = serverMetrics.InstrumentRepository(repo)
+
+	var notificationsService services.NotificationsService
+	if cfg.NotificationsFile != "" {
+		notificationsCfg, err := notifications.LoadConfig(cfg.NotificationsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		notificationsService = notifications.NewNotifications(logger, notificationsCfg)
+	}
+
+	var alertsService services.AlertsService
+	if cfg.AlertRulesFile != "" {
+		rules, err := alerting.LoadRules(cfg.AlertRulesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		alertsService = alerting.NewAlerting(logger, rules, notificationsService)
+	}
+
+	complianceService, ipConflictsService, err := newAnalyzers(cfg, logger, repo)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	service := snapshots.NewSnapshots(logger, repo)
+	service := serverMetrics.InstrumentSnapshots(snapshots.NewSnapshots(logger, repo, alertsService, complianceService, ipConflictsService))
+
+	grpcServer := api.NewSnapshotsGRPCServer(logger, service, cfg.GRPCMaxRecvBytes)
 
-	grpcServer := api.NewSnapshotsGRPCServer(logger, service)
+	historyService := history.NewHistory(logger, repo)
 
-	httpServer, err := api.NewSnapshotsHTTPServer(logger, service)
+	archiveService := archive.NewArchive(logger, repo, complianceService, ipConflictsService)
+
+	ipamService := ipam.NewIPAM(logger, repo)
+
+	topologyService := topology.NewTopology(logger, repo)
+
+	importCfg := handlers.ImportConfig{Token: cfg.ImportToken, MaxBytes: cfg.ImportMaxBytes}
+	httpServer, err := api.NewSnapshotsHTTPServer(logger, service, historyService, archiveService, importCfg, complianceService, ipConflictsService, ipamService, topologyService, serverMetrics)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	policies, err := retention.ParsePolicies(cfg.RetentionPolicy)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	a := app.NewApp(cfg, logger, repo, httpServer, grpcServer)
+	var retentionService services.RetentionService
+	if len(policies) > 0 {
+		retentionService = retention.NewRetention(logger, repo, policies, cfg.RetentionInterval, cfg.RetentionDryRun)
+	}
+
+	a := app.NewApp(cfg, logger, repo, httpServer, grpcServer, retentionService, notificationsService, shutdownTracing)
 
 	a.Run()
 }
+
+// loadConfig loads environment variables from [envFile] and returns the server config and logger.
+func loadConfig(envFile string) (*config.Config, *zap.Logger, error) {
+	if err := godotenv.Load(envFile); err != nil {
+		return nil, nil, err
+	}
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger, err := logging.NewLogger(cfg.LogLevel, cfg.LogFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cfg, logger, nil
+}
+
+// newAnalyzers returns the compliance and IP conflicts services set in the config,
+// which analyze saved and imported snapshots.
+func newAnalyzers(cfg *config.Config, logger *zap.Logger, repo repository.Repository) (services.ComplianceService, services.IPConflictsService, error) {
+	var compliancePolicies []compliance.Policy
+	if cfg.CompliancePolicies != "" {
+		var err error
+		compliancePolicies, err = compliance.LoadPolicies(cfg.CompliancePolicies)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	ignoredPrefixes, err := ipconflicts.ParsePrefixes(cfg.IPConflictsIgnored)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return compliance.NewCompliance(logger, repo, compliancePolicies), ipconflicts.NewIPConflicts(logger, repo, ignoredPrefixes), nil
+}
+
+// closeRepository releases the database of the repository if it holds one.
+func closeRepository(repo repository.Repository, logger *zap.Logger) {
+	if c, ok := repo.(repository.Closer); ok {
+		if err := c.Close(); err != nil {
+			logger.Error(err.Error())
+		}
+	}
+}
+
+// newRepository returns the repository selected by the database driver in the config.
+func newRepository(cfg *config.Config, logger *zap.Logger) (repository.Repository, error) {
+	mode, err := repository.ParseIdentityMode(cfg.DeviceIdentity)
+	if err != nil {
+		return nil, err
+	}
+	identity := repository.Identity{Mode: mode, IgnoredSerials: cfg.DeviceIgnoredSerials}
+
+	switch cfg.DatabaseDriver {
+	case config.SQLiteDriver:
+		return sqlite.NewSQLite(logger, cfg.DatabaseDSN, identity)
+	case config.MemoryDriver:
+		return memory.NewMemory(logger, identity), nil
+	default:
+		return postgresql.NewPostgreSQL(logger, cfg.DatabaseDSN, cfg.DatabaseBulkThreshold, identity)
+	}
+}
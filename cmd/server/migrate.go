@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+)
+
+const (
+	migrateCommand        = "migrate"
+	migrateLimitInSeconds = 300
+)
+
+// Possible migrate actions.
+const (
+	upAction     = "up"
+	downAction   = "down"
+	statusAction = "status"
+)
+
+const migrateUsage = `Usage: server migrate [-e env file] <action> [-n number]
+
+Actions:
+  up      apply all pending migrations
+  down    roll back the last -n applied migrations (1 by default)
+  status  print all migrations and whether they are applied
+
+Flags:
+`
+
+// runMigrate runs the migrate subcommand with arguments following it.
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet(migrateCommand, flag.ExitOnError)
+	envFile := flags.String("e", "env/server.env", "Path to the file storing environment variables")
+	n := flags.Int("n", 1, "Number of migrations to roll back")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), migrateUsage)
+		flags.PrintDefaults()
+	}
+
+	// Flags are accepted both before and after the action.
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	action := flags.Arg(0)
+	if flags.NArg() > 0 {
+		if err := flags.Parse(flags.Args()[1:]); err != nil {
+			return err
+		}
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	cfg, logger, err := loadConfig(*envFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	repo, err := newRepository(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeRepository(repo, logger)
+
+	m, ok := repo.(repository.Migratable)
+	if !ok {
+		return fmt.Errorf("database driver %s does not support migrations", cfg.DatabaseDriver)
+	}
+	migrator := m.Migrator()
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrateLimitInSeconds*time.Second)
+	defer cancel()
+
+	switch action {
+	case upAction:
+		return migrator.Up(ctx)
+	case downAction:
+		return migrator.Down(ctx, *n)
+	case statusAction:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printMigrationStatus(statuses)
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown migrate action: %q", action)
+	}
+}
+
+// printMigrationStatus prints migrations as a table to standard out.
+func printMigrationStatus(statuses []repository.MigrationStatus) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		name := status.Name
+		if name == "" {
+			name = "(unknown)"
+		}
+
+		state, appliedAt := "pending", "-"
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.Local().Format(time.DateTime)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, name, state, appliedAt)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/server/services/retention"
+)
+
+const (
+	pruneCommand        = "prune"
+	pruneLimitInSeconds = 300
+)
+
+const pruneUsage = `Usage: server prune [-e env file] [-dry-run]
+
+Deletes snapshots not kept by RETENTION_POLICY once and prints the number of deleted rows.
+
+Flags:
+`
+
+// runPrune runs the prune subcommand with arguments following it.
+func runPrune(args []string) error {
+	flags := flag.NewFlagSet(pruneCommand, flag.ExitOnError)
+	envFile := flags.String("e", "env/server.env", "Path to the file storing environment variables")
+	dryRun := flags.Bool("dry-run", false, "Report what would be deleted without deleting anything")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), pruneUsage)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected arguments: %v", flags.Args())
+	}
+
+	cfg, logger, err := loadConfig(*envFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+
+	policies, err := retention.ParsePolicies(cfg.RetentionPolicy)
+	if err != nil {
+		return err
+	}
+	if len(policies) == 0 {
+		return fmt.Errorf("retention policy is not specified")
+	}
+
+	repo, err := newRepository(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeRepository(repo, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pruneLimitInSeconds*time.Second)
+	defer cancel()
+
+	result, err := retention.NewRetention(logger, repo, policies, cfg.RetentionInterval, *dryRun).Prune(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	action := "Deleted"
+	if *dryRun {
+		action = "Would delete"
+	}
+	fmt.Printf(
+		"%s %d snapshots, %d devices, %d interfaces, %d vendors and %d operating systems\n",
+		action, result.Snapshots, result.Devices, result.Interfaces, result.Vendors, result.OperatingSystems,
+	)
+
+	return nil
+}
@@ -2,9 +2,11 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"io/fs"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -14,36 +16,78 @@ import (
 
 	"github.com/sudeeya/net-monitor/internal/client/client"
 	"github.com/sudeeya/net-monitor/internal/client/config"
+	"github.com/sudeeya/net-monitor/internal/client/snapper"
+	"github.com/sudeeya/net-monitor/internal/pkg/tracing"
 )
 
 // app describes client application and all necessary layers.
 type app struct {
-	cfg    *config.Config
-	logger *zap.Logger
-	client *client.Client
+	cfg      *config.Config
+	logger   *zap.Logger
+	client   *client.Client
+	reloader snapper.Reloader
+	handler  http.Handler
+	tracing  tracing.Shutdown
 }
 
+// Time limit for exporting the remaining spans on shutdown.
+const tracingLimitInSeconds = 5
+
 // NewApp returns app object to interact with client.
+// If [handler] is nil, the client does not listen for HTTP requests.
+// [shutdownTracing] is called on shutdown to export the remaining spans.
 func NewApp(
 	cfg *config.Config,
 	logger *zap.Logger,
 	client *client.Client,
+	reloader snapper.Reloader,
+	handler http.Handler,
+	shutdownTracing tracing.Shutdown,
 ) *app {
 	return &app{
-		cfg:    cfg,
-		logger: logger,
-		client: client,
+		cfg:      cfg,
+		logger:   logger,
+		client:   client,
+		reloader: reloader,
+		handler:  handler,
+		tracing:  shutdownTracing,
 	}
 }
 
 // Run starts the client.
-// It initiates periodic sending of gRPC requests to server and monitors for OS signals.
+// It initiates periodic sending of gRPC requests to server, listens for health checks and monitors for OS signals.
+// SIGHUP and changes reported by the inventory make the client reload the list of target devices.
 func (a *app) Run() {
 	a.logger.Info("Client is running")
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
 
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+
+	go func() {
+		for range hupCh {
+			a.logger.Info("Client received SIGHUP")
+			_ = a.reloader.Reload()
+		}
+	}()
+
+	go func() {
+		if err := a.reloader.Watch(context.Background()); err != nil {
+			a.logger.Sugar().Errorf("Stopped watching the inventory: %s", err.Error())
+		}
+	}()
+
+	if a.handler != nil {
+		go func() {
+			a.logger.Info("Listening for HTTP requests")
+			if err := http.ListenAndServe(a.cfg.HTTPAddr, a.handler); err != nil {
+				a.logger.Error(err.Error())
+			}
+		}()
+	}
+
 	uploadTicker := time.NewTicker(a.cfg.SnapInterval)
 
 	go func() {
@@ -63,8 +107,14 @@ func (a *app) Run() {
 }
 
 // Shutdown shuts down the client.
-// It syncs client logger before shutdown.
+// It exports the remaining spans and syncs client logger before shutdown.
 func (a *app) Shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), tracingLimitInSeconds*time.Second)
+	defer cancel()
+	if err := a.tracing(ctx); err != nil {
+		log.Printf("Failed to export spans: %v\n", err)
+	}
+
 	if err := a.client.Close(); err != nil {
 		log.Printf("Failed to close the client: %v/n", err)
 	}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -17,23 +20,50 @@ import (
 
 const limitInSeconds = 100
 
+// tracer starts the root span of every upload.
+var tracer = otel.Tracer("github.com/sudeeya/net-monitor/internal/client/client")
+
+// Reasons for which a snapshot was not uploaded.
+const (
+	// The snapshot could not be created.
+	ReasonSnapFailed = "snap_failed"
+
+	// The server could not be reached or the request failed.
+	ReasonRequestFailed = "request_failed"
+
+	// The server could not save the snapshot.
+	ReasonRejected = "rejected"
+)
+
+// Observer describes an object recording the uploads of snapshots.
+type Observer interface {
+	// UploadFinished is called after an attempt to upload a snapshot with the time taken by the request
+	// and the reason if the snapshot was not uploaded. The time is zero if no request was sent.
+	UploadFinished(duration time.Duration, reason string)
+}
+
 // Client describes client.
 type Client struct {
-	logger  *zap.Logger
-	snapper snapper.Snapper
-	conn    *grpc.ClientConn
-	client  pb.SnapshotsClient
+	logger   *zap.Logger
+	snapper  snapper.Snapper
+	conn     *grpc.ClientConn
+	client   pb.SnapshotsClient
+	observer Observer
 }
 
 // NewClient returns client object.
+// If [observer] is not nil, it is notified about every upload.
+// Requests carry the trace context of the upload in their metadata.
 func NewClient(
 	logger *zap.Logger,
 	snapper snapper.Snapper,
 	serverAddr string,
+	observer Observer,
 ) (*Client, error) {
 	conn, err := grpc.NewClient(
 		serverAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 	)
 	if err != nil {
 		return nil, err
@@ -42,10 +72,11 @@ func NewClient(
 	client := pb.NewSnapshotsClient(conn)
 
 	return &Client{
-		logger:  logger,
-		snapper: snapper,
-		conn:    conn,
-		client:  client,
+		logger:   logger,
+		snapper:  snapper,
+		conn:     conn,
+		client:   client,
+		observer: observer,
 	}, nil
 }
 
@@ -54,27 +85,45 @@ func (c *Client) UploadSnapshot() error {
 	ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
 	defer cancel()
 
+	ctx, span := tracer.Start(ctx, "UploadSnapshot")
+	defer span.End()
+
 	c.logger.Info("Snapshot is being created")
-	s, err := c.snapper.Snap()
+	s, err := c.snapper.Snap(ctx)
 	if err != nil {
+		c.observe(0, ReasonSnapFailed)
+		span.SetStatus(codes.Error, ReasonSnapFailed)
 		return err
 	}
 	c.logger.Info("Snapshot is ready to be saved")
 
 	snapshot := converter.ToProtoFromSnapshot(s)
 
+	start := time.Now()
 	response, err := c.client.SaveSnapshot(ctx, &pb.SaveSnapshotRequest{Snapshot: snapshot})
 	if err != nil {
+		c.observe(time.Since(start), ReasonRequestFailed)
+		span.SetStatus(codes.Error, ReasonRequestFailed)
 		return err
 	}
 	if response.Error != "" {
+		c.observe(time.Since(start), ReasonRejected)
+		span.SetStatus(codes.Error, ReasonRejected)
 		return fmt.Errorf(response.Error)
 	}
+	c.observe(time.Since(start), "")
 	c.logger.Info("Snapshot has been saved")
 
 	return nil
 }
 
+// observe notifies the observer about an upload, if there is one.
+func (c *Client) observe(duration time.Duration, reason string) {
+	if c.observer != nil {
+		c.observer.UploadFinished(duration, reason)
+	}
+}
+
 // Close tears down connections.
 func (c *Client) Close() error {
 	return c.conn.Close()
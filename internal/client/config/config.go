@@ -2,18 +2,70 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/caarlos0/env"
 )
 
+// Possible inventory providers.
+const (
+	FileInventory   = "file"
+	NetBoxInventory = "netbox"
+)
+
+// Possible credential providers.
+const (
+	EnvCredentials      = "env"
+	KeystoreCredentials = "keystore"
+	VaultCredentials    = "vault"
+)
+
 // Config describes client config.
 type Config struct {
 	ServerAddr   string        `env:"SERVER_ADDR" envDefault:"localhost:9090"`
-	TargetsFile  string        `env:"TARGETS_FILE,required"`
 	SnapInterval time.Duration `env:"SNAP_INTERVAL" envDefault:"10m"`
 	LogLevel     string        `env:"LOG_LEVEL" envDefault:"INFO"`
 	LogFile      string        `env:"LOG_FILE"`
+
+	CollectRunningConfig bool `env:"COLLECT_RUNNING_CONFIG" envDefault:"false"`
+
+	HTTPAddr       string        `env:"HTTP_ADDR"`
+	ReadyStaleness time.Duration `env:"READY_STALENESS"`
+
+	TracingExporter string `env:"TRACING_EXPORTER" envDefault:"none"`
+	TracingEndpoint string `env:"TRACING_ENDPOINT"`
+	TracingInsecure bool   `env:"TRACING_INSECURE"`
+
+	Inventory                string        `env:"INVENTORY" envDefault:"file"`
+	InventoryRefreshInterval time.Duration `env:"INVENTORY_REFRESH_INTERVAL" envDefault:"1h"`
+	TargetsFile              string        `env:"TARGETS_FILE"`
+
+	NetBoxURL          string   `env:"NETBOX_URL"`
+	NetBoxToken        string   `env:"NETBOX_TOKEN"`
+	NetBoxSites        []string `env:"NETBOX_SITES" envSeparator:","`
+	NetBoxRoles        []string `env:"NETBOX_ROLES" envSeparator:","`
+	NetBoxTags         []string `env:"NETBOX_TAGS" envSeparator:","`
+	NetBoxPlatforms    []string `env:"NETBOX_PLATFORMS" envSeparator:","`
+	NetBoxPlatformMap  string   `env:"NETBOX_PLATFORM_MAP"`
+	NetBoxUsePrimaryIP bool     `env:"NETBOX_USE_PRIMARY_IP"`
+
+	TargetUsername       string `env:"TARGET_USERNAME"`
+	TargetPassword       string `env:"TARGET_PASSWORD"`
+	TargetPrivateKeyPath string `env:"TARGET_PRIVATE_KEY_PATH"`
+	TargetPassphrase     string `env:"TARGET_PASSPHRASE"`
+	TargetNoStrictKey    bool   `env:"TARGET_NO_STRICT_KEY"`
+	TargetCredential     string `env:"TARGET_CREDENTIAL"`
+
+	CredentialsProvider  string `env:"CREDENTIALS_PROVIDER" envDefault:"env"`
+	CredentialsEnvPrefix string `env:"CREDENTIALS_ENV_PREFIX" envDefault:"CREDENTIAL_"`
+	KeystoreFile         string `env:"KEYSTORE_FILE"`
+	KeystorePassphrase   string `env:"KEYSTORE_PASSPHRASE"`
+	VaultAddr            string `env:"VAULT_ADDR"`
+	VaultToken           string `env:"VAULT_TOKEN"`
+	VaultMount           string `env:"VAULT_MOUNT" envDefault:"secret"`
+	VaultPathPrefix      string `env:"VAULT_PATH_PREFIX"`
+	VaultKVVersion       int    `env:"VAULT_KV_VERSION" envDefault:"2"`
 }
 
 // NewConfig returns client config.
@@ -24,5 +76,40 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
+	switch cfg.Inventory {
+	case FileInventory:
+		if cfg.TargetsFile == "" {
+			return nil, fmt.Errorf("TARGETS_FILE is required for %s inventory", FileInventory)
+		}
+	case NetBoxInventory:
+		if cfg.NetBoxURL == "" {
+			return nil, fmt.Errorf("NETBOX_URL is required for %s inventory", NetBoxInventory)
+		}
+	default:
+		return nil, fmt.Errorf("unknown inventory: %s", cfg.Inventory)
+	}
+
+	switch cfg.CredentialsProvider {
+	case EnvCredentials:
+	case KeystoreCredentials:
+		if cfg.KeystoreFile == "" {
+			return nil, fmt.Errorf("KEYSTORE_FILE is required for %s credentials", KeystoreCredentials)
+		}
+	case VaultCredentials:
+		if cfg.VaultAddr == "" {
+			return nil, fmt.Errorf("VAULT_ADDR is required for %s credentials", VaultCredentials)
+		}
+	default:
+		return nil, fmt.Errorf("unknown credentials provider: %s", cfg.CredentialsProvider)
+	}
+
+	// Snapshots are uploaded once per interval, so two intervals leave time for a slow snapshot.
+	switch {
+	case cfg.ReadyStaleness < 0:
+		return nil, fmt.Errorf("READY_STALENESS must not be negative")
+	case cfg.ReadyStaleness == 0:
+		cfg.ReadyStaleness = 2 * cfg.SnapInterval
+	}
+
 	return &cfg, nil
 }
@@ -0,0 +1,186 @@
+// Package credentials defines sources of credentials referenced by target devices.
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sudeeya/net-monitor/internal/client/inventory"
+)
+
+// Credential describes information needed for SSH authentication.
+type Credential struct {
+	Username       string `json:"username"`
+	Password       string `json:"password"`
+	PrivateKeyPath string `json:"private_key_path"`
+	Passphrase     string `json:"passphrase"`
+}
+
+// Provider describes a source of credentials.
+type Provider interface {
+	// Credential returns a credential by its name.
+	// Returns an error if the credential could not be found.
+	Credential(ctx context.Context, name string) (Credential, error)
+}
+
+// Resolver resolves credential references using registered providers.
+// A reference has the form "provider:name", for example "vault:core-routers".
+// References without a provider prefix are resolved by the default provider.
+type Resolver struct {
+	providers       map[string]Provider
+	defaultProvider string
+}
+
+// NewResolver returns Resolver object that uses [defaultProvider] for references without a provider prefix.
+func NewResolver(defaultProvider string) *Resolver {
+	return &Resolver{
+		providers:       make(map[string]Provider),
+		defaultProvider: defaultProvider,
+	}
+}
+
+// Register adds a provider available under [name].
+func (r *Resolver) Register(name string, provider Provider) {
+	r.providers[name] = provider
+}
+
+// Resolve returns a credential by its reference.
+func (r *Resolver) Resolve(ctx context.Context, reference string) (Credential, error) {
+	providerName, name, ok := strings.Cut(reference, ":")
+	if !ok {
+		providerName, name = r.defaultProvider, reference
+	}
+
+	provider, ok := r.providers[providerName]
+	if !ok {
+		return Credential{}, fmt.Errorf("unknown credential provider: %s", providerName)
+	}
+
+	credential, err := provider.Credential(ctx, name)
+	if err != nil {
+		return Credential{}, fmt.Errorf("credential %s: %w", reference, err)
+	}
+
+	return credential, nil
+}
+
+var (
+	_ inventory.Provider = (*resolvingInventory)(nil)
+	_ inventory.Watcher  = (*resolvingInventory)(nil)
+)
+
+// resolvingInventory implements the [Provider] and [Watcher] interfaces.
+// It resolves credential references of target devices returned by another provider.
+type resolvingInventory struct {
+	inventory inventory.Provider
+	resolver  *Resolver
+}
+
+// NewInventory returns inventory provider that resolves credential references
+// of target devices returned by [inventory].
+// Fields set explicitly for a target device take precedence over its credential.
+func NewInventory(inventory inventory.Provider, resolver *Resolver) *resolvingInventory {
+	return &resolvingInventory{
+		inventory: inventory,
+		resolver:  resolver,
+	}
+}
+
+// Targets implements the [Provider] interface.
+// Every reference is resolved once per call, however many targets share it.
+func (r *resolvingInventory) Targets(ctx context.Context) ([]inventory.Target, error) {
+	targets, err := r.inventory.Targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]Credential)
+	resolve := func(reference string) (Credential, error) {
+		credential, ok := resolved[reference]
+		if !ok {
+			credential, err = r.resolver.Resolve(ctx, reference)
+			if err != nil {
+				return Credential{}, err
+			}
+			resolved[reference] = credential
+		}
+
+		return credential, nil
+	}
+
+	for targetIdx, target := range targets {
+		if target.Credential != "" {
+			credential, err := resolve(target.Credential)
+			if err != nil {
+				return nil, fmt.Errorf("target %s: %w", target.Hostname, err)
+			}
+			target = applyCredential(target, credential)
+		}
+
+		jumpHosts := make([]inventory.JumpHost, len(target.JumpHosts))
+		for jumpHostIdx, jumpHost := range target.JumpHosts {
+			if jumpHost.Credential != "" {
+				credential, err := resolve(jumpHost.Credential)
+				if err != nil {
+					return nil, fmt.Errorf("target %s: jump host %s: %w", target.Hostname, jumpHost.Hostname, err)
+				}
+				jumpHost = applyJumpHostCredential(jumpHost, credential)
+			}
+			jumpHosts[jumpHostIdx] = jumpHost
+		}
+		target.JumpHosts = jumpHosts
+
+		targets[targetIdx] = target
+	}
+
+	return targets, nil
+}
+
+// Watch implements the [Watcher] interface.
+// If the underlying provider cannot report changes, Watch blocks until the context is done.
+func (r *resolvingInventory) Watch(ctx context.Context, changed func()) error {
+	watcher, ok := r.inventory.(inventory.Watcher)
+	if !ok {
+		<-ctx.Done()
+		return nil
+	}
+
+	return watcher.Watch(ctx, changed)
+}
+
+// applyCredential fills authentication fields of the target that are not set explicitly.
+func applyCredential(target inventory.Target, credential Credential) inventory.Target {
+	if target.Username == "" {
+		target.Username = credential.Username
+	}
+	if target.Password == "" {
+		target.Password = credential.Password
+	}
+	if target.PrivateKeyPath == "" {
+		target.PrivateKeyPath = credential.PrivateKeyPath
+	}
+	if target.Passphrase == "" {
+		target.Passphrase = credential.Passphrase
+	}
+
+	return target
+}
+
+// applyJumpHostCredential fills authentication fields of the jump host that are not set explicitly.
+func applyJumpHostCredential(jumpHost inventory.JumpHost, credential Credential) inventory.JumpHost {
+	if jumpHost.Username == "" {
+		jumpHost.Username = credential.Username
+	}
+	if jumpHost.Password == "" {
+		jumpHost.Password = credential.Password
+	}
+	if jumpHost.PrivateKeyPath == "" {
+		jumpHost.PrivateKeyPath = credential.PrivateKeyPath
+	}
+	if jumpHost.Passphrase == "" {
+		jumpHost.Passphrase = credential.Passphrase
+	}
+
+	return jumpHost
+}
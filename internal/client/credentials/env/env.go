@@ -0,0 +1,75 @@
+// Package env defines credential provider that reads credentials from environment variables.
+package env
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sudeeya/net-monitor/internal/client/credentials"
+)
+
+// Suffixes of environment variables forming a credential.
+const (
+	usernameSuffix       = "_USERNAME"
+	passwordSuffix       = "_PASSWORD"
+	privateKeyPathSuffix = "_PRIVATE_KEY_PATH"
+	passphraseSuffix     = "_PASSPHRASE"
+)
+
+var _ credentials.Provider = (*env)(nil)
+
+// env implements the [Provider] interface.
+type env struct {
+	prefix string
+}
+
+// NewEnv returns env object.
+// A credential named "core-routers" is read from variables
+// [prefix]CORE_ROUTERS_USERNAME, [prefix]CORE_ROUTERS_PASSWORD,
+// [prefix]CORE_ROUTERS_PRIVATE_KEY_PATH and [prefix]CORE_ROUTERS_PASSPHRASE.
+func NewEnv(prefix string) *env {
+	return &env{
+		prefix: prefix,
+	}
+}
+
+// Credential implements the [Provider] interface.
+// Returns an error if none of the variables is set.
+func (e *env) Credential(_ context.Context, name string) (credentials.Credential, error) {
+	base := e.prefix + toVariableName(name)
+
+	var found bool
+	lookup := func(suffix string) string {
+		value, ok := os.LookupEnv(base + suffix)
+		found = found || ok
+		return value
+	}
+
+	credential := credentials.Credential{
+		Username:       lookup(usernameSuffix),
+		Password:       lookup(passwordSuffix),
+		PrivateKeyPath: lookup(privateKeyPathSuffix),
+		Passphrase:     lookup(passphraseSuffix),
+	}
+	if !found {
+		return credentials.Credential{}, fmt.Errorf("no environment variables with prefix %s", base)
+	}
+
+	return credential, nil
+}
+
+// toVariableName converts a credential name to a part of an environment variable name.
+func toVariableName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
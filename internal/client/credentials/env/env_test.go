@@ -0,0 +1,47 @@
+package env
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sudeeya/net-monitor/internal/client/credentials"
+)
+
+func TestCredential(t *testing.T) {
+	t.Setenv("CREDENTIAL_CORE_ROUTERS_USERNAME", "admin")
+	t.Setenv("CREDENTIAL_CORE_ROUTERS_PASSWORD", "secret")
+	t.Setenv("CREDENTIAL_EDGE_1_PRIVATE_KEY_PATH", "/keys/edge")
+	t.Setenv("CREDENTIAL_EDGE_1_PASSPHRASE", "")
+
+	tests := []struct {
+		name    string
+		want    credentials.Credential
+		wantErr bool
+	}{
+		{
+			name: "core-routers",
+			want: credentials.Credential{Username: "admin", Password: "secret"},
+		},
+		{
+			name: "edge.1",
+			want: credentials.Credential{PrivateKeyPath: "/keys/edge"},
+		},
+		{
+			name:    "missing",
+			wantErr: true,
+		},
+	}
+
+	e := NewEnv("CREDENTIAL_")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.Credential(context.Background(), tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Package keystore defines credential provider that reads credentials from an encrypted file.
+//
+// The file stores a json object mapping credential names to credentials.
+// The object is encrypted with AES-256-GCM using a key derived from a passphrase with scrypt.
+package keystore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/sudeeya/net-monitor/internal/client/credentials"
+)
+
+// Key derivation parameters.
+const (
+	version   = 1
+	saltSize  = 16
+	keySize   = 32
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	kdfScrypt = "scrypt"
+)
+
+var _ credentials.Provider = (*keystore)(nil)
+
+// keystore implements the [Provider] interface.
+type keystore struct {
+	credentials map[string]credentials.Credential
+}
+
+// sealedFile describes the keystore file.
+type sealedFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// NewKeystore returns keystore object.
+// The function reads and decrypts the keystore file.
+func NewKeystore(path, passphrase string) (*keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := Open(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keystore{
+		credentials: creds,
+	}, nil
+}
+
+// Credential implements the [Provider] interface.
+func (k *keystore) Credential(_ context.Context, name string) (credentials.Credential, error) {
+	credential, ok := k.credentials[name]
+	if !ok {
+		return credentials.Credential{}, fmt.Errorf("not found in keystore")
+	}
+
+	return credential, nil
+}
+
+// Seal encrypts credentials with a key derived from [passphrase] and returns the keystore file contents.
+func Seal(creds map[string]credentials.Credential, passphrase string) ([]byte, error) {
+	plaintext, err := json.Marshal(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(sealedFile{
+		Version:    version,
+		KDF:        kdfScrypt,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+	}, "", "    ")
+}
+
+// Open decrypts the keystore file contents with a key derived from [passphrase].
+func Open(data []byte, passphrase string) (map[string]credentials.Credential, error) {
+	var file sealedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if file.Version != version || file.KDF != kdfScrypt {
+		return nil, fmt.Errorf("unsupported keystore version %d with kdf %s", file.Version, file.KDF)
+	}
+
+	aead, err := newAEAD(passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: wrong passphrase or corrupted file")
+	}
+
+	creds := make(map[string]credentials.Credential)
+	if err := json.Unmarshal(plaintext, &creds); err != nil {
+		return nil, err
+	}
+
+	return creds, nil
+}
+
+// newAEAD derives a key from [passphrase] and returns AES-GCM cipher.
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
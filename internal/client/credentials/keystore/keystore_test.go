@@ -0,0 +1,77 @@
+package keystore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sudeeya/net-monitor/internal/client/credentials"
+)
+
+var testCredentials = map[string]credentials.Credential{
+	"core": {Username: "admin", Password: "secret"},
+	"edge": {Username: "ops", PrivateKeyPath: "/keys/edge", Passphrase: "key-secret"},
+}
+
+func TestSealOpen(t *testing.T) {
+	sealed, err := Seal(testCredentials, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opened, err := Open(sealed, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(opened, testCredentials) {
+		t.Errorf("got %+v, want %+v", opened, testCredentials)
+	}
+}
+
+func TestOpenWrongPassphrase(t *testing.T) {
+	sealed, err := Seal(testCredentials, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(sealed, "wrong"); err == nil {
+		t.Error("expected an error for a wrong passphrase")
+	}
+}
+
+func TestOpenUnsupportedVersion(t *testing.T) {
+	if _, err := Open([]byte(`{"version": 2, "kdf": "scrypt"}`), "passphrase"); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+}
+
+func TestKeystoreCredential(t *testing.T) {
+	sealed, err := Seal(testCredentials, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	if err := os.WriteFile(path, sealed, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := NewKeystore(path, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := k.Credential(context.Background(), "edge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != testCredentials["edge"] {
+		t.Errorf("got %+v, want %+v", got, testCredentials["edge"])
+	}
+
+	if _, err := k.Credential(context.Background(), "missing"); err == nil {
+		t.Error("expected an error for a missing credential")
+	}
+}
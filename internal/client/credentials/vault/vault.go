@@ -0,0 +1,132 @@
+// Package vault defines credential provider that reads credentials from HashiCorp Vault's KV secrets engine.
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/client/credentials"
+)
+
+const limitInSeconds = 10
+
+// Supported versions of the KV secrets engine.
+const (
+	KVv1 = 1
+	KVv2 = 2
+)
+
+var _ credentials.Provider = (*vault)(nil)
+
+// Config describes Vault connection.
+type Config struct {
+	// Vault address, for example https://vault.example.com:8200.
+	Addr string
+
+	// Vault token.
+	Token string
+
+	// Mount path of the KV secrets engine, for example "secret".
+	Mount string
+
+	// Path prepended to credential names, for example "net-monitor".
+	PathPrefix string
+
+	// Version of the KV secrets engine.
+	KVVersion int
+}
+
+// vault implements the [Provider] interface.
+type vault struct {
+	cfg    Config
+	client *http.Client
+}
+
+// secret is an auxiliary structure into which the Vault response is written.
+type secret struct {
+	Data json.RawMessage `json:"data"`
+}
+
+// kvV2Data is an auxiliary structure into which the KV version 2 secret data is written.
+type kvV2Data struct {
+	Data credentials.Credential `json:"data"`
+}
+
+// NewVault returns vault object.
+func NewVault(cfg Config) (*vault, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("vault address is not specified")
+	}
+
+	if cfg.KVVersion != KVv1 && cfg.KVVersion != KVv2 {
+		return nil, fmt.Errorf("unsupported kv version: %d", cfg.KVVersion)
+	}
+
+	return &vault{
+		cfg:    cfg,
+		client: &http.Client{Timeout: limitInSeconds * time.Second},
+	}, nil
+}
+
+// Credential implements the [Provider] interface.
+// The secret must contain "username" and any of "password", "private_key_path" and "passphrase" keys.
+func (v *vault) Credential(ctx context.Context, name string) (credentials.Credential, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, v.secretURL(name), nil)
+	if err != nil {
+		return credentials.Credential{}, err
+	}
+	request.Header.Set("X-Vault-Token", v.cfg.Token)
+
+	response, err := v.client.Do(request)
+	if err != nil {
+		return credentials.Credential{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return credentials.Credential{}, fmt.Errorf("vault responded with status %s", response.Status)
+	}
+
+	var s secret
+	if err := json.NewDecoder(response.Body).Decode(&s); err != nil {
+		return credentials.Credential{}, err
+	}
+
+	var credential credentials.Credential
+	switch v.cfg.KVVersion {
+	case KVv1:
+		if err := json.Unmarshal(s.Data, &credential); err != nil {
+			return credentials.Credential{}, err
+		}
+	default:
+		var data kvV2Data
+		if err := json.Unmarshal(s.Data, &data); err != nil {
+			return credentials.Credential{}, err
+		}
+		credential = data.Data
+	}
+
+	return credential, nil
+}
+
+// secretURL returns URL of the secret storing the credential.
+func (v *vault) secretURL(name string) string {
+	segments := []string{strings.Trim(v.cfg.Mount, "/")}
+	if v.cfg.KVVersion == KVv2 {
+		segments = append(segments, "data")
+	}
+	if prefix := strings.Trim(v.cfg.PathPrefix, "/"); prefix != "" {
+		segments = append(segments, prefix)
+	}
+	// Names of nested secrets contain slashes, so each segment is escaped separately.
+	for _, segment := range strings.Split(strings.Trim(name, "/"), "/") {
+		segments = append(segments, url.PathEscape(segment))
+	}
+
+	return strings.TrimRight(v.cfg.Addr, "/") + "/v1/" + strings.Join(segments, "/")
+}
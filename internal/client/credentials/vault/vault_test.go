@@ -0,0 +1,153 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sudeeya/net-monitor/internal/client/credentials"
+)
+
+// newVaultServer returns a Vault stand-in serving the secrets by their raw request paths
+// in the format of the KV secrets engine of the version.
+func newVaultServer(t *testing.T, token string, kvVersion int, secrets map[string]credentials.Credential) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+
+		credential, ok := secrets[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		var data any = credential
+		if kvVersion == KVv2 {
+			data = map[string]any{
+				"data":     credential,
+				"metadata": map[string]any{"version": 3},
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]any{"data": data}); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCredential(t *testing.T) {
+	credential := credentials.Credential{Username: "admin", Password: "secret"}
+
+	tests := []struct {
+		name       string
+		kvVersion  int
+		pathPrefix string
+		secret     string
+		path       string
+	}{
+		{
+			name:      "kv v1",
+			kvVersion: KVv1,
+			secret:    "core",
+			path:      "/v1/secret/core",
+		},
+		{
+			name:      "kv v2",
+			kvVersion: KVv2,
+			secret:    "core",
+			path:      "/v1/secret/data/core",
+		},
+		{
+			name:       "kv v1 nested with prefix",
+			kvVersion:  KVv1,
+			pathPrefix: "/net-monitor/",
+			secret:     "dc1/core",
+			path:       "/v1/secret/net-monitor/dc1/core",
+		},
+		{
+			name:       "kv v2 nested with prefix",
+			kvVersion:  KVv2,
+			pathPrefix: "net-monitor",
+			secret:     "dc1/core",
+			path:       "/v1/secret/data/net-monitor/dc1/core",
+		},
+		{
+			name:      "segments are escaped",
+			kvVersion: KVv2,
+			secret:    "dc 1/core?",
+			path:      "/v1/secret/data/dc%201/core%3F",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newVaultServer(t, "token", tt.kvVersion, map[string]credentials.Credential{tt.path: credential})
+
+			v, err := NewVault(Config{
+				Addr:       server.URL + "/",
+				Token:      "token",
+				Mount:      "/secret/",
+				PathPrefix: tt.pathPrefix,
+				KVVersion:  tt.kvVersion,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := v.Credential(context.Background(), tt.secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != credential {
+				t.Errorf("got %+v, want %+v", got, credential)
+			}
+		})
+	}
+}
+
+func TestCredentialErrors(t *testing.T) {
+	server := newVaultServer(t, "token", KVv2, map[string]credentials.Credential{
+		"/v1/secret/data/core": {Username: "admin"},
+	})
+
+	tests := []struct {
+		name   string
+		token  string
+		secret string
+	}{
+		{name: "wrong token", token: "wrong", secret: "core"},
+		{name: "missing secret", token: "token", secret: "edge"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewVault(Config{Addr: server.URL, Token: tt.token, Mount: "secret", KVVersion: KVv2})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := v.Credential(context.Background(), tt.secret); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewVaultValidatesConfig(t *testing.T) {
+	if _, err := NewVault(Config{KVVersion: KVv2}); err == nil {
+		t.Error("expected an error without address")
+	}
+	if _, err := NewVault(Config{Addr: "http://localhost:8200", KVVersion: 3}); err == nil {
+		t.Error("expected an error for an unsupported kv version")
+	}
+}
@@ -0,0 +1,135 @@
+// Package file defines inventory provider that reads target devices from a json file.
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/sudeeya/net-monitor/internal/client/inventory"
+)
+
+// Editors and deployment tools often write a file in several steps,
+// so events that arrive within this period are reported once.
+const debounceInMilliseconds = 500
+
+var (
+	_ inventory.Provider = (*file)(nil)
+	_ inventory.Watcher  = (*file)(nil)
+)
+
+// file implements the [Provider] interface.
+type file struct {
+	path string
+}
+
+// NewFile returns file object that reads target devices from [path].
+func NewFile(path string) *file {
+	return &file{
+		path: path,
+	}
+}
+
+// targetsFile describes the file with groups of target devices.
+// A file containing only a list of target devices is also accepted.
+type targetsFile struct {
+	// Defaults applied to the targets of each group.
+	Groups map[string]inventory.Target `json:"groups"`
+
+	// Shared lists of jump hosts referenced by targets and groups.
+	Bastions map[string][]inventory.JumpHost `json:"bastions"`
+
+	Targets []inventory.Target `json:"targets"`
+}
+
+// Targets implements the [Provider] interface.
+// The file is read on every call, so changes to it are picked up.
+func (f *file) Targets(_ context.Context) ([]inventory.Target, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var tf targetsFile
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(data, &tf.Targets)
+	} else {
+		err = json.Unmarshal(data, &tf)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]inventory.Target, len(tf.Targets))
+	for targetIdx, target := range tf.Targets {
+		if target.Group != "" {
+			defaults, ok := tf.Groups[target.Group]
+			if !ok {
+				return nil, fmt.Errorf("target %s: unknown group %s", target.Hostname, target.Group)
+			}
+			target = target.ApplyDefaults(defaults)
+		}
+
+		if target.Bastion != "" && len(target.JumpHosts) == 0 {
+			jumpHosts, ok := tf.Bastions[target.Bastion]
+			if !ok {
+				return nil, fmt.Errorf("target %s: unknown bastion %s", target.Hostname, target.Bastion)
+			}
+			target.JumpHosts = append([]inventory.JumpHost(nil), jumpHosts...)
+		}
+
+		targets[targetIdx] = target
+	}
+
+	return targets, nil
+}
+
+// Watch implements the [Watcher] interface.
+// The directory containing the file is watched, so the file may be replaced rather than modified in place.
+func (f *file) Watch(ctx context.Context, changed func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	path := filepath.Clean(f.path)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	debounce := time.NewTimer(0)
+	<-debounce.C
+
+	for {
+		select {
+		case <-ctx.Done():
+			debounce.Stop()
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) != path {
+				continue
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			debounce.Reset(debounceInMilliseconds * time.Millisecond)
+		case <-debounce.C:
+			changed()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		}
+	}
+}
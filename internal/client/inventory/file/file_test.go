@@ -0,0 +1,171 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sudeeya/net-monitor/internal/client/inventory"
+)
+
+// bastions of the test file, referenced by groups and targets.
+const testBastions = `"bastions": {
+		"dc1": [
+			{"hostname": "bastion1.dc1", "username": "jump", "credential": "bastion"},
+			{"hostname": "bastion2.dc1", "port": 2222, "username": "jump", "credential": "bastion"}
+		],
+		"dc2": [{"hostname": "bastion.dc2", "username": "jump", "no_strict_key": true}]
+	}`
+
+var dc1JumpHosts = []inventory.JumpHost{
+	{Hostname: "bastion1.dc1", Username: "jump", Credential: "bastion"},
+	{Hostname: "bastion2.dc1", Port: 2222, Username: "jump", Credential: "bastion"},
+}
+
+// writeFile writes the data to a targets file and returns its path.
+func writeFile(t *testing.T, data string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "targets.json")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []inventory.Target
+		wantErr bool
+	}{
+		{
+			name: "list of targets",
+			data: `[{"os": "nokia_srlinux", "hostname": "srl1", "username": "admin", "password": "secret"}]`,
+			want: []inventory.Target{{OS: "nokia_srlinux", Hostname: "srl1", Username: "admin", Password: "secret"}},
+		},
+		{
+			name: "group defaults",
+			data: `{
+				"groups": {
+					"srlinux": {"os": "nokia_srlinux", "username": "admin", "credential": "srl", "no_strict_key": true}
+				},
+				"targets": [
+					{"hostname": "srl1", "group": "srlinux"},
+					{"hostname": "srl2", "group": "srlinux", "username": "operator"},
+					{"hostname": "ceos1", "os": "arista_eos", "username": "admin", "password": "secret"}
+				]
+			}`,
+			want: []inventory.Target{
+				{OS: "nokia_srlinux", Hostname: "srl1", Username: "admin", Credential: "srl", NoStrictKey: true, Group: "srlinux"},
+				{OS: "nokia_srlinux", Hostname: "srl2", Username: "operator", Credential: "srl", NoStrictKey: true, Group: "srlinux"},
+				{OS: "arista_eos", Hostname: "ceos1", Username: "admin", Password: "secret"},
+			},
+		},
+		{
+			name: "bastions of groups and targets",
+			data: `{
+				"groups": {
+					"dc1": {"os": "nokia_srlinux", "credential": "srl", "bastion": "dc1"}
+				},
+				` + testBastions + `,
+				"targets": [
+					{"hostname": "srl1", "group": "dc1"},
+					{"hostname": "srl2", "group": "dc1", "bastion": "dc2"},
+					{"hostname": "srl3", "os": "nokia_srlinux", "bastion": "dc2"}
+				]
+			}`,
+			want: []inventory.Target{
+				{OS: "nokia_srlinux", Hostname: "srl1", Credential: "srl", Group: "dc1", Bastion: "dc1", JumpHosts: dc1JumpHosts},
+				{
+					OS: "nokia_srlinux", Hostname: "srl2", Credential: "srl", Group: "dc1", Bastion: "dc2",
+					JumpHosts: []inventory.JumpHost{{Hostname: "bastion.dc2", Username: "jump", NoStrictKey: true}},
+				},
+				{
+					OS: "nokia_srlinux", Hostname: "srl3", Bastion: "dc2",
+					JumpHosts: []inventory.JumpHost{{Hostname: "bastion.dc2", Username: "jump", NoStrictKey: true}},
+				},
+			},
+		},
+		{
+			name: "jump hosts of the target take precedence over the bastion",
+			data: `{
+				` + testBastions + `,
+				"targets": [
+					{"hostname": "srl1", "bastion": "dc1", "jump_hosts": [{"hostname": "bastion.lab"}]}
+				]
+			}`,
+			want: []inventory.Target{
+				{Hostname: "srl1", Bastion: "dc1", JumpHosts: []inventory.JumpHost{{Hostname: "bastion.lab"}}},
+			},
+		},
+		{
+			name: "unknown bastion",
+			data: `{
+				` + testBastions + `,
+				"targets": [{"hostname": "srl1", "bastion": "dc3"}]
+			}`,
+			wantErr: true,
+		},
+		{
+			name: "unknown bastion of a group",
+			data: `{
+				"groups": {"dc3": {"bastion": "dc3"}},
+				"targets": [{"hostname": "srl1", "group": "dc3"}]
+			}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown group",
+			data:    `{"targets": [{"hostname": "srl1", "group": "srlinux"}]}`,
+			wantErr: true,
+		},
+		{name: "invalid json", data: `{"targets": [`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := NewFile(writeFile(t, tt.data)).Targets(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want an error, got %+v", targets)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(targets, tt.want) {
+				t.Errorf("targets = %+v, want %+v", targets, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetsDoNotShareBastions(t *testing.T) {
+	path := writeFile(t, `{
+		`+testBastions+`,
+		"targets": [{"hostname": "srl1", "bastion": "dc1"}, {"hostname": "srl2", "bastion": "dc1"}]
+	}`)
+
+	targets, err := NewFile(path).Targets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Jump hosts are copied from the bastion, so a change to those of one target does not affect another.
+	targets[0].JumpHosts[0].Password = "resolved"
+	if targets[1].JumpHosts[0].Password != "" {
+		t.Errorf("jump hosts of %s changed with those of %s", targets[1].Hostname, targets[0].Hostname)
+	}
+}
+
+func TestTargetsMissingFile(t *testing.T) {
+	if _, err := NewFile(filepath.Join(t.TempDir(), "targets.json")).Targets(context.Background()); err == nil {
+		t.Error("want an error")
+	}
+}
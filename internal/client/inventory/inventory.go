@@ -0,0 +1,88 @@
+// Package inventory defines the source of target devices.
+package inventory
+
+import "context"
+
+// Target defines device OS and information needed for an SSH connection.
+type Target struct {
+	OS             string `json:"os"`
+	Hostname       string `json:"hostname"`
+	Username       string `json:"username"`
+	Password       string `json:"password"`
+	PrivateKeyPath string `json:"private_key_path"`
+	Passphrase     string `json:"passphrase"`
+	NoStrictKey    bool   `json:"no_strict_key"`
+
+	// Reference to a credential used instead of plaintext authentication fields.
+	Credential string `json:"credential"`
+
+	// Group whose defaults are applied to the target.
+	Group string `json:"group"`
+
+	// Name of a shared bastion profile providing jump hosts.
+	Bastion string `json:"bastion"`
+
+	// Jump hosts the SSH session is tunneled through, in the order of connection.
+	JumpHosts []JumpHost `json:"jump_hosts"`
+}
+
+// JumpHost defines a host the SSH session to a target device is tunneled through.
+type JumpHost struct {
+	Hostname       string `json:"hostname"`
+	Port           int    `json:"port"`
+	Username       string `json:"username"`
+	Password       string `json:"password"`
+	PrivateKeyPath string `json:"private_key_path"`
+	Passphrase     string `json:"passphrase"`
+	NoStrictKey    bool   `json:"no_strict_key"`
+
+	// Reference to a credential used instead of plaintext authentication fields.
+	Credential string `json:"credential"`
+}
+
+// ApplyDefaults fills fields of the target that are not set with values from [defaults].
+func (t Target) ApplyDefaults(defaults Target) Target {
+	if t.OS == "" {
+		t.OS = defaults.OS
+	}
+	if t.Username == "" {
+		t.Username = defaults.Username
+	}
+	if t.Password == "" {
+		t.Password = defaults.Password
+	}
+	if t.PrivateKeyPath == "" {
+		t.PrivateKeyPath = defaults.PrivateKeyPath
+	}
+	if t.Passphrase == "" {
+		t.Passphrase = defaults.Passphrase
+	}
+	if !t.NoStrictKey {
+		t.NoStrictKey = defaults.NoStrictKey
+	}
+	if t.Credential == "" {
+		t.Credential = defaults.Credential
+	}
+	if t.Bastion == "" {
+		t.Bastion = defaults.Bastion
+	}
+	if len(t.JumpHosts) == 0 {
+		t.JumpHosts = defaults.JumpHosts
+	}
+
+	return t
+}
+
+// Provider describes a source of target devices.
+type Provider interface {
+	// Targets returns the current list of target devices.
+	// Returns an error if the list could not be obtained.
+	Targets(ctx context.Context) ([]Target, error)
+}
+
+// Watcher is implemented by providers that can report changes of the list of target devices.
+type Watcher interface {
+	// Watch calls [changed] every time the list of target devices may have changed.
+	// It blocks until the context is done or watching fails.
+	Watch(ctx context.Context, changed func()) error
+}
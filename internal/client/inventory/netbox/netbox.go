@@ -0,0 +1,246 @@
+// Package netbox defines inventory provider that queries target devices from NetBox.
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/client/inventory"
+)
+
+const (
+	limitInSeconds = 30
+	pageSize       = 200
+	devicesPath    = "/api/dcim/devices/"
+)
+
+// DefaultPlatforms maps NetBox platform slugs to operating systems known to the snapper.
+var DefaultPlatforms = map[string]string{
+	"nokia-srlinux": "nokia_srlinux",
+	"nokia_srlinux": "nokia_srlinux",
+	"srlinux":       "nokia_srlinux",
+	"sr-linux":      "nokia_srlinux",
+}
+
+var _ inventory.Provider = (*netBox)(nil)
+
+// Config describes NetBox connection and device filters.
+type Config struct {
+	// NetBox base URL, for example https://netbox.example.com.
+	URL string
+
+	// API token.
+	Token string
+
+	// Filters. Devices must match at least one value of every non-empty filter.
+	Sites     []string
+	Roles     []string
+	Tags      []string
+	Platforms []string
+
+	// Mapping of NetBox platform slugs to operating systems.
+	// If nil, [DefaultPlatforms] is used.
+	PlatformMap map[string]string
+
+	// If set, the primary IP address is used to connect instead of the device name.
+	UsePrimaryIP bool
+
+	// Connection settings applied to every device found in NetBox.
+	Defaults inventory.Target
+}
+
+// netBox implements the [Provider] interface.
+type netBox struct {
+	logger *zap.Logger
+	cfg    Config
+	client *http.Client
+}
+
+// device is an auxiliary structure into which the NetBox response is written.
+type device struct {
+	Name     string `json:"name"`
+	Platform *struct {
+		Slug string `json:"slug"`
+	} `json:"platform"`
+	PrimaryIP *struct {
+		Address string `json:"address"`
+	} `json:"primary_ip"`
+}
+
+// devicesPage is an auxiliary structure into which the NetBox response is written.
+type devicesPage struct {
+	Next    string   `json:"next"`
+	Results []device `json:"results"`
+}
+
+// NewNetBox returns netBox object to query target devices from NetBox.
+func NewNetBox(logger *zap.Logger, cfg Config) (*netBox, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("netbox url is not specified")
+	}
+
+	if cfg.PlatformMap == nil {
+		cfg.PlatformMap = DefaultPlatforms
+	}
+
+	return &netBox{
+		logger: logger,
+		cfg:    cfg,
+		client: &http.Client{Timeout: limitInSeconds * time.Second},
+	}, nil
+}
+
+// ParsePlatformMap parses a list of "slug:os" pairs separated by commas.
+func ParsePlatformMap(s string) (map[string]string, error) {
+	platforms := make(map[string]string)
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		slug, os, ok := strings.Cut(pair, ":")
+		if !ok || slug == "" || os == "" {
+			return nil, fmt.Errorf("invalid platform mapping: %s", pair)
+		}
+
+		platforms[strings.TrimSpace(slug)] = strings.TrimSpace(os)
+	}
+
+	return platforms, nil
+}
+
+// Targets implements the [Provider] interface.
+// Devices without a mapped platform or an address to connect to are skipped.
+func (n *netBox) Targets(ctx context.Context) ([]inventory.Target, error) {
+	n.logger.Info("Querying devices from NetBox")
+
+	devices, err := n.devices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]inventory.Target, 0, len(devices))
+	for _, d := range devices {
+		if d.Platform == nil {
+			n.logger.Sugar().Errorf("Skipping NetBox device %s: platform is not set", d.Name)
+			continue
+		}
+
+		os, ok := n.cfg.PlatformMap[d.Platform.Slug]
+		if !ok {
+			n.logger.Sugar().Errorf("Skipping NetBox device %s: unknown platform %s", d.Name, d.Platform.Slug)
+			continue
+		}
+
+		hostname := d.Name
+		if n.cfg.UsePrimaryIP && d.PrimaryIP != nil {
+			prefix, err := netip.ParsePrefix(d.PrimaryIP.Address)
+			if err != nil {
+				n.logger.Sugar().Errorf("Skipping NetBox device %s: %s", d.Name, err.Error())
+				continue
+			}
+			hostname = prefix.Addr().String()
+		}
+		if hostname == "" {
+			n.logger.Error("Skipping NetBox device without name")
+			continue
+		}
+
+		target := n.cfg.Defaults
+		target.OS = os
+		target.Hostname = hostname
+
+		targets = append(targets, target)
+	}
+
+	n.logger.Sugar().Infof("NetBox returned %d target devices", len(targets))
+
+	return targets, nil
+}
+
+// devices returns all devices matching the filters following NetBox pagination.
+func (n *netBox) devices(ctx context.Context) ([]device, error) {
+	next, err := n.devicesURL()
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]device, 0)
+	for next != "" {
+		page, err := n.getPage(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+
+		devices = append(devices, page.Results...)
+		next = page.Next
+	}
+
+	return devices, nil
+}
+
+// devicesURL returns URL of the first page of devices matching the filters.
+func (n *netBox) devicesURL() (string, error) {
+	u, err := url.Parse(strings.TrimRight(n.cfg.URL, "/") + devicesPath)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("limit", fmt.Sprint(pageSize))
+	for _, site := range n.cfg.Sites {
+		query.Add("site", site)
+	}
+	for _, role := range n.cfg.Roles {
+		query.Add("role", role)
+	}
+	for _, tag := range n.cfg.Tags {
+		query.Add("tag", tag)
+	}
+	for _, platform := range n.cfg.Platforms {
+		query.Add("platform", platform)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// getPage requests a single page of devices.
+func (n *netBox) getPage(ctx context.Context, pageURL string) (*devicesPage, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Accept", "application/json")
+	if n.cfg.Token != "" {
+		request.Header.Set("Authorization", "Token "+n.cfg.Token)
+	}
+
+	response, err := n.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("netbox responded with status %s", response.Status)
+	}
+
+	var page devicesPage
+	if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
@@ -0,0 +1,239 @@
+package netbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/client/inventory"
+)
+
+// newNetBoxServer returns a NetBox stand-in that serves the pages in order,
+// linking each page to the next one, and records the queries of the requests.
+func newNetBoxServer(t *testing.T, token string, pages ...[]device) (*httptest.Server, *[]map[string][]string) {
+	t.Helper()
+
+	queries := make([]map[string][]string, 0)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != devicesPath {
+			http.NotFound(w, r)
+			return
+		}
+		if token != "" && r.Header.Get("Authorization") != "Token "+token {
+			http.Error(w, "invalid token", http.StatusForbidden)
+			return
+		}
+		queries = append(queries, r.URL.Query())
+
+		pageIdx := 0
+		if param := r.URL.Query().Get("page"); param != "" {
+			var err error
+			if pageIdx, err = strconv.Atoi(param); err != nil {
+				t.Errorf("invalid page: %s", param)
+			}
+		}
+
+		page := devicesPage{Results: pages[pageIdx]}
+		if pageIdx+1 < len(pages) {
+			page.Next = server.URL + devicesPath + "?page=" + strconv.Itoa(pageIdx+1)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(page); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &queries
+}
+
+func newDevice(name, platform, primaryIP string) device {
+	d := device{Name: name}
+	if platform != "" {
+		d.Platform = &struct {
+			Slug string `json:"slug"`
+		}{Slug: platform}
+	}
+	if primaryIP != "" {
+		d.PrimaryIP = &struct {
+			Address string `json:"address"`
+		}{Address: primaryIP}
+	}
+
+	return d
+}
+
+func TestTargetsFollowsPagination(t *testing.T) {
+	server, queries := newNetBoxServer(t, "secret",
+		[]device{newDevice("leaf1", "srlinux", ""), newDevice("leaf2", "nokia-srlinux", "")},
+		[]device{newDevice("spine1", "sr-linux", "")},
+		[]device{newDevice("spine2", "nokia_srlinux", "")},
+	)
+
+	n, err := NewNetBox(zap.NewNop(), Config{
+		URL:      server.URL + "/",
+		Token:    "secret",
+		Defaults: inventory.Target{Username: "admin", Credential: "lab"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targets, err := n.Targets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []inventory.Target{
+		{OS: "nokia_srlinux", Hostname: "leaf1", Username: "admin", Credential: "lab"},
+		{OS: "nokia_srlinux", Hostname: "leaf2", Username: "admin", Credential: "lab"},
+		{OS: "nokia_srlinux", Hostname: "spine1", Username: "admin", Credential: "lab"},
+		{OS: "nokia_srlinux", Hostname: "spine2", Username: "admin", Credential: "lab"},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("targets = %+v, want %+v", targets, want)
+	}
+	if len(*queries) != 3 {
+		t.Errorf("requested %d pages, want 3", len(*queries))
+	}
+}
+
+func TestTargetsSendsFilters(t *testing.T) {
+	server, queries := newNetBoxServer(t, "", []device{})
+
+	n, err := NewNetBox(zap.NewNop(), Config{
+		URL:       server.URL,
+		Sites:     []string{"dc1", "dc2"},
+		Roles:     []string{"leaf"},
+		Tags:      []string{"monitored"},
+		Platforms: []string{"srlinux"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := n.Targets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*queries) != 1 {
+		t.Fatalf("requested %d pages, want 1", len(*queries))
+	}
+	query := (*queries)[0]
+	for param, want := range map[string][]string{
+		"site":     {"dc1", "dc2"},
+		"role":     {"leaf"},
+		"tag":      {"monitored"},
+		"platform": {"srlinux"},
+		"limit":    {"200"},
+	} {
+		if !reflect.DeepEqual(query[param], want) {
+			t.Errorf("%s = %v, want %v", param, query[param], want)
+		}
+	}
+}
+
+func TestTargetsUsePrimaryIP(t *testing.T) {
+	server, _ := newNetBoxServer(t, "", []device{
+		newDevice("leaf1", "srlinux", "192.0.2.1/24"),
+		newDevice("leaf2", "srlinux", "2001:db8::2/64"),
+		newDevice("leaf3", "srlinux", ""),
+		newDevice("leaf4", "srlinux", "not-an-address"),
+		newDevice("leaf5", "", "192.0.2.5/24"),
+		newDevice("leaf6", "eos", "192.0.2.6/24"),
+	})
+
+	n, err := NewNetBox(zap.NewNop(), Config{URL: server.URL, UsePrimaryIP: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	targets, err := n.Targets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostnames := make([]string, len(targets))
+	for targetIdx, target := range targets {
+		hostnames[targetIdx] = target.Hostname
+	}
+	// Devices without a primary IP keep their name; invalid addresses and unknown platforms are skipped.
+	want := []string{"192.0.2.1", "2001:db8::2", "leaf3"}
+	if !reflect.DeepEqual(hostnames, want) {
+		t.Errorf("hostnames = %v, want %v", hostnames, want)
+	}
+}
+
+func TestTargetsFailsOnErrorStatus(t *testing.T) {
+	server, _ := newNetBoxServer(t, "secret", []device{})
+
+	n, err := NewNetBox(zap.NewNop(), Config{URL: server.URL, Token: "wrong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := n.Targets(context.Background()); err == nil {
+		t.Error("expected an error for a rejected token")
+	}
+}
+
+func TestNewNetBoxRequiresURL(t *testing.T) {
+	if _, err := NewNetBox(zap.NewNop(), Config{}); err == nil {
+		t.Error("expected an error without url")
+	}
+}
+
+func TestParsePlatformMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			input: "",
+			want:  map[string]string{},
+		},
+		{
+			name:  "pairs with spaces",
+			input: " srl:nokia_srlinux , eos : arista_eos,",
+			want:  map[string]string{"srl": "nokia_srlinux", "eos": "arista_eos"},
+		},
+		{
+			name:    "missing separator",
+			input:   "srl",
+			wantErr: true,
+		},
+		{
+			name:    "missing os",
+			input:   "srl:",
+			wantErr: true,
+		},
+		{
+			name:    "missing slug",
+			input:   ":nokia_srlinux",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePlatformMap(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
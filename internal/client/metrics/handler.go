@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// Endpoints.
+const (
+	healthzEndpoint = "/healthz"
+	readyzEndpoint  = "/readyz"
+	metricsEndpoint = "/metrics"
+)
+
+// Handler returns an http.Handler serving the health and readiness checks and the metrics.
+// The health check succeeds while the client is running; the readiness check fails as [Metrics.Ready] does.
+func (m *Metrics) Handler(logger *zap.Logger) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(healthzEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(logger, w, http.StatusOK, "ok")
+	})
+
+	mux.HandleFunc(readyzEndpoint, func(w http.ResponseWriter, r *http.Request) {
+		if err := m.Ready(); err != nil {
+			writeStatus(logger, w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeStatus(logger, w, http.StatusOK, "ok")
+	})
+
+	mux.Handle(metricsEndpoint, promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorLog:      zap.NewStdLog(logger),
+		ErrorHandling: promhttp.ContinueOnError,
+	}))
+
+	return mux
+}
+
+// writeStatus writes the status code and the message as plain text.
+func writeStatus(logger *zap.Logger, w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	if _, err := w.Write([]byte(message + "\n")); err != nil {
+		logger.Error(err.Error())
+	}
+}
@@ -0,0 +1,153 @@
+// Package metrics defines Prometheus metrics and health checks of the client.
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/sudeeya/net-monitor/internal/client/client"
+	"github.com/sudeeya/net-monitor/internal/client/snapper"
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+// namespace prefixes the names of all metrics.
+const namespace = "netmonitor_client"
+
+var (
+	_ snapper.Observer = (*Metrics)(nil)
+	_ client.Observer  = (*Metrics)(nil)
+)
+
+// Metrics implements the [snapper.Observer] and [client.Observer] interfaces
+// by recording metrics of snapped target devices and uploaded snapshots.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	targetDuration    *prometheus.GaugeVec
+	targetsTotal      *prometheus.CounterVec
+	targetsInFlight   prometheus.Gauge
+	uploadDuration    prometheus.Histogram
+	uploadsTotal      *prometheus.CounterVec
+	lastUploadSuccess prometheus.Gauge
+
+	// mu protects the time of the last successful upload used by readiness checks.
+	mu          sync.Mutex
+	staleAfter  time.Duration
+	startedAt   time.Time
+	lastSuccess time.Time
+}
+
+// NewMetrics returns Metrics object.
+// The client is not ready once no snapshot has been uploaded for [staleAfter].
+func NewMetrics(staleAfter time.Duration) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		targetDuration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "target_duration_seconds",
+			Help:      "Time taken by the last collection from the target device.",
+		}, []string{"target"}),
+		targetsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "target_collections_total",
+			Help:      "Number of collections from the target device by status and failure reason.",
+		}, []string{"target", "status", "reason"}),
+		targetsInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "targets_in_flight",
+			Help:      "Number of target devices being collected from.",
+		}),
+		uploadDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upload_duration_seconds",
+			Help:      "Time taken by the server to save an uploaded snapshot.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14),
+		}),
+		uploadsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "uploads_total",
+			Help:      "Number of snapshot uploads by result and failure reason.",
+		}, []string{"result", "reason"}),
+		lastUploadSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Unix time of the last snapshot saved by the server.",
+		}),
+		staleAfter: staleAfter,
+		startedAt:  time.Now(),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.targetDuration,
+		m.targetsTotal,
+		m.targetsInFlight,
+		m.uploadDuration,
+		m.uploadsTotal,
+		m.lastUploadSuccess,
+	)
+
+	return m
+}
+
+// TargetStarted implements the [snapper.Observer] interface.
+func (m *Metrics) TargetStarted(hostname string) {
+	m.targetsInFlight.Inc()
+}
+
+// TargetFinished implements the [snapper.Observer] interface.
+func (m *Metrics) TargetFinished(hostname string, duration time.Duration, status model.DeviceStatus, reason string) {
+	m.targetsInFlight.Dec()
+	m.targetDuration.WithLabelValues(hostname).Set(duration.Seconds())
+	m.targetsTotal.WithLabelValues(hostname, string(status), reason).Inc()
+}
+
+// TargetRemoved implements the [snapper.Observer] interface.
+// Series of the removed target device are deleted, so they are not exported forever.
+func (m *Metrics) TargetRemoved(hostname string) {
+	m.targetDuration.DeleteLabelValues(hostname)
+	m.targetsTotal.DeletePartialMatch(prometheus.Labels{"target": hostname})
+}
+
+// UploadFinished implements the [client.Observer] interface.
+// Snapshots that could not be created are not sent, so their time is not recorded.
+func (m *Metrics) UploadFinished(duration time.Duration, reason string) {
+	if reason != client.ReasonSnapFailed {
+		m.uploadDuration.Observe(duration.Seconds())
+	}
+
+	if reason != "" {
+		m.uploadsTotal.WithLabelValues("failure", reason).Inc()
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastSuccess = time.Now()
+	m.uploadsTotal.WithLabelValues("success", "").Inc()
+	m.lastUploadSuccess.Set(float64(m.lastSuccess.Unix()))
+}
+
+// Ready returns an error if no snapshot has been uploaded for too long.
+// A single failed upload does not make the client not ready while the last success is recent.
+// Before the first upload, the time is counted from the start of the client.
+func (m *Metrics) Ready() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	since := m.startedAt
+	if !m.lastSuccess.IsZero() {
+		since = m.lastSuccess
+	}
+	if m.staleAfter > 0 && time.Since(since) > m.staleAfter {
+		return fmt.Errorf("no snapshot uploaded for %s", time.Since(since).Round(time.Second))
+	}
+
+	return nil
+}
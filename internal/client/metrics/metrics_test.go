@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sudeeya/net-monitor/internal/client/client"
+	"github.com/sudeeya/net-monitor/internal/client/snapper"
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+func TestTargetRemovedDeletesSeries(t *testing.T) {
+	m := NewMetrics(0)
+	m.TargetFinished("srl1", time.Second, model.StatusSuccess, "")
+	m.TargetFinished("srl1", time.Second, model.StatusDegraded, snapper.ReasonWarnings)
+	m.TargetFinished("srl2", time.Second, model.StatusSuccess, "")
+
+	m.TargetRemoved("srl1")
+
+	if n := testutil.CollectAndCount(m.targetDuration); n != 1 {
+		t.Errorf("target duration series = %d, want 1", n)
+	}
+	if n := testutil.CollectAndCount(m.targetsTotal); n != 1 {
+		t.Errorf("target collection series = %d, want 1", n)
+	}
+	if v := testutil.ToFloat64(m.targetsTotal.WithLabelValues("srl2", string(model.StatusSuccess), "")); v != 1 {
+		t.Errorf("collections of srl2 = %v, want 1", v)
+	}
+}
+
+func TestReadyDependsOnStaleness(t *testing.T) {
+	m := NewMetrics(time.Hour)
+	if err := m.Ready(); err != nil {
+		t.Errorf("want ready after start, got %v", err)
+	}
+
+	m.UploadFinished(time.Second, "")
+	m.UploadFinished(time.Second, client.ReasonSnapFailed)
+	if err := m.Ready(); err != nil {
+		t.Errorf("want ready after a failure following a recent success, got %v", err)
+	}
+
+	m.lastSuccess = time.Now().Add(-2 * time.Hour)
+	if err := m.Ready(); err == nil {
+		t.Error("want not ready without a recent success")
+	}
+}
@@ -2,9 +2,52 @@
 package snapper
 
 import (
+	"context"
+	"time"
+
 	"github.com/sudeeya/net-monitor/internal/pkg/model"
 )
 
 type Snapper interface {
-	Snap() (*model.Snapshot, error)
+	Snap(ctx context.Context) (*model.Snapshot, error)
+}
+
+// Reloader describes an object whose list of target devices can be replaced without restarting the client.
+type Reloader interface {
+	// Reload requests the list of target devices again and replaces the current one.
+	// The current list is kept if the new one is invalid.
+	// A snapshot in progress is not affected.
+	Reload() error
+
+	// Watch reloads the list of target devices every time its source reports a change.
+	// It blocks until the context is done or watching fails.
+	Watch(ctx context.Context) error
+}
+
+// Reasons for which a target device was not snapped completely.
+const (
+	// The target config is invalid, for example its OS is not supported.
+	ReasonInvalidTarget = "invalid_target"
+
+	// The device could not be connected to.
+	ReasonUnreachable = "unreachable"
+
+	// Every command failed on the device.
+	ReasonCommandsFailed = "commands_failed"
+
+	// Some commands or fields failed, so the device is degraded.
+	ReasonWarnings = "warnings"
+)
+
+// Observer describes an object recording how target devices are snapped.
+type Observer interface {
+	// TargetStarted is called before a target device is snapped.
+	TargetStarted(hostname string)
+
+	// TargetFinished is called after a target device is snapped with the time taken,
+	// the status of the device and the reason if the status is not [model.StatusSuccess].
+	TargetFinished(hostname string, duration time.Duration, status model.DeviceStatus, reason string)
+
+	// TargetRemoved is called after a target device is dropped from the list of target devices.
+	TargetRemoved(hostname string)
 }
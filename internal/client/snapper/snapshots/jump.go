@@ -0,0 +1,286 @@
+package snapshots
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/scrapli/scrapligo/transport"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/sudeeya/net-monitor/internal/client/inventory"
+)
+
+const (
+	defaultSSHPort  = 22
+	termType        = "xterm"
+	defaultTTYSpeed = 115200
+)
+
+var _ transport.Implementation = (*jumpTransport)(nil)
+
+// jumpTransport implements the scrapligo transport that tunnels the SSH session
+// to a target device through its jump hosts.
+type jumpTransport struct {
+	cfg inventory.Target
+
+	// Clients of every hop, the last one is connected to the target device.
+	clients []*ssh.Client
+	session *ssh.Session
+	writer  io.WriteCloser
+	reader  io.Reader
+}
+
+// hop describes a single SSH connection of the tunnel.
+type hop struct {
+	hostname       string
+	port           int
+	username       string
+	password       string
+	privateKeyPath string
+	passphrase     string
+	noStrictKey    bool
+}
+
+// newJumpTransport returns jumpTransport object for the target device.
+func newJumpTransport(cfg inventory.Target) *jumpTransport {
+	return &jumpTransport{
+		cfg: cfg,
+	}
+}
+
+// Open connects to every jump host in turn and opens a shell on the target device.
+func (t *jumpTransport) Open(a *transport.Args) error {
+	hops := make([]hop, 0, len(t.cfg.JumpHosts)+1)
+	for _, jumpHost := range t.cfg.JumpHosts {
+		hops = append(hops, hop{
+			hostname:       jumpHost.Hostname,
+			port:           jumpHost.Port,
+			username:       jumpHost.Username,
+			password:       jumpHost.Password,
+			privateKeyPath: jumpHost.PrivateKeyPath,
+			passphrase:     jumpHost.Passphrase,
+			noStrictKey:    jumpHost.NoStrictKey,
+		})
+	}
+	hops = append(hops, hop{
+		hostname:       a.Host,
+		port:           a.Port,
+		username:       a.User,
+		password:       a.Password,
+		privateKeyPath: t.cfg.PrivateKeyPath,
+		passphrase:     t.cfg.Passphrase,
+		noStrictKey:    t.cfg.NoStrictKey,
+	})
+
+	for _, h := range hops {
+		if err := t.dial(h, a); err != nil {
+			_ = t.Close()
+			return fmt.Errorf("failed to connect to %s: %w", h.hostname, err)
+		}
+	}
+
+	if err := t.openShell(a); err != nil {
+		_ = t.Close()
+		return err
+	}
+
+	return nil
+}
+
+// dial connects to the hop directly if it is the first one or through the previous hop otherwise.
+func (t *jumpTransport) dial(h hop, a *transport.Args) error {
+	cfg, err := clientConfig(h, a)
+	if err != nil {
+		return err
+	}
+
+	port := h.port
+	if port == 0 {
+		port = defaultSSHPort
+	}
+	addr := net.JoinHostPort(h.hostname, strconv.Itoa(port))
+
+	if len(t.clients) == 0 {
+		client, err := ssh.Dial("tcp", addr, cfg)
+		if err != nil {
+			return err
+		}
+		t.clients = append(t.clients, client)
+
+		return nil
+	}
+
+	conn, err := t.clients[len(t.clients)-1].Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, addr, cfg)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	t.clients = append(t.clients, ssh.NewClient(clientConn, chans, reqs))
+
+	return nil
+}
+
+// openShell requests a terminal and a shell on the target device.
+func (t *jumpTransport) openShell(a *transport.Args) error {
+	var err error
+
+	t.session, err = t.clients[len(t.clients)-1].NewSession()
+	if err != nil {
+		return err
+	}
+
+	t.writer, err = t.session.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	t.reader, err = t.session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: defaultTTYSpeed,
+		ssh.TTY_OP_OSPEED: defaultTTYSpeed,
+	}
+	if err := t.session.RequestPty(termType, a.TermHeight, a.TermWidth, modes); err != nil {
+		return err
+	}
+
+	return t.session.Shell()
+}
+
+// Close closes the session and every hop starting from the target device.
+func (t *jumpTransport) Close() error {
+	var errs []error
+
+	if t.session != nil {
+		if err := t.session.Close(); err != nil && err != io.EOF {
+			errs = append(errs, err)
+		}
+		t.session = nil
+	}
+
+	for clientIdx := len(t.clients) - 1; clientIdx >= 0; clientIdx-- {
+		if err := t.clients[clientIdx].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.clients = nil
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// IsAlive returns true if the session to the target device is open.
+func (t *jumpTransport) IsAlive() bool {
+	return t.session != nil
+}
+
+// Read reads up to n bytes from the session.
+func (t *jumpTransport) Read(n int) ([]byte, error) {
+	b := make([]byte, n)
+
+	n, err := t.reader.Read(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return b[:n], nil
+}
+
+// Write writes bytes to the session.
+func (t *jumpTransport) Write(b []byte) error {
+	_, err := t.writer.Write(b)
+
+	return err
+}
+
+// clientConfig returns SSH client config for the hop.
+// Host keys are checked against ~/.ssh/known_hosts unless strict key checking is disabled.
+func clientConfig(h hop, a *transport.Args) (*ssh.ClientConfig, error) {
+	/* #nosec G106 */
+	keyCallback := ssh.InsecureIgnoreHostKey()
+
+	if !h.noStrictKey {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+
+		keyCallback, err = knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	authMethods := make([]ssh.AuthMethod, 0)
+
+	if h.privateKeyPath != "" {
+		signer, err := readPrivateKey(h.privateKeyPath, h.passphrase)
+		if err != nil {
+			return nil, err
+		}
+
+		authMethods = append(authMethods, ssh.PublicKeys(signer))
+	}
+
+	if h.password != "" {
+		password := h.password
+		authMethods = append(authMethods, ssh.Password(password),
+			ssh.KeyboardInteractive(
+				func(_, _ string, questions []string, _ []bool) ([]string, error) {
+					answers := make([]string, len(questions))
+					for i := range answers {
+						answers[i] = password
+					}
+
+					return answers, nil
+				},
+			))
+	}
+
+	return &ssh.ClientConfig{
+		User:            h.username,
+		Auth:            authMethods,
+		Timeout:         a.TimeoutSocket,
+		HostKeyCallback: keyCallback,
+	}, nil
+}
+
+// readPrivateKey reads and parses a private key, expanding "~" to the home directory.
+func readPrivateKey(path, passphrase string) (ssh.Signer, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, rest)
+	}
+
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if passphrase != "" {
+		return ssh.ParsePrivateKeyWithPassphrase(key, []byte(passphrase))
+	}
+
+	return ssh.ParsePrivateKey(key)
+}
@@ -0,0 +1,315 @@
+package snapshots
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scrapli/scrapligo/transport"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/sudeeya/net-monitor/internal/client/inventory"
+)
+
+// sshServer is an SSH server that accepts a single username and password
+// and passes every new channel to its handler.
+type sshServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	handle   func(ssh.NewChannel)
+}
+
+// newSSHServer starts an SSH server listening on a local port.
+func newSSHServer(t *testing.T, username, password string, handle func(ssh.NewChannel)) *sshServer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if conn.User() != username || string(pass) != password {
+				return nil, errors.New("wrong username or password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &sshServer{listener: listener, config: config, handle: handle}
+	go s.serve()
+
+	return s
+}
+
+// port returns the port the server listens on.
+func (s *sshServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// serve accepts connections until the listener is closed.
+func (s *sshServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			go ssh.DiscardRequests(reqs)
+			for newChannel := range chans {
+				s.handle(newChannel)
+			}
+		}()
+	}
+}
+
+// forwarder returns a handler of a jump host that forwards connections to the requested addresses
+// and records the addresses.
+func forwarder(mu *sync.Mutex, forwarded *[]string) func(ssh.NewChannel) {
+	return func(newChannel ssh.NewChannel) {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			return
+		}
+
+		var payload struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			return
+		}
+		addr := net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port)))
+		mu.Lock()
+		*forwarded = append(*forwarded, addr)
+		mu.Unlock()
+
+		target, err := net.Dial("tcp", addr)
+		if err != nil {
+			_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			return
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			target.Close()
+			return
+		}
+		go ssh.DiscardRequests(requests)
+		go func() {
+			defer channel.Close()
+			_, _ = io.Copy(channel, target)
+		}()
+		go func() {
+			defer target.Close()
+			_, _ = io.Copy(target, channel)
+		}()
+	}
+}
+
+// echoShell is a handler of a device whose shell answers every line with "echo: <line>".
+func echoShell(newChannel ssh.NewChannel) {
+	if newChannel.ChannelType() != "session" {
+		_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+		return
+	}
+
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	go func() {
+		for req := range requests {
+			ok := req.Type == "pty-req" || req.Type == "shell"
+			_ = req.Reply(ok, nil)
+			if req.Type != "shell" {
+				continue
+			}
+			go func() {
+				defer channel.Close()
+				var line strings.Builder
+				b := make([]byte, 1)
+				for {
+					if _, err := channel.Read(b); err != nil {
+						return
+					}
+					if b[0] != '\n' {
+						line.WriteByte(b[0])
+						continue
+					}
+					if _, err := io.WriteString(channel, "echo: "+line.String()+"\n"); err != nil {
+						return
+					}
+					line.Reset()
+				}
+			}()
+		}
+	}()
+}
+
+// readUntil reads from the transport until the output contains the string.
+func readUntil(t *testing.T, jt *jumpTransport, s string) {
+	t.Helper()
+
+	var output strings.Builder
+	for !strings.Contains(output.String(), s) {
+		b, err := jt.Read(64)
+		if err != nil {
+			t.Fatalf("read %q, then: %v", output.String(), err)
+		}
+		output.Write(b)
+	}
+}
+
+func TestJumpTransport(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		forwarded1 []string
+		forwarded2 []string
+	)
+	device := newSSHServer(t, "admin", "NokiaSrl1!", echoShell)
+	jump2 := newSSHServer(t, "jump2", "secret2", forwarder(&mu, &forwarded2))
+	jump1 := newSSHServer(t, "jump1", "secret1", forwarder(&mu, &forwarded1))
+
+	jt := newJumpTransport(inventory.Target{
+		NoStrictKey: true,
+		JumpHosts: []inventory.JumpHost{
+			{Hostname: "127.0.0.1", Port: jump1.port(), Username: "jump1", Password: "secret1", NoStrictKey: true},
+			{Hostname: "127.0.0.1", Port: jump2.port(), Username: "jump2", Password: "secret2", NoStrictKey: true},
+		},
+	})
+	err := jt.Open(&transport.Args{
+		Host:          "127.0.0.1",
+		Port:          device.port(),
+		User:          "admin",
+		Password:      "NokiaSrl1!",
+		TimeoutSocket: 5 * time.Second,
+		TermHeight:    24,
+		TermWidth:     80,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jt.Close()
+
+	if !jt.IsAlive() {
+		t.Error("transport is not alive after opening")
+	}
+
+	if err := jt.Write([]byte("show version\n")); err != nil {
+		t.Fatal(err)
+	}
+	readUntil(t, jt, "echo: show version\n")
+
+	// Every jump host is asked to connect to the next hop only.
+	mu.Lock()
+	wantForwarded1 := []string{net.JoinHostPort("127.0.0.1", strconv.Itoa(jump2.port()))}
+	wantForwarded2 := []string{net.JoinHostPort("127.0.0.1", strconv.Itoa(device.port()))}
+	if !reflect.DeepEqual(forwarded1, wantForwarded1) || !reflect.DeepEqual(forwarded2, wantForwarded2) {
+		t.Errorf("forwarded by jump hosts: %v and %v, want %v and %v", forwarded1, forwarded2, wantForwarded1, wantForwarded2)
+	}
+	mu.Unlock()
+
+	if err := jt.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if jt.IsAlive() || jt.clients != nil {
+		t.Error("transport is alive after closing")
+	}
+}
+
+func TestJumpTransportFailure(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		forwarded []string
+	)
+	device := newSSHServer(t, "admin", "NokiaSrl1!", echoShell)
+	jump := newSSHServer(t, "jump", "secret", forwarder(&mu, &forwarded))
+
+	// A listener that is closed leaves a port nothing listens on.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	tests := []struct {
+		name       string
+		jumpHost   inventory.JumpHost
+		devicePort int
+		password   string
+	}{
+		{
+			name:       "wrong jump host password",
+			jumpHost:   inventory.JumpHost{Hostname: "127.0.0.1", Port: jump.port(), Username: "jump", Password: "wrong", NoStrictKey: true},
+			devicePort: device.port(),
+			password:   "NokiaSrl1!",
+		},
+		{
+			name:       "unreachable jump host",
+			jumpHost:   inventory.JumpHost{Hostname: "127.0.0.1", Port: closedPort, Username: "jump", Password: "secret", NoStrictKey: true},
+			devicePort: device.port(),
+			password:   "NokiaSrl1!",
+		},
+		{
+			name:       "device unreachable from the jump host",
+			jumpHost:   inventory.JumpHost{Hostname: "127.0.0.1", Port: jump.port(), Username: "jump", Password: "secret", NoStrictKey: true},
+			devicePort: closedPort,
+			password:   "NokiaSrl1!",
+		},
+		{
+			name:       "wrong device password",
+			jumpHost:   inventory.JumpHost{Hostname: "127.0.0.1", Port: jump.port(), Username: "jump", Password: "secret", NoStrictKey: true},
+			devicePort: device.port(),
+			password:   "wrong",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jt := newJumpTransport(inventory.Target{NoStrictKey: true, JumpHosts: []inventory.JumpHost{tt.jumpHost}})
+			err := jt.Open(&transport.Args{
+				Host:          "127.0.0.1",
+				Port:          tt.devicePort,
+				User:          "admin",
+				Password:      tt.password,
+				TimeoutSocket: 5 * time.Second,
+			})
+			if err == nil || !strings.HasPrefix(err.Error(), "failed to connect to 127.0.0.1") {
+				t.Errorf("error = %v, want a failure to connect to a hop", err)
+			}
+			// Hops connected before the failure are closed.
+			if jt.IsAlive() || jt.clients != nil {
+				t.Error("transport is alive after failing to open")
+			}
+		})
+	}
+}
@@ -2,66 +2,126 @@
 package snapshots
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
 	"net/netip"
-	"os"
+	"reflect"
+	"sort"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/scrapli/scrapligo/driver/generic"
 	"github.com/scrapli/scrapligo/driver/options"
 	"github.com/scrapli/scrapligo/util"
 
+	"github.com/sudeeya/net-monitor/internal/client/inventory"
 	"github.com/sudeeya/net-monitor/internal/client/snapper"
 	"github.com/sudeeya/net-monitor/internal/pkg/model"
 )
 
-var _ snapper.Snapper = (*snapshots)(nil)
+const limitInSeconds = 30
 
-// snapshots implements the [Snapper] interface.
+// lastChangeTolerance is the largest difference between last changes of an interface taken as the same change.
+// Times passed since the change are counted back from the time the response is read, not the time it was shown.
+const lastChangeTolerance = 10 * time.Second
+
+// tracer starts spans of snapshots, target devices, SSH commands and TextFSM parsing.
+var tracer = otel.Tracer("github.com/sudeeya/net-monitor/internal/client/snapper/snapshots")
+
+var (
+	_ snapper.Snapper  = (*snapshots)(nil)
+	_ snapper.Reloader = (*snapshots)(nil)
+)
+
+// snapshots implements the [Snapper] and [Reloader] interfaces.
 type snapshots struct {
-	logger  *zap.Logger
-	targets []target
+	logger          *zap.Logger
+	inventory       inventory.Provider
+	refreshInterval time.Duration
+	collectConfig   bool
+	observer        snapper.Observer
+
+	// refreshMu serializes refreshes, so concurrent reloads do not overwrite each other.
+	refreshMu   sync.Mutex
+	refreshedAt time.Time
+
+	// targets is swapped as a whole, so a snapshot in progress keeps the list it started with.
+	targets atomic.Pointer[[]target]
+
+	// lastChanges holds the last changes of interfaces by hostname and interface name reported in the previous snapshot.
+	lastChangesMu sync.Mutex
+	lastChanges   map[string]map[string]time.Time
 }
 
 // target defines a target device.
 type target struct {
-	cfg       targetConfig
+	cfg       inventory.Target
 	templates []template
-}
 
-// targetConfig defines device OS and information needed for an SSH connection.
-type targetConfig struct {
-	OS             string `json:"os"`
-	Hostname       string `json:"hostname"`
-	Username       string `json:"username"`
-	Password       string `json:"password"`
-	PrivateKeyPath string `json:"private_key_path"`
-	Passphrase     string `json:"passphrase"`
-	NoStrictKey    bool   `json:"no_strict_key"`
+	// Command that shows the running configuration, empty if it is not collected.
+	configCmd string
 }
 
 // NewSnapshots returns snapshots object.
-// The function extracts target network devices from  a json file.
-func NewSnapshots(logger *zap.Logger, targetsFile string) (*snapshots, error) {
-	logger.Sugar().Infof("Extracting configs from file %s", targetsFile)
-	cfgs, err := extractConfigs(targetsFile)
-	if err != nil {
-		return nil, err
+// The function requests target network devices from the inventory provider.
+// The list of targets is requested again before a snapshot once [refreshInterval] has passed.
+// If [collectConfig] is set, the running configuration of every target device is collected.
+// If [observer] is not nil, it is notified about every target device snapped.
+func NewSnapshots(
+	logger *zap.Logger,
+	inventory inventory.Provider,
+	refreshInterval time.Duration,
+	collectConfig bool,
+	observer snapper.Observer,
+) (*snapshots, error) {
+	s := &snapshots{
+		logger:          logger,
+		inventory:       inventory,
+		refreshInterval: refreshInterval,
+		collectConfig:   collectConfig,
+		observer:        observer,
+		lastChanges:     make(map[string]map[string]time.Time),
 	}
 
-	logger.Info("Forming a list of target devices")
-	targets, err := formTargets(cfgs)
-	if err != nil {
+	if err := s.refreshTargets(); err != nil {
 		return nil, err
 	}
 
-	return &snapshots{
-		logger:  logger,
-		targets: targets,
-	}, nil
+	return s, nil
+}
+
+// Reload implements the [Reloader] interface.
+func (s *snapshots) Reload() error {
+	s.logger.Info("Reloading target devices")
+	if err := s.refreshTargets(); err != nil {
+		s.logger.Sugar().Errorf("Failed to reload targets, keeping the previous list: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Watch implements the [Reloader] interface.
+// If the inventory provider cannot report changes, Watch blocks until the context is done.
+func (s *snapshots) Watch(ctx context.Context) error {
+	watcher, ok := s.inventory.(inventory.Watcher)
+	if !ok {
+		<-ctx.Done()
+		return nil
+	}
+
+	s.logger.Info("Watching the inventory for changes")
+	return watcher.Watch(ctx, func() {
+		_ = s.Reload()
+	})
 }
 
 type snapResult struct {
@@ -71,19 +131,31 @@ type snapResult struct {
 }
 
 // Snap implements the [Snapper] interface.
-func (s *snapshots) Snap() (*model.Snapshot, error) {
+func (s *snapshots) Snap(ctx context.Context) (*model.Snapshot, error) {
+	ctx, span := tracer.Start(ctx, "Snap")
+	defer span.End()
+
+	if s.isRefreshDue() {
+		if err := s.refreshTargets(); err != nil {
+			s.logger.Sugar().Errorf("Failed to refresh targets, keeping the previous list: %s", err.Error())
+		}
+	}
+
+	targets := *s.targets.Load()
+	span.SetAttributes(attribute.Int("targets", len(targets)))
+
 	timestamp := time.Now()
 	devices := make([]model.Device, 0)
-	resultChan := make(chan snapResult, len(s.targets))
+	resultChan := make(chan snapResult, len(targets))
 
-	for _, t := range s.targets {
+	for _, t := range targets {
 		go func(t target) {
-			device, err := s.snapTarget(t)
+			device, err := s.observeTarget(ctx, t)
 			resultChan <- snapResult{t.cfg.Hostname, device, err}
 		}(t)
 	}
 
-	for range len(s.targets) {
+	for range len(targets) {
 		res := <-resultChan
 
 		if res.err != nil {
@@ -99,41 +171,180 @@ func (s *snapshots) Snap() (*model.Snapshot, error) {
 	}, nil
 }
 
-func extractConfigs(targetFile string) ([]targetConfig, error) {
-	file, err := os.Open(targetFile)
+// observeTarget snaps the target device within a span and notifies the observer.
+func (s *snapshots) observeTarget(ctx context.Context, t target) (*model.Device, error) {
+	ctx, span := tracer.Start(ctx, "SnapTarget", trace.WithAttributes(
+		attribute.String("target.hostname", t.cfg.Hostname),
+		attribute.String("target.os", t.cfg.OS),
+	))
+	defer span.End()
+
+	if s.observer != nil {
+		s.observer.TargetStarted(t.cfg.Hostname)
+	}
+
+	start := time.Now()
+	device, err := s.snapTarget(ctx, t)
+	status, reason := outcome(device, err)
+
+	span.SetAttributes(attribute.String("target.status", string(status)))
+	if status == model.StatusFailure {
+		span.SetStatus(codes.Error, reason)
+	}
+
+	if s.observer != nil {
+		s.observer.TargetFinished(t.cfg.Hostname, time.Since(start), status, reason)
+	}
+
+	return device, err
+}
+
+// outcome returns the status of the snapped device and the reason if it is not successful.
+// A failed device without warnings could not be connected to, since failed commands are reported as warnings.
+func outcome(device *model.Device, err error) (model.DeviceStatus, string) {
+	switch {
+	case err != nil:
+		return model.StatusFailure, snapper.ReasonInvalidTarget
+	case device.Status == model.StatusFailure && len(device.Warnings) == 0:
+		return model.StatusFailure, snapper.ReasonUnreachable
+	case device.Status == model.StatusFailure:
+		return model.StatusFailure, snapper.ReasonCommandsFailed
+	case device.Status == model.StatusDegraded:
+		return model.StatusDegraded, snapper.ReasonWarnings
+	default:
+		return model.StatusSuccess, ""
+	}
+}
+
+// isRefreshDue reports whether the refresh interval has passed since the last refresh.
+func (s *snapshots) isRefreshDue() bool {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	return s.refreshInterval > 0 && time.Since(s.refreshedAt) >= s.refreshInterval
+}
+
+// refreshTargets requests target devices from the inventory provider and replaces the current list.
+// If the request fails or the new list is invalid, the current list is kept.
+func (s *snapshots) refreshTargets() error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
+	defer cancel()
+
+	s.logger.Info("Requesting target devices from the inventory")
+	cfgs, err := s.inventory.Targets(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer file.Close()
 
-	cfgs := make([]targetConfig, 0)
+	s.logger.Info("Forming a list of target devices")
+	targets, err := formTargets(cfgs, s.collectConfig)
+	if err != nil {
+		return err
+	}
 
-	if err := json.NewDecoder(file).Decode(&cfgs); err != nil {
-		return nil, err
+	old := s.targets.Load()
+	s.targets.Store(&targets)
+	s.refreshedAt = time.Now()
+
+	if old != nil {
+		s.forgetTargets(s.logTargetsDiff(*old, targets))
+	}
+
+	return nil
+}
+
+// forgetTargets drops the state kept for removed target devices and notifies the observer about them.
+func (s *snapshots) forgetTargets(removed []string) {
+	s.lastChangesMu.Lock()
+	for _, hostname := range removed {
+		delete(s.lastChanges, hostname)
+	}
+	s.lastChangesMu.Unlock()
+
+	if s.observer == nil {
+		return
+	}
+	for _, hostname := range removed {
+		s.observer.TargetRemoved(hostname)
+	}
+}
+
+// logTargetsDiff logs a summary of target devices added, removed and changed
+// and returns the hostnames of removed target devices.
+func (s *snapshots) logTargetsDiff(old, new []target) []string {
+	oldCfgs := make(map[string]inventory.Target, len(old))
+	for _, t := range old {
+		oldCfgs[t.cfg.Hostname] = t.cfg
+	}
+
+	added := make([]string, 0)
+	changed := make([]string, 0)
+	for _, t := range new {
+		cfg, ok := oldCfgs[t.cfg.Hostname]
+		switch {
+		case !ok:
+			added = append(added, t.cfg.Hostname)
+		case !reflect.DeepEqual(cfg, t.cfg):
+			changed = append(changed, t.cfg.Hostname)
+		}
+		delete(oldCfgs, t.cfg.Hostname)
 	}
 
-	return cfgs, nil
+	removed := make([]string, 0, len(oldCfgs))
+	for hostname := range oldCfgs {
+		removed = append(removed, hostname)
+	}
+	sort.Strings(removed)
+
+	s.logger.Sugar().Infof(
+		"Targets updated: %d added %v, %d removed %v, %d changed %v",
+		len(added), added, len(removed), removed, len(changed), changed,
+	)
+
+	return removed
 }
 
-func formTargets(cfgs []targetConfig) ([]target, error) {
+// formTargets validates target configs and forms a list of target devices.
+// If [collectConfig] is set, targets also get the command that shows the running configuration.
+func formTargets(cfgs []inventory.Target, collectConfig bool) ([]target, error) {
 	targets := make([]target, len(cfgs))
+	hostnames := make(map[string]struct{}, len(cfgs))
 
 	for cfgIdx, cfg := range cfgs {
+		if cfg.Hostname == "" {
+			return nil, fmt.Errorf("target %d: hostname is not specified", cfgIdx)
+		}
+		if _, ok := hostnames[cfg.Hostname]; ok {
+			return nil, fmt.Errorf("target %d: duplicate hostname %s", cfgIdx, cfg.Hostname)
+		}
+		hostnames[cfg.Hostname] = struct{}{}
+
 		templates, err := getTemplates(cfg.OS)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("target %s: %w", cfg.Hostname, err)
+		}
+
+		configCmd := ""
+		if collectConfig {
+			if configCmd, err = getConfigCommand(cfg.OS); err != nil {
+				return nil, fmt.Errorf("target %s: %w", cfg.Hostname, err)
+			}
 		}
 
 		targets[cfgIdx] = target{
 			cfg:       cfg,
 			templates: templates,
+			configCmd: configCmd,
 		}
 	}
 
 	return targets, nil
 }
 
-func (s *snapshots) snapTarget(t target) (*model.Device, error) {
+func (s *snapshots) snapTarget(ctx context.Context, t target) (*model.Device, error) {
 	vendor, err := getVendor(t.cfg.OS)
 	if err != nil {
 		return nil, err
@@ -151,12 +362,15 @@ func (s *snapshots) snapTarget(t target) (*model.Device, error) {
 	}
 
 	s.logger.Sugar().Infof("Trying to connect to %s", t.cfg.Hostname)
+	_, connectSpan := tracer.Start(ctx, "ssh.Connect")
 	err = driver.Open()
+	endSpan(connectSpan, err)
 	defer driver.Close()
 	if err != nil {
 		s.logger.Sugar().Errorf("Failed to connect to %s: %s", t.cfg.Hostname, err.Error())
 
 		device.IsSnapshotSuccessful = false
+		device.Status = model.StatusFailure
 
 		return device, nil
 	}
@@ -164,88 +378,228 @@ func (s *snapshots) snapTarget(t target) (*model.Device, error) {
 	s.logger.Sugar().Infof("Connection to %s established", t.cfg.Hostname)
 
 	ifaces := make([]model.Interface, 0)
+	warnings := make([]model.Warning, 0)
+	failedCommands := 0
 
 	for _, template := range t.templates {
 		s.logger.Sugar().Infof("Sending command: %s", template.cmd)
+		_, commandSpan := tracer.Start(ctx, "ssh.SendCommand", trace.WithAttributes(attribute.String("ssh.command", template.cmd)))
 		response, err := driver.SendCommand(template.cmd)
+		collectedAt := time.Now()
+		endSpan(commandSpan, err)
 		if err != nil {
-			return nil, err
+			s.logger.Sugar().Errorf("Command %q failed on %s: %s", template.cmd, t.cfg.Hostname, err.Error())
+			warnings = append(warnings, model.Warning{Command: template.cmd, Message: err.Error()})
+			failedCommands++
+			continue
 		}
 
 		s.logger.Info("Parsing response")
+		_, parseSpan := tracer.Start(ctx, "textfsm.Parse", trace.WithAttributes(attribute.String("textfsm.template", template.file)))
 		parsed, err := response.TextFsmParse(template.file)
+		endSpan(parseSpan, err)
 		if err != nil {
-			return nil, err
+			s.logger.Sugar().Errorf("Failed to parse response to %q from %s: %s", template.cmd, t.cfg.Hostname, err.Error())
+			warnings = append(warnings, model.Warning{Command: template.cmd, Message: err.Error()})
+			failedCommands++
+			continue
 		}
 
 		for _, p := range parsed {
-			iface := model.Interface{}
-
-			for _, output := range template.outputs {
-				if p == nil {
-					continue
-				}
-
-				value, ok := p[output].(string)
-				if !ok {
-					continue
-				}
-				if value == "" {
-					continue
-				}
-
-				switch output {
-				case hostnameOutput:
-					device.Hostname = value
-				case osOutput:
-					device.OSName = value
-				case versionOutput:
-					device.OSVersion = value
-				case serialOutput:
-					device.Serial = value
-				case interfaceOutput:
-					iface.Name = value
-				case stateOutput:
-					switch value {
-					case "up":
-						iface.IsUp = true
-					case "down":
-						iface.IsUp = false
-					}
-				case ipv4Output:
-					ip, err := netip.ParsePrefix(value)
-					if err != nil {
-						return nil, err
-					}
-					iface.IP = ip
-				case mtuOutput:
-					mtu, err := strconv.Atoi(value)
-					if err != nil {
-						return nil, err
-					}
-					iface.MTU = int64(mtu)
-				}
+			if p == nil {
+				continue
 			}
 
-			if iface.Name != "" {
+			iface, fieldWarnings := parseRecord(device, template, p, collectedAt)
+			warnings = append(warnings, fieldWarnings...)
+
+			switch {
+			case iface.Name == "":
+			case template.neighbors:
+				setNeighbor(ifaces, iface)
+			default:
 				ifaces = append(ifaces, iface)
 			}
 		}
 	}
 
+	commands := len(t.templates)
+	if t.configCmd != "" {
+		commands++
+
+		s.logger.Sugar().Infof("Sending command: %s", t.configCmd)
+		_, commandSpan := tracer.Start(ctx, "ssh.SendCommand", trace.WithAttributes(attribute.String("ssh.command", t.configCmd)))
+		response, err := driver.SendCommand(t.configCmd)
+		endSpan(commandSpan, err)
+		if err != nil {
+			s.logger.Sugar().Errorf("Command %q failed on %s: %s", t.configCmd, t.cfg.Hostname, err.Error())
+			warnings = append(warnings, model.Warning{Command: t.configCmd, Message: err.Error()})
+			failedCommands++
+		} else {
+			device.RunningConfig = response.Result
+		}
+	}
+
+	s.keepLastChanges(t.cfg.Hostname, ifaces)
+
 	device.Interfaces = ifaces
+	device.Warnings = warnings
 	device.IsSnapshotSuccessful = true
 
+	switch {
+	case failedCommands == commands:
+		device.IsSnapshotSuccessful = false
+		device.Status = model.StatusFailure
+	case len(warnings) > 0:
+		device.Status = model.StatusDegraded
+	default:
+		device.Status = model.StatusSuccess
+	}
+
 	return device, nil
 }
 
+// keepLastChanges replaces last changes that differ from those of the previous snapshot by less than [lastChangeTolerance]
+// with the previous ones, so that interfaces whose state did not change keep the same state.
+// If there are no interfaces, for example because the command failed, the previous last changes are kept.
+func (s *snapshots) keepLastChanges(hostname string, ifaces []model.Interface) {
+	if len(ifaces) == 0 {
+		return
+	}
+
+	s.lastChangesMu.Lock()
+	defer s.lastChangesMu.Unlock()
+
+	prev := s.lastChanges[hostname]
+	current := make(map[string]time.Time, len(ifaces))
+	for ifaceIdx := range ifaces {
+		iface := &ifaces[ifaceIdx]
+		if iface.LastChange.IsZero() {
+			continue
+		}
+
+		if prevChange, ok := prev[iface.Name]; ok {
+			if diff := iface.LastChange.Sub(prevChange); diff > -lastChangeTolerance && diff < lastChangeTolerance {
+				iface.LastChange = prevChange
+			}
+		}
+		current[iface.Name] = iface.LastChange
+	}
+	s.lastChanges[hostname] = current
+}
+
+// setNeighbor copies the neighbor of [neighbor] to the interface with the same name.
+// Neighbors of interfaces that were not collected are skipped.
+func setNeighbor(ifaces []model.Interface, neighbor model.Interface) {
+	for ifaceIdx := range ifaces {
+		if ifaces[ifaceIdx].Name == neighbor.Name {
+			ifaces[ifaceIdx].NeighborHostname = neighbor.NeighborHostname
+			ifaces[ifaceIdx].NeighborInterface = neighbor.NeighborInterface
+			return
+		}
+	}
+}
+
+// endSpan records the error, if any, and ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// parseRecord fills device fields from a single parsed record and returns the interface described by it.
+// Values that cannot be parsed are skipped and reported as warnings, so the rest of the record is kept.
+// Times relative to the moment the command ran are counted back from [collectedAt].
+func parseRecord(device *model.Device, template template, record map[string]interface{}, collectedAt time.Time) (model.Interface, []model.Warning) {
+	iface := model.Interface{}
+	warnings := make([]model.Warning, 0)
+
+	for _, output := range template.outputs {
+		value, ok := record[output].(string)
+		if !ok {
+			continue
+		}
+		if value == "" {
+			continue
+		}
+
+		switch output {
+		case hostnameOutput:
+			device.Hostname = value
+		case osOutput:
+			device.OSName = value
+		case versionOutput:
+			device.OSVersion = value
+		case serialOutput:
+			device.Serial = value
+		case interfaceOutput:
+			iface.Name = value
+		case stateOutput:
+			switch value {
+			case "up":
+				iface.IsUp = true
+			case "down":
+				iface.IsUp = false
+			}
+		case ipv4Output:
+			ip, err := netip.ParsePrefix(value)
+			if err != nil {
+				warnings = append(warnings, fieldWarning(template.cmd, output, record, err))
+				continue
+			}
+			iface.IP = ip
+		case mtuOutput:
+			mtu, err := strconv.Atoi(value)
+			if err != nil {
+				warnings = append(warnings, fieldWarning(template.cmd, output, record, err))
+				continue
+			}
+			iface.MTU = int64(mtu)
+		case descOutput:
+			if value != noDescription {
+				iface.Description = value
+			}
+		case lastChangeOutput:
+			lastChange, err := parseLastChange(value, collectedAt)
+			if err != nil {
+				warnings = append(warnings, fieldWarning(template.cmd, output, record, err))
+				continue
+			}
+			iface.LastChange = lastChange
+		case neighborOutput:
+			iface.NeighborHostname = value
+		case neighborInterfaceOutput:
+			iface.NeighborInterface = value
+		}
+	}
+
+	return iface, warnings
+}
+
+// fieldWarning returns a warning about an output field that could not be parsed.
+// If the record describes an interface, its name is included in the field.
+func fieldWarning(cmd, output string, record map[string]interface{}, err error) model.Warning {
+	field := output
+	if name, ok := record[interfaceOutput].(string); ok && name != "" {
+		field = fmt.Sprintf("%s (%s)", output, name)
+	}
+
+	return model.Warning{
+		Command: cmd,
+		Field:   field,
+		Message: err.Error(),
+	}
+}
+
 func newTargetDriver(t target) (*generic.Driver, error) {
 	opts := toOptions(t.cfg)
 
 	return generic.NewDriver(t.cfg.Hostname, opts...)
 }
 
-func toOptions(cfg targetConfig) []util.Option {
+func toOptions(cfg inventory.Target) []util.Option {
 	opts := []util.Option{
 		options.WithAuthUsername(cfg.Username),
 		options.WithAuthPassword(cfg.Password),
@@ -259,5 +613,9 @@ func toOptions(cfg targetConfig) []util.Option {
 		opts = append(opts, options.WithAuthNoStrictKey())
 	}
 
+	if len(cfg.JumpHosts) > 0 {
+		opts = append(opts, options.WithCustomTransport(newJumpTransport(cfg)))
+	}
+
 	return opts
 }
@@ -0,0 +1,116 @@
+package snapshots
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/client/inventory"
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+// staticInventory returns the targets it holds.
+type staticInventory struct {
+	targets []inventory.Target
+}
+
+func (i *staticInventory) Targets(ctx context.Context) ([]inventory.Target, error) {
+	return i.targets, nil
+}
+
+// removedObserver records the target devices removed.
+type removedObserver struct {
+	removed []string
+}
+
+func (o *removedObserver) TargetStarted(hostname string) {}
+
+func (o *removedObserver) TargetFinished(hostname string, duration time.Duration, status model.DeviceStatus, reason string) {
+}
+
+func (o *removedObserver) TargetRemoved(hostname string) {
+	o.removed = append(o.removed, hostname)
+}
+
+func TestKeepLastChanges(t *testing.T) {
+	s := &snapshots{lastChanges: make(map[string]map[string]time.Time)}
+
+	first := []model.Interface{
+		{Name: "ethernet-1/1", LastChange: collectedAt},
+		{Name: "ethernet-1/2", LastChange: collectedAt},
+		{Name: "mgmt0"},
+	}
+	s.keepLastChanges("srl1", first)
+
+	// The first interface is read a second later, the second one changed since.
+	second := []model.Interface{
+		{Name: "ethernet-1/1", LastChange: collectedAt.Add(time.Second)},
+		{Name: "ethernet-1/2", LastChange: collectedAt.Add(time.Hour)},
+		{Name: "mgmt0"},
+	}
+	s.keepLastChanges("srl1", second)
+
+	want := []model.Interface{
+		{Name: "ethernet-1/1", LastChange: collectedAt},
+		{Name: "ethernet-1/2", LastChange: collectedAt.Add(time.Hour)},
+		{Name: "mgmt0"},
+	}
+	if !reflect.DeepEqual(second, want) {
+		t.Errorf("interfaces = %+v, want %+v", second, want)
+	}
+
+	// A snapshot without interfaces does not forget the last changes.
+	s.keepLastChanges("srl1", nil)
+	third := []model.Interface{{Name: "ethernet-1/2", LastChange: collectedAt.Add(time.Hour - time.Second)}}
+	s.keepLastChanges("srl1", third)
+	if !third[0].LastChange.Equal(collectedAt.Add(time.Hour)) {
+		t.Errorf("last change = %s, want %s", third[0].LastChange, collectedAt.Add(time.Hour))
+	}
+}
+
+func TestReloadForgetsRemovedTargets(t *testing.T) {
+	inv := &staticInventory{targets: []inventory.Target{
+		{Hostname: "srl1", OS: nokiaSRLinux},
+		{Hostname: "srl2", OS: nokiaSRLinux},
+		{Hostname: "srl3", OS: nokiaSRLinux},
+	}}
+	observer := &removedObserver{}
+	s, err := NewSnapshots(zap.NewNop(), inv, time.Hour, true, observer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.keepLastChanges("srl2", []model.Interface{{Name: "ethernet-1/1", LastChange: collectedAt}})
+
+	inv.targets = []inventory.Target{{Hostname: "srl1", OS: nokiaSRLinux}, {Hostname: "srl4", OS: nokiaSRLinux}}
+	if err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"srl2", "srl3"}; !reflect.DeepEqual(observer.removed, want) {
+		t.Errorf("removed = %v, want %v", observer.removed, want)
+	}
+	if _, ok := s.lastChanges["srl2"]; ok {
+		t.Error("last changes of a removed target are kept")
+	}
+}
+
+func TestSetNeighbor(t *testing.T) {
+	ifaces := []model.Interface{
+		{Name: "ethernet-1/1", IsUp: true},
+		{Name: "ethernet-1/2"},
+	}
+	setNeighbor(ifaces, model.Interface{Name: "ethernet-1/1", NeighborHostname: "spine1", NeighborInterface: "ethernet-1/3"})
+	// Neighbors of interfaces that were not collected are not added as interfaces.
+	setNeighbor(ifaces, model.Interface{Name: "mgmt0", NeighborHostname: "oob-switch", NeighborInterface: "Gi0/12"})
+
+	want := []model.Interface{
+		{Name: "ethernet-1/1", IsUp: true, NeighborHostname: "spine1", NeighborInterface: "ethernet-1/3"},
+		{Name: "ethernet-1/2"},
+	}
+	if !reflect.DeepEqual(ifaces, want) {
+		t.Errorf("interfaces = %+v, want %+v", ifaces, want)
+	}
+}
@@ -1,6 +1,10 @@
 package snapshots
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
 
 // Vendors.
 const (
@@ -14,16 +18,32 @@ const (
 
 // Output data.
 const (
-	hostnameOutput  = "HOSTNAME"
-	osOutput        = "OS"
-	versionOutput   = "VERSION"
-	serialOutput    = "SERIAL_NUMBER"
-	interfaceOutput = "INTERFACE"
-	stateOutput     = "STATE"
-	ipv4Output      = "IPV4"
-	mtuOutput       = "MTU"
+	hostnameOutput   = "HOSTNAME"
+	osOutput         = "OS"
+	versionOutput    = "VERSION"
+	serialOutput     = "SERIAL_NUMBER"
+	interfaceOutput  = "INTERFACE"
+	stateOutput      = "STATE"
+	ipv4Output       = "IPV4"
+	mtuOutput        = "MTU"
+	descOutput       = "DESCRIPTION"
+	lastChangeOutput = "LAST_CHANGE"
+
+	neighborOutput          = "NEIGHBOR"
+	neighborInterfaceOutput = "NEIGHBOR_INTERFACE"
 )
 
+// noDescription is shown by Nokia SR Linux for interfaces without a description.
+const noDescription = "<None>"
+
+// lastChangeUnits are the units of the time passed since the last change, as Nokia SR Linux shows it, such as 2d3h40m12s.
+var lastChangeUnits = map[byte]time.Duration{
+	'd': 24 * time.Hour,
+	'h': time.Hour,
+	'm': time.Minute,
+	's': time.Second,
+}
+
 // template defines information needed to examine the configuration of a network device.
 type template struct {
 	// Command that need to be used on the device.
@@ -34,6 +54,9 @@ type template struct {
 
 	// Output data present in the response.
 	outputs []string
+
+	// Records describe neighbors of interfaces collected by the previous templates rather than interfaces of their own.
+	neighbors bool
 }
 
 // OS-specific templates.
@@ -57,8 +80,20 @@ var (
 				stateOutput,
 				ipv4Output,
 				mtuOutput,
+				descOutput,
+				lastChangeOutput,
 			},
 		},
+		{
+			cmd:  "show system lldp neighbor",
+			file: "templates/nokia_srlinux_show_system_lldp_neighbor.textfsm",
+			outputs: []string{
+				interfaceOutput,
+				neighborOutput,
+				neighborInterfaceOutput,
+			},
+			neighbors: true,
+		},
 	}
 )
 
@@ -72,6 +107,17 @@ func getVendor(os string) (string, error) {
 	}
 }
 
+// getConfigCommand returns the command that shows the running configuration by OS.
+// Nokia SR Linux shows it in the flat format, one complete statement per line.
+func getConfigCommand(os string) (string, error) {
+	switch os {
+	case nokiaSRLinux:
+		return "info flat from running", nil
+	default:
+		return "", fmt.Errorf("unknown operating system: %s", os)
+	}
+}
+
 // getTemplates returns templates by OS.
 func getTemplates(os string) ([]template, error) {
 	switch os {
@@ -81,3 +127,36 @@ func getTemplates(os string) ([]template, error) {
 		return nil, fmt.Errorf("unknown operating system: %s", os)
 	}
 }
+
+// parseLastChange returns the time of the last change shown either as an RFC 3339 time
+// or as the time passed since the change, which is counted back from [collectedAt].
+func parseLastChange(value string, collectedAt time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	var passed time.Duration
+	for rest := value; rest != ""; {
+		digits := 0
+		for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 || digits == len(rest) {
+			return time.Time{}, fmt.Errorf("invalid last change: %s", value)
+		}
+
+		unit, ok := lastChangeUnits[rest[digits]]
+		if !ok {
+			return time.Time{}, fmt.Errorf("invalid last change unit: %s", value)
+		}
+		n, err := strconv.Atoi(rest[:digits])
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		passed += time.Duration(n) * unit
+		rest = rest[digits+1:]
+	}
+
+	return collectedAt.Add(-passed), nil
+}
@@ -0,0 +1,136 @@
+package snapshots
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/scrapli/scrapligo/util"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+// collectedAt is the time at which the command outputs stored in testdata are taken to be read.
+var collectedAt = time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+// parseTestdata parses the command output stored in testdata with the template,
+// which is looked up from the repository root as the client does.
+func parseTestdata(t *testing.T, tmpl template, output string) (*model.Device, []model.Interface, []model.Warning) {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", output))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := util.TextFsmParse(string(data), filepath.Join("..", "..", "..", "..", tmpl.file))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	device := &model.Device{}
+	ifaces := make([]model.Interface, 0)
+	warnings := make([]model.Warning, 0)
+	for _, record := range records {
+		iface, fieldWarnings := parseRecord(device, tmpl, record, collectedAt)
+		warnings = append(warnings, fieldWarnings...)
+		if iface.Name != "" {
+			ifaces = append(ifaces, iface)
+		}
+	}
+
+	return device, ifaces, warnings
+}
+
+func TestNokiaSRLinuxInterfaceDetail(t *testing.T) {
+	_, ifaces, warnings := parseTestdata(t, nokiaSRLinuxTemplates[1], "nokia_srlinux_show_interface_detail.txt")
+
+	// The address and IP MTU of a subinterface are reported for its interface, but its last change is not.
+	want := []model.Interface{
+		{
+			Name:        "ethernet-1/1",
+			IsUp:        true,
+			IP:          netip.MustParsePrefix("10.0.0.1/31"),
+			MTU:         1500,
+			Description: "to spine1 ethernet-1/3",
+			LastChange:  collectedAt.Add(-(51*time.Hour + 40*time.Minute + 12*time.Second)),
+		},
+		{Name: "ethernet-1/2", IsUp: false, MTU: 9232, LastChange: collectedAt.Add(-(14*time.Minute + 5*time.Second))},
+	}
+	if !reflect.DeepEqual(ifaces, want) {
+		t.Errorf("interfaces = %+v, want %+v", ifaces, want)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %+v, want none", warnings)
+	}
+}
+
+func TestNokiaSRLinuxLLDPNeighbor(t *testing.T) {
+	_, ifaces, warnings := parseTestdata(t, nokiaSRLinuxTemplates[2], "nokia_srlinux_show_system_lldp_neighbor.txt")
+
+	want := []model.Interface{
+		{Name: "ethernet-1/1", NeighborHostname: "spine1", NeighborInterface: "ethernet-1/3"},
+		{Name: "mgmt0", NeighborHostname: "oob-switch", NeighborInterface: "Gi0/12"},
+	}
+	if !reflect.DeepEqual(ifaces, want) {
+		t.Errorf("interfaces = %+v, want %+v", ifaces, want)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %+v, want none", warnings)
+	}
+}
+
+func TestParseLastChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:  "time passed",
+			value: "2d3h40m12s",
+			want:  collectedAt.Add(-(51*time.Hour + 40*time.Minute + 12*time.Second)),
+		},
+		{
+			name:  "seconds only",
+			value: "42s",
+			want:  collectedAt.Add(-42 * time.Second),
+		},
+		{
+			name:  "RFC 3339 time",
+			value: "2024-09-30T08:15:00.000Z",
+			want:  time.Date(2024, 9, 30, 8, 15, 0, 0, time.UTC),
+		},
+		{
+			name:    "unknown unit",
+			value:   "3w",
+			wantErr: true,
+		},
+		{
+			name:    "missing unit",
+			value:   "12",
+			wantErr: true,
+		},
+		{
+			name:    "not a time",
+			value:   "N/A",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLastChange(tt.value, collectedAt)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
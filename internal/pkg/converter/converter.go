@@ -33,6 +33,11 @@ func ToProtoFromDevice(device model.Device) *pb.Snapshot_Device {
 		ifaces[ifaceIdx] = ToProtoFromInterface(iface)
 	}
 
+	warnings := make([]*pb.Snapshot_Device_Warning, len(device.Warnings))
+	for warningIdx, warning := range device.Warnings {
+		warnings[warningIdx] = ToProtoFromWarning(warning)
+	}
+
 	return &pb.Snapshot_Device{
 		Hostname:             device.Hostname,
 		Vendor:               device.Vendor,
@@ -41,17 +46,52 @@ func ToProtoFromDevice(device model.Device) *pb.Snapshot_Device {
 		Serial:               device.Serial,
 		IsSnapshotSuccessful: device.IsSnapshotSuccessful,
 		Interfaces:           ifaces,
+		Status:               ToProtoFromStatus(device.Status),
+		Warnings:             warnings,
+		RunningConfig:        device.RunningConfig,
+	}
+}
+
+// ToProtoFromStatus converts model representation of device status to protobuf.
+func ToProtoFromStatus(status model.DeviceStatus) pb.Snapshot_Device_Status {
+	switch status {
+	case model.StatusSuccess:
+		return pb.Snapshot_Device_STATUS_SUCCESS
+	case model.StatusDegraded:
+		return pb.Snapshot_Device_STATUS_DEGRADED
+	case model.StatusFailure:
+		return pb.Snapshot_Device_STATUS_FAILURE
+	default:
+		return pb.Snapshot_Device_STATUS_UNSPECIFIED
+	}
+}
+
+// ToProtoFromWarning converts model representation of warning to protobuf.
+func ToProtoFromWarning(warning model.Warning) *pb.Snapshot_Device_Warning {
+	return &pb.Snapshot_Device_Warning{
+		Command: warning.Command,
+		Field:   warning.Field,
+		Message: warning.Message,
 	}
 }
 
 // ToProtoFromInterface converts model representation of interface to protobuf.
+// An unknown last change is left unset.
 func ToProtoFromInterface(iface model.Interface) *pb.Snapshot_Device_Interface {
-	return &pb.Snapshot_Device_Interface{
-		Name: iface.Name,
-		IsUp: iface.IsUp,
-		Ip:   iface.IP.String(),
-		Mtu:  iface.MTU,
+	protoIface := &pb.Snapshot_Device_Interface{
+		Name:              iface.Name,
+		IsUp:              iface.IsUp,
+		Ip:                iface.IP.String(),
+		Mtu:               iface.MTU,
+		Description:       iface.Description,
+		NeighborHostname:  iface.NeighborHostname,
+		NeighborInterface: iface.NeighborInterface,
+	}
+	if !iface.LastChange.IsZero() {
+		protoIface.LastChange = timestamppb.New(iface.LastChange)
 	}
+
+	return protoIface
 }
 
 // ToDeviceFromProto converts protobuf representation of snapshot to model.
@@ -84,6 +124,11 @@ func ToDeviceFromProto(device *pb.Snapshot_Device) (*model.Device, error) {
 		ifaces[ifaceIdx] = *i
 	}
 
+	warnings := make([]model.Warning, len(device.Warnings))
+	for warningIdx, warning := range device.Warnings {
+		warnings[warningIdx] = ToWarningFromProto(warning)
+	}
+
 	return &model.Device{
 		Hostname:             device.Hostname,
 		Vendor:               device.Vendor,
@@ -91,10 +136,40 @@ func ToDeviceFromProto(device *pb.Snapshot_Device) (*model.Device, error) {
 		OSVersion:            device.OsVersion,
 		Serial:               device.Serial,
 		IsSnapshotSuccessful: device.IsSnapshotSuccessful,
+		Status:               ToStatusFromProto(device.Status, device.IsSnapshotSuccessful),
+		Warnings:             warnings,
 		Interfaces:           ifaces,
+		RunningConfig:        device.RunningConfig,
 	}, nil
 }
 
+// ToStatusFromProto converts protobuf representation of device status to model.
+// Clients that do not report a status are handled by deriving it from [isSnapshotSuccessful].
+func ToStatusFromProto(status pb.Snapshot_Device_Status, isSnapshotSuccessful bool) model.DeviceStatus {
+	switch status {
+	case pb.Snapshot_Device_STATUS_SUCCESS:
+		return model.StatusSuccess
+	case pb.Snapshot_Device_STATUS_DEGRADED:
+		return model.StatusDegraded
+	case pb.Snapshot_Device_STATUS_FAILURE:
+		return model.StatusFailure
+	default:
+		if isSnapshotSuccessful {
+			return model.StatusSuccess
+		}
+		return model.StatusFailure
+	}
+}
+
+// ToWarningFromProto converts protobuf representation of warning to model.
+func ToWarningFromProto(warning *pb.Snapshot_Device_Warning) model.Warning {
+	return model.Warning{
+		Command: warning.Command,
+		Field:   warning.Field,
+		Message: warning.Message,
+	}
+}
+
 // ToInterfaceFromProto converts protobuf representation of interface to model.
 func ToInterfaceFromProto(iface *pb.Snapshot_Device_Interface) (*model.Interface, error) {
 	ip, err := netip.ParsePrefix(iface.Ip)
@@ -104,10 +179,18 @@ func ToInterfaceFromProto(iface *pb.Snapshot_Device_Interface) (*model.Interface
 		}
 	}
 
-	return &model.Interface{
-		Name: iface.Name,
-		IsUp: iface.IsUp,
-		IP:   ip,
-		MTU:  iface.Mtu,
-	}, nil
+	modelIface := &model.Interface{
+		Name:              iface.Name,
+		IsUp:              iface.IsUp,
+		IP:                ip,
+		MTU:               iface.Mtu,
+		Description:       iface.Description,
+		NeighborHostname:  iface.NeighborHostname,
+		NeighborInterface: iface.NeighborInterface,
+	}
+	if iface.LastChange != nil {
+		modelIface.LastChange = iface.LastChange.AsTime()
+	}
+
+	return modelIface, nil
 }
@@ -2,6 +2,7 @@
 package model
 
 import (
+	"encoding/json"
 	"net/netip"
 	"time"
 )
@@ -20,13 +21,46 @@ type Snapshot struct {
 
 // Device describes a network device.
 type Device struct {
-	Hostname             string      `json:"hostname"`
-	Vendor               string      `json:"vendor"`
-	OSName               string      `json:"os_name"`
-	OSVersion            string      `json:"os_version"`
-	Serial               string      `json:"serial_number"`
-	IsSnapshotSuccessful bool        `json:"is_snapshot_successful"`
-	Interfaces           []Interface `json:"interfaces"`
+	Hostname             string       `json:"hostname"`
+	Vendor               string       `json:"vendor"`
+	OSName               string       `json:"os_name"`
+	OSVersion            string       `json:"os_version"`
+	Serial               string       `json:"serial_number"`
+	IsSnapshotSuccessful bool         `json:"is_snapshot_successful"`
+	Status               DeviceStatus `json:"status"`
+	Warnings             []Warning    `json:"warnings"`
+	Interfaces           []Interface  `json:"interfaces"`
+
+	// Running configuration as the device shows it, empty if it was not collected.
+	RunningConfig string `json:"running_config,omitempty"`
+}
+
+// DeviceStatus describes the outcome of a device snapshot.
+type DeviceStatus string
+
+// Possible device statuses.
+const (
+	// All commands succeeded and every field was parsed.
+	StatusSuccess DeviceStatus = "success"
+
+	// The device was reached, but some commands or fields failed.
+	// Data that was collected is kept and the problems are listed as warnings.
+	StatusDegraded DeviceStatus = "degraded"
+
+	// The device could not be reached or no data was collected.
+	StatusFailure DeviceStatus = "failure"
+)
+
+// Warning describes a problem encountered while collecting device data.
+type Warning struct {
+	// Command that caused the problem.
+	Command string `json:"command"`
+
+	// Output field that could not be parsed, empty if the whole command failed.
+	Field string `json:"field"`
+
+	// Description of the problem.
+	Message string `json:"message"`
 }
 
 // Interface describes a network device interface.
@@ -35,4 +69,34 @@ type Interface struct {
 	IsUp bool         `json:"is_up"`
 	IP   netip.Prefix `json:"ip"`
 	MTU  int64        `json:"mtu"`
+
+	// Description configured on the interface, empty if there is none.
+	// It is omitted from JSON when empty, so states of interfaces without descriptions keep their hashes.
+	Description string `json:"description,omitempty"`
+
+	// Time of the last change of the operational state reported by the device, zero if it is unknown.
+	// A change of this time without a change of the state means that the interface flapped between snapshots.
+	// It is omitted from JSON when zero, see [Interface.MarshalJSON].
+	LastChange time.Time `json:"last_change"`
+
+	// Hostname and interface of the neighbor discovered by LLDP, empty if there is none.
+	// They are omitted from JSON when empty, so states of interfaces without neighbors keep their hashes.
+	NeighborHostname  string `json:"neighbor_hostname,omitempty"`
+	NeighborInterface string `json:"neighbor_interface,omitempty"`
+}
+
+// MarshalJSON omits a zero LastChange, so states of interfaces without it keep their hashes.
+// The omitzero tag option is not used, since Go before 1.24 ignores it.
+func (i Interface) MarshalJSON() ([]byte, error) {
+	type plain Interface
+
+	var lastChange *time.Time
+	if !i.LastChange.IsZero() {
+		lastChange = &i.LastChange
+	}
+
+	return json.Marshal(struct {
+		plain
+		LastChange *time.Time `json:"last_change,omitempty"`
+	}{plain(i), lastChange})
 }
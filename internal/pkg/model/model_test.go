@@ -0,0 +1,53 @@
+package model
+
+import (
+	"encoding/json"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestInterfaceJSON(t *testing.T) {
+	lastChange := time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		iface Interface
+		want  string
+	}{
+		{
+			name:  "without last change",
+			iface: Interface{Name: "ethernet-1/1", IsUp: true, IP: netip.MustParsePrefix("10.0.0.1/31"), MTU: 9214},
+			want:  `{"name":"ethernet-1/1","is_up":true,"ip":"10.0.0.1/31","mtu":9214}`,
+		},
+		{
+			name:  "with last change",
+			iface: Interface{Name: "ethernet-1/1", Description: "uplink", LastChange: lastChange},
+			want:  `{"name":"ethernet-1/1","is_up":false,"ip":"","mtu":0,"description":"uplink","last_change":"2024-10-01T12:00:00Z"}`,
+		},
+		{
+			name:  "with neighbor",
+			iface: Interface{Name: "ethernet-1/1", NeighborHostname: "spine1", NeighborInterface: "ethernet-1/3"},
+			want:  `{"name":"ethernet-1/1","is_up":false,"ip":"","mtu":0,"neighbor_hostname":"spine1","neighbor_interface":"ethernet-1/3"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := json.Marshal(test.iface)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != test.want {
+				t.Errorf("json = %s, want %s", data, test.want)
+			}
+
+			var got Interface
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !got.LastChange.Equal(test.iface.LastChange) || got.Name != test.iface.Name ||
+				got.NeighborHostname != test.iface.NeighborHostname || got.NeighborInterface != test.iface.NeighborInterface {
+				t.Errorf("unmarshaled %+v, want %+v", got, test.iface)
+			}
+		})
+	}
+}
@@ -22,6 +22,58 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Snapshot_Device_Status int32
+
+const (
+	Snapshot_Device_STATUS_UNSPECIFIED Snapshot_Device_Status = 0
+	Snapshot_Device_STATUS_SUCCESS     Snapshot_Device_Status = 1
+	Snapshot_Device_STATUS_DEGRADED    Snapshot_Device_Status = 2
+	Snapshot_Device_STATUS_FAILURE     Snapshot_Device_Status = 3
+)
+
+// Enum value maps for Snapshot_Device_Status.
+var (
+	Snapshot_Device_Status_name = map[int32]string{
+		0: "STATUS_UNSPECIFIED",
+		1: "STATUS_SUCCESS",
+		2: "STATUS_DEGRADED",
+		3: "STATUS_FAILURE",
+	}
+	Snapshot_Device_Status_value = map[string]int32{
+		"STATUS_UNSPECIFIED": 0,
+		"STATUS_SUCCESS":     1,
+		"STATUS_DEGRADED":    2,
+		"STATUS_FAILURE":     3,
+	}
+)
+
+func (x Snapshot_Device_Status) Enum() *Snapshot_Device_Status {
+	p := new(Snapshot_Device_Status)
+	*p = x
+	return p
+}
+
+func (x Snapshot_Device_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Snapshot_Device_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_snapshots_proto_enumTypes[0].Descriptor()
+}
+
+func (Snapshot_Device_Status) Type() protoreflect.EnumType {
+	return &file_proto_snapshots_proto_enumTypes[0]
+}
+
+func (x Snapshot_Device_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Snapshot_Device_Status.Descriptor instead.
+func (Snapshot_Device_Status) EnumDescriptor() ([]byte, []int) {
+	return file_proto_snapshots_proto_rawDescGZIP(), []int{2, 0, 0}
+}
+
 type SaveSnapshotRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Snapshot      *Snapshot              `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"`
@@ -171,6 +223,9 @@ type Snapshot_Device struct {
 	Serial               string                       `protobuf:"bytes,5,opt,name=serial,proto3" json:"serial,omitempty"`
 	IsSnapshotSuccessful bool                         `protobuf:"varint,6,opt,name=is_snapshot_successful,json=isSnapshotSuccessful,proto3" json:"is_snapshot_successful,omitempty"`
 	Interfaces           []*Snapshot_Device_Interface `protobuf:"bytes,7,rep,name=interfaces,proto3" json:"interfaces,omitempty"`
+	Status               Snapshot_Device_Status       `protobuf:"varint,8,opt,name=status,proto3,enum=snapshots.Snapshot_Device_Status" json:"status,omitempty"`
+	Warnings             []*Snapshot_Device_Warning   `protobuf:"bytes,9,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	RunningConfig        string                       `protobuf:"bytes,10,opt,name=running_config,json=runningConfig,proto3" json:"running_config,omitempty"`
 	unknownFields        protoimpl.UnknownFields
 	sizeCache            protoimpl.SizeCache
 }
@@ -254,14 +309,39 @@ func (x *Snapshot_Device) GetInterfaces() []*Snapshot_Device_Interface {
 	return nil
 }
 
+func (x *Snapshot_Device) GetStatus() Snapshot_Device_Status {
+	if x != nil {
+		return x.Status
+	}
+	return Snapshot_Device_STATUS_UNSPECIFIED
+}
+
+func (x *Snapshot_Device) GetWarnings() []*Snapshot_Device_Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *Snapshot_Device) GetRunningConfig() string {
+	if x != nil {
+		return x.RunningConfig
+	}
+	return ""
+}
+
 type Snapshot_Device_Interface struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	IsUp          bool                   `protobuf:"varint,2,opt,name=is_up,json=isUp,proto3" json:"is_up,omitempty"`
-	Ip            string                 `protobuf:"bytes,3,opt,name=ip,proto3" json:"ip,omitempty"`
-	Mtu           int64                  `protobuf:"varint,4,opt,name=mtu,proto3" json:"mtu,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Name              string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	IsUp              bool                   `protobuf:"varint,2,opt,name=is_up,json=isUp,proto3" json:"is_up,omitempty"`
+	Ip                string                 `protobuf:"bytes,3,opt,name=ip,proto3" json:"ip,omitempty"`
+	Mtu               int64                  `protobuf:"varint,4,opt,name=mtu,proto3" json:"mtu,omitempty"`
+	Description       string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	LastChange        *timestamp.Timestamp   `protobuf:"bytes,6,opt,name=last_change,json=lastChange,proto3" json:"last_change,omitempty"`
+	NeighborHostname  string                 `protobuf:"bytes,7,opt,name=neighbor_hostname,json=neighborHostname,proto3" json:"neighbor_hostname,omitempty"`
+	NeighborInterface string                 `protobuf:"bytes,8,opt,name=neighbor_interface,json=neighborInterface,proto3" json:"neighbor_interface,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *Snapshot_Device_Interface) Reset() {
@@ -322,6 +402,94 @@ func (x *Snapshot_Device_Interface) GetMtu() int64 {
 	return 0
 }
 
+func (x *Snapshot_Device_Interface) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Snapshot_Device_Interface) GetLastChange() *timestamp.Timestamp {
+	if x != nil {
+		return x.LastChange
+	}
+	return nil
+}
+
+func (x *Snapshot_Device_Interface) GetNeighborHostname() string {
+	if x != nil {
+		return x.NeighborHostname
+	}
+	return ""
+}
+
+func (x *Snapshot_Device_Interface) GetNeighborInterface() string {
+	if x != nil {
+		return x.NeighborInterface
+	}
+	return ""
+}
+
+type Snapshot_Device_Warning struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Command       string                 `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Field         string                 `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snapshot_Device_Warning) Reset() {
+	*x = Snapshot_Device_Warning{}
+	mi := &file_proto_snapshots_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot_Device_Warning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot_Device_Warning) ProtoMessage() {}
+
+func (x *Snapshot_Device_Warning) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_snapshots_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot_Device_Warning.ProtoReflect.Descriptor instead.
+func (*Snapshot_Device_Warning) Descriptor() ([]byte, []int) {
+	return file_proto_snapshots_proto_rawDescGZIP(), []int{2, 0, 1}
+}
+
+func (x *Snapshot_Device_Warning) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *Snapshot_Device_Warning) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Snapshot_Device_Warning) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
 var File_proto_snapshots_proto protoreflect.FileDescriptor
 
 var file_proto_snapshots_proto_rawDesc = string([]byte{
@@ -336,7 +504,7 @@ var file_proto_snapshots_proto_rawDesc = string([]byte{
 	0x74, 0x52, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x22, 0x2c, 0x0a, 0x14, 0x53,
 	0x61, 0x76, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
 	0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xdd, 0x03, 0x0a, 0x08, 0x53, 0x6e,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xef, 0x07, 0x0a, 0x08, 0x53, 0x6e,
 	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
 	0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
 	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
@@ -344,7 +512,7 @@ var file_proto_snapshots_proto_rawDesc = string([]byte{
 	0x12, 0x34, 0x0a, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
 	0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x2e, 0x53, 0x6e,
 	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x07, 0x64,
-	0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x1a, 0xe0, 0x02, 0x0a, 0x06, 0x44, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x1a, 0xf2, 0x06, 0x0a, 0x06, 0x44, 0x65, 0x76, 0x69, 0x63,
 	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
 	0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a,
 	0x06, 0x76, 0x65, 0x6e, 0x64, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x76,
@@ -361,20 +529,53 @@ var file_proto_snapshots_proto_rawDesc = string([]byte{
 	0x24, 0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x2e, 0x53, 0x6e, 0x61, 0x70,
 	0x73, 0x68, 0x6f, 0x74, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x49, 0x6e, 0x74, 0x65,
 	0x72, 0x66, 0x61, 0x63, 0x65, 0x52, 0x0a, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65,
-	0x73, 0x1a, 0x56, 0x0a, 0x09, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x12, 0x12,
-	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x12, 0x13, 0x0a, 0x05, 0x69, 0x73, 0x5f, 0x75, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x08, 0x52, 0x04, 0x69, 0x73, 0x55, 0x70, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x74, 0x75, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6d, 0x74, 0x75, 0x32, 0x5c, 0x0a, 0x09, 0x53, 0x6e, 0x61,
-	0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x12, 0x4f, 0x0a, 0x0c, 0x53, 0x61, 0x76, 0x65, 0x53, 0x6e,
-	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x1e, 0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
-	0x74, 0x73, 0x2e, 0x53, 0x61, 0x76, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
-	0x74, 0x73, 0x2e, 0x53, 0x61, 0x76, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x11, 0x5a, 0x0f, 0x69, 0x6e, 0x74, 0x65, 0x72,
-	0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+	0x73, 0x12, 0x39, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x21, 0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x2e, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x3e, 0x0a, 0x08,
+	0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22,
+	0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x57, 0x61, 0x72, 0x6e, 0x69,
+	0x6e, 0x67, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x25, 0x0a, 0x0e,
+	0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x1a, 0x91, 0x02, 0x0a, 0x09, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x13, 0x0a, 0x05, 0x69, 0x73, 0x5f, 0x75, 0x70, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x69, 0x73, 0x55, 0x70, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x74,
+	0x75, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6d, 0x74, 0x75, 0x12, 0x20, 0x0a, 0x0b,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3b,
+	0x0a, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
+	0x0a, 0x6c, 0x61, 0x73, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x6e,
+	0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x5f, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x6e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72,
+	0x48, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2d, 0x0a, 0x12, 0x6e, 0x65, 0x69, 0x67,
+	0x68, 0x62, 0x6f, 0x72, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x6e, 0x65, 0x69, 0x67, 0x68, 0x62, 0x6f, 0x72, 0x49, 0x6e,
+	0x74, 0x65, 0x72, 0x66, 0x61, 0x63, 0x65, 0x1a, 0x53, 0x0a, 0x07, 0x57, 0x61, 0x72, 0x6e, 0x69,
+	0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x66, 0x69, 0x65,
+	0x6c, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x5d, 0x0a, 0x06,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x12, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53,
+	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x12,
+	0x0a, 0x0e, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x53, 0x55, 0x43, 0x43, 0x45, 0x53, 0x53,
+	0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x44, 0x45, 0x47,
+	0x52, 0x41, 0x44, 0x45, 0x44, 0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x54, 0x41, 0x54, 0x55,
+	0x53, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x55, 0x52, 0x45, 0x10, 0x03, 0x32, 0x5c, 0x0a, 0x09, 0x53,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x12, 0x4f, 0x0a, 0x0c, 0x53, 0x61, 0x76, 0x65,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x1e, 0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x73, 0x2e, 0x53, 0x61, 0x76, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x73, 0x2e, 0x53, 0x61, 0x76, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x11, 0x5a, 0x0f, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 })
 
 var (
@@ -389,27 +590,33 @@ func file_proto_snapshots_proto_rawDescGZIP() []byte {
 	return file_proto_snapshots_proto_rawDescData
 }
 
-var file_proto_snapshots_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_snapshots_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_snapshots_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_proto_snapshots_proto_goTypes = []any{
-	(*SaveSnapshotRequest)(nil),       // 0: snapshots.SaveSnapshotRequest
-	(*SaveSnapshotResponse)(nil),      // 1: snapshots.SaveSnapshotResponse
-	(*Snapshot)(nil),                  // 2: snapshots.Snapshot
-	(*Snapshot_Device)(nil),           // 3: snapshots.Snapshot.Device
-	(*Snapshot_Device_Interface)(nil), // 4: snapshots.Snapshot.Device.Interface
-	(*timestamp.Timestamp)(nil),       // 5: google.protobuf.Timestamp
+	(Snapshot_Device_Status)(0),       // 0: snapshots.Snapshot.Device.Status
+	(*SaveSnapshotRequest)(nil),       // 1: snapshots.SaveSnapshotRequest
+	(*SaveSnapshotResponse)(nil),      // 2: snapshots.SaveSnapshotResponse
+	(*Snapshot)(nil),                  // 3: snapshots.Snapshot
+	(*Snapshot_Device)(nil),           // 4: snapshots.Snapshot.Device
+	(*Snapshot_Device_Interface)(nil), // 5: snapshots.Snapshot.Device.Interface
+	(*Snapshot_Device_Warning)(nil),   // 6: snapshots.Snapshot.Device.Warning
+	(*timestamp.Timestamp)(nil),       // 7: google.protobuf.Timestamp
 }
 var file_proto_snapshots_proto_depIdxs = []int32{
-	2, // 0: snapshots.SaveSnapshotRequest.snapshot:type_name -> snapshots.Snapshot
-	5, // 1: snapshots.Snapshot.timestamp:type_name -> google.protobuf.Timestamp
-	3, // 2: snapshots.Snapshot.devices:type_name -> snapshots.Snapshot.Device
-	4, // 3: snapshots.Snapshot.Device.interfaces:type_name -> snapshots.Snapshot.Device.Interface
-	0, // 4: snapshots.Snapshots.SaveSnapshot:input_type -> snapshots.SaveSnapshotRequest
-	1, // 5: snapshots.Snapshots.SaveSnapshot:output_type -> snapshots.SaveSnapshotResponse
-	5, // [5:6] is the sub-list for method output_type
-	4, // [4:5] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	3, // 0: snapshots.SaveSnapshotRequest.snapshot:type_name -> snapshots.Snapshot
+	7, // 1: snapshots.Snapshot.timestamp:type_name -> google.protobuf.Timestamp
+	4, // 2: snapshots.Snapshot.devices:type_name -> snapshots.Snapshot.Device
+	5, // 3: snapshots.Snapshot.Device.interfaces:type_name -> snapshots.Snapshot.Device.Interface
+	0, // 4: snapshots.Snapshot.Device.status:type_name -> snapshots.Snapshot.Device.Status
+	6, // 5: snapshots.Snapshot.Device.warnings:type_name -> snapshots.Snapshot.Device.Warning
+	7, // 6: snapshots.Snapshot.Device.Interface.last_change:type_name -> google.protobuf.Timestamp
+	1, // 7: snapshots.Snapshots.SaveSnapshot:input_type -> snapshots.SaveSnapshotRequest
+	2, // 8: snapshots.Snapshots.SaveSnapshot:output_type -> snapshots.SaveSnapshotResponse
+	8, // [8:9] is the sub-list for method output_type
+	7, // [7:8] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_proto_snapshots_proto_init() }
@@ -422,13 +629,14 @@ func file_proto_snapshots_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_snapshots_proto_rawDesc), len(file_proto_snapshots_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   5,
+			NumEnums:      1,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_snapshots_proto_goTypes,
 		DependencyIndexes: file_proto_snapshots_proto_depIdxs,
+		EnumInfos:         file_proto_snapshots_proto_enumTypes,
 		MessageInfos:      file_proto_snapshots_proto_msgTypes,
 	}.Build()
 	File_proto_snapshots_proto = out.File
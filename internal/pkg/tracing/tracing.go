@@ -0,0 +1,90 @@
+// Package tracing provides a way to export OpenTelemetry traces.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Possible exporters.
+const (
+	None   = "none"
+	Stdout = "stdout"
+	OTLP   = "otlp"
+)
+
+// Shutdown exports the remaining spans and stops the exporter.
+type Shutdown func(ctx context.Context) error
+
+// Config describes where traces are exported.
+type Config struct {
+	// Service is the name of the service reported with every span.
+	Service string
+
+	// Exporter is one of [None], [Stdout] or [OTLP].
+	Exporter string
+
+	// Endpoint is the address of the OTLP gRPC receiver.
+	// If empty, the OTEL_EXPORTER_OTLP_ENDPOINT variable or localhost:4317 is used.
+	Endpoint string
+
+	// Insecure disables TLS for the OTLP receiver.
+	Insecure bool
+}
+
+// Setup sets the global tracer provider and the W3C trace context propagator,
+// so that spans are exported as set in the config and their context is passed in gRPC metadata.
+// With [None], spans are not recorded.
+func Setup(ctx context.Context, cfg Config) (Shutdown, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch cfg.Exporter {
+	case None, "":
+		return func(context.Context) error { return nil }, nil
+	case Stdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case OTLP:
+		opts := make([]otlptracegrpc.Option, 0)
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %s", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(cfg.Service)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
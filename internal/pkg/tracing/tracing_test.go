@@ -0,0 +1,392 @@
+package tracing
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/sudeeya/net-monitor/internal/client/client"
+	"github.com/sudeeya/net-monitor/internal/client/inventory"
+	"github.com/sudeeya/net-monitor/internal/client/snapper/snapshots"
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/api"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/sqlite"
+	serversnapshots "github.com/sudeeya/net-monitor/internal/server/services/snapshots"
+)
+
+// exporter records the spans of every test.
+var (
+	exporter    = tracetest.NewInMemoryExporter()
+	setProvider sync.Once
+)
+
+// Credentials accepted by the fake device.
+const (
+	deviceUsername = "admin"
+	devicePassword = "NokiaSrl1!"
+)
+
+const devicePrompt = "A:srl1# "
+
+const showVersionOutput = `--------------------------------------------------------------------------
+Hostname             : srl1
+Chassis Type         : 7220 IXR-D2L
+Part Number          : Sim Part No.
+Serial Number        : Sim Serial No.
+System HW MAC Address: 1A:B0:00:FF:00:00
+OS                   : SR Linux
+Software Version     : v24.10.1
+--------------------------------------------------------------------------
+`
+
+const runningConfigOutput = `set / system ntp server 10.0.0.1 admin-state enable
+`
+
+// fakeDevice is an SSH server that answers SR Linux commands with stored outputs.
+// It also acts as a jump host forwarding every connection to itself,
+// since the client connects to target devices on the default SSH port.
+type fakeDevice struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	outputs  map[string]string
+}
+
+// newFakeDevice starts a fake device listening on a local port.
+func newFakeDevice(t *testing.T, outputs map[string]string) *fakeDevice {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() != deviceUsername || string(password) != devicePassword {
+				return nil, errors.New("wrong username or password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	d := &fakeDevice{listener: listener, config: config, outputs: outputs}
+	go d.serve()
+
+	return d
+}
+
+// port returns the port the device listens on.
+func (d *fakeDevice) port() int {
+	return d.listener.Addr().(*net.TCPAddr).Port
+}
+
+// serve accepts connections until the listener is closed.
+func (d *fakeDevice) serve() {
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		go d.handle(conn)
+	}
+}
+
+// handle serves a single SSH connection: shells and forwarded connections.
+func (d *fakeDevice) handle(conn net.Conn) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, d.config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "direct-tcpip":
+			target, err := net.Dial("tcp", d.listener.Addr().String())
+			if err != nil {
+				_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				target.Close()
+				continue
+			}
+			go ssh.DiscardRequests(requests)
+			go func() {
+				defer channel.Close()
+				_, _ = io.Copy(channel, target)
+			}()
+			go func() {
+				defer target.Close()
+				_, _ = io.Copy(target, channel)
+			}()
+		case "session":
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			go func() {
+				for req := range requests {
+					ok := req.Type == "pty-req" || req.Type == "shell"
+					_ = req.Reply(ok, nil)
+					if req.Type == "shell" {
+						go d.shell(channel)
+					}
+				}
+			}()
+		default:
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+		}
+	}
+}
+
+// shell echoes the input as a terminal does and answers every line with the stored output.
+func (d *fakeDevice) shell(channel ssh.Channel) {
+	defer channel.Close()
+
+	if _, err := io.WriteString(channel, devicePrompt); err != nil {
+		return
+	}
+
+	var line strings.Builder
+	b := make([]byte, 1)
+	for {
+		if _, err := channel.Read(b); err != nil {
+			return
+		}
+		if b[0] != '\n' && b[0] != '\r' {
+			line.WriteByte(b[0])
+			if _, err := channel.Write(b); err != nil {
+				return
+			}
+			continue
+		}
+
+		output := d.outputs[strings.TrimSpace(line.String())]
+		line.Reset()
+		if _, err := io.WriteString(channel, "\n"+output+"\n"+devicePrompt); err != nil {
+			return
+		}
+	}
+}
+
+// spansByName indexes exported spans by name.
+func spansByName(spans tracetest.SpanStubs) map[string][]tracetest.SpanStub {
+	byName := make(map[string][]tracetest.SpanStub)
+	for _, span := range spans {
+		byName[span.Name] = append(byName[span.Name], span)
+	}
+
+	return byName
+}
+
+// onlySpan returns the single span with the name.
+func onlySpan(t *testing.T, byName map[string][]tracetest.SpanStub, name string) tracetest.SpanStub {
+	t.Helper()
+
+	spans := byName[name]
+	if len(spans) != 1 {
+		t.Fatalf("spans named %s: %d, want 1", name, len(spans))
+	}
+
+	return spans[0]
+}
+
+// assertChild fails the test unless the span is a child of the parent.
+func assertChild(t *testing.T, span, parent tracetest.SpanStub) {
+	t.Helper()
+
+	if span.Parent.SpanID() != parent.SpanContext.SpanID() {
+		t.Errorf("span %s is a child of %s, want %s", span.Name, span.Parent.SpanID(), parent.Name)
+	}
+}
+
+func TestSpansFromDeviceToDatabase(t *testing.T) {
+	ctx := context.Background()
+
+	detail, err := os.ReadFile(filepath.Join("..", "..", "client", "snapper", "snapshots", "testdata", "nokia_srlinux_show_interface_detail.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	neighbors, err := os.ReadFile(filepath.Join("..", "..", "client", "snapper", "snapshots", "testdata", "nokia_srlinux_show_system_lldp_neighbor.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	device := newFakeDevice(t, map[string]string{
+		"show version":              showVersionOutput,
+		"show interface detail":     string(detail),
+		"show system lldp neighbor": string(neighbors),
+		"info flat from running":    runningConfigOutput,
+	})
+
+	// Templates are looked up from the repository root, where the client is run.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(filepath.Join("..", "..", "..")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	if _, err := Setup(ctx, Config{Service: "test", Exporter: None}); err != nil {
+		t.Fatal(err)
+	}
+	// Tracers of instrumented packages keep the first global provider, so it is set once.
+	setProvider.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	})
+	exporter.Reset()
+
+	repo, err := sqlite.NewSQLite(zap.NewNop(), "file:"+filepath.Join(t.TempDir(), "net-monitor.db"), repository.DefaultIdentity())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	if err := repo.Migrator().Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := api.NewSnapshotsGRPCServer(zap.NewNop(), serversnapshots.NewSnapshots(zap.NewNop(), repo, nil), 1<<20)
+	go func() { _ = server.Serve(listener) }()
+
+	inv := staticInventory{{
+		Hostname:    "127.0.0.1",
+		OS:          "nokia_srlinux",
+		Username:    deviceUsername,
+		Password:    devicePassword,
+		NoStrictKey: true,
+		JumpHosts: []inventory.JumpHost{{
+			Hostname:    "127.0.0.1",
+			Port:        device.port(),
+			Username:    deviceUsername,
+			Password:    devicePassword,
+			NoStrictKey: true,
+		}},
+	}}
+	snapper, err := snapshots.NewSnapshots(zap.NewNop(), inv, 0, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := client.NewClient(zap.NewNop(), snapper, listener.Addr().String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.UploadSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	// Server spans end after the response is sent.
+	server.GracefulStop()
+
+	timestamps, err := repo.GetNTimestamps(ctx, 1)
+	if err != nil || len(timestamps) != 1 {
+		t.Fatalf("timestamps = %v, %v, want one", timestamps, err)
+	}
+	saved, err := repo.GetSnapshot(ctx, timestamps[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Devices) != 1 || saved.Devices[0].Status != model.StatusSuccess || saved.Devices[0].RunningConfig != strings.TrimSpace(runningConfigOutput) {
+		t.Fatalf("saved devices = %+v, want srl1 collected completely", saved.Devices)
+	}
+	if iface := saved.Devices[0].Interfaces[0]; iface.NeighborHostname != "spine1" || iface.NeighborInterface != "ethernet-1/3" {
+		t.Errorf("saved interface = %+v, want the LLDP neighbor spine1 ethernet-1/3", iface)
+	}
+
+	spans := exporter.GetSpans()
+	byName := spansByName(spans)
+
+	upload := onlySpan(t, byName, "UploadSnapshot")
+	snap := onlySpan(t, byName, "Snap")
+	snapTarget := onlySpan(t, byName, "SnapTarget")
+	connect := onlySpan(t, byName, "ssh.Connect")
+	assertChild(t, snap, upload)
+	assertChild(t, snapTarget, snap)
+	assertChild(t, connect, snapTarget)
+
+	commands := byName["ssh.SendCommand"]
+	if len(commands) != 4 {
+		t.Errorf("ssh.SendCommand spans: %d, want 4", len(commands))
+	}
+	for _, span := range commands {
+		assertChild(t, span, snapTarget)
+	}
+	parses := byName["textfsm.Parse"]
+	if len(parses) != 3 {
+		t.Errorf("textfsm.Parse spans: %d, want 3", len(parses))
+	}
+	for _, span := range parses {
+		assertChild(t, span, snapTarget)
+	}
+
+	var rpcClient, rpcServer tracetest.SpanStub
+	for _, span := range byName["snapshots.Snapshots/SaveSnapshot"] {
+		switch span.SpanKind {
+		case trace.SpanKindClient:
+			rpcClient = span
+		case trace.SpanKindServer:
+			rpcServer = span
+		}
+	}
+	if !rpcClient.SpanContext.IsValid() || !rpcServer.SpanContext.IsValid() {
+		t.Fatalf("want client and server gRPC spans, got %v", byName["snapshots.Snapshots/SaveSnapshot"])
+	}
+	assertChild(t, rpcClient, upload)
+	assertChild(t, rpcServer, rpcClient)
+	if !rpcServer.Parent.IsRemote() {
+		t.Error("server gRPC span does not continue a remote trace")
+	}
+
+	save := onlySpan(t, byName, "SaveSnapshot")
+	store := onlySpan(t, byName, "sqlite.StoreSnapshot")
+	assertChild(t, save, rpcServer)
+	assertChild(t, store, save)
+
+	for _, span := range spans {
+		if span.SpanContext.TraceID() != upload.SpanContext.TraceID() {
+			t.Errorf("span %s belongs to trace %s, want %s", span.Name, span.SpanContext.TraceID(), upload.SpanContext.TraceID())
+		}
+	}
+}
+
+// staticInventory returns the targets it holds.
+type staticInventory []inventory.Target
+
+func (i staticInventory) Targets(ctx context.Context) ([]inventory.Target, error) {
+	return i, nil
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
@@ -19,13 +20,18 @@ type snapshotsImplementation struct {
 }
 
 // NewSnapshotsGRPCServer returns snapshotsGRPCServer object.
-func NewSnapshotsGRPCServer(logger *zap.Logger, service services.SnapshotsService) *grpc.Server {
+// Requests continue the traces whose context is passed in their metadata.
+// Snapshots larger than [maxRecvBytes], which grow with running configurations of devices, are rejected.
+func NewSnapshotsGRPCServer(logger *zap.Logger, service services.SnapshotsService, maxRecvBytes int) *grpc.Server {
 	snapshots := &snapshotsImplementation{
 		logger:  logger,
 		service: service,
 	}
 
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.MaxRecvMsgSize(maxRecvBytes),
+	)
 	pb.RegisterSnapshotsServer(grpcServer, snapshots)
 
 	return grpcServer
@@ -2,12 +2,14 @@ package api
 
 import (
 	"html/template"
+	"net/http"
 	"path/filepath"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 
 	"github.com/sudeeya/net-monitor/internal/server/handlers"
+	"github.com/sudeeya/net-monitor/internal/server/metrics"
 	"github.com/sudeeya/net-monitor/internal/server/services"
 )
 
@@ -16,13 +18,38 @@ const (
 	defaultEndpoint       = "/"
 	getTimestampsEndpoint = "/timestamps"
 	getSnapshotEndpoint   = "/snapshots"
+
+	getDeviceHistoryEndpoint    = "/device-history"
+	getInterfaceHistoryEndpoint = "/interface-history"
+	getFlappingEndpoint         = "/flapping"
+
+	getComplianceEndpoint       = "/compliance"
+	getDeviceComplianceEndpoint = "/device-compliance"
+	getIPConflictsEndpoint      = "/ip-conflicts"
+	getIPAMEndpoint             = "/ipam"
+	getTopologyEndpoint         = "/topology"
+	getTopologyDiffEndpoint     = "/topology-diff"
+
+	exportEndpoint = "/export"
+	importEndpoint = "/import"
+
+	metricsEndpoint = "/metrics"
 )
 
 // snapshotsHTTPServer defines object to interact with the server using HTTP.
 type snapshotsHTTPServer struct {
 	*chi.Mux
-	logger  *zap.Logger
-	service services.SnapshotsService
+	logger         *zap.Logger
+	service        services.SnapshotsService
+	historyService services.HistoryService
+	archiveService services.ArchiveService
+
+	complianceService  services.ComplianceService
+	ipConflictsService services.IPConflictsService
+	ipamService        services.IPAMService
+	topologyService    services.TopologyService
+
+	metrics *metrics.Metrics
 }
 
 // Paths to HTML files.
@@ -31,23 +58,60 @@ var (
 	indexPath      = filepath.Join("assets", "html", "index.html")
 	timestampsPath = filepath.Join("assets", "html", "timestamps.html")
 	snapshotsPath  = filepath.Join("assets", "html", "snapshots.html")
+
+	deviceHistoryPath    = filepath.Join("assets", "html", "device_history.html")
+	interfaceHistoryPath = filepath.Join("assets", "html", "interface_history.html")
+	flappingPath         = filepath.Join("assets", "html", "flapping.html")
+
+	compliancePath       = filepath.Join("assets", "html", "compliance.html")
+	deviceCompliancePath = filepath.Join("assets", "html", "device_compliance.html")
+	ipConflictsPath      = filepath.Join("assets", "html", "ip_conflicts.html")
+	ipamPath             = filepath.Join("assets", "html", "ipam.html")
+	topologyPath         = filepath.Join("assets", "html", "topology.html")
+	topologyDiffPath     = filepath.Join("assets", "html", "topology_diff.html")
 )
 
 // NewSnapshotsHTTPServer returns snapshotsHTTPServer object.
-func NewSnapshotsHTTPServer(logger *zap.Logger, service services.SnapshotsService) (*snapshotsHTTPServer, error) {
+// If [serverMetrics] is not nil, requests are counted and metrics are served on the metrics endpoint.
+// Archives can be exported and imported only if the [importCfg] sets a token.
+func NewSnapshotsHTTPServer(
+	logger *zap.Logger,
+	service services.SnapshotsService,
+	historyService services.HistoryService,
+	archiveService services.ArchiveService,
+	importCfg handlers.ImportConfig,
+	complianceService services.ComplianceService,
+	ipConflictsService services.IPConflictsService,
+	ipamService services.IPAMService,
+	topologyService services.TopologyService,
+	serverMetrics *metrics.Metrics,
+) (*snapshotsHTTPServer, error) {
 	mux := chi.NewRouter()
+	if serverMetrics != nil {
+		mux.Use(serverMetrics.Middleware)
+		mux.Method(http.MethodGet, metricsEndpoint, serverMetrics.Handler())
+	}
 
 	tmpls, err := parseHTMLFiles()
 	if err != nil {
 		return nil, err
 	}
 
-	registerEndpoints(mux, logger, service, tmpls)
+	registerEndpoints(mux, logger, service, historyService, archiveService, importCfg, complianceService, ipConflictsService, ipamService, topologyService, tmpls)
 
 	return &snapshotsHTTPServer{
-		Mux:     mux,
-		logger:  logger,
-		service: service,
+		Mux:            mux,
+		logger:         logger,
+		service:        service,
+		historyService: historyService,
+		archiveService: archiveService,
+
+		complianceService:  complianceService,
+		ipConflictsService: ipConflictsService,
+		ipamService:        ipamService,
+		topologyService:    topologyService,
+
+		metrics: serverMetrics,
 	}, nil
 }
 
@@ -68,10 +132,64 @@ func parseHTMLFiles() (map[string]*template.Template, error) {
 		return nil, err
 	}
 
+	deviceHistoryTmpl, err := template.ParseFiles(deviceHistoryPath, commonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	interfaceHistoryTmpl, err := template.ParseFiles(interfaceHistoryPath, commonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	flappingTmpl, err := template.ParseFiles(flappingPath, commonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	complianceTmpl, err := template.ParseFiles(compliancePath, commonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceComplianceTmpl, err := template.ParseFiles(deviceCompliancePath, commonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ipConflictsTmpl, err := template.ParseFiles(ipConflictsPath, commonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ipamTmpl, err := template.ParseFiles(ipamPath, commonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	topologyTmpl, err := template.ParseFiles(topologyPath, commonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	topologyDiffTmpl, err := template.ParseFiles(topologyDiffPath, commonPath)
+	if err != nil {
+		return nil, err
+	}
+
 	return map[string]*template.Template{
-		defaultEndpoint:       indexTmpl,
-		getTimestampsEndpoint: timestampsTmpl,
-		getSnapshotEndpoint:   snapshotsTmpl,
+		defaultEndpoint:             indexTmpl,
+		getTimestampsEndpoint:       timestampsTmpl,
+		getSnapshotEndpoint:         snapshotsTmpl,
+		getDeviceHistoryEndpoint:    deviceHistoryTmpl,
+		getInterfaceHistoryEndpoint: interfaceHistoryTmpl,
+		getFlappingEndpoint:         flappingTmpl,
+		getComplianceEndpoint:       complianceTmpl,
+		getDeviceComplianceEndpoint: deviceComplianceTmpl,
+		getIPConflictsEndpoint:      ipConflictsTmpl,
+		getIPAMEndpoint:             ipamTmpl,
+		getTopologyEndpoint:         topologyTmpl,
+		getTopologyDiffEndpoint:     topologyDiffTmpl,
 	}, nil
 }
 
@@ -80,9 +198,29 @@ func registerEndpoints(
 	mux *chi.Mux,
 	logger *zap.Logger,
 	service services.SnapshotsService,
+	historyService services.HistoryService,
+	archiveService services.ArchiveService,
+	importCfg handlers.ImportConfig,
+	complianceService services.ComplianceService,
+	ipConflictsService services.IPConflictsService,
+	ipamService services.IPAMService,
+	topologyService services.TopologyService,
 	tmpls map[string]*template.Template,
 ) {
 	mux.Get(defaultEndpoint, handlers.DefaultHandler(logger, tmpls[defaultEndpoint]))
 	mux.Get(getTimestampsEndpoint, handlers.GetTimestampsHandler(logger, service, tmpls[getTimestampsEndpoint]))
 	mux.Get(getSnapshotEndpoint, handlers.GetSnapshotHandler(logger, service, tmpls[getSnapshotEndpoint]))
+	mux.Get(getDeviceHistoryEndpoint, handlers.GetDeviceHistoryHandler(logger, historyService, tmpls[getDeviceHistoryEndpoint]))
+	mux.Get(getInterfaceHistoryEndpoint, handlers.GetInterfaceHistoryHandler(logger, historyService, tmpls[getInterfaceHistoryEndpoint]))
+	mux.Get(getFlappingEndpoint, handlers.GetFlappingInterfacesHandler(logger, historyService, tmpls[getFlappingEndpoint]))
+	mux.Get(getComplianceEndpoint, handlers.GetComplianceReportHandler(logger, complianceService, tmpls[getComplianceEndpoint]))
+	mux.Get(getDeviceComplianceEndpoint, handlers.GetDeviceComplianceHandler(logger, complianceService, tmpls[getDeviceComplianceEndpoint]))
+	mux.Get(getIPConflictsEndpoint, handlers.GetIPConflictsHandler(logger, ipConflictsService, tmpls[getIPConflictsEndpoint]))
+	mux.Get(getIPAMEndpoint, handlers.GetPrefixTreeHandler(logger, ipamService, tmpls[getIPAMEndpoint]))
+	mux.Get(getTopologyEndpoint, handlers.GetTopologyHandler(logger, topologyService, tmpls[getTopologyEndpoint]))
+	mux.Get(getTopologyDiffEndpoint, handlers.GetTopologyDiffHandler(logger, topologyService, tmpls[getTopologyDiffEndpoint]))
+	if importCfg.Token != "" {
+		mux.Get(exportEndpoint, handlers.ExportHandler(logger, archiveService, importCfg.Token))
+		mux.Post(importEndpoint, handlers.ImportHandler(logger, archiveService, importCfg))
+	}
 }
@@ -2,6 +2,7 @@
 package app
 
 import (
+	"context"
 	"errors"
 	"io/fs"
 	"log"
@@ -10,42 +11,59 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 
+	"github.com/sudeeya/net-monitor/internal/pkg/tracing"
 	"github.com/sudeeya/net-monitor/internal/server/config"
 	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/services"
 )
 
 // app describes server application and all necessary layers.
 type app struct {
-	cfg        *config.Config
-	logger     *zap.Logger
-	repo       repository.Repository
-	handler    http.Handler
-	grpcServer *grpc.Server
+	cfg           *config.Config
+	logger        *zap.Logger
+	repo          repository.Repository
+	handler       http.Handler
+	grpcServer    *grpc.Server
+	retention     services.RetentionService
+	notifications services.NotificationsService
+	tracing       tracing.Shutdown
 }
 
+// Time limit for exporting the remaining spans on shutdown.
+const tracingLimitInSeconds = 5
+
 // NewApp returns app object to interact with server.
+// If [retention] is nil, snapshots are not pruned; if [notifications] is nil, no notifications are sent.
+// [shutdownTracing] is called on shutdown to export the remaining spans.
 func NewApp(
 	cfg *config.Config,
 	logger *zap.Logger,
 	repo repository.Repository,
 	handler http.Handler,
 	grpcServer *grpc.Server,
+	retention services.RetentionService,
+	notifications services.NotificationsService,
+	shutdownTracing tracing.Shutdown,
 ) *app {
 	return &app{
-		cfg:        cfg,
-		logger:     logger,
-		repo:       repo,
-		handler:    handler,
-		grpcServer: grpcServer,
+		cfg:           cfg,
+		logger:        logger,
+		repo:          repo,
+		handler:       handler,
+		grpcServer:    grpcServer,
+		retention:     retention,
+		notifications: notifications,
+		tracing:       shutdownTracing,
 	}
 }
 
 // Run starts the server.
-// It initiates listening for HTTP and gRPC requests and monitors for OS signals.
+// It initiates listening for HTTP and gRPC requests, prunes snapshots, sends notifications and monitors for OS signals.
 func (a *app) Run() {
 	a.logger.Info("Server is running")
 
@@ -71,14 +89,29 @@ func (a *app) Run() {
 		}
 	}()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	if a.retention != nil {
+		go a.retention.Run(ctx)
+	}
+	if a.notifications != nil {
+		go a.notifications.Run(ctx)
+	}
+
 	<-sigCh
 	a.logger.Info("Server is shutting down")
+	cancel()
 	a.Shutdown()
 }
 
 // Shutdown shuts down the server.
-// It syncs server logger before shutdown.
+// It exports the remaining spans and syncs server logger before shutdown.
 func (a *app) Shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), tracingLimitInSeconds*time.Second)
+	defer cancel()
+	if err := a.tracing(ctx); err != nil {
+		log.Printf("Failed to export spans: %v\n", err)
+	}
+
 	var pathErr fs.PathError
 	if err := a.logger.Sync(); err != nil && errors.Is(err, &pathErr) {
 		log.Printf("Failed to sync logger: %v\n", err)
@@ -1,15 +1,45 @@
 // Package config defines server config.
 package config
 
-import "github.com/caarlos0/env"
+import (
+	"fmt"
+	"time"
+
+	"github.com/caarlos0/env"
+)
+
+// Possible database drivers.
+const (
+	PostgresDriver = "postgres"
+	SQLiteDriver   = "sqlite"
+	MemoryDriver   = "memory"
+)
 
 // Config describes server config.
 type Config struct {
-	HTTPAddr    string `env:"HTTP_ADDR" envDefault:"localhost:8080"`
-	GRPCAddr    string `env:"GRPC_ADDR" envDefault:"localhost:9090"`
-	DatabaseDSN string `env:"DATABASE_DSN,required"`
-	LogLevel    string `env:"LOG_LEVEL" envDefault:"INFO"`
-	LogFile     string `env:"LOG_FILE"`
+	HTTPAddr              string        `env:"HTTP_ADDR" envDefault:"localhost:8080"`
+	GRPCAddr              string        `env:"GRPC_ADDR" envDefault:"localhost:9090"`
+	GRPCMaxRecvBytes      int           `env:"GRPC_MAX_RECV_BYTES" envDefault:"67108864"`
+	DatabaseDriver        string        `env:"DATABASE_DRIVER" envDefault:"postgres"`
+	DatabaseDSN           string        `env:"DATABASE_DSN"`
+	DatabaseAutoMigrate   bool          `env:"DATABASE_AUTO_MIGRATE" envDefault:"true"`
+	DatabaseBulkThreshold int           `env:"DATABASE_BULK_THRESHOLD" envDefault:"20"`
+	RetentionPolicy       string        `env:"RETENTION_POLICY"`
+	RetentionInterval     time.Duration `env:"RETENTION_INTERVAL" envDefault:"1h"`
+	RetentionDryRun       bool          `env:"RETENTION_DRY_RUN"`
+	DeviceIdentity        string        `env:"DEVICE_IDENTITY" envDefault:"serial"`
+	DeviceIgnoredSerials  []string      `env:"DEVICE_IGNORED_SERIALS" envSeparator:","`
+	AlertRulesFile        string        `env:"ALERT_RULES_FILE"`
+	NotificationsFile     string        `env:"NOTIFICATIONS_FILE"`
+	CompliancePolicies    string        `env:"COMPLIANCE_POLICIES_FILE"`
+	IPConflictsIgnored    []string      `env:"IP_CONFLICTS_IGNORED_PREFIXES" envSeparator:","`
+	ImportToken           string        `env:"IMPORT_TOKEN"`
+	ImportMaxBytes        int64         `env:"IMPORT_MAX_BYTES" envDefault:"268435456"`
+	LogLevel              string        `env:"LOG_LEVEL" envDefault:"INFO"`
+	LogFile               string        `env:"LOG_FILE"`
+	TracingExporter       string        `env:"TRACING_EXPORTER" envDefault:"none"`
+	TracingEndpoint       string        `env:"TRACING_ENDPOINT"`
+	TracingInsecure       bool          `env:"TRACING_INSECURE"`
 }
 
 // NewConfig returns server config.
@@ -20,5 +50,31 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
+	switch cfg.DatabaseDriver {
+	case PostgresDriver, SQLiteDriver:
+		if cfg.DatabaseDSN == "" {
+			return nil, fmt.Errorf("database dsn is not specified")
+		}
+	case MemoryDriver:
+	default:
+		return nil, fmt.Errorf("unknown database driver: %s", cfg.DatabaseDriver)
+	}
+
+	if cfg.DatabaseBulkThreshold < 0 {
+		return nil, fmt.Errorf("database bulk threshold must not be negative")
+	}
+
+	if cfg.GRPCMaxRecvBytes <= 0 {
+		return nil, fmt.Errorf("grpc max recv bytes must be positive")
+	}
+
+	if cfg.ImportMaxBytes <= 0 {
+		return nil, fmt.Errorf("import max bytes must be positive")
+	}
+
+	if cfg.RetentionInterval <= 0 {
+		return nil, fmt.Errorf("retention interval must be positive")
+	}
+
 	return &cfg, nil
 }
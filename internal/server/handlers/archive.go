@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// archiveLimitInSeconds limits export and import, which read or write many snapshots.
+const archiveLimitInSeconds = 300
+
+// ExportHandler returns an http.HandlerFunc that writes snapshots taken in the range
+// set by the from and to parameters to the response as an archive.
+// By default, the range includes all snapshots taken until now.
+// Archives hold running configurations of devices, so requests without the [token] are rejected.
+// If an error occurs, it logs the error and returns an appropriate HTTP status code.
+func ExportHandler(logger *zap.Logger, service services.ArchiveService, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, token) {
+			logger.Error("Export request with a missing or invalid token")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), archiveLimitInSeconds*time.Second)
+		defer cancel()
+
+		query := r.URL.Query()
+
+		from, to := time.Unix(0, 0), time.Now()
+		var err error
+		if query.Get("from") != "" {
+			if from, err = parseTime(query.Get("from")); err != nil {
+				logger.Error(err.Error())
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if query.Get("to") != "" {
+			if to, err = parseTime(query.Get("to")); err != nil {
+				logger.Error(err.Error())
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set(
+			"Content-Disposition",
+			fmt.Sprintf("attachment; filename=%q", "snapshots-"+time.Now().UTC().Format("20060102T150405Z")+".jsonl.gz"),
+		)
+
+		// The status is sent with the first written bytes, so a failure can only be logged.
+		if _, err := service.Export(ctx, w, from, to); err != nil {
+			logger.Error(err.Error())
+			return
+		}
+	}
+}
+
+// ImportConfig describes who may import archives over HTTP and how large the archives may be.
+type ImportConfig struct {
+	// Token must be sent as a bearer token in the Authorization header.
+	// Browsers do not add the header to cross-site requests, so forms on other sites cannot import archives.
+	Token string
+
+	// MaxBytes limits the size of the compressed archive in the request body.
+	MaxBytes int64
+}
+
+// ImportHandler returns an http.HandlerFunc that stores snapshots from the archive in the request body
+// and writes the number of imported and skipped snapshots to the response.
+// Requests without the token of the [cfg] are rejected, and bodies larger than its limit are cut off.
+// If an error occurs, it logs the error and returns an appropriate HTTP status code.
+func ImportHandler(logger *zap.Logger, service services.ArchiveService, cfg ImportConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, cfg.Token) {
+			logger.Error("Import request with a missing or invalid token")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), archiveLimitInSeconds*time.Second)
+		defer cancel()
+
+		result, err := service.Import(ctx, http.MaxBytesReader(w, r.Body, cfg.MaxBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			logger.Error(err.Error())
+			http.Error(w, fmt.Sprintf("archive is larger than %d bytes; imported %d snapshots", tooLarge.Limit, result.Imported), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if errors.Is(err, services.ErrInvalidArchive) {
+			logger.Error(err.Error())
+			http.Error(w, fmt.Sprintf("%s; imported %d snapshots", err, result.Imported), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, fmt.Sprintf("%s; imported %d snapshots", err, result.Imported), http.StatusInternalServerError)
+			return
+		}
+
+		fmt.Fprintf(w, "Imported %d snapshots, skipped %d already stored\n", result.Imported, result.Skipped)
+	}
+}
+
+// authorized reports whether the request carries the token as a bearer token in the Authorization header.
+func authorized(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
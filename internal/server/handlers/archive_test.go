@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// fakeArchive reads the whole archive on import and counts its bytes as imported snapshots.
+type fakeArchive struct{}
+
+func (fakeArchive) Export(context.Context, io.Writer, time.Time, time.Time) (int, error) {
+	return 0, nil
+}
+
+func (fakeArchive) Import(_ context.Context, r io.Reader) (services.ImportResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return services.ImportResult{}, fmt.Errorf("%w: %w", services.ErrInvalidArchive, err)
+	}
+
+	return services.ImportResult{Imported: len(data)}, nil
+}
+
+func TestImportHandler(t *testing.T) {
+	handler := ImportHandler(zap.NewNop(), fakeArchive{}, ImportConfig{Token: "secret", MaxBytes: 8})
+
+	tests := []struct {
+		name          string
+		authorization string
+		body          string
+		wantCode      int
+	}{
+		{name: "imported", authorization: "Bearer secret", body: "archive", wantCode: http.StatusOK},
+		{name: "missing token", body: "archive", wantCode: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer wrong", body: "archive", wantCode: http.StatusUnauthorized},
+		{name: "basic auth", authorization: "Basic c2VjcmV0", body: "archive", wantCode: http.StatusUnauthorized},
+		{name: "too large", authorization: "Bearer secret", body: "large archive", wantCode: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(tt.body))
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			handler(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestExportHandler(t *testing.T) {
+	handler := ExportHandler(zap.NewNop(), fakeArchive{}, "secret")
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantCode      int
+	}{
+		{name: "exported", authorization: "Bearer secret", wantCode: http.StatusOK},
+		{name: "missing token", wantCode: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer wrong", wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/export", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			handler(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// GetComplianceReportHandler returns an http.HandlerFunc that requests the compliance report
+// of the snapshot set by the id parameter from the service and writes it to the response.
+// If the id parameter is not set, the report of the latest snapshot is written.
+// If an error occurs, it logs the error and returns an appropriate HTTP status code.
+func GetComplianceReportHandler(logger *zap.Logger, service services.ComplianceService, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
+		defer cancel()
+
+		id, err := parseOptionalID(r.URL.Query(), "id")
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		report, err := service.GetReport(ctx, id)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err = tmpl.Execute(w, report); err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// deviceCompliancePage is the data of the device compliance template.
+type deviceCompliancePage struct {
+	Hostname string
+	From     time.Time
+	To       time.Time
+	Reports  []services.DeviceCompliance
+}
+
+// GetDeviceComplianceHandler returns an http.HandlerFunc that requests the compliance reports of the device
+// set by the hostname parameter from the service and writes them to the response.
+// The time range is set by the from and to parameters; by default, it is the last week.
+// If an error occurs, it logs the error and returns an appropriate HTTP status code.
+func GetDeviceComplianceHandler(logger *zap.Logger, service services.ComplianceService, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
+		defer cancel()
+
+		query := r.URL.Query()
+		page := deviceCompliancePage{Hostname: query.Get("hostname")}
+		if page.Hostname == "" {
+			logger.Error("hostname is not set")
+			http.Error(w, "hostname is not set", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		page.From, page.To, err = parseRange(query)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		page.Reports, err = service.GetDeviceReports(ctx, page.Hostname, page.From, page.To)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err = tmpl.Execute(w, page); err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
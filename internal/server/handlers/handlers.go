@@ -3,6 +3,8 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"html/template"
 	"net/http"
 	"strconv"
@@ -10,10 +12,15 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
 	"github.com/sudeeya/net-monitor/internal/server/services"
 )
 
-const limitInSeconds = 5
+const (
+	limitInSeconds   = 5
+	defaultPageLimit = 20
+)
 
 // DefaultHandler returns an http.HandlerFunc that writes default page to the response.
 // If an error occurs, it logs the error and returns an appropriate HTTP status code.
@@ -27,29 +34,84 @@ func DefaultHandler(logger *zap.Logger, tmpl *template.Template) http.HandlerFun
 	}
 }
 
+// timestampsPage is the data of the timestamps template.
+// Next and Limit are set if timestamps are paged.
+type timestampsPage struct {
+	Timestamps []model.Snapshot
+	Next       string
+	Limit      int
+}
+
 // GetTimestampsHandler returns an http.HandlerFunc that requests a list of
 // snapshot ids and timestamps from the service and writes them to the response.
+// Timestamps are paged with the cursor and limit parameters, selected by the from and to parameters,
+// or limited to the last count timestamps.
 // If an error occurs, it logs the error and returns an appropriate HTTP status code.
 func GetTimestampsHandler(logger *zap.Logger, service services.SnapshotsService, tmpl *template.Template) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
 		defer cancel()
 
-		n, err := strconv.Atoi(r.URL.Query().Get("count"))
-		if err != nil {
-			logger.Error(err.Error())
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
+		query := r.URL.Query()
 
-		timestamps, err := service.GetNTimestamps(ctx, n)
+		var (
+			page timestampsPage
+			err  error
+		)
+		switch {
+		case query.Has("count"):
+			n, convErr := strconv.Atoi(query.Get("count"))
+			if convErr != nil {
+				logger.Error(convErr.Error())
+				http.Error(w, convErr.Error(), http.StatusBadRequest)
+				return
+			}
+
+			page.Timestamps, err = service.GetNTimestamps(ctx, n)
+		case query.Has("from") || query.Has("to"):
+			from, parseErr := parseTime(query.Get("from"))
+			if parseErr != nil {
+				logger.Error(parseErr.Error())
+				http.Error(w, parseErr.Error(), http.StatusBadRequest)
+				return
+			}
+
+			to, parseErr := parseTime(query.Get("to"))
+			if parseErr != nil {
+				logger.Error(parseErr.Error())
+				http.Error(w, parseErr.Error(), http.StatusBadRequest)
+				return
+			}
+
+			page.Timestamps, err = service.GetTimestampsBetween(ctx, from, to)
+		default:
+			page.Limit = defaultPageLimit
+			if query.Has("limit") {
+				page.Limit, err = strconv.Atoi(query.Get("limit"))
+				if err != nil || page.Limit <= 0 {
+					logger.Sugar().Errorf("invalid limit: %q", query.Get("limit"))
+					http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+					return
+				}
+			}
+
+			var timestamps services.TimestampsPage
+			timestamps, err = service.GetTimestampsPage(ctx, query.Get("cursor"), page.Limit)
+			if errors.Is(err, services.ErrInvalidCursor) {
+				logger.Error(err.Error())
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			page.Timestamps = timestamps.Timestamps
+			page.Next = timestamps.Next
+		}
 		if err != nil {
 			logger.Error(err.Error())
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		if err = tmpl.Execute(w, timestamps); err != nil {
+		if err = tmpl.Execute(w, page); err != nil {
 			logger.Error(err.Error())
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
@@ -59,30 +121,73 @@ func GetTimestampsHandler(logger *zap.Logger, service services.SnapshotsService,
 
 // GetSnapshotHandler returns an http.HandlerFunc that requests a snapshot
 // from the service and writes it to the response.
+// The snapshot is selected by the id parameter or as the one closest to the at parameter.
+// Devices are filtered by the hostname, vendor, os_name and os_version parameters.
 // If an error occurs, it logs the error and returns an appropriate HTTP status code.
 func GetSnapshotHandler(logger *zap.Logger, service services.SnapshotsService, tmpl *template.Template) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
 		defer cancel()
 
-		id, err := strconv.Atoi(r.URL.Query().Get("id"))
-		if err != nil {
-			logger.Error(err.Error())
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
+		query := r.URL.Query()
+		filter := repository.DeviceFilter{
+			Hostname:  query.Get("hostname"),
+			Vendor:    query.Get("vendor"),
+			OSName:    query.Get("os_name"),
+			OSVersion: query.Get("os_version"),
 		}
 
-		snapshot, err := service.GetSnapshot(ctx, id)
-		if err != nil {
-			logger.Error(err.Error())
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+		var snapshot model.Snapshot
+		if query.Get("at") != "" {
+			at, err := parseTime(query.Get("at"))
+			if err != nil {
+				logger.Error(err.Error())
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			snapshot, err = service.GetClosestSnapshot(ctx, at, filter)
+			if err != nil {
+				logger.Error(err.Error())
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		} else {
+			id, err := strconv.Atoi(query.Get("id"))
+			if err != nil {
+				logger.Error(err.Error())
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			snapshot, err = service.GetFilteredSnapshot(ctx, id, filter)
+			if err != nil {
+				logger.Error(err.Error())
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
 		}
 
-		if err = tmpl.Execute(w, snapshot); err != nil {
+		if err := tmpl.Execute(w, snapshot); err != nil {
 			logger.Error(err.Error())
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 	}
 }
+
+// parseTime parses a time in RFC 3339 format or in the format of an HTML datetime-local input.
+// A time without a time zone is in the local time zone of the server.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, want RFC 3339 or YYYY-MM-DDThh:mm", value)
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// defaultHistoryRange is the time range of a history if the from parameter is not set.
+const defaultHistoryRange = 7 * 24 * time.Hour
+
+// defaultMinDowns is the number of times an interface must go down to be flapping if the min_downs parameter is not set.
+const defaultMinDowns = 3
+
+// flappingLimitInSeconds limits the search for flapping interfaces, which reads the history of every device.
+const flappingLimitInSeconds = 60
+
+// historyPage is the data of the history templates.
+type historyPage[T any] struct {
+	From    time.Time
+	To      time.Time
+	History T
+}
+
+// GetDeviceHistoryHandler returns an http.HandlerFunc that requests the history of the device
+// set by the hostname parameter from the service and writes it to the response.
+// The time range is set by the from and to parameters; by default, it is the last week.
+// If an error occurs, it logs the error and returns an appropriate HTTP status code.
+func GetDeviceHistoryHandler(logger *zap.Logger, service services.HistoryService, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
+		defer cancel()
+
+		query := r.URL.Query()
+		hostname := query.Get("hostname")
+		if hostname == "" {
+			logger.Error("hostname is not set")
+			http.Error(w, "hostname is not set", http.StatusBadRequest)
+			return
+		}
+
+		from, to, err := parseRange(query)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		history, err := service.GetDeviceHistory(ctx, hostname, from, to)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		page := historyPage[services.DeviceHistory]{
+			From:    from,
+			To:      to,
+			History: history,
+		}
+		if err = tmpl.Execute(w, page); err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// GetInterfaceHistoryHandler returns an http.HandlerFunc that requests the history of the interface
+// set by the hostname and name parameters from the service and writes it to the response.
+// The time range is set by the from and to parameters; by default, it is the last week.
+// If an error occurs, it logs the error and returns an appropriate HTTP status code.
+func GetInterfaceHistoryHandler(logger *zap.Logger, service services.HistoryService, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
+		defer cancel()
+
+		query := r.URL.Query()
+		hostname, name := query.Get("hostname"), query.Get("name")
+		if hostname == "" || name == "" {
+			logger.Error("hostname or interface name is not set")
+			http.Error(w, "hostname or interface name is not set", http.StatusBadRequest)
+			return
+		}
+
+		from, to, err := parseRange(query)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		history, err := service.GetInterfaceHistory(ctx, hostname, name, from, to)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		page := historyPage[services.InterfaceHistory]{
+			From:    from,
+			To:      to,
+			History: history,
+		}
+		if err = tmpl.Execute(w, page); err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// flappingPage is the data of the flapping interfaces template.
+type flappingPage struct {
+	From       time.Time
+	To         time.Time
+	MinDowns   int
+	Interfaces []services.FlappingInterface
+}
+
+// GetFlappingInterfacesHandler returns an http.HandlerFunc that requests interfaces that went down
+// at least min_downs times from the service and writes them to the response.
+// The time range is set by the from and to parameters; by default, it is the last week.
+// If an error occurs, it logs the error and returns an appropriate HTTP status code.
+func GetFlappingInterfacesHandler(logger *zap.Logger, service services.HistoryService, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(context.Background(), flappingLimitInSeconds*time.Second)
+		defer cancel()
+
+		query := r.URL.Query()
+
+		page := flappingPage{MinDowns: defaultMinDowns}
+		if query.Get("min_downs") != "" {
+			minDowns, err := strconv.Atoi(query.Get("min_downs"))
+			if err != nil || minDowns <= 0 {
+				logger.Sugar().Errorf("invalid min_downs: %q", query.Get("min_downs"))
+				http.Error(w, "min_downs must be a positive integer", http.StatusBadRequest)
+				return
+			}
+			page.MinDowns = minDowns
+		}
+
+		var err error
+		page.From, page.To, err = parseRange(query)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		page.Interfaces, err = service.GetFlappingInterfaces(ctx, page.From, page.To, page.MinDowns)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err = tmpl.Execute(w, page); err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// parseRange returns the time range set by the from and to parameters.
+// If to is not set, the range ends now; if from is not set, the range starts [defaultHistoryRange] before its end.
+func parseRange(query url.Values) (from, to time.Time, err error) {
+	to = time.Now()
+	if query.Get("to") != "" {
+		to, err = parseTime(query.Get("to"))
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	from = to.Add(-defaultHistoryRange)
+	if query.Get("from") != "" {
+		from, err = parseTime(query.Get("from"))
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// GetPrefixTreeHandler returns an http.HandlerFunc that requests the tree of the subnets
+// of the latest snapshot from the service and writes it to the response.
+// If the format parameter is json, the tree is written as json instead of HTML.
+// If an error occurs, it logs the error and returns an appropriate HTTP status code.
+func GetPrefixTreeHandler(logger *zap.Logger, service services.IPAMService, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
+		defer cancel()
+
+		tree, err := service.GetPrefixTree(ctx)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if r.URL.Query().Get("format") == "json" {
+			writeJSON(logger, w, tree)
+			return
+		}
+
+		if err = tmpl.Execute(w, tree); err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// GetIPConflictsHandler returns an http.HandlerFunc that requests the IP conflicts of the snapshot
+// set by the id parameter from the service and writes them to the response.
+// If the id parameter is not set, the conflicts of the latest snapshot are written;
+// the severity parameter keeps only the conflicts with this severity.
+// If the format parameter is json, the conflicts are written as json instead of HTML.
+// If an error occurs, it logs the error and returns an appropriate HTTP status code.
+func GetIPConflictsHandler(logger *zap.Logger, service services.IPConflictsService, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
+		defer cancel()
+
+		query := r.URL.Query()
+
+		id, err := parseOptionalID(query, "id")
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		report, err := service.GetConflicts(ctx, id, query.Get("severity"))
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if query.Get("format") == "json" {
+			writeJSON(logger, w, report)
+			return
+		}
+
+		if err = tmpl.Execute(w, report); err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// writeJSON writes v to the response as json.
+func writeJSON(logger *zap.Logger, w http.ResponseWriter, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		logger.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(body); err != nil {
+		logger.Error(err.Error())
+	}
+}
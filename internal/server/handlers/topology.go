@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// Size of the topology drawing in pixels; nodes are placed on a circle.
+const (
+	topologySize   = 800
+	topologyRadius = 320
+)
+
+// Colors of links and nodes in topology drawings and DOT graphs.
+const (
+	upColor       = "green"
+	downColor     = "red"
+	degradedColor = "orange"
+)
+
+// topologyPage is the data of the topology template.
+type topologyPage struct {
+	services.Topology
+	Size  int
+	Nodes []drawnNode
+	Links []drawnLink
+}
+
+// drawnNode describes a device placed on the drawing.
+type drawnNode struct {
+	services.TopologyNode
+	X, Y  int
+	Color string
+}
+
+// drawnLink describes a link placed on the drawing.
+type drawnLink struct {
+	services.Link
+	X1, Y1, X2, Y2 int
+	Color          string
+}
+
+// GetTopologyHandler returns an http.HandlerFunc that requests the topology of the snapshot
+// set by the id parameter from the service and writes it to the response.
+// If the id parameter is not set, the topology of the latest snapshot is written.
+// If the format parameter is json or dot, the topology is written as json or as a Graphviz DOT graph instead of HTML.
+// If an error occurs, it logs the error and returns an appropriate HTTP status code.
+func GetTopologyHandler(logger *zap.Logger, service services.TopologyService, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
+		defer cancel()
+
+		query := r.URL.Query()
+
+		id, err := parseOptionalID(query, "id")
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		topology, err := service.GetTopology(ctx, id)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		switch query.Get("format") {
+		case "json":
+			writeJSON(logger, w, topology)
+			return
+		case "dot":
+			w.Header().Set("Content-Type", "text/vnd.graphviz")
+			w.Header().Set("Content-Disposition",
+				fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("topology-%d.dot", topology.SnapshotID)))
+			if _, err := w.Write([]byte(toDOT(topology))); err != nil {
+				logger.Error(err.Error())
+			}
+			return
+		}
+
+		if err = tmpl.Execute(w, draw(topology)); err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// GetTopologyDiffHandler returns an http.HandlerFunc that requests the links that appeared and disappeared
+// between the snapshots set by the from and to parameters from the service and writes them to the response.
+// If the format parameter is json, the diff is written as json instead of HTML.
+// If an error occurs, it logs the error and returns an appropriate HTTP status code.
+func GetTopologyDiffHandler(logger *zap.Logger, service services.TopologyService, tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
+		defer cancel()
+
+		query := r.URL.Query()
+
+		fromID, err := strconv.Atoi(query.Get("from"))
+		if err != nil || fromID <= 0 {
+			logger.Sugar().Errorf("invalid from: %q", query.Get("from"))
+			http.Error(w, "from must be a snapshot id", http.StatusBadRequest)
+			return
+		}
+		toID, err := strconv.Atoi(query.Get("to"))
+		if err != nil || toID <= 0 {
+			logger.Sugar().Errorf("invalid to: %q", query.Get("to"))
+			http.Error(w, "to must be a snapshot id", http.StatusBadRequest)
+			return
+		}
+
+		diff, err := service.GetTopologyDiff(ctx, fromID, toID)
+		if err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if query.Get("format") == "json" {
+			writeJSON(logger, w, diff)
+			return
+		}
+
+		if err = tmpl.Execute(w, diff); err != nil {
+			logger.Error(err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// parseOptionalID returns the positive id set by the parameter, or 0 if it is not set.
+func parseOptionalID(query url.Values, name string) (int, error) {
+	if query.Get(name) == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.Atoi(query.Get(name))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+
+	return id, nil
+}
+
+// draw places the nodes of the topology on a circle.
+func draw(topology services.Topology) topologyPage {
+	page := topologyPage{
+		Topology: topology,
+		Size:     topologySize,
+		Nodes:    make([]drawnNode, len(topology.Nodes)),
+		Links:    make([]drawnLink, len(topology.Links)),
+	}
+
+	positions := make(map[string]drawnNode, len(topology.Nodes))
+	for nodeIdx, node := range topology.Nodes {
+		angle := 2 * math.Pi * float64(nodeIdx) / float64(len(topology.Nodes))
+		page.Nodes[nodeIdx] = drawnNode{
+			TopologyNode: node,
+			X:            topologySize/2 + int(topologyRadius*math.Cos(angle)),
+			Y:            topologySize/2 + int(topologyRadius*math.Sin(angle)),
+			Color:        nodeColor(node.Status),
+		}
+		positions[node.Hostname] = page.Nodes[nodeIdx]
+	}
+
+	for linkIdx, link := range topology.Links {
+		a, b := positions[link.A.Hostname], positions[link.B.Hostname]
+		page.Links[linkIdx] = drawnLink{
+			Link:  link,
+			X1:    a.X,
+			Y1:    a.Y,
+			X2:    b.X,
+			Y2:    b.Y,
+			Color: linkColor(link),
+		}
+	}
+
+	return page
+}
+
+// toDOT returns the topology as an undirected Graphviz DOT graph.
+func toDOT(topology services.Topology) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "graph %s {\n", strconv.Quote(fmt.Sprintf("snapshot %d", topology.SnapshotID)))
+	for _, node := range topology.Nodes {
+		fmt.Fprintf(&b, "\t%s [color=%s];\n", strconv.Quote(node.Hostname), nodeColor(node.Status))
+	}
+	for _, link := range topology.Links {
+		attributes := []string{
+			"taillabel=" + strconv.Quote(link.A.Interface),
+			"headlabel=" + strconv.Quote(link.B.Interface),
+		}
+		if link.Subnet.IsValid() {
+			attributes = append(attributes, "label="+strconv.Quote(link.Subnet.String()))
+		}
+		attributes = append(attributes, "color="+linkColor(link))
+		// Links inferred from subnets are dashed, since they may not be cabled as drawn.
+		if link.Source == services.LinkSourceSubnet {
+			attributes = append(attributes, "style=dashed")
+		}
+
+		fmt.Fprintf(&b, "\t%s -- %s [%s];\n",
+			strconv.Quote(link.A.Hostname), strconv.Quote(link.B.Hostname), strings.Join(attributes, ", "))
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// nodeColor returns the color of a device with the status.
+func nodeColor(status model.DeviceStatus) string {
+	switch status {
+	case model.StatusFailure:
+		return downColor
+	case model.StatusDegraded:
+		return degradedColor
+	default:
+		return upColor
+	}
+}
+
+// linkColor returns the color of the link by the state of its ends.
+func linkColor(link services.Link) string {
+	if link.Up() {
+		return upColor
+	}
+
+	return downColor
+}
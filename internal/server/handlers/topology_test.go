@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// testTopology has a link found by LLDP without a shared subnet and a link inferred from a subnet that is down.
+var testTopology = services.Topology{
+	SnapshotID: 7,
+	Timestamp:  time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC),
+	Nodes: []services.TopologyNode{
+		{Hostname: "leaf1", Status: model.StatusSuccess},
+		{Hostname: "leaf2", Status: model.StatusDegraded},
+		{Hostname: "spine1", Status: model.StatusFailure},
+	},
+	Links: []services.Link{
+		{
+			A:      services.LinkEnd{Hostname: "leaf1", Interface: "ethernet-1/1", IsUp: true},
+			B:      services.LinkEnd{Hostname: "spine1", Interface: "ethernet-1/3", IsUp: true},
+			Source: services.LinkSourceLLDP,
+		},
+		{
+			A:      services.LinkEnd{Hostname: "leaf2", Interface: "ethernet-1/1", IsUp: true},
+			B:      services.LinkEnd{Hostname: "spine1", Interface: "ethernet-1/4"},
+			Source: services.LinkSourceSubnet,
+			Subnet: netip.MustParsePrefix("10.0.0.2/31"),
+		},
+	},
+}
+
+// fakeTopology returns testTopology whatever the snapshot.
+type fakeTopology struct{}
+
+func (fakeTopology) GetTopology(context.Context, int) (services.Topology, error) {
+	return testTopology, nil
+}
+
+func (fakeTopology) GetTopologyDiff(context.Context, int, int) (services.TopologyDiff, error) {
+	return services.TopologyDiff{From: testTopology, To: testTopology, Added: testTopology.Links, Removed: []services.Link{}}, nil
+}
+
+func TestToDOT(t *testing.T) {
+	want := `graph "snapshot 7" {
+	"leaf1" [color=green];
+	"leaf2" [color=orange];
+	"spine1" [color=red];
+	"leaf1" -- "spine1" [taillabel="ethernet-1/1", headlabel="ethernet-1/3", color=green];
+	"leaf2" -- "spine1" [taillabel="ethernet-1/1", headlabel="ethernet-1/4", label="10.0.0.2/31", color=red, style=dashed];
+}
+`
+	if got := toDOT(testTopology); got != want {
+		t.Errorf("DOT =\n%s\nwant\n%s", got, want)
+	}
+
+	if got, want := toDOT(services.Topology{}), "graph \"snapshot 0\" {\n}\n"; got != want {
+		t.Errorf("DOT of an empty topology = %q, want %q", got, want)
+	}
+}
+
+func TestGetTopologyHandler(t *testing.T) {
+	tmpl := template.Must(template.ParseFiles(
+		filepath.Join("..", "..", "..", "assets", "html", "topology.html"),
+		filepath.Join("..", "..", "..", "assets", "html", "common.html"),
+	))
+	handler := GetTopologyHandler(zap.NewNop(), fakeTopology{}, tmpl)
+
+	t.Run("json", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/topology?format=json", nil))
+
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+			t.Fatalf("status = %d, content type %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+
+		var links struct {
+			Links []struct {
+				A      services.LinkEnd `json:"a"`
+				B      services.LinkEnd `json:"b"`
+				Source string           `json:"source"`
+				Subnet string           `json:"subnet"`
+			} `json:"links"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &links); err != nil {
+			t.Fatal(err)
+		}
+		if len(links.Links) != 2 {
+			t.Fatalf("links = %+v, want 2", links.Links)
+		}
+		if links.Links[0].Source != "lldp" || links.Links[0].Subnet != "" || links.Links[0].A != testTopology.Links[0].A {
+			t.Errorf("first link = %+v, want the LLDP link without a subnet", links.Links[0])
+		}
+		if links.Links[1].Source != "subnet" || links.Links[1].Subnet != "10.0.0.2/31" || links.Links[1].B != testTopology.Links[1].B {
+			t.Errorf("second link = %+v, want the subnet link", links.Links[1])
+		}
+
+		var topology services.Topology
+		if err := json.Unmarshal(w.Body.Bytes(), &topology); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(topology, testTopology) {
+			t.Errorf("topology = %+v, want %+v", topology, testTopology)
+		}
+	})
+
+	t.Run("dot", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/topology?format=dot", nil))
+
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/vnd.graphviz" {
+			t.Fatalf("status = %d, content type %q", w.Code, w.Header().Get("Content-Type"))
+		}
+		if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="topology-7.dot"` {
+			t.Errorf("content disposition = %q", got)
+		}
+		if w.Body.String() != toDOT(testTopology) {
+			t.Errorf("body = %s, want the DOT graph", w.Body.String())
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/topology", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+		}
+		body := w.Body.String()
+		for _, want := range []string{"Subnet (inferred)", "LLDP", "10.0.0.2/31", `stroke-dasharray="6 4"`} {
+			if !strings.Contains(body, want) {
+				t.Errorf("page does not contain %q", want)
+			}
+		}
+		if strings.Contains(body, "invalid Prefix") {
+			t.Error("page shows the missing subnet of the LLDP link as invalid")
+		}
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/topology?id=-1", nil))
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestGetTopologyDiffHandler(t *testing.T) {
+	tmpl := template.Must(template.ParseFiles(
+		filepath.Join("..", "..", "..", "assets", "html", "topology_diff.html"),
+		filepath.Join("..", "..", "..", "assets", "html", "common.html"),
+	))
+	handler := GetTopologyDiffHandler(zap.NewNop(), fakeTopology{}, tmpl)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode int
+	}{
+		{name: "html", query: "from=1&to=7", wantCode: http.StatusOK},
+		{name: "json", query: "from=1&to=7&format=json", wantCode: http.StatusOK},
+		{name: "missing from", query: "to=7", wantCode: http.StatusBadRequest},
+		{name: "invalid to", query: "from=1&to=x", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, "/topology-diff?"+tt.query, nil))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
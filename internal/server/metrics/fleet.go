@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+)
+
+// Time limit for reading the latest snapshot on a scrape.
+const scrapeLimitInSeconds = 10
+
+// Descriptions of the fleet metrics.
+var (
+	snapshotAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "snapshot_age_seconds"),
+		"Time since the latest snapshot was taken.",
+		nil, nil,
+	)
+	deviceUpDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "device_up"),
+		"Whether the device was reached in the latest snapshot.",
+		[]string{"hostname", "status"}, nil,
+	)
+	interfaceUpDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "interface_up"),
+		"Whether the interface was operationally up in the latest snapshot.",
+		[]string{"hostname", "interface"}, nil,
+	)
+	interfaceMTUDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "interface_mtu_bytes"),
+		"MTU of the interface in the latest snapshot.",
+		[]string{"hostname", "interface"}, nil,
+	)
+	devicesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "devices"),
+		"Number of devices of the latest snapshot by vendor, OS and OS version.",
+		[]string{"vendor", "os_name", "os_version"}, nil,
+	)
+)
+
+var _ prometheus.Collector = (*fleetCollector)(nil)
+
+// fleetCollector implements the [prometheus.Collector] interface with the state of the devices of the latest snapshot.
+type fleetCollector struct {
+	logger *zap.Logger
+	repo   repository.Repository
+}
+
+// newFleetCollector returns fleetCollector object reading the latest snapshot of [repo].
+func newFleetCollector(logger *zap.Logger, repo repository.Repository) *fleetCollector {
+	return &fleetCollector{
+		logger: logger,
+		repo:   repo,
+	}
+}
+
+// Describe implements the [prometheus.Collector] interface.
+func (c *fleetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- snapshotAgeDesc
+	ch <- deviceUpDesc
+	ch <- interfaceUpDesc
+	ch <- interfaceMTUDesc
+	ch <- devicesDesc
+}
+
+// Collect implements the [prometheus.Collector] interface.
+// If there are no snapshots, no fleet metrics are collected.
+func (c *fleetCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeLimitInSeconds*time.Second)
+	defer cancel()
+
+	snapshot, err := c.latest(ctx)
+	if err != nil {
+		c.logger.Sugar().Errorf("Failed to collect fleet metrics: %v", err)
+		ch <- prometheus.NewInvalidMetric(snapshotAgeDesc, err)
+		return
+	}
+	if snapshot.ID == 0 {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(snapshotAgeDesc, prometheus.GaugeValue, time.Since(snapshot.Timestamp).Seconds())
+
+	// Label values must be unique, so repeated devices and interfaces are reported once.
+	type version struct {
+		vendor, osName, osVersion string
+	}
+	versions := make(map[version]int)
+	seenDevices := make(map[string]bool, len(snapshot.Devices))
+	for _, device := range snapshot.Devices {
+		if seenDevices[device.Hostname] {
+			continue
+		}
+		seenDevices[device.Hostname] = true
+
+		ch <- prometheus.MustNewConstMetric(deviceUpDesc, prometheus.GaugeValue,
+			boolToFloat(device.Status != model.StatusFailure), device.Hostname, string(device.Status))
+		versions[version{device.Vendor, device.OSName, device.OSVersion}]++
+
+		seenInterfaces := make(map[string]bool, len(device.Interfaces))
+		for _, iface := range device.Interfaces {
+			if seenInterfaces[iface.Name] {
+				continue
+			}
+			seenInterfaces[iface.Name] = true
+
+			ch <- prometheus.MustNewConstMetric(interfaceUpDesc, prometheus.GaugeValue,
+				boolToFloat(iface.IsUp), device.Hostname, iface.Name)
+			ch <- prometheus.MustNewConstMetric(interfaceMTUDesc, prometheus.GaugeValue,
+				float64(iface.MTU), device.Hostname, iface.Name)
+		}
+	}
+
+	for v, count := range versions {
+		ch <- prometheus.MustNewConstMetric(devicesDesc, prometheus.GaugeValue, float64(count), v.vendor, v.osName, v.osVersion)
+	}
+}
+
+// latest returns the latest snapshot, or an empty snapshot if there are none.
+func (c *fleetCollector) latest(ctx context.Context) (model.Snapshot, error) {
+	timestamps, err := c.repo.GetNTimestamps(ctx, 1)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+	if len(timestamps) == 0 {
+		return model.Snapshot{}, nil
+	}
+
+	snapshot, err := c.repo.GetSnapshot(ctx, timestamps[0].ID)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+	snapshot.ID, snapshot.Timestamp = timestamps[0].ID, timestamps[0].Timestamp
+
+	return snapshot, nil
+}
+
+// boolToFloat returns 1 if b is true and 0 otherwise.
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
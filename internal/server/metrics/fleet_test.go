@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+)
+
+// latestRepository returns the snapshot as the latest one, or the error.
+// Other methods of [Repository] are not implemented.
+type latestRepository struct {
+	repository.Repository
+	snapshot model.Snapshot
+	err      error
+}
+
+func (r latestRepository) GetNTimestamps(context.Context, int) ([]model.Snapshot, error) {
+	if r.err != nil || r.snapshot.ID == 0 {
+		return []model.Snapshot{}, r.err
+	}
+	return []model.Snapshot{{ID: r.snapshot.ID, Timestamp: r.snapshot.Timestamp}}, nil
+}
+
+func (r latestRepository) GetSnapshot(context.Context, int) (model.Snapshot, error) {
+	// Like the repositories, the snapshot is returned without its id and timestamp.
+	return model.Snapshot{Devices: r.snapshot.Devices}, r.err
+}
+
+func TestFleetCollector(t *testing.T) {
+	repo := latestRepository{snapshot: model.Snapshot{
+		ID:        3,
+		Timestamp: time.Now().Add(-time.Minute),
+		Devices: []model.Device{
+			{
+				Hostname: "srl1", Vendor: "Nokia", OSName: "SR Linux", OSVersion: "v24.10.1", Status: model.StatusSuccess,
+				Interfaces: []model.Interface{
+					{Name: "ethernet-1/1", IsUp: true, MTU: 9232},
+					{Name: "ethernet-1/2", MTU: 1500},
+					// Interfaces are reported once.
+					{Name: "ethernet-1/1", MTU: 1500},
+				},
+			},
+			{Hostname: "srl2", Vendor: "Nokia", OSName: "SR Linux", OSVersion: "v24.10.1", Status: model.StatusDegraded},
+			{Hostname: "ceos1", Status: model.StatusFailure},
+			// Devices are reported once.
+			{Hostname: "srl2", Vendor: "Nokia", OSName: "SR Linux", OSVersion: "v23.10.1", Status: model.StatusFailure},
+		},
+	}}
+	c := newFleetCollector(zap.NewNop(), repo)
+
+	want := `
+# HELP netmonitor_device_up Whether the device was reached in the latest snapshot.
+# TYPE netmonitor_device_up gauge
+netmonitor_device_up{hostname="ceos1",status="failure"} 0
+netmonitor_device_up{hostname="srl1",status="success"} 1
+netmonitor_device_up{hostname="srl2",status="degraded"} 1
+# HELP netmonitor_devices Number of devices of the latest snapshot by vendor, OS and OS version.
+# TYPE netmonitor_devices gauge
+netmonitor_devices{os_name="",os_version="",vendor=""} 1
+netmonitor_devices{os_name="SR Linux",os_version="v24.10.1",vendor="Nokia"} 2
+# HELP netmonitor_interface_mtu_bytes MTU of the interface in the latest snapshot.
+# TYPE netmonitor_interface_mtu_bytes gauge
+netmonitor_interface_mtu_bytes{hostname="srl1",interface="ethernet-1/1"} 9232
+netmonitor_interface_mtu_bytes{hostname="srl1",interface="ethernet-1/2"} 1500
+# HELP netmonitor_interface_up Whether the interface was operationally up in the latest snapshot.
+# TYPE netmonitor_interface_up gauge
+netmonitor_interface_up{hostname="srl1",interface="ethernet-1/1"} 1
+netmonitor_interface_up{hostname="srl1",interface="ethernet-1/2"} 0
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(want),
+		"netmonitor_device_up", "netmonitor_devices", "netmonitor_interface_mtu_bytes", "netmonitor_interface_up")
+	if err != nil {
+		t.Error(err)
+	}
+
+	if problems, err := testutil.CollectAndLint(c); err != nil || len(problems) > 0 {
+		t.Errorf("lint problems: %+v, %v", problems, err)
+	}
+
+	ch := make(chan prometheus.Metric, 64)
+	c.Collect(ch)
+	close(ch)
+	for metric := range ch {
+		if metric.Desc() != snapshotAgeDesc {
+			continue
+		}
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatal(err)
+		}
+		if age := m.GetGauge().GetValue(); age < 60 || age > 120 {
+			t.Errorf("snapshot age = %v seconds, want about a minute", age)
+		}
+	}
+}
+
+func TestFleetCollectorWithoutSnapshots(t *testing.T) {
+	c := newFleetCollector(zap.NewNop(), latestRepository{})
+
+	if n := testutil.CollectAndCount(c); n != 0 {
+		t.Errorf("metrics without snapshots = %d, want 0", n)
+	}
+}
+
+func TestFleetCollectorError(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(newFleetCollector(zap.NewNop(), latestRepository{err: errors.New("database is locked")}))
+
+	if _, err := registry.Gather(); err == nil || !strings.Contains(err.Error(), "database is locked") {
+		t.Errorf("gather error = %v, want the repository error", err)
+	}
+}
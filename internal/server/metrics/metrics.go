@@ -0,0 +1,134 @@
+// Package metrics defines Prometheus metrics of the server and the fleet state of the latest snapshot.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// namespace prefixes the names of all metrics.
+const namespace = "netmonitor"
+
+// Metrics describes metrics of the server.
+type Metrics struct {
+	logger   *zap.Logger
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	saveDuration prometheus.Histogram
+	dbDuration   *prometheus.HistogramVec
+}
+
+// NewMetrics returns Metrics object with the fleet state read from the latest snapshot of [repo] on every scrape.
+func NewMetrics(logger *zap.Logger, repo repository.Repository) *Metrics {
+	m := &Metrics{
+		logger:   logger,
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "code"}),
+		saveDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "save_snapshot_duration_seconds",
+			Help:      "Time taken to save a snapshot, including alerts and analyses.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+		}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Time taken by repository methods.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{"method", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.saveDuration,
+		m.dbDuration,
+		newFleetCollector(logger, repo),
+	)
+
+	return m
+}
+
+// Handler returns an http.Handler that writes the metrics in the Prometheus text format.
+// Metrics that could not be collected are logged and skipped.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorLog:      zap.NewStdLog(m.logger),
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
+
+// Middleware counts the HTTP requests served by next.
+// Requests are labeled by route pattern rather than path, so query parameters do not add label values.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unknown"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		m.httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+	})
+}
+
+// observeQuery records the duration of the repository method started at [start].
+func (m *Metrics) observeQuery(method string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	m.dbDuration.WithLabelValues(method, result).Observe(time.Since(start).Seconds())
+}
+
+var _ services.SnapshotsService = (*instrumentedSnapshots)(nil)
+
+// instrumentedSnapshots measures the latency of saving snapshots by a [SnapshotsService] object.
+type instrumentedSnapshots struct {
+	services.SnapshotsService
+	metrics *Metrics
+}
+
+// InstrumentSnapshots returns a [SnapshotsService] that records the latency of SaveSnapshot of [service].
+func (m *Metrics) InstrumentSnapshots(service services.SnapshotsService) *instrumentedSnapshots {
+	return &instrumentedSnapshots{
+		SnapshotsService: service,
+		metrics:          m,
+	}
+}
+
+// SaveSnapshot implements the [SnapshotsService] interface.
+func (s *instrumentedSnapshots) SaveSnapshot(ctx context.Context, snapshot model.Snapshot) error {
+	start := time.Now()
+	err := s.SnapshotsService.SaveSnapshot(ctx, snapshot)
+	s.metrics.saveDuration.Observe(time.Since(start).Seconds())
+
+	return err
+}
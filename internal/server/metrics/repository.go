@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+)
+
+var _ repository.Repository = (*instrumentedRepository)(nil)
+
+// instrumentedRepository measures the duration of the methods of a [Repository] object.
+type instrumentedRepository struct {
+	repo    repository.Repository
+	metrics *Metrics
+}
+
+// InstrumentRepository returns a [Repository] that records the duration of every method of [repo].
+// The returned object does not implement [Migratable], so migrations must be run on [repo].
+func (m *Metrics) InstrumentRepository(repo repository.Repository) *instrumentedRepository {
+	return &instrumentedRepository{
+		repo:    repo,
+		metrics: m,
+	}
+}
+
+// StoreSnapshot implements the [Repository] interface.
+func (r *instrumentedRepository) StoreSnapshot(ctx context.Context, snapshot model.Snapshot) error {
+	start := time.Now()
+	err := r.repo.StoreSnapshot(ctx, snapshot)
+	r.metrics.observeQuery("StoreSnapshot", start, err)
+
+	return err
+}
+
+// GetSnapshot implements the [Repository] interface.
+func (r *instrumentedRepository) GetSnapshot(ctx context.Context, timestampID int) (model.Snapshot, error) {
+	start := time.Now()
+	result, err := r.repo.GetSnapshot(ctx, timestampID)
+	r.metrics.observeQuery("GetSnapshot", start, err)
+
+	return result, err
+}
+
+// GetNTimestamps implements the [Repository] interface.
+func (r *instrumentedRepository) GetNTimestamps(ctx context.Context, n int) ([]model.Snapshot, error) {
+	start := time.Now()
+	result, err := r.repo.GetNTimestamps(ctx, n)
+	r.metrics.observeQuery("GetNTimestamps", start, err)
+
+	return result, err
+}
+
+// GetTimestampsBefore implements the [Repository] interface.
+func (r *instrumentedRepository) GetTimestampsBefore(ctx context.Context, before time.Time, n int) ([]model.Snapshot, error) {
+	start := time.Now()
+	result, err := r.repo.GetTimestampsBefore(ctx, before, n)
+	r.metrics.observeQuery("GetTimestampsBefore", start, err)
+
+	return result, err
+}
+
+// GetTimestampsBetween implements the [Repository] interface.
+func (r *instrumentedRepository) GetTimestampsBetween(ctx context.Context, from, to time.Time) ([]model.Snapshot, error) {
+	start := time.Now()
+	result, err := r.repo.GetTimestampsBetween(ctx, from, to)
+	r.metrics.observeQuery("GetTimestampsBetween", start, err)
+
+	return result, err
+}
+
+// GetClosestTimestamp implements the [Repository] interface.
+func (r *instrumentedRepository) GetClosestTimestamp(ctx context.Context, at time.Time) (model.Snapshot, error) {
+	start := time.Now()
+	result, err := r.repo.GetClosestTimestamp(ctx, at)
+	r.metrics.observeQuery("GetClosestTimestamp", start, err)
+
+	return result, err
+}
+
+// GetFilteredSnapshot implements the [Repository] interface.
+func (r *instrumentedRepository) GetFilteredSnapshot(ctx context.Context, timestampID int, filter repository.DeviceFilter) (model.Snapshot, error) {
+	start := time.Now()
+	result, err := r.repo.GetFilteredSnapshot(ctx, timestampID, filter)
+	r.metrics.observeQuery("GetFilteredSnapshot", start, err)
+
+	return result, err
+}
+
+// GetDeviceHistory implements the [Repository] interface.
+func (r *instrumentedRepository) GetDeviceHistory(ctx context.Context, hostname string, from, to time.Time) ([]model.Snapshot, error) {
+	start := time.Now()
+	result, err := r.repo.GetDeviceHistory(ctx, hostname, from, to)
+	r.metrics.observeQuery("GetDeviceHistory", start, err)
+
+	return result, err
+}
+
+// GetDevicesHistory implements the [Repository] interface.
+func (r *instrumentedRepository) GetDevicesHistory(ctx context.Context, from, to time.Time) (map[string][]model.Snapshot, error) {
+	start := time.Now()
+	result, err := r.repo.GetDevicesHistory(ctx, from, to)
+	r.metrics.observeQuery("GetDevicesHistory", start, err)
+
+	return result, err
+}
+
+// ListDevices implements the [Repository] interface.
+func (r *instrumentedRepository) ListDevices(ctx context.Context) ([]repository.StoredDevice, error) {
+	start := time.Now()
+	result, err := r.repo.ListDevices(ctx)
+	r.metrics.observeQuery("ListDevices", start, err)
+
+	return result, err
+}
+
+// ListAddresses implements the [Repository] interface.
+func (r *instrumentedRepository) ListAddresses(ctx context.Context) ([]repository.StoredAddress, error) {
+	start := time.Now()
+	result, err := r.repo.ListAddresses(ctx)
+	r.metrics.observeQuery("ListAddresses", start, err)
+
+	return result, err
+}
+
+// RenameDevice implements the [Repository] interface.
+func (r *instrumentedRepository) RenameDevice(ctx context.Context, id int, hostname string) error {
+	start := time.Now()
+	err := r.repo.RenameDevice(ctx, id, hostname)
+	r.metrics.observeQuery("RenameDevice", start, err)
+
+	return err
+}
+
+// MergeDevices implements the [Repository] interface.
+func (r *instrumentedRepository) MergeDevices(ctx context.Context, from, into int) error {
+	start := time.Now()
+	err := r.repo.MergeDevices(ctx, from, into)
+	r.metrics.observeQuery("MergeDevices", start, err)
+
+	return err
+}
+
+// StoreFindings implements the [Repository] interface.
+func (r *instrumentedRepository) StoreFindings(ctx context.Context, timestamp time.Time, analysis string, findings []repository.Finding) error {
+	start := time.Now()
+	err := r.repo.StoreFindings(ctx, timestamp, analysis, findings)
+	r.metrics.observeQuery("StoreFindings", start, err)
+
+	return err
+}
+
+// GetFindings implements the [Repository] interface.
+func (r *instrumentedRepository) GetFindings(ctx context.Context, filter repository.FindingFilter) ([]repository.Finding, error) {
+	start := time.Now()
+	result, err := r.repo.GetFindings(ctx, filter)
+	r.metrics.observeQuery("GetFindings", start, err)
+
+	return result, err
+}
+
+// DeleteSnapshot implements the [Repository] interface.
+func (r *instrumentedRepository) DeleteSnapshot(ctx context.Context, timestampID int) error {
+	start := time.Now()
+	err := r.repo.DeleteSnapshot(ctx, timestampID)
+	r.metrics.observeQuery("DeleteSnapshot", start, err)
+
+	return err
+}
+
+// PruneSnapshots implements the [Repository] interface.
+func (r *instrumentedRepository) PruneSnapshots(ctx context.Context, timestampIDs []int, dryRun bool) (repository.PruneResult, error) {
+	start := time.Now()
+	result, err := r.repo.PruneSnapshots(ctx, timestampIDs, dryRun)
+	r.metrics.observeQuery("PruneSnapshots", start, err)
+
+	return result, err
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/memory"
+)
+
+// failingRepository fails to store snapshots.
+type failingRepository struct {
+	repository.Repository
+}
+
+func (failingRepository) StoreSnapshot(context.Context, model.Snapshot) error {
+	return errors.New("disk is full")
+}
+
+// queries returns the number of observed calls of the repository method with the result.
+func queries(t *testing.T, m *Metrics, method, result string) uint64 {
+	t.Helper()
+
+	var metric dto.Metric
+	if err := m.dbDuration.WithLabelValues(method, result).(prometheus.Histogram).Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestInstrumentRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemory(zap.NewNop(), repository.DefaultIdentity())
+	m := NewMetrics(zap.NewNop(), repo)
+	instrumented := m.InstrumentRepository(repo)
+
+	snapshot := model.Snapshot{
+		Timestamp: time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC),
+		Devices:   []model.Device{{Hostname: "srl1", Status: model.StatusSuccess}},
+	}
+	if err := instrumented.StoreSnapshot(ctx, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		timestamps, err := instrumented.GetNTimestamps(ctx, 1)
+		if err != nil || len(timestamps) != 1 {
+			t.Fatalf("timestamps = %v, %v, want the stored snapshot", timestamps, err)
+		}
+	}
+
+	failing := m.InstrumentRepository(failingRepository{repo})
+	if err := failing.StoreSnapshot(ctx, snapshot); err == nil {
+		t.Fatal("want the error of the repository")
+	}
+
+	tests := []struct {
+		method string
+		result string
+		want   uint64
+	}{
+		{method: "StoreSnapshot", result: "success", want: 1},
+		{method: "StoreSnapshot", result: "error", want: 1},
+		{method: "GetNTimestamps", result: "success", want: 2},
+		{method: "GetNTimestamps", result: "error", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+"/"+tt.result, func(t *testing.T) {
+			if got := queries(t, m, tt.method, tt.result); got != tt.want {
+				t.Errorf("observed %s %s = %d, want %d", tt.method, tt.result, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstrumentRepositoryEveryMethod(t *testing.T) {
+	repo := memory.NewMemory(zap.NewNop(), repository.DefaultIdentity())
+	m := NewMetrics(zap.NewNop(), repo)
+	instrumented := reflect.ValueOf(m.InstrumentRepository(repo))
+
+	// Every method is called with zero arguments, so the results are not checked.
+	methods := reflect.TypeFor[repository.Repository]()
+	for methodIdx := range methods.NumMethod() {
+		method := instrumented.MethodByName(methods.Method(methodIdx).Name)
+		args := make([]reflect.Value, method.Type().NumIn())
+		for argIdx := range args {
+			args[argIdx] = reflect.Zero(method.Type().In(argIdx))
+		}
+		args[0] = reflect.ValueOf(context.Background())
+		method.Call(args)
+	}
+
+	if n := testutil.CollectAndCount(m.dbDuration); n != methods.NumMethod() {
+		t.Errorf("series of repository methods = %d, want one for each of %d methods", n, methods.NumMethod())
+	}
+	for methodIdx := range methods.NumMethod() {
+		name := methods.Method(methodIdx).Name
+		if queries(t, m, name, "success")+queries(t, m, name, "error") != 1 {
+			t.Errorf("%s is not observed once", name)
+		}
+	}
+}
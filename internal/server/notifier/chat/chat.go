@@ -0,0 +1,110 @@
+// Package chat defines channels that post alerts to chat incoming webhooks.
+// Slack payloads are also accepted by Mattermost and Rocket.Chat.
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sudeeya/net-monitor/internal/server/notifier"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// Theme colors of Teams cards.
+const (
+	firingColor   = "D93F0B"
+	resolvedColor = "2EA44F"
+)
+
+var (
+	_ notifier.Channel = (*slack)(nil)
+	_ notifier.Channel = (*teams)(nil)
+)
+
+// slack implements the [Channel] interface.
+type slack struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlack returns slack object that posts alerts to a Slack incoming webhook at [url].
+func NewSlack(url string) *slack {
+	return &slack{
+		url:    url,
+		client: &http.Client{Timeout: notifier.DefaultTimeout},
+	}
+}
+
+// Send implements the [Channel] interface.
+func (s *slack) Send(ctx context.Context, alerts []services.Alert) error {
+	body, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{
+		Text: "*Net Monitor: " + notifier.Summary(alerts) + "*\n" + lines(alerts),
+	})
+	if err != nil {
+		return err
+	}
+
+	return notifier.PostJSON(ctx, s.client, s.url, body, nil)
+}
+
+// teams implements the [Channel] interface.
+type teams struct {
+	url    string
+	client *http.Client
+}
+
+// NewTeams returns teams object that posts alerts as message cards to a Microsoft Teams incoming webhook at [url].
+func NewTeams(url string) *teams {
+	return &teams{
+		url:    url,
+		client: &http.Client{Timeout: notifier.DefaultTimeout},
+	}
+}
+
+// Send implements the [Channel] interface.
+func (t *teams) Send(ctx context.Context, alerts []services.Alert) error {
+	color := resolvedColor
+	for _, alert := range alerts {
+		if alert.State == services.AlertFiring {
+			color = firingColor
+			break
+		}
+	}
+
+	summary := "Net Monitor: " + notifier.Summary(alerts)
+	body, err := json.Marshal(struct {
+		Type       string `json:"@type"`
+		Context    string `json:"@context"`
+		Summary    string `json:"summary"`
+		ThemeColor string `json:"themeColor"`
+		Title      string `json:"title"`
+		Text       string `json:"text"`
+	}{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    summary,
+		ThemeColor: color,
+		Title:      summary,
+		// Teams renders markdown, where lines are separated by blank lines.
+		Text: strings.ReplaceAll(lines(alerts), "\n", "\n\n"),
+	})
+	if err != nil {
+		return err
+	}
+
+	return notifier.PostJSON(ctx, t.client, t.url, body, nil)
+}
+
+// lines returns a line per alert.
+func lines(alerts []services.Alert) string {
+	l := make([]string, len(alerts))
+	for alertIdx, alert := range alerts {
+		l[alertIdx] = notifier.Line(alert)
+	}
+
+	return strings.Join(l, "\n")
+}
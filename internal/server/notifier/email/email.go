@@ -0,0 +1,120 @@
+// Package email defines channel that sends alerts by email through an SMTP server.
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/server/notifier"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+var _ notifier.Channel = (*email)(nil)
+
+// email implements the [Channel] interface.
+type email struct {
+	addr     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// NewEmail returns email object that sends alerts from [from] to [to] through the SMTP server at [addr].
+// If [username] is set, the client authenticates with PLAIN auth, which requires TLS unless the server is local.
+func NewEmail(addr, username, password, from string, to []string) *email {
+	return &email{
+		addr:     addr,
+		username: username,
+		password: password,
+		from:     from,
+		to:       to,
+	}
+}
+
+// Send implements the [Channel] interface.
+// STARTTLS is used if the server supports it.
+func (e *email) Send(ctx context.Context, alerts []services.Alert) error {
+	host, _, err := net.SplitHostPort(e.addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifier.DefaultTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+
+	if e.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.username, e.password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(e.from); err != nil {
+		return err
+	}
+	for _, to := range e.to {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(e.message(alerts)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// message returns the email with alerts.
+func (e *email) message(alerts []services.Alert) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&b, "Subject: [net-monitor] %s\r\n", notifier.Summary(alerts))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	for _, alert := range alerts {
+		b.WriteString(notifier.Line(alert))
+		b.WriteString("\r\n")
+	}
+
+	return []byte(b.String())
+}
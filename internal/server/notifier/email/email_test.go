@@ -0,0 +1,156 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// delivery describes a message received by the SMTP stand-in.
+type delivery struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// newSMTPServer starts an in-process SMTP server accepting a single message without TLS.
+// It advertises PLAIN auth, which clients use only for local servers without TLS.
+func newSMTPServer(t *testing.T) (string, <-chan delivery) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	deliveries := make(chan delivery, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(lines ...string) {
+			for _, line := range lines {
+				if _, err := conn.Write([]byte(line + "\r\n")); err != nil {
+					t.Error(err)
+				}
+			}
+		}
+
+		var d delivery
+		reply("220 localhost ESMTP stand-in")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+
+			switch strings.ToUpper(cmd) {
+			case "EHLO":
+				reply("250-localhost", "250 AUTH PLAIN")
+			case "AUTH":
+				d.auth = arg
+				reply("235 2.7.0 Authentication successful")
+			case "MAIL":
+				d.from = arg
+				reply("250 2.1.0 OK")
+			case "RCPT":
+				d.to = append(d.to, arg)
+				reply("250 2.1.5 OK")
+			case "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				d.data = data.String()
+				reply("250 2.0.0 OK")
+			case "QUIT":
+				reply("221 2.0.0 Bye")
+				deliveries <- d
+				return
+			default:
+				reply("502 5.5.2 Command not recognized")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), deliveries
+}
+
+func TestSend(t *testing.T) {
+	addr, deliveries := newSMTPServer(t)
+
+	alerts := []services.Alert{{
+		Rule:     "device-unreachable",
+		Severity: "critical",
+		State:    services.AlertFiring,
+		Hostname: "leaf1",
+		Message:  "device leaf1 is unreachable",
+		StartsAt: time.Date(2024, time.October, 1, 3, 0, 0, 0, time.UTC),
+	}}
+
+	e := NewEmail(addr, "alerts", "secret", "net-monitor@example.com", []string{"noc@example.com", "oncall@example.com"})
+	if err := e.Send(context.Background(), alerts); err != nil {
+		t.Fatal(err)
+	}
+
+	var d delivery
+	select {
+	case d = <-deliveries:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message delivered")
+	}
+
+	if want := "PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00alerts\x00secret")); d.auth != want {
+		t.Errorf("auth = %q, want %q", d.auth, want)
+	}
+	if d.from != "FROM:<net-monitor@example.com>" {
+		t.Errorf("from = %q", d.from)
+	}
+	if len(d.to) != 2 || d.to[0] != "TO:<noc@example.com>" || d.to[1] != "TO:<oncall@example.com>" {
+		t.Errorf("to = %q", d.to)
+	}
+	for _, want := range []string{
+		"Subject: [net-monitor] 1 firing\r\n",
+		"To: noc@example.com, oncall@example.com\r\n",
+		"[FIRING] device-unreachable (critical): device leaf1 is unreachable at 2024-10-01T03:00:00Z\r\n",
+	} {
+		if !strings.Contains(d.data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, d.data)
+		}
+	}
+}
+
+func TestSendFailsWithoutServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	e := NewEmail(addr, "", "", "net-monitor@example.com", []string{"noc@example.com"})
+	if err := e.Send(context.Background(), nil); err == nil {
+		t.Error("expected an error")
+	}
+}
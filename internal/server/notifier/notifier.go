@@ -0,0 +1,88 @@
+// Package notifier defines channels through which alerts are sent.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// DefaultTimeout limits a single attempt to send a notification.
+const DefaultTimeout = 10 * time.Second
+
+// Channel describes a destination of notifications.
+type Channel interface {
+	// Send sends alerts as a single notification.
+	// Returns an error if the notification was not accepted.
+	Send(ctx context.Context, alerts []services.Alert) error
+}
+
+// Summary returns a short description of alerts, for example "2 firing, 1 resolved".
+func Summary(alerts []services.Alert) string {
+	var firing, resolved int
+	for _, alert := range alerts {
+		if alert.State == services.AlertFiring {
+			firing++
+		} else {
+			resolved++
+		}
+	}
+
+	parts := make([]string, 0, 2)
+	if firing > 0 {
+		parts = append(parts, fmt.Sprintf("%d firing", firing))
+	}
+	if resolved > 0 {
+		parts = append(parts, fmt.Sprintf("%d resolved", resolved))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Line returns a line of text describing the alert.
+func Line(alert services.Alert) string {
+	at := alert.StartsAt
+	if alert.State == services.AlertResolved {
+		at = alert.EndsAt
+	}
+
+	return fmt.Sprintf(
+		"[%s] %s (%s): %s at %s",
+		strings.ToUpper(string(alert.State)), alert.Rule, alert.Severity, alert.Message, at.UTC().Format(time.RFC3339),
+	)
+}
+
+// PostJSON posts a json body to [url] with the additional headers.
+// Returns an error if the response status is not 2xx.
+func PostJSON(ctx context.Context, client *http.Client, url string, body []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s responded with %s: %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	// The body is drained so the connection can be reused.
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
@@ -0,0 +1,109 @@
+// Package webhook defines channel that posts alerts as json to an HTTP endpoint.
+//
+// If a secret is set, requests are signed: the X-Net-Monitor-Signature header holds
+// "sha256=" followed by the hex-encoded HMAC-SHA256 of the X-Net-Monitor-Timestamp header value,
+// a dot and the request body. Receivers should reject requests with old timestamps to prevent replays.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/server/notifier"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// Headers of signed requests.
+const (
+	SignatureHeader = "X-Net-Monitor-Signature"
+	TimestampHeader = "X-Net-Monitor-Timestamp"
+)
+
+var _ notifier.Channel = (*webhook)(nil)
+
+// webhook implements the [Channel] interface.
+type webhook struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhook returns webhook object that posts alerts to [url], signing requests with [secret] if it is set.
+func NewWebhook(url, secret string) *webhook {
+	return &webhook{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: notifier.DefaultTimeout},
+	}
+}
+
+// payload is the body of a request.
+type payload struct {
+	Summary string  `json:"summary"`
+	Alerts  []alert `json:"alerts"`
+}
+
+// alert describes an alert in a request.
+type alert struct {
+	Rule      string     `json:"rule"`
+	Severity  string     `json:"severity"`
+	State     string     `json:"state"`
+	Hostname  string     `json:"hostname"`
+	Interface string     `json:"interface,omitempty"`
+	Message   string     `json:"message"`
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+}
+
+// Send implements the [Channel] interface.
+func (w *webhook) Send(ctx context.Context, alerts []services.Alert) error {
+	p := payload{
+		Summary: notifier.Summary(alerts),
+		Alerts:  make([]alert, len(alerts)),
+	}
+	for alertIdx, a := range alerts {
+		p.Alerts[alertIdx] = alert{
+			Rule:      a.Rule,
+			Severity:  a.Severity,
+			State:     string(a.State),
+			Hostname:  a.Hostname,
+			Interface: a.Interface,
+			Message:   a.Message,
+			StartsAt:  a.StartsAt,
+		}
+		if a.State == services.AlertResolved {
+			endsAt := a.EndsAt
+			p.Alerts[alertIdx].EndsAt = &endsAt
+		}
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	header := make(http.Header)
+	if w.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		header.Set(TimestampHeader, timestamp)
+		header.Set(SignatureHeader, "sha256="+Sign(w.secret, timestamp, body))
+	}
+
+	return notifier.PostJSON(ctx, w.client, w.url, body, header)
+}
+
+// Sign returns the hex-encoded signature of a request with the timestamp and body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+var testAlerts = []services.Alert{
+	{
+		Rule:      "uplink-down",
+		Severity:  "critical",
+		State:     services.AlertFiring,
+		Hostname:  "leaf1",
+		Interface: "ethernet-1/1",
+		Message:   "interface ethernet-1/1 of leaf1 is down",
+		StartsAt:  time.Date(2024, time.October, 1, 3, 0, 0, 0, time.UTC),
+	},
+	{
+		Rule:     "device-unreachable",
+		Severity: "critical",
+		State:    services.AlertResolved,
+		Hostname: "leaf2",
+		Message:  "device leaf2 is unreachable",
+		StartsAt: time.Date(2024, time.October, 1, 2, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2024, time.October, 1, 3, 0, 0, 0, time.UTC),
+	},
+}
+
+// request describes a request received by the stand-in.
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver returns an endpoint that responds with the status and sends received requests to the channel.
+func newReceiver(t *testing.T, status int) (*httptest.Server, <-chan request) {
+	t.Helper()
+
+	requests := make(chan request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		requests <- request{header: r.Header, body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+func TestSendSignsRequest(t *testing.T) {
+	server, requests := newReceiver(t, http.StatusNoContent)
+
+	if err := NewWebhook(server.URL, "secret").Send(context.Background(), testAlerts); err != nil {
+		t.Fatal(err)
+	}
+	r := <-requests
+
+	timestamp := r.header.Get(TimestampHeader)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("invalid timestamp %q: %v", timestamp, err)
+	}
+	if d := time.Since(time.Unix(sent, 0)); d < -time.Second || d > time.Minute {
+		t.Errorf("timestamp is %s old", d)
+	}
+
+	want := "sha256=" + Sign("secret", timestamp, r.body)
+	if got := r.header.Get(SignatureHeader); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+	if got := "sha256=" + Sign("other", timestamp, r.body); got == want {
+		t.Error("signatures with different secrets are equal")
+	}
+
+	var p payload
+	if err := json.Unmarshal(r.body, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Summary != "1 firing, 1 resolved" {
+		t.Errorf("summary = %q", p.Summary)
+	}
+	if len(p.Alerts) != 2 || p.Alerts[0].EndsAt != nil || p.Alerts[1].EndsAt == nil || !p.Alerts[1].EndsAt.Equal(testAlerts[1].EndsAt) {
+		t.Errorf("alerts = %+v", p.Alerts)
+	}
+}
+
+func TestSendWithoutSecret(t *testing.T) {
+	server, requests := newReceiver(t, http.StatusOK)
+
+	if err := NewWebhook(server.URL, "").Send(context.Background(), testAlerts); err != nil {
+		t.Fatal(err)
+	}
+	r := <-requests
+
+	if r.header.Get(SignatureHeader) != "" || r.header.Get(TimestampHeader) != "" {
+		t.Errorf("unsigned request has signature headers: %v", r.header)
+	}
+}
+
+func TestSendFailsOnErrorStatus(t *testing.T) {
+	server, _ := newReceiver(t, http.StatusInternalServerError)
+
+	if err := NewWebhook(server.URL, "secret").Send(context.Background(), testAlerts); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Computed with: printf '1727751600.{}' | openssl dgst -sha256 -hmac secret
+	const want = "afa33eff8914d581c5430b92832a3d5cb379da66aa54bc882a6f0f853febb5f1"
+	if got := Sign("secret", "1727751600", []byte("{}")); got != want {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+}
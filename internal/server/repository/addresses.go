@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"net/netip"
+	"time"
+)
+
+// StoredAddress describes an address that an interface of a stored device had in stored snapshots.
+type StoredAddress struct {
+	DeviceID int
+	// Current hostname of the device.
+	Hostname  string
+	Interface string
+	IP        netip.Prefix
+
+	// Times of the first and last snapshots in which the interface had the address.
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
@@ -0,0 +1,41 @@
+package repository
+
+import "time"
+
+// Finding describes a problem found on a device or one of its interfaces by an analysis of a snapshot.
+type Finding struct {
+	SnapshotID int       `json:"snapshot_id"`
+	Timestamp  time.Time `json:"timestamp"`
+
+	// Analysis that produced the finding, for example "compliance".
+	Analysis string `json:"analysis"`
+
+	Hostname string `json:"hostname"`
+	// Interface is empty for findings on the whole device.
+	Interface string `json:"interface,omitempty"`
+
+	// Check that failed within the analysis, for example a policy name.
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// FindingFilter describes findings to return. Empty fields match any finding.
+type FindingFilter struct {
+	SnapshotID int
+	Analysis   string
+	Hostname   string
+
+	// Findings of snapshots taken from From to To inclusive.
+	From time.Time
+	To   time.Time
+}
+
+// Match reports whether the finding matches the filter.
+func (f FindingFilter) Match(finding Finding) bool {
+	return (f.SnapshotID == 0 || f.SnapshotID == finding.SnapshotID) &&
+		(f.Analysis == "" || f.Analysis == finding.Analysis) &&
+		(f.Hostname == "" || f.Hostname == finding.Hostname) &&
+		(f.From.IsZero() || !finding.Timestamp.Before(f.From)) &&
+		(f.To.IsZero() || !finding.Timestamp.After(f.To))
+}
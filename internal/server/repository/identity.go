@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+// IdentityMode describes how devices of a new snapshot are matched with stored devices.
+type IdentityMode string
+
+const (
+	// IdentitySerial matches devices by serial number and falls back to hostname for devices without a usable one.
+	// A device whose hostname changed is the same device; a device with a known hostname
+	// but a different serial number, for example after a chassis swap, is a new device.
+	IdentitySerial IdentityMode = "serial"
+
+	// IdentityHostname matches devices by hostname only, so a serial number change is an attribute change.
+	IdentityHostname IdentityMode = "hostname"
+)
+
+// ParseIdentityMode returns the identity mode with the given name.
+func ParseIdentityMode(s string) (IdentityMode, error) {
+	switch mode := IdentityMode(s); mode {
+	case IdentitySerial, IdentityHostname:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown device identity %q", s)
+	}
+}
+
+// Identity describes the rules by which devices are identified across snapshots.
+type Identity struct {
+	Mode IdentityMode
+
+	// IgnoredSerials are serial numbers that do not identify a device,
+	// for example placeholders reported by virtual devices.
+	IgnoredSerials []string
+}
+
+// DefaultIdentity returns rules that match devices by serial number.
+func DefaultIdentity() Identity {
+	return Identity{Mode: IdentitySerial}
+}
+
+// StoredDevice describes a device with its current attributes, which are those of its latest snapshot
+// unless the device has been renamed since.
+type StoredDevice struct {
+	ID        int
+	Hostname  string
+	Serial    string
+	Vendor    string
+	OSName    string
+	OSVersion string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Snapshots int
+}
+
+// Match returns ids of the stored devices matching the devices of a snapshot, or 0 for new devices.
+// Only the ids, hostnames and serial numbers of the stored devices are used.
+// A stored device matches at most one device of the snapshot; if several stored devices match, the latest one wins.
+func (i Identity) Match(stored []StoredDevice, devices []model.Device) []int {
+	ids := make([]int, len(devices))
+
+	sorted := append([]StoredDevice(nil), stored...)
+	sort.Slice(sorted, func(a, b int) bool { return sorted[a].ID > sorted[b].ID })
+
+	ignored := make(map[string]bool, len(i.IgnoredSerials))
+	for _, serial := range i.IgnoredSerials {
+		ignored[serial] = true
+	}
+	usable := func(serial string) bool {
+		return i.Mode == IdentitySerial && serial != "" && !ignored[serial]
+	}
+
+	// A serial number shared by several devices of the snapshot does not identify any of them.
+	serials := make(map[string]int, len(devices))
+	for _, device := range devices {
+		serials[device.Serial]++
+	}
+	identifying := func(device model.Device) bool {
+		return usable(device.Serial) && serials[device.Serial] == 1
+	}
+
+	taken := make(map[int]bool, len(devices))
+
+	// Serial numbers are matched first, so a renamed device is not taken by a device that got its old hostname.
+	for idx, device := range devices {
+		if !identifying(device) {
+			continue
+		}
+
+		for _, s := range sorted {
+			if s.Serial == device.Serial && !taken[s.ID] {
+				ids[idx] = s.ID
+				taken[s.ID] = true
+				break
+			}
+		}
+	}
+
+	for idx, device := range devices {
+		if ids[idx] != 0 {
+			continue
+		}
+
+		for _, s := range sorted {
+			if s.Hostname != device.Hostname || taken[s.ID] {
+				continue
+			}
+			// Different hardware under the same hostname.
+			if identifying(device) && usable(s.Serial) && s.Serial != device.Serial {
+				continue
+			}
+
+			ids[idx] = s.ID
+			taken[s.ID] = true
+			break
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,742 @@
+// Package memory defines object that stores snapshots in memory.
+// It is intended for unit tests and ephemeral demo servers: stored snapshots are lost on restart.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+)
+
+var _ repository.Repository = (*memory)(nil)
+
+// memory implements the [Repository] interface.
+type memory struct {
+	logger   *zap.Logger
+	identity repository.Identity
+
+	mu        sync.RWMutex
+	lastID    int
+	snapshots map[int]model.Snapshot
+
+	// Ids of the devices of every snapshot in the order of its devices.
+	snapshotDevices map[int][]int
+
+	// Findings of every snapshot.
+	findings map[int][]repository.Finding
+
+	// Devices with their current attributes by id.
+	lastDeviceID int
+	devices      map[int]repository.StoredDevice
+
+	// Device states, shared by snapshots in which the device did not change.
+	// Snapshots keep full copies of devices; states are tracked to report the same prune results as the database.
+	deviceStates map[deviceStateKey]struct{}
+
+	// Like the tables of the database, these are kept until pruned.
+	interfaces       map[interfaceKey]struct{}
+	vendors          map[string]struct{}
+	operatingSystems map[osKey]struct{}
+}
+
+// deviceStateKey identifies a device state.
+type deviceStateKey struct {
+	deviceID int
+	hash     string
+}
+
+// interfaceKey identifies an interface of a device.
+type interfaceKey struct {
+	deviceID int
+	name     string
+}
+
+// osKey identifies an operating system version.
+type osKey struct {
+	name    string
+	version string
+}
+
+// NewMemory returns memory object.
+// Devices of stored snapshots are matched with known devices by the [identity] rules.
+func NewMemory(logger *zap.Logger, identity repository.Identity) *memory {
+	return &memory{
+		logger:          logger,
+		identity:        identity,
+		snapshots:       make(map[int]model.Snapshot),
+		snapshotDevices: make(map[int][]int),
+		findings:        make(map[int][]repository.Finding),
+		devices:         make(map[int]repository.StoredDevice),
+
+		deviceStates:     make(map[deviceStateKey]struct{}),
+		interfaces:       make(map[interfaceKey]struct{}),
+		vendors:          make(map[string]struct{}),
+		operatingSystems: make(map[osKey]struct{}),
+	}
+}
+
+// StoreSnapshot implements the [Repository] interface.
+// Like the database, stored devices get the attributes they have in the snapshot.
+func (m *memory) StoreSnapshot(_ context.Context, snapshot model.Snapshot) error {
+	m.logger.Info("Storing a snapshot in memory")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.snapshots {
+		if s.Timestamp.Equal(snapshot.Timestamp) {
+			return fmt.Errorf("snapshot with timestamp %s already exists", snapshot.Timestamp)
+		}
+	}
+
+	stored := make([]repository.StoredDevice, 0, len(m.devices))
+	for _, device := range m.devices {
+		stored = append(stored, device)
+	}
+
+	deviceIDs := m.identity.Match(stored, snapshot.Devices)
+	for deviceIdx, device := range snapshot.Devices {
+		if deviceIDs[deviceIdx] == 0 {
+			m.lastDeviceID++
+			deviceIDs[deviceIdx] = m.lastDeviceID
+		}
+		deviceID := deviceIDs[deviceIdx]
+
+		m.devices[deviceID] = repository.StoredDevice{
+			ID:        deviceID,
+			Hostname:  device.Hostname,
+			Serial:    device.Serial,
+			Vendor:    device.Vendor,
+			OSName:    device.OSName,
+			OSVersion: device.OSVersion,
+		}
+
+		m.deviceStates[deviceStateKey{deviceID, repository.StateHash(device)}] = struct{}{}
+		m.vendors[device.Vendor] = struct{}{}
+		m.operatingSystems[osKey{device.OSName, device.OSVersion}] = struct{}{}
+		for _, iface := range device.Interfaces {
+			m.interfaces[interfaceKey{deviceID, iface.Name}] = struct{}{}
+		}
+	}
+
+	m.lastID++
+	snapshot = copySnapshot(snapshot)
+	snapshot.ID = m.lastID
+	m.snapshots[snapshot.ID] = snapshot
+	m.snapshotDevices[snapshot.ID] = deviceIDs
+
+	return nil
+}
+
+// GetNTimestamps implements the [Repository] interface.
+func (m *memory) GetNTimestamps(_ context.Context, n int) ([]model.Snapshot, error) {
+	m.logger.Sugar().Infof("Getting the last %d timestamps from memory", n)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	timestamps := m.timestamps(func(time.Time) bool { return true })
+	if n < len(timestamps) {
+		timestamps = timestamps[:n]
+	}
+
+	return timestamps, nil
+}
+
+// GetTimestampsBefore implements the [Repository] interface.
+func (m *memory) GetTimestampsBefore(_ context.Context, before time.Time, n int) ([]model.Snapshot, error) {
+	m.logger.Sugar().Infof("Getting %d timestamps before %s from memory", n, before)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	timestamps := m.timestamps(func(t time.Time) bool { return t.Before(before) })
+	if n < len(timestamps) {
+		timestamps = timestamps[:n]
+	}
+
+	return timestamps, nil
+}
+
+// GetTimestampsBetween implements the [Repository] interface.
+func (m *memory) GetTimestampsBetween(_ context.Context, from, to time.Time) ([]model.Snapshot, error) {
+	m.logger.Sugar().Infof("Getting timestamps from %s to %s from memory", from, to)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.timestamps(func(t time.Time) bool { return !t.Before(from) && !t.After(to) }), nil
+}
+
+// GetClosestTimestamp implements the [Repository] interface.
+func (m *memory) GetClosestTimestamp(_ context.Context, at time.Time) (model.Snapshot, error) {
+	m.logger.Sugar().Infof("Getting the timestamp closest to %s from memory", at)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return repository.ClosestTimestamp(m.timestamps(func(time.Time) bool { return true }), at), nil
+}
+
+// timestamps returns ids and timestamps of snapshots taken at times matching [match], from newest to oldest.
+func (m *memory) timestamps(match func(time.Time) bool) []model.Snapshot {
+	timestamps := make([]model.Snapshot, 0, len(m.snapshots))
+	for _, s := range m.snapshots {
+		if !match(s.Timestamp) {
+			continue
+		}
+
+		timestamps = append(timestamps, model.Snapshot{
+			ID:        s.ID,
+			Timestamp: s.Timestamp,
+		})
+	}
+
+	sort.Slice(timestamps, func(i, j int) bool {
+		return timestamps[i].Timestamp.After(timestamps[j].Timestamp)
+	})
+
+	return timestamps
+}
+
+// GetSnapshot implements the [Repository] interface.
+// Like the database, returns an empty snapshot if there is no snapshot with devices with the given id.
+func (m *memory) GetSnapshot(_ context.Context, id int) (model.Snapshot, error) {
+	m.logger.Info("Getting a snapshot from memory")
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.snapshot(id, repository.DeviceFilter{}), nil
+}
+
+// GetFilteredSnapshot implements the [Repository] interface.
+func (m *memory) GetFilteredSnapshot(_ context.Context, id int, filter repository.DeviceFilter) (model.Snapshot, error) {
+	m.logger.Sugar().Infof("Getting a snapshot filtered by %+v from memory", filter)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.snapshot(id, filter), nil
+}
+
+// snapshot returns a copy of the snapshot with the devices matching the filter.
+// Like the database, devices are sorted by id.
+func (m *memory) snapshot(id int, filter repository.DeviceFilter) model.Snapshot {
+	s, ok := m.snapshots[id]
+	if !ok {
+		return model.Snapshot{}
+	}
+
+	snapshot := copySnapshot(s)
+	devices := make([]model.Device, 0, len(snapshot.Devices))
+	for _, deviceIdx := range m.sortedDevices(id) {
+		if device := snapshot.Devices[deviceIdx]; filter.Match(device) {
+			devices = append(devices, device)
+		}
+	}
+	if len(devices) == 0 {
+		return model.Snapshot{}
+	}
+	snapshot.Devices = devices
+
+	return snapshot
+}
+
+// sortedDevices returns indexes of the snapshot devices sorted by device id.
+func (m *memory) sortedDevices(id int) []int {
+	deviceIDs := m.snapshotDevices[id]
+
+	indexes := make([]int, len(deviceIDs))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return deviceIDs[indexes[i]] < deviceIDs[indexes[j]]
+	})
+
+	return indexes
+}
+
+// GetDeviceHistory implements the [Repository] interface.
+func (m *memory) GetDeviceHistory(_ context.Context, hostname string, from, to time.Time) ([]model.Snapshot, error) {
+	m.logger.Sugar().Infof("Getting the history of %s from memory", hostname)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	history, ok := m.devicesHistory(hostname, from, to)[hostname]
+	if !ok {
+		history = []model.Snapshot{}
+	}
+
+	return history, nil
+}
+
+// GetDevicesHistory implements the [Repository] interface.
+func (m *memory) GetDevicesHistory(_ context.Context, from, to time.Time) (map[string][]model.Snapshot, error) {
+	m.logger.Info("Getting the history of all devices from memory")
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.devicesHistory("", from, to), nil
+}
+
+// devicesHistory returns the histories of devices whose current hostname is [hostname], or of all devices if it is empty,
+// by current hostname.
+func (m *memory) devicesHistory(hostname string, from, to time.Time) map[string][]model.Snapshot {
+	histories := make(map[string][]model.Snapshot)
+	for _, timestamp := range m.timestamps(func(t time.Time) bool { return !t.Before(from) && !t.After(to) }) {
+		snapshot := m.snapshot(timestamp.ID, repository.DeviceFilter{})
+		deviceIDs := m.snapshotDevices[timestamp.ID]
+		for i, deviceIdx := range m.sortedDevices(timestamp.ID) {
+			current := m.devices[deviceIDs[deviceIdx]].Hostname
+			if hostname != "" && current != hostname {
+				continue
+			}
+
+			histories[current] = append(histories[current], model.Snapshot{
+				ID:        snapshot.ID,
+				Timestamp: snapshot.Timestamp,
+				Devices:   []model.Device{snapshot.Devices[i]},
+			})
+		}
+	}
+
+	for _, history := range histories {
+		sort.SliceStable(history, func(i, j int) bool {
+			return history[i].Timestamp.Before(history[j].Timestamp)
+		})
+	}
+
+	return histories
+}
+
+// ListDevices implements the [Repository] interface.
+func (m *memory) ListDevices(_ context.Context) ([]repository.StoredDevice, error) {
+	m.logger.Info("Listing devices in memory")
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	devices := make(map[int]*repository.StoredDevice, len(m.devices))
+	for id, device := range m.devices {
+		devices[id] = &device
+	}
+
+	for id, deviceIDs := range m.snapshotDevices {
+		timestamp := m.snapshots[id].Timestamp
+		for _, deviceID := range deviceIDs {
+			device := devices[deviceID]
+			if device.Snapshots == 0 || timestamp.Before(device.FirstSeen) {
+				device.FirstSeen = timestamp
+			}
+			if device.Snapshots == 0 || timestamp.After(device.LastSeen) {
+				device.LastSeen = timestamp
+			}
+			device.Snapshots++
+		}
+	}
+
+	list := make([]repository.StoredDevice, 0, len(devices))
+	for _, device := range devices {
+		list = append(list, *device)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	return list, nil
+}
+
+// ListAddresses implements the [Repository] interface.
+func (m *memory) ListAddresses(_ context.Context) ([]repository.StoredAddress, error) {
+	m.logger.Info("Listing addresses in memory")
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	type key struct {
+		deviceID int
+		iface    string
+		ip       netip.Prefix
+	}
+	addresses := make(map[key]*repository.StoredAddress)
+	for id, deviceIDs := range m.snapshotDevices {
+		snapshot := m.snapshots[id]
+		for deviceIdx, deviceID := range deviceIDs {
+			for _, iface := range snapshot.Devices[deviceIdx].Interfaces {
+				if !iface.IP.IsValid() {
+					continue
+				}
+
+				k := key{deviceID, iface.Name, iface.IP}
+				address, ok := addresses[k]
+				if !ok {
+					address = &repository.StoredAddress{
+						DeviceID:  deviceID,
+						Hostname:  m.devices[deviceID].Hostname,
+						Interface: iface.Name,
+						IP:        iface.IP,
+						FirstSeen: snapshot.Timestamp,
+						LastSeen:  snapshot.Timestamp,
+					}
+					addresses[k] = address
+				}
+				if snapshot.Timestamp.Before(address.FirstSeen) {
+					address.FirstSeen = snapshot.Timestamp
+				}
+				if snapshot.Timestamp.After(address.LastSeen) {
+					address.LastSeen = snapshot.Timestamp
+				}
+			}
+		}
+	}
+
+	list := make([]repository.StoredAddress, 0, len(addresses))
+	for _, address := range addresses {
+		list = append(list, *address)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].DeviceID != list[j].DeviceID {
+			return list[i].DeviceID < list[j].DeviceID
+		}
+		if list[i].Interface != list[j].Interface {
+			return list[i].Interface < list[j].Interface
+		}
+		return list[i].IP.String() < list[j].IP.String()
+	})
+
+	return list, nil
+}
+
+// RenameDevice implements the [Repository] interface.
+func (m *memory) RenameDevice(_ context.Context, id int, hostname string) error {
+	m.logger.Sugar().Infof("Renaming device %d to %s in memory", id, hostname)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	device, ok := m.devices[id]
+	if !ok {
+		return repository.ErrDeviceNotFound
+	}
+	device.Hostname = hostname
+	m.devices[id] = device
+
+	return nil
+}
+
+// MergeDevices implements the [Repository] interface.
+func (m *memory) MergeDevices(_ context.Context, from, into int) error {
+	m.logger.Sugar().Infof("Merging device %d into %d in memory", from, into)
+
+	if from == into {
+		return repository.ErrSameDevice
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.devices[from]; !ok {
+		return repository.ErrDeviceNotFound
+	}
+	device, ok := m.devices[into]
+	if !ok {
+		return repository.ErrDeviceNotFound
+	}
+
+	for _, deviceIDs := range m.snapshotDevices {
+		if slices.Contains(deviceIDs, from) && slices.Contains(deviceIDs, into) {
+			return repository.ErrDevicesOverlap
+		}
+	}
+
+	var latest model.Snapshot
+	for id, deviceIDs := range m.snapshotDevices {
+		for deviceIdx, deviceID := range deviceIDs {
+			if deviceID == from {
+				deviceIDs[deviceIdx] = into
+				deviceID = into
+			}
+
+			if snapshot := m.snapshots[id]; deviceID == into && snapshot.Timestamp.After(latest.Timestamp) {
+				latest = snapshot
+				last := snapshot.Devices[deviceIdx]
+				device.Hostname = last.Hostname
+				device.Serial = last.Serial
+				device.Vendor = last.Vendor
+				device.OSName = last.OSName
+				device.OSVersion = last.OSVersion
+			}
+		}
+	}
+
+	for key := range m.deviceStates {
+		if key.deviceID == from {
+			delete(m.deviceStates, key)
+			m.deviceStates[deviceStateKey{into, key.hash}] = struct{}{}
+		}
+	}
+	for key := range m.interfaces {
+		if key.deviceID == from {
+			delete(m.interfaces, key)
+			m.interfaces[interfaceKey{into, key.name}] = struct{}{}
+		}
+	}
+
+	m.devices[into] = device
+	delete(m.devices, from)
+
+	return nil
+}
+
+// StoreFindings implements the [Repository] interface.
+func (m *memory) StoreFindings(_ context.Context, timestamp time.Time, analysis string, findings []repository.Finding) error {
+	m.logger.Sugar().Infof("Storing %d findings of %s in memory", len(findings), analysis)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := 0
+	for snapshotID, snapshot := range m.snapshots {
+		if snapshot.Timestamp.Equal(timestamp) {
+			id = snapshotID
+			break
+		}
+	}
+	if id == 0 {
+		return repository.ErrSnapshotNotFound
+	}
+
+	stored := slices.DeleteFunc(m.findings[id], func(f repository.Finding) bool {
+		return f.Analysis == analysis
+	})
+	for _, finding := range findings {
+		finding.SnapshotID = id
+		finding.Timestamp = m.snapshots[id].Timestamp
+		finding.Analysis = analysis
+		stored = append(stored, finding)
+	}
+	m.findings[id] = stored
+
+	return nil
+}
+
+// GetFindings implements the [Repository] interface.
+func (m *memory) GetFindings(_ context.Context, filter repository.FindingFilter) ([]repository.Finding, error) {
+	m.logger.Info("Getting findings from memory")
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	findings := make([]repository.Finding, 0)
+	for _, snapshotFindings := range m.findings {
+		for _, finding := range snapshotFindings {
+			if filter.Match(finding) {
+				findings = append(findings, finding)
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		switch {
+		case !a.Timestamp.Equal(b.Timestamp):
+			return a.Timestamp.Before(b.Timestamp)
+		case a.Analysis != b.Analysis:
+			return a.Analysis < b.Analysis
+		case a.Hostname != b.Hostname:
+			return a.Hostname < b.Hostname
+		case a.Interface != b.Interface:
+			return a.Interface < b.Interface
+		default:
+			return a.Check < b.Check
+		}
+	})
+
+	return findings, nil
+}
+
+// DeleteSnapshot implements the [Repository] interface.
+// Device states used only by this snapshot are deleted, while devices are kept, like in the database.
+func (m *memory) DeleteSnapshot(_ context.Context, id int) error {
+	m.logger.Info("Deleting a snapshot from memory")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot, ok := m.snapshots[id]
+	if !ok {
+		return nil
+	}
+	deviceIDs := m.snapshotDevices[id]
+	delete(m.snapshots, id)
+	delete(m.snapshotDevices, id)
+	delete(m.findings, id)
+
+	used := m.usedDeviceStates(nil)
+	for deviceIdx, device := range snapshot.Devices {
+		key := deviceStateKey{deviceIDs[deviceIdx], repository.StateHash(device)}
+		if _, ok := used[key]; !ok {
+			delete(m.deviceStates, key)
+		}
+	}
+
+	return nil
+}
+
+// usedDeviceStates returns device states referenced by snapshots except the deleted ones.
+func (m *memory) usedDeviceStates(deleted map[int]struct{}) map[deviceStateKey]struct{} {
+	used := make(map[deviceStateKey]struct{})
+	for id, snapshot := range m.snapshots {
+		if _, ok := deleted[id]; ok {
+			continue
+		}
+
+		for deviceIdx, device := range snapshot.Devices {
+			used[deviceStateKey{m.snapshotDevices[id][deviceIdx], repository.StateHash(device)}] = struct{}{}
+		}
+	}
+
+	return used
+}
+
+// PruneSnapshots implements the [Repository] interface.
+func (m *memory) PruneSnapshots(_ context.Context, ids []int, dryRun bool) (repository.PruneResult, error) {
+	m.logger.Sugar().Infof("Pruning %d snapshots from memory", len(ids))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := make(map[int]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := m.snapshots[id]; ok {
+			deleted[id] = struct{}{}
+		}
+	}
+
+	// Rows referenced by the remaining snapshots.
+	// Like device states in the database, the remaining devices refer to vendors and operating systems.
+	usedDevices := make(map[int]struct{})
+	usedInterfaces := make(map[interfaceKey]struct{})
+	usedVendors := make(map[string]struct{})
+	usedOperatingSystems := make(map[osKey]struct{})
+	for id, snapshot := range m.snapshots {
+		if _, ok := deleted[id]; ok {
+			continue
+		}
+
+		for deviceIdx, device := range snapshot.Devices {
+			deviceID := m.snapshotDevices[id][deviceIdx]
+			usedDevices[deviceID] = struct{}{}
+			usedVendors[device.Vendor] = struct{}{}
+			usedOperatingSystems[osKey{device.OSName, device.OSVersion}] = struct{}{}
+			for _, iface := range device.Interfaces {
+				usedInterfaces[interfaceKey{deviceID, iface.Name}] = struct{}{}
+			}
+		}
+	}
+
+	usedDeviceStates := m.usedDeviceStates(deleted)
+	orphanedDeviceStates := make([]deviceStateKey, 0)
+	for key := range m.deviceStates {
+		if _, ok := usedDeviceStates[key]; !ok {
+			orphanedDeviceStates = append(orphanedDeviceStates, key)
+		}
+	}
+
+	orphanedDevices := make([]int, 0)
+	for id, device := range m.devices {
+		if _, ok := usedDevices[id]; !ok {
+			orphanedDevices = append(orphanedDevices, id)
+			continue
+		}
+		usedVendors[device.Vendor] = struct{}{}
+		usedOperatingSystems[osKey{device.OSName, device.OSVersion}] = struct{}{}
+	}
+
+	orphanedInterfaces := make([]interfaceKey, 0)
+	for key := range m.interfaces {
+		if _, ok := usedInterfaces[key]; !ok {
+			orphanedInterfaces = append(orphanedInterfaces, key)
+		}
+	}
+
+	orphanedVendors := make([]string, 0)
+	for vendor := range m.vendors {
+		if _, ok := usedVendors[vendor]; !ok {
+			orphanedVendors = append(orphanedVendors, vendor)
+		}
+	}
+
+	orphanedOperatingSystems := make([]osKey, 0)
+	for key := range m.operatingSystems {
+		if _, ok := usedOperatingSystems[key]; !ok {
+			orphanedOperatingSystems = append(orphanedOperatingSystems, key)
+		}
+	}
+
+	result := repository.PruneResult{
+		Snapshots:        len(deleted),
+		DeviceStates:     len(orphanedDeviceStates),
+		Devices:          len(orphanedDevices),
+		Interfaces:       len(orphanedInterfaces),
+		Vendors:          len(orphanedVendors),
+		OperatingSystems: len(orphanedOperatingSystems),
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	for id := range deleted {
+		delete(m.snapshots, id)
+		delete(m.snapshotDevices, id)
+		delete(m.findings, id)
+	}
+	for _, key := range orphanedDeviceStates {
+		delete(m.deviceStates, key)
+	}
+	for _, id := range orphanedDevices {
+		delete(m.devices, id)
+	}
+	for _, key := range orphanedInterfaces {
+		delete(m.interfaces, key)
+	}
+	for _, vendor := range orphanedVendors {
+		delete(m.vendors, vendor)
+	}
+	for _, key := range orphanedOperatingSystems {
+		delete(m.operatingSystems, key)
+	}
+
+	return result, nil
+}
+
+// copySnapshot returns a deep copy of the snapshot, so callers cannot modify stored data.
+func copySnapshot(snapshot model.Snapshot) model.Snapshot {
+	devices := make([]model.Device, len(snapshot.Devices))
+	for deviceIdx, device := range snapshot.Devices {
+		if device.Interfaces != nil {
+			device.Interfaces = append([]model.Interface(nil), device.Interfaces...)
+		}
+		if device.Warnings != nil {
+			device.Warnings = append([]model.Warning(nil), device.Warnings...)
+		}
+		devices[deviceIdx] = device
+	}
+	snapshot.Devices = devices
+
+	return snapshot
+}
@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T, identity repository.Identity) repository.Repository {
+		return NewMemory(zap.NewNop(), identity)
+	})
+}
+
+func BenchmarkStoreSnapshot(b *testing.B) {
+	for _, devices := range []int{100, 1000} {
+		b.Run(fmt.Sprintf("devices=%d", devices), func(b *testing.B) {
+			m := NewMemory(zap.NewNop(), repository.DefaultIdentity())
+			if err := repositorytest.BenchmarkStoreSnapshot(b, m, devices, 48, 0.05); err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
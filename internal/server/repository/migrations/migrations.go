@@ -0,0 +1,281 @@
+// Package migrations defines object that applies and rolls back versioned schema migrations.
+//
+// Migrations are SQL files named "<version>_<name>.up.sql" and "<version>_<name>.down.sql".
+// Versions are positive integers applied in ascending order; applied versions are recorded
+// in the schema_migrations table by a database-specific [Driver].
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+)
+
+var _ repository.Migrator = (*migrator)(nil)
+
+// fileNameRegexp matches migration file names and captures the version, name and direction.
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Driver describes the database-specific part of migrating a schema.
+type Driver interface {
+	// Lock prevents other servers from migrating the schema until unlock is called.
+	Lock(ctx context.Context) (unlock func(), err error)
+
+	// Init creates the schema_migrations table if it does not exist.
+	Init(ctx context.Context) error
+
+	// Applied returns applied migration versions and the time they were applied.
+	Applied(ctx context.Context) (map[int]time.Time, error)
+
+	// Apply runs the up query and records the migration in a single transaction.
+	Apply(ctx context.Context, m Migration) error
+
+	// Revert runs the down query and removes the migration record in a single transaction.
+	Revert(ctx context.Context, m Migration) error
+}
+
+// Migration describes a single schema migration.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// migrator implements the [Migrator] interface.
+type migrator struct {
+	logger     *zap.Logger
+	driver     Driver
+	migrations []Migration
+}
+
+// NewMigrator returns migrator object that migrates the schema with migrations read from [fsys].
+func NewMigrator(logger *zap.Logger, driver Driver, fsys fs.FS) (*migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &migrator{
+		logger:     logger,
+		driver:     driver,
+		migrations: migrations,
+	}, nil
+}
+
+// Load reads migrations from the root directory of [fsys] and sorts them by version.
+// Every migration must have both up and down files.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, m.Name, match[2])
+		}
+
+		switch match[3] {
+		case "up":
+			m.Up = string(content)
+		case "down":
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up implements the [Migrator] interface.
+// Returns an error if the database has migrations unknown to this server, for example applied by a newer version.
+func (m *migrator) Up(ctx context.Context) error {
+	unlock, applied, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if unknown := m.unknownVersions(applied); len(unknown) > 0 {
+		return fmt.Errorf("database has unknown migrations %v, the server may be outdated", unknown)
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		m.logger.Sugar().Infof("Applying migration %d_%s", migration.Version, migration.Name)
+		if err := m.driver.Apply(ctx, migration); err != nil {
+			return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+
+	m.logger.Sugar().Infof("Database schema is up to date, %d migrations applied", count)
+
+	return nil
+}
+
+// Down implements the [Migrator] interface.
+func (m *migrator) Down(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("number of migrations to roll back must be positive")
+	}
+
+	unlock, applied, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	known := make(map[int]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	if n > len(versions) {
+		n = len(versions)
+	}
+
+	for _, version := range versions[:n] {
+		migration, ok := known[version]
+		if !ok {
+			return fmt.Errorf("cannot roll back unknown migration %d", version)
+		}
+
+		m.logger.Sugar().Infof("Rolling back migration %d_%s", migration.Version, migration.Name)
+		if err := m.driver.Revert(ctx, migration); err != nil {
+			return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Status implements the [Migrator] interface.
+// Applied migrations unknown to this server are listed without a name.
+func (m *migrator) Status(ctx context.Context) ([]repository.MigrationStatus, error) {
+	unlock, applied, err := m.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	statuses := make([]repository.MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, repository.MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	for _, version := range m.unknownVersions(applied) {
+		statuses = append(statuses, repository.MigrationStatus{
+			Version:   version,
+			Applied:   true,
+			AppliedAt: applied[version],
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// begin takes the migration lock, creates the schema_migrations table and returns applied versions.
+func (m *migrator) begin(ctx context.Context) (unlock func(), applied map[int]time.Time, err error) {
+	m.logger.Info("Waiting for the migration lock")
+	unlock, err = m.driver.Lock(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := m.driver.Init(ctx); err != nil {
+		unlock()
+		return nil, nil, err
+	}
+
+	applied, err = m.driver.Applied(ctx)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+
+	return unlock, applied, nil
+}
+
+// unknownVersions returns applied versions that have no migration files in ascending order.
+func (m *migrator) unknownVersions(applied map[int]time.Time) []int {
+	known := make(map[int]struct{}, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = struct{}{}
+	}
+
+	unknown := make([]int, 0)
+	for version := range applied {
+		if _, ok := known[version]; !ok {
+			unknown = append(unknown, version)
+		}
+	}
+	sort.Ints(unknown)
+
+	return unknown
+}
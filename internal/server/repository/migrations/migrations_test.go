@@ -0,0 +1,278 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fakeDriver keeps applied migrations in memory and records applied and reverted versions.
+type fakeDriver struct {
+	applied map[int]time.Time
+	// Applied versions are recorded as positive numbers and reverted ones as negative.
+	log []int
+}
+
+func newFakeDriver(applied ...int) *fakeDriver {
+	d := &fakeDriver{applied: make(map[int]time.Time)}
+	for _, version := range applied {
+		d.applied[version] = time.Now()
+	}
+
+	return d
+}
+
+func (d *fakeDriver) Lock(ctx context.Context) (func(), error) { return func() {}, nil }
+
+func (d *fakeDriver) Init(ctx context.Context) error { return nil }
+
+func (d *fakeDriver) Applied(ctx context.Context) (map[int]time.Time, error) {
+	applied := make(map[int]time.Time, len(d.applied))
+	for version, at := range d.applied {
+		applied[version] = at
+	}
+
+	return applied, nil
+}
+
+func (d *fakeDriver) Apply(ctx context.Context, m Migration) error {
+	d.applied[m.Version] = time.Now()
+	d.log = append(d.log, m.Version)
+	return nil
+}
+
+func (d *fakeDriver) Revert(ctx context.Context, m Migration) error {
+	delete(d.applied, m.Version)
+	d.log = append(d.log, -m.Version)
+	return nil
+}
+
+// files returns a file system with up and down files of the named migrations.
+func files(names ...string) fstest.MapFS {
+	fsys := make(fstest.MapFS)
+	for _, name := range names {
+		fsys[name+".up.sql"] = &fstest.MapFile{Data: []byte("-- up " + name)}
+		fsys[name+".down.sql"] = &fstest.MapFile{Data: []byte("-- down " + name)}
+	}
+
+	return fsys
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []int
+		wantErr bool
+	}{
+		{
+			name: "sorted by version",
+			fsys: files("0010_ten", "0002_two", "0001_one"),
+			want: []int{1, 2, 10},
+		},
+		{
+			name: "gaps are kept",
+			fsys: files("0001_one", "0003_three", "0007_seven"),
+			want: []int{1, 3, 7},
+		},
+		{
+			name: "directories are skipped",
+			fsys: func() fstest.MapFS {
+				fsys := files("0001_one")
+				fsys["old/0002_two.up.sql"] = &fstest.MapFile{Data: []byte("-- up")}
+				return fsys
+			}(),
+			want: []int{1},
+		},
+		{name: "empty", fsys: fstest.MapFS{}, want: []int{}},
+		{
+			name: "missing down",
+			fsys: fstest.MapFS{
+				"0001_one.up.sql": &fstest.MapFile{Data: []byte("-- up")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing up",
+			fsys: fstest.MapFS{
+				"0001_one.down.sql": &fstest.MapFile{Data: []byte("-- down")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty up",
+			fsys: fstest.MapFS{
+				"0001_one.up.sql":   &fstest.MapFile{},
+				"0001_one.down.sql": &fstest.MapFile{Data: []byte("-- down")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "different names",
+			fsys: fstest.MapFS{
+				"0001_one.up.sql":   &fstest.MapFile{Data: []byte("-- up")},
+				"0001_uno.down.sql": &fstest.MapFile{Data: []byte("-- down")},
+			},
+			wantErr: true,
+		},
+		{name: "zero version", fsys: files("0000_zero"), wantErr: true},
+		{name: "no version", fsys: files("one"), wantErr: true},
+		{
+			name: "invalid direction",
+			fsys: fstest.MapFS{
+				"0001_one.sideways.sql": &fstest.MapFile{Data: []byte("-- sideways")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "not sql",
+			fsys: fstest.MapFS{
+				"README.md": &fstest.MapFile{Data: []byte("# Migrations")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.fsys)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want an error, got %+v", migrations)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			versions := make([]int, 0, len(migrations))
+			for _, m := range migrations {
+				versions = append(versions, m.Version)
+				wantUp := fmt.Sprintf("-- up %04d_%s", m.Version, m.Name)
+				wantDown := fmt.Sprintf("-- down %04d_%s", m.Version, m.Name)
+				if m.Up != wantUp || m.Down != wantDown {
+					t.Errorf("migration %d: up %q, down %q, want %q and %q", m.Version, m.Up, m.Down, wantUp, wantDown)
+				}
+			}
+			if !reflect.DeepEqual(versions, tt.want) {
+				t.Errorf("versions = %v, want %v", versions, tt.want)
+			}
+		})
+	}
+}
+
+func TestUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied []int
+		want    []int
+		wantErr bool
+	}{
+		{name: "empty database", want: []int{1, 3, 7}},
+		{name: "partially applied", applied: []int{1}, want: []int{3, 7}},
+		{name: "missed migration in a gap", applied: []int{1, 7}, want: []int{3}},
+		{name: "up to date", applied: []int{1, 3, 7}, want: nil},
+		{name: "unknown version", applied: []int{1, 3, 7, 8}, wantErr: true},
+		{name: "unknown version in a gap", applied: []int{1, 2}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := newFakeDriver(tt.applied...)
+			m, err := NewMigrator(zap.NewNop(), driver, files("0001_one", "0003_three", "0007_seven"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = m.Up(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Error("want an error")
+				}
+				if len(driver.log) > 0 {
+					t.Errorf("applied %v despite the error", driver.log)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(driver.log, tt.want) {
+				t.Errorf("applied %v, want %v", driver.log, tt.want)
+			}
+		})
+	}
+}
+
+func TestDown(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied []int
+		n       int
+		want    []int
+		wantErr bool
+	}{
+		{name: "latest", applied: []int{1, 3, 7}, n: 1, want: []int{-7}},
+		{name: "in descending order", applied: []int{1, 3, 7}, n: 2, want: []int{-7, -3}},
+		{name: "more than applied", applied: []int{1, 3}, n: 5, want: []int{-3, -1}},
+		{name: "nothing applied", n: 1, want: nil},
+		{name: "zero", applied: []int{1}, n: 0, wantErr: true},
+		{name: "unknown version", applied: []int{1, 3, 7, 8}, n: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver := newFakeDriver(tt.applied...)
+			m, err := NewMigrator(zap.NewNop(), driver, files("0001_one", "0003_three", "0007_seven"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = m.Down(context.Background(), tt.n)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(driver.log, tt.want) {
+				t.Errorf("reverted %v, want %v", driver.log, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	m, err := NewMigrator(zap.NewNop(), newFakeDriver(1, 8), files("0001_one", "0003_three"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type status struct {
+		version int
+		name    string
+		applied bool
+	}
+	got := make([]status, 0, len(statuses))
+	for _, s := range statuses {
+		got = append(got, status{s.Version, s.Name, s.Applied})
+	}
+	want := []status{{1, "one", true}, {3, "three", false}, {8, "", true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("statuses = %+v, want %+v", got, want)
+	}
+}
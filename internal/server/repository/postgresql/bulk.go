@@ -0,0 +1,151 @@
+package postgresql
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+)
+
+// Columns of staging tables filled by COPY.
+var (
+	stagedDevicesColumns = []string{
+		"position", "hostname", "vendor", "os", "version", "serial_number",
+		"is_snapshot_successful", "status", "state_hash", "running_config", "device_id", "is_new_device",
+	}
+	stagedWarningsColumns = []string{
+		"position", "warning_idx", "command", "field", "message",
+	}
+	stagedInterfacesColumns = []string{
+		"position", "interface_idx", "name", "is_up", "ip", "mtu", "description", "last_change",
+		"neighbor_hostname", "neighbor_interface",
+	}
+)
+
+// storeSnapshotBulk inserts the snapshot within the transaction with a fixed number of round trips.
+// Devices, warnings and interfaces are copied into temporary staging tables,
+// from which all other tables are filled by set-based queries sent as a single batch.
+func storeSnapshotBulk(ctx context.Context, tx pgx.Tx, identity repository.Identity, snapshot model.Snapshot) error {
+	if _, err := tx.Exec(ctx, createStagingTablesQuery); err != nil {
+		return err
+	}
+
+	snapshotArgs := pgx.NamedArgs{
+		"timestamp": snapshot.Timestamp,
+	}
+	var snapshotID int
+	if err := tx.QueryRow(ctx, insertSnapshotQuery, snapshotArgs).Scan(&snapshotID); err != nil {
+		return err
+	}
+
+	deviceIDs, isNewDevice, err := assignDeviceIDs(ctx, tx, identity, snapshot.Devices)
+	if err != nil {
+		return err
+	}
+
+	devices := make([][]any, 0, len(snapshot.Devices))
+	warnings := make([][]any, 0)
+	ifaces := make([][]any, 0)
+	for position, device := range snapshot.Devices {
+		devices = append(devices, []any{
+			position, device.Hostname, device.Vendor, device.OSName, device.OSVersion, device.Serial,
+			device.IsSnapshotSuccessful, string(device.Status), repository.StateHash(device), device.RunningConfig,
+			deviceIDs[position], isNewDevice[position],
+		})
+
+		for warningIdx, warning := range device.Warnings {
+			warnings = append(warnings, []any{
+				position, warningIdx, warning.Command, warning.Field, warning.Message,
+			})
+		}
+
+		for ifaceIdx, iface := range device.Interfaces {
+			ifaces = append(ifaces, []any{
+				position, ifaceIdx, iface.Name, iface.IsUp, iface.IP, iface.MTU, iface.Description,
+				toDBFromLastChange(iface.LastChange), iface.NeighborHostname, iface.NeighborInterface,
+			})
+		}
+	}
+
+	copies := []struct {
+		table   string
+		columns []string
+		rows    [][]any
+	}{
+		{"staged_devices", stagedDevicesColumns, devices},
+		{"staged_warnings", stagedWarningsColumns, warnings},
+		{"staged_interfaces", stagedInterfacesColumns, ifaces},
+	}
+	for _, c := range copies {
+		if len(c.rows) == 0 {
+			continue
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{c.table}, c.columns, pgx.CopyFromRows(c.rows)); err != nil {
+			return err
+		}
+	}
+
+	batch := &pgx.Batch{}
+	for _, query := range []string{
+		upsertStagedVendorsQuery,
+		upsertStagedOperatingSystemsQuery,
+		updateStagedAttributeIDsQuery,
+		insertStagedDevicesQuery,
+		updateStagedDevicesQuery,
+		insertStagedDeviceStatesQuery,
+		updateStagedDeviceStateIDsQuery,
+		insertStagedWarningsQuery,
+		upsertStagedInterfacesQuery,
+		insertStagedInterfaceStatesQuery,
+	} {
+		batch.Queue(query)
+	}
+	batch.Queue(insertStagedSnapshotDevicesQuery, pgx.NamedArgs{
+		"snapshot_id": snapshotID,
+	})
+
+	return tx.SendBatch(ctx, batch).Close()
+}
+
+// assignDeviceIDs returns ids of the devices and whether each device is new.
+// New devices get ids from the sequence, so they can be inserted together with the matched ones.
+func assignDeviceIDs(ctx context.Context, tx pgx.Tx, identity repository.Identity, devices []model.Device) ([]int, []bool, error) {
+	deviceIDs, err := matchDevices(ctx, tx, identity, devices)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	isNewDevice := make([]bool, len(deviceIDs))
+	count := 0
+	for i, id := range deviceIDs {
+		if id == 0 {
+			isNewDevice[i] = true
+			count++
+		}
+	}
+	if count == 0 {
+		return deviceIDs, isNewDevice, nil
+	}
+
+	args := pgx.NamedArgs{
+		"count": count,
+	}
+	rows, err := tx.Query(ctx, allocateDeviceIDsQuery, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	newIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range deviceIDs {
+		if isNewDevice[i] {
+			deviceIDs[i], newIDs = newIDs[0], newIDs[1:]
+		}
+	}
+
+	return deviceIDs, isNewDevice, nil
+}
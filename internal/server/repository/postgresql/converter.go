@@ -1,23 +1,48 @@
 package postgresql
 
 import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
 	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
 )
 
 // toSnapshotFromDB creates a snapshot from a slice of database responses.
-func toSnapshotFromDB(parts []dbSnapshotPart) model.Snapshot {
+// Warnings and running configurations are attached to the devices they belong to.
+func toSnapshotFromDB(parts []dbSnapshotPart, warnings []dbWarning, configs []dbConfig) model.Snapshot {
 	if len(parts) == 0 {
 		return model.Snapshot{}
 	}
 
+	deviceIDs := make([]int, 0)
 	deviceParts := make(map[int][]dbSnapshotPart, 1)
 	for _, part := range parts {
-		deviceParts[int(part.DeviceID.Int64)] = append(deviceParts[int(part.DeviceID.Int64)], part)
+		deviceID := int(part.DeviceID.Int64)
+		if _, ok := deviceParts[deviceID]; !ok {
+			deviceIDs = append(deviceIDs, deviceID)
+		}
+		deviceParts[deviceID] = append(deviceParts[deviceID], part)
+	}
+
+	deviceWarnings := make(map[int][]model.Warning, 1)
+	for _, warning := range warnings {
+		deviceWarnings[int(warning.DeviceID.Int64)] = append(deviceWarnings[int(warning.DeviceID.Int64)], model.Warning{
+			Command: warning.Command.String,
+			Field:   warning.Field.String,
+			Message: warning.Message.String,
+		})
+	}
+
+	deviceConfigs := make(map[int]string, len(configs))
+	for _, config := range configs {
+		deviceConfigs[int(config.DeviceID.Int64)] = config.RunningConfig.String
 	}
 
-	devices := make([]model.Device, len(deviceParts))
-	devicesIdx := 0
-	for _, devicePart := range deviceParts {
+	devices := make([]model.Device, len(deviceIDs))
+	for devicesIdx, deviceID := range deviceIDs {
+		devicePart := deviceParts[deviceID]
 		device := model.Device{
 			Hostname:             devicePart[0].Hostname.String,
 			Vendor:               devicePart[0].VendorName.String,
@@ -25,20 +50,31 @@ func toSnapshotFromDB(parts []dbSnapshotPart) model.Snapshot {
 			OSVersion:            devicePart[0].OSVersion.String,
 			Serial:               devicePart[0].SerialNumber.String,
 			IsSnapshotSuccessful: devicePart[0].IsSnapshotSuccessful.Bool,
+			Status:               toStatusFromDB(devicePart[0].Status, devicePart[0].IsSnapshotSuccessful),
+			Warnings:             deviceWarnings[deviceID],
+			RunningConfig:        deviceConfigs[deviceID],
 		}
 
 		for _, part := range devicePart {
+			// A device without interfaces is returned as a single part without an interface.
+			if !part.InterfaceName.Valid {
+				continue
+			}
+
 			iface := model.Interface{
-				Name: part.InterfaceName.String,
-				IsUp: part.IsUp.Bool,
-				IP:   part.IP,
-				MTU:  part.MTU.Int64,
+				Name:              part.InterfaceName.String,
+				IsUp:              part.IsUp.Bool,
+				IP:                part.IP,
+				MTU:               part.MTU.Int64,
+				Description:       part.Description.String,
+				LastChange:        toLastChangeFromDB(part.LastChange),
+				NeighborHostname:  part.NeighborHostname.String,
+				NeighborInterface: part.NeighborInterface.String,
 			}
 			device.Interfaces = append(device.Interfaces, iface)
 		}
 
 		devices[devicesIdx] = device
-		devicesIdx++
 	}
 
 	return model.Snapshot{
@@ -47,3 +83,151 @@ func toSnapshotFromDB(parts []dbSnapshotPart) model.Snapshot {
 		Devices:   devices,
 	}
 }
+
+// toDeviceHistoryFromDB creates snapshots with a single device from a slice of database responses,
+// grouped by the current hostname of the device.
+// Snapshots sharing a device state share its interfaces, warnings and running configuration.
+func toDeviceHistoryFromDB(
+	snapshots []dbDeviceSnapshot,
+	parts []dbDeviceStatePart,
+	warnings []dbDeviceStateWarning,
+	configs []dbDeviceStateConfig,
+) map[string][]model.Snapshot {
+	stateWarnings := make(map[int][]model.Warning)
+	for _, warning := range warnings {
+		stateID := int(warning.DeviceStateID.Int64)
+		stateWarnings[stateID] = append(stateWarnings[stateID], model.Warning{
+			Command: warning.Command.String,
+			Field:   warning.Field.String,
+			Message: warning.Message.String,
+		})
+	}
+
+	stateConfigs := make(map[int]string, len(configs))
+	for _, config := range configs {
+		stateConfigs[int(config.DeviceStateID.Int64)] = config.RunningConfig.String
+	}
+
+	states := make(map[int]model.Device)
+	for _, part := range parts {
+		stateID := int(part.DeviceStateID.Int64)
+		device, ok := states[stateID]
+		if !ok {
+			device = model.Device{
+				Hostname:             part.Hostname.String,
+				Vendor:               part.VendorName.String,
+				OSName:               part.OSName.String,
+				OSVersion:            part.OSVersion.String,
+				Serial:               part.SerialNumber.String,
+				IsSnapshotSuccessful: part.IsSnapshotSuccessful.Bool,
+				Status:               toStatusFromDB(part.Status, part.IsSnapshotSuccessful),
+				Warnings:             stateWarnings[stateID],
+				RunningConfig:        stateConfigs[stateID],
+			}
+		}
+
+		// A device state without interfaces is returned as a single part without an interface.
+		if part.InterfaceName.Valid {
+			device.Interfaces = append(device.Interfaces, model.Interface{
+				Name:              part.InterfaceName.String,
+				IsUp:              part.IsUp.Bool,
+				IP:                part.IP,
+				MTU:               part.MTU.Int64,
+				Description:       part.Description.String,
+				LastChange:        toLastChangeFromDB(part.LastChange),
+				NeighborHostname:  part.NeighborHostname.String,
+				NeighborInterface: part.NeighborInterface.String,
+			})
+		}
+
+		states[stateID] = device
+	}
+
+	histories := make(map[string][]model.Snapshot)
+	for _, snapshot := range snapshots {
+		device, ok := states[int(snapshot.DeviceStateID.Int64)]
+		if !ok {
+			continue
+		}
+
+		hostname := snapshot.Hostname.String
+		histories[hostname] = append(histories[hostname], model.Snapshot{
+			ID:        int(snapshot.ID.Int64),
+			Timestamp: snapshot.Timestamp.Time,
+			Devices:   []model.Device{device},
+		})
+	}
+
+	return histories
+}
+
+// toStatusFromDB returns device status stored in the database.
+// Rows written before statuses were introduced are handled by deriving the status from [isSnapshotSuccessful].
+func toStatusFromDB(status pgtype.Text, isSnapshotSuccessful pgtype.Bool) model.DeviceStatus {
+	if status.Valid && status.String != "" {
+		return model.DeviceStatus(status.String)
+	}
+
+	if isSnapshotSuccessful.Bool {
+		return model.StatusSuccess
+	}
+
+	return model.StatusFailure
+}
+
+// toStoredDeviceFromDB creates a stored device from the database response.
+// A device without snapshots has zero first and last seen times.
+func toStoredDeviceFromDB(d dbDevice) repository.StoredDevice {
+	return repository.StoredDevice{
+		ID:        int(d.ID.Int64),
+		Hostname:  d.Hostname.String,
+		Serial:    d.SerialNumber.String,
+		Vendor:    d.VendorName.String,
+		OSName:    d.OSName.String,
+		OSVersion: d.OSVersion.String,
+		FirstSeen: d.FirstSeen.Time,
+		LastSeen:  d.LastSeen.Time,
+		Snapshots: int(d.Snapshots.Int64),
+	}
+}
+
+// toStoredAddressFromDB creates a stored address from the database response.
+func toStoredAddressFromDB(a dbAddress) repository.StoredAddress {
+	return repository.StoredAddress{
+		DeviceID:  int(a.DeviceID.Int64),
+		Hostname:  a.Hostname.String,
+		Interface: a.InterfaceName.String,
+		IP:        a.IP,
+		FirstSeen: a.FirstSeen.Time,
+		LastSeen:  a.LastSeen.Time,
+	}
+}
+
+// toFindingFromDB creates a finding from the database response.
+func toFindingFromDB(f dbFinding) repository.Finding {
+	return repository.Finding{
+		SnapshotID: int(f.SnapshotID.Int64),
+		Timestamp:  f.Timestamp.Time,
+		Analysis:   f.Analysis.String,
+		Hostname:   f.Hostname.String,
+		Interface:  f.InterfaceName.String,
+		Check:      f.CheckName.String,
+		Severity:   f.Severity.String,
+		Message:    f.Message.String,
+	}
+}
+
+// toDBFromLastChange converts the last change of an interface to the value stored in the database.
+// An unknown last change is stored as NULL.
+func toDBFromLastChange(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: !t.IsZero()}
+}
+
+// toLastChangeFromDB converts a value stored in the database to the last change of an interface.
+func toLastChangeFromDB(t pgtype.Timestamptz) time.Time {
+	if !t.Valid {
+		return time.Time{}
+	}
+
+	return t.Time
+}
@@ -0,0 +1,139 @@
+package postgresql
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/migrations"
+)
+
+// migrationsLockKey identifies the advisory lock held while migrating.
+// Any constant works as long as every server uses the same one.
+const migrationsLockKey = 7_305_521_646_017
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var _ migrations.Driver = (*migrationDriver)(nil)
+
+// migrationDriver implements the [Driver] interface.
+type migrationDriver struct {
+	db *pgxpool.Pool
+}
+
+// Migrator implements the [Migratable] interface.
+func (p *postgreSQL) Migrator() repository.Migrator {
+	return p.migrator
+}
+
+// newMigrator returns an object that migrates the schema with the embedded migrations.
+func newMigrator(p *postgreSQL) (repository.Migrator, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return migrations.NewMigrator(p.logger, &migrationDriver{db: p.db}, files)
+}
+
+// Lock implements the [Driver] interface.
+// The lock is a session-level advisory lock, so it is held by a dedicated connection.
+func (d *migrationDriver) Lock(ctx context.Context) (func(), error) {
+	conn, err := d.db.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lockArgs := pgx.NamedArgs{
+		"key": migrationsLockKey,
+	}
+	if _, err := conn.Exec(ctx, lockMigrationsQuery, lockArgs); err != nil {
+		conn.Release()
+		return nil, err
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
+		defer cancel()
+
+		// If unlocking fails, the connection is closed, which releases the lock.
+		if _, err := conn.Exec(ctx, unlockMigrationsQuery, lockArgs); err != nil {
+			_ = conn.Conn().Close(ctx)
+		}
+		conn.Release()
+	}, nil
+}
+
+// Init implements the [Driver] interface.
+func (d *migrationDriver) Init(ctx context.Context) error {
+	_, err := d.db.Exec(ctx, createTableSchemaMigrationsQuery)
+	return err
+}
+
+// Applied implements the [Driver] interface.
+func (d *migrationDriver) Applied(ctx context.Context) (map[int]time.Time, error) {
+	rows, err := d.db.Query(ctx, selectSchemaMigrationsQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// Apply implements the [Driver] interface.
+func (d *migrationDriver) Apply(ctx context.Context, m migrations.Migration) error {
+	return d.migrate(ctx, m.Up, insertSchemaMigrationQuery, pgx.NamedArgs{
+		"version": m.Version,
+		"name":    m.Name,
+	})
+}
+
+// Revert implements the [Driver] interface.
+func (d *migrationDriver) Revert(ctx context.Context, m migrations.Migration) error {
+	return d.migrate(ctx, m.Down, deleteSchemaMigrationQuery, pgx.NamedArgs{
+		"version": m.Version,
+	})
+}
+
+// migrate runs the migration query and updates schema_migrations in a single transaction.
+func (d *migrationDriver) migrate(ctx context.Context, query, recordQuery string, recordArgs pgx.NamedArgs) error {
+	tx, err := d.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, query); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, recordQuery, recordArgs); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
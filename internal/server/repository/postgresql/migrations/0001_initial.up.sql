@@ -0,0 +1,49 @@
+-- Tables are created only if missing, so databases created before migrations were introduced are adopted as is.
+
+CREATE TABLE IF NOT EXISTS snapshots (
+	id SERIAL PRIMARY KEY,
+	timestamp TIMESTAMPTZ UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS vendors (
+	id SERIAL PRIMARY KEY,
+	name TEXT UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS operating_systems (
+	id SERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	version TEXT,
+	UNIQUE (name, version)
+);
+
+CREATE TABLE IF NOT EXISTS devices (
+	id SERIAL PRIMARY KEY,
+	vendor_id INT REFERENCES vendors(id) ON DELETE RESTRICT,
+	operating_system_id INT REFERENCES operating_systems(id) ON DELETE RESTRICT,
+	hostname TEXT UNIQUE NOT NULL,
+	serial_number TEXT
+);
+
+CREATE TABLE IF NOT EXISTS device_states (
+	id SERIAL PRIMARY KEY,
+	snapshot_id INT REFERENCES snapshots(id) ON DELETE CASCADE,
+	device_id INT REFERENCES devices(id) ON DELETE RESTRICT,
+	is_snapshot_successful BOOLEAN NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS interfaces (
+	id SERIAL PRIMARY KEY,
+	device_id INT REFERENCES devices(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	UNIQUE (device_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS interface_states (
+	id SERIAL PRIMARY KEY,
+	interface_id INT REFERENCES interfaces(id) ON DELETE CASCADE,
+	device_state_id INT REFERENCES device_states(id) ON DELETE CASCADE,
+	is_up BOOLEAN NOT NULL,
+	ip INET,
+	mtu INT
+);
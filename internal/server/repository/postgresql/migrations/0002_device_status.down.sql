@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS device_warnings;
+
+ALTER TABLE device_states
+DROP COLUMN IF EXISTS status;
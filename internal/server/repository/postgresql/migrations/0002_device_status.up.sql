@@ -0,0 +1,10 @@
+ALTER TABLE device_states
+ADD COLUMN IF NOT EXISTS status TEXT;
+
+CREATE TABLE IF NOT EXISTS device_warnings (
+	id SERIAL PRIMARY KEY,
+	device_state_id INT REFERENCES device_states(id) ON DELETE CASCADE,
+	command TEXT NOT NULL,
+	field TEXT NOT NULL,
+	message TEXT NOT NULL
+);
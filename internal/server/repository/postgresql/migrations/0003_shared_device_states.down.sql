@@ -0,0 +1,42 @@
+-- Every snapshot gets its own copy of the device states it refers to.
+
+DROP INDEX IF EXISTS device_warnings_device_state_id_idx;
+DROP INDEX IF EXISTS interface_states_device_state_id_idx;
+
+ALTER TABLE device_states
+ADD COLUMN snapshot_id INT REFERENCES snapshots(id) ON DELETE CASCADE;
+
+CREATE TEMPORARY TABLE device_state_copies ON COMMIT DROP AS
+SELECT
+	s_d.snapshot_id,
+	s_d.device_state_id AS original_id,
+	nextval(pg_get_serial_sequence('device_states', 'id')) AS copy_id
+FROM snapshot_devices AS s_d;
+
+INSERT INTO device_states (id, snapshot_id, device_id, is_snapshot_successful, status)
+SELECT c.copy_id, c.snapshot_id, d_s.device_id, d_s.is_snapshot_successful, d_s.status
+FROM
+	device_state_copies AS c
+	JOIN device_states AS d_s ON d_s.id = c.original_id;
+
+INSERT INTO device_warnings (device_state_id, command, field, message)
+SELECT c.copy_id, w.command, w.field, w.message
+FROM
+	device_state_copies AS c
+	JOIN device_warnings AS w ON w.device_state_id = c.original_id
+ORDER BY c.copy_id, w.id;
+
+INSERT INTO interface_states (interface_id, device_state_id, is_up, ip, mtu)
+SELECT i_s.interface_id, c.copy_id, i_s.is_up, i_s.ip, i_s.mtu
+FROM
+	device_state_copies AS c
+	JOIN interface_states AS i_s ON i_s.device_state_id = c.original_id
+ORDER BY c.copy_id, i_s.id;
+
+DROP TABLE snapshot_devices;
+
+DELETE FROM device_states
+WHERE snapshot_id IS NULL;
+
+ALTER TABLE device_states
+DROP COLUMN state_hash;
@@ -0,0 +1,14 @@
+-- Fails if several devices have the same hostname; merge them first.
+-- Device states lose the attributes they were stored with.
+
+ALTER TABLE device_states
+DROP COLUMN vendor_id,
+DROP COLUMN operating_system_id,
+DROP COLUMN hostname,
+DROP COLUMN serial_number;
+
+DROP INDEX IF EXISTS devices_serial_number_idx;
+DROP INDEX IF EXISTS devices_hostname_idx;
+
+ALTER TABLE devices
+ADD CONSTRAINT devices_hostname_key UNIQUE (hostname);
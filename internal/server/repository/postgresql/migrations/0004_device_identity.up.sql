@@ -0,0 +1,24 @@
+-- A hostname may belong to several devices, for example after a chassis swap, so it is no longer unique.
+-- Device states store the attributes a device had in a snapshot; devices keep the current ones.
+-- Existing states keep hashes computed without attributes, so the next snapshot stores new states once.
+
+ALTER TABLE devices
+DROP CONSTRAINT IF EXISTS devices_hostname_key;
+
+CREATE INDEX devices_hostname_idx ON devices (hostname);
+CREATE INDEX devices_serial_number_idx ON devices (serial_number);
+
+ALTER TABLE device_states
+ADD COLUMN vendor_id INT REFERENCES vendors(id) ON DELETE RESTRICT,
+ADD COLUMN operating_system_id INT REFERENCES operating_systems(id) ON DELETE RESTRICT,
+ADD COLUMN hostname TEXT,
+ADD COLUMN serial_number TEXT;
+
+UPDATE device_states AS d_s
+SET
+	vendor_id = d.vendor_id,
+	operating_system_id = d.operating_system_id,
+	hostname = d.hostname,
+	serial_number = d.serial_number
+FROM devices AS d
+WHERE d.id = d_s.device_id;
@@ -0,0 +1,13 @@
+CREATE TABLE findings (
+	id SERIAL PRIMARY KEY,
+	snapshot_id INT NOT NULL REFERENCES snapshots(id) ON DELETE CASCADE,
+	analysis TEXT NOT NULL,
+	hostname TEXT NOT NULL,
+	interface_name TEXT NOT NULL,
+	check_name TEXT NOT NULL,
+	severity TEXT NOT NULL,
+	message TEXT NOT NULL
+);
+
+CREATE INDEX findings_snapshot_id_analysis_idx ON findings (snapshot_id, analysis);
+CREATE INDEX findings_hostname_idx ON findings (hostname);
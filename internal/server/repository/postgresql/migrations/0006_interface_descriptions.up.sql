@@ -0,0 +1,2 @@
+-- Interfaces of existing states have no description.
+ALTER TABLE interface_states ADD COLUMN description TEXT NOT NULL DEFAULT '';
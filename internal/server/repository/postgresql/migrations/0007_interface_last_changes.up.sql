@@ -0,0 +1,2 @@
+-- Interfaces of existing states have no last change.
+ALTER TABLE interface_states ADD COLUMN last_change TIMESTAMPTZ;
@@ -0,0 +1,2 @@
+ALTER TABLE interface_states DROP COLUMN IF EXISTS neighbor_interface;
+ALTER TABLE interface_states DROP COLUMN IF EXISTS neighbor_hostname;
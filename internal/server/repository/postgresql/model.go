@@ -23,8 +23,111 @@ type dbSnapshotPart struct {
 	Hostname             pgtype.Text        `db:"hostname"`
 	SerialNumber         pgtype.Text        `db:"serial_number"`
 	IsSnapshotSuccessful pgtype.Bool        `db:"is_snapshot_successful"`
+	Status               pgtype.Text        `db:"status"`
 	InterfaceName        pgtype.Text        `db:"interface_name"`
 	IsUp                 pgtype.Bool        `db:"is_up"`
 	IP                   netip.Prefix       `db:"ip"`
 	MTU                  pgtype.Int8        `db:"mtu"`
+	Description          pgtype.Text        `db:"description"`
+	LastChange           pgtype.Timestamptz `db:"last_change"`
+	NeighborHostname     pgtype.Text        `db:"neighbor_hostname"`
+	NeighborInterface    pgtype.Text        `db:"neighbor_interface"`
+}
+
+// dbWarning is an auxiliary structure into which the database response is written.
+type dbWarning struct {
+	DeviceID pgtype.Int8 `db:"device_id"`
+	Command  pgtype.Text `db:"command"`
+	Field    pgtype.Text `db:"field"`
+	Message  pgtype.Text `db:"message"`
+}
+
+// dbConfig is an auxiliary structure into which the database response is written.
+type dbConfig struct {
+	DeviceID      pgtype.Int8 `db:"device_id"`
+	RunningConfig pgtype.Text `db:"running_config"`
+}
+
+// dbDeviceSnapshot is an auxiliary structure into which the database response is written.
+type dbDeviceSnapshot struct {
+	ID            pgtype.Int8        `db:"id"`
+	Timestamp     pgtype.Timestamptz `db:"timestamp"`
+	DeviceStateID pgtype.Int8        `db:"device_state_id"`
+	Hostname      pgtype.Text        `db:"hostname"`
+}
+
+// dbDeviceStatePart is an auxiliary structure into which the database response is written.
+type dbDeviceStatePart struct {
+	DeviceStateID        pgtype.Int8        `db:"device_state_id"`
+	VendorName           pgtype.Text        `db:"vendor_name"`
+	OSName               pgtype.Text        `db:"os_name"`
+	OSVersion            pgtype.Text        `db:"os_version"`
+	Hostname             pgtype.Text        `db:"hostname"`
+	SerialNumber         pgtype.Text        `db:"serial_number"`
+	IsSnapshotSuccessful pgtype.Bool        `db:"is_snapshot_successful"`
+	Status               pgtype.Text        `db:"status"`
+	InterfaceName        pgtype.Text        `db:"interface_name"`
+	IsUp                 pgtype.Bool        `db:"is_up"`
+	IP                   netip.Prefix       `db:"ip"`
+	MTU                  pgtype.Int8        `db:"mtu"`
+	Description          pgtype.Text        `db:"description"`
+	LastChange           pgtype.Timestamptz `db:"last_change"`
+	NeighborHostname     pgtype.Text        `db:"neighbor_hostname"`
+	NeighborInterface    pgtype.Text        `db:"neighbor_interface"`
+}
+
+// dbDeviceStateWarning is an auxiliary structure into which the database response is written.
+type dbDeviceStateWarning struct {
+	DeviceStateID pgtype.Int8 `db:"device_state_id"`
+	Command       pgtype.Text `db:"command"`
+	Field         pgtype.Text `db:"field"`
+	Message       pgtype.Text `db:"message"`
+}
+
+// dbDeviceStateConfig is an auxiliary structure into which the database response is written.
+type dbDeviceStateConfig struct {
+	DeviceStateID pgtype.Int8 `db:"device_state_id"`
+	RunningConfig pgtype.Text `db:"running_config"`
+}
+
+// dbDeviceIdentity is an auxiliary structure into which the database response is written.
+type dbDeviceIdentity struct {
+	ID           pgtype.Int8 `db:"id"`
+	Hostname     pgtype.Text `db:"hostname"`
+	SerialNumber pgtype.Text `db:"serial_number"`
+}
+
+// dbDevice is an auxiliary structure into which the database response is written.
+type dbDevice struct {
+	ID           pgtype.Int8        `db:"id"`
+	Hostname     pgtype.Text        `db:"hostname"`
+	SerialNumber pgtype.Text        `db:"serial_number"`
+	VendorName   pgtype.Text        `db:"vendor_name"`
+	OSName       pgtype.Text        `db:"os_name"`
+	OSVersion    pgtype.Text        `db:"os_version"`
+	FirstSeen    pgtype.Timestamptz `db:"first_seen"`
+	LastSeen     pgtype.Timestamptz `db:"last_seen"`
+	Snapshots    pgtype.Int8        `db:"snapshots"`
+}
+
+// dbAddress is an auxiliary structure into which the database response is written.
+type dbAddress struct {
+	DeviceID      pgtype.Int8        `db:"device_id"`
+	Hostname      pgtype.Text        `db:"hostname"`
+	InterfaceName pgtype.Text        `db:"interface_name"`
+	IP            netip.Prefix       `db:"ip"`
+	FirstSeen     pgtype.Timestamptz `db:"first_seen"`
+	LastSeen      pgtype.Timestamptz `db:"last_seen"`
+}
+
+// dbFinding is an auxiliary structure into which the database response is written.
+type dbFinding struct {
+	SnapshotID    pgtype.Int8        `db:"snapshot_id"`
+	Timestamp     pgtype.Timestamptz `db:"timestamp"`
+	Analysis      pgtype.Text        `db:"analysis"`
+	Hostname      pgtype.Text        `db:"hostname"`
+	InterfaceName pgtype.Text        `db:"interface_name"`
+	CheckName     pgtype.Text        `db:"check_name"`
+	Severity      pgtype.Text        `db:"severity"`
+	Message       pgtype.Text        `db:"message"`
 }
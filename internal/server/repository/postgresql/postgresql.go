@@ -3,10 +3,16 @@ package postgresql
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/sudeeya/net-monitor/internal/pkg/model"
@@ -15,90 +21,131 @@ import (
 
 const limitInSeconds = 5
 
-var _ repository.Repository = (*postgreSQL)(nil)
+// devicesLockKey identifies the advisory lock held while devices of a snapshot are matched and stored,
+// so concurrent snapshots do not create the same new device twice,
+// and while devices are merged or orphaned rows are pruned.
+const devicesLockKey = 7_305_521_646_018
 
-// postgreSQL implements the [Repository] interface.
+var (
+	_ repository.Repository = (*postgreSQL)(nil)
+	_ repository.Migratable = (*postgreSQL)(nil)
+	_ repository.Closer     = (*postgreSQL)(nil)
+)
+
+// postgreSQL implements the [Repository], [Migratable] and [Closer] interfaces.
 type postgreSQL struct {
-	logger *zap.Logger
-	db     *pgxpool.Pool
+	logger        *zap.Logger
+	db            *pgxpool.Pool
+	migrator      repository.Migrator
+	bulkThreshold int
+	identity      repository.Identity
 }
 
 // NewPostgreSQL returns postgreSQL object to interact with PostgreSQL database.
-// The function establishes and tests the connection to the database and creates the necessary tables.
-func NewPostgreSQL(logger *zap.Logger, dsn string) (*postgreSQL, error) {
+// The function establishes the connection to the database.
+// Snapshots with at least [bulkThreshold] devices are stored through staging tables; zero disables it.
+// Devices of stored snapshots are matched with known devices by the [identity] rules.
+// The schema is not changed; it is created and updated by the [Migrator].
+func NewPostgreSQL(logger *zap.Logger, dsn string, bulkThreshold int, identity repository.Identity) (*postgreSQL, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
 	defer cancel()
 
-	logger.Info("Establishing a connection to the database")
-	db, err := pgxpool.New(ctx, dsn)
+	poolCfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, err
 	}
+	poolCfg.ConnConfig.Tracer = queryTracer{}
 
-	logger.Info("Creating necessary tables")
-	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	logger.Info("Establishing a connection to the database")
+	db, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, err
 	}
 
-	createTableQueries := []string{
-		createTableSnapshotsQuery,
-		createTableVendorsQuery,
-		createTableOperatingSystemsQuery,
-		createTableDevicesQuery,
-		createTableDeviceStatesQuery,
-		createTableInterfacesQuery,
-		createTableInterfaceStatesQuery,
+	p := &postgreSQL{
+		logger:        logger,
+		db:            db,
+		bulkThreshold: bulkThreshold,
+		identity:      identity,
 	}
 
-	for _, query := range createTableQueries {
-		if _, err := tx.Exec(ctx, query); err != nil {
-			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-				return nil, rollbackErr
-			}
-			return nil, err
-		}
-	}
-
-	if err := tx.Commit(ctx); err != nil {
+	p.migrator, err = newMigrator(p)
+	if err != nil {
 		return nil, err
 	}
 
-	return &postgreSQL{
-		logger: logger,
-		db:     db,
-	}, nil
+	return p, nil
+}
+
+// Close implements the [Closer] interface.
+func (p *postgreSQL) Close() error {
+	p.logger.Info("Closing the connections to the database")
+	p.db.Close()
+	return nil
 }
 
 // StoreSnapshot implements the [Repository] interface.
-func (p *postgreSQL) StoreSnapshot(ctx context.Context, snapshot model.Snapshot) error {
+// A device state is written only if it differs from the states already stored for the device.
+func (p *postgreSQL) StoreSnapshot(ctx context.Context, snapshot model.Snapshot) (err error) {
 	p.logger.Info("Storing a snapshot to the database")
 
+	bulk := p.bulkThreshold > 0 && len(snapshot.Devices) >= p.bulkThreshold
+	ctx, span := tracer.Start(ctx, "postgresql.StoreSnapshot", trace.WithAttributes(
+		semconv.DBSystemPostgreSQL,
+		attribute.Int("snapshot.devices", len(snapshot.Devices)),
+		attribute.Bool("snapshot.bulk", bulk),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return err
 	}
 
+	store := storeSnapshotByRow
+	if bulk {
+		store = storeSnapshotBulk
+	}
+
+	if err := store(ctx, tx, p.identity, snapshot); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// storeSnapshotByRow inserts the snapshot within the transaction row by row.
+// It needs several round trips per device and interface, but no staging tables,
+// so it is faster for small snapshots.
+func storeSnapshotByRow(ctx context.Context, tx pgx.Tx, identity repository.Identity, snapshot model.Snapshot) error {
 	snapshotArgs := pgx.NamedArgs{
 		"timestamp": snapshot.Timestamp,
 	}
 	var snapshotID int
 	if err := tx.QueryRow(ctx, insertSnapshotQuery, snapshotArgs).Scan(&snapshotID); err != nil {
-		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-			return rollbackErr
-		}
 		return err
 	}
 
-	for _, device := range snapshot.Devices {
+	deviceIDs, err := matchDevices(ctx, tx, identity, snapshot.Devices)
+	if err != nil {
+		return err
+	}
+
+	for deviceIdx, device := range snapshot.Devices {
 		vendorArgs := pgx.NamedArgs{
 			"vendor": device.Vendor,
 		}
 		var vendorID int
 		if err := tx.QueryRow(ctx, insertVendorQuery, vendorArgs).Scan(&vendorID); err != nil {
-			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-				return rollbackErr
-			}
 			return err
 		}
 
@@ -108,69 +155,138 @@ func (p *postgreSQL) StoreSnapshot(ctx context.Context, snapshot model.Snapshot)
 		}
 		var osID int
 		if err := tx.QueryRow(ctx, insertOperatingSystemQuery, osArgs).Scan(&osID); err != nil {
-			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-				return rollbackErr
-			}
 			return err
 		}
 
+		deviceID := deviceIDs[deviceIdx]
 		deviceArgs := pgx.NamedArgs{
+			"id":                  deviceID,
 			"vendor_id":           vendorID,
 			"operating_system_id": osID,
 			"hostname":            device.Hostname,
 			"serial_number":       device.Serial,
 		}
-		var deviceID int
-		if err := tx.QueryRow(ctx, insertDeviceQuery, deviceArgs).Scan(&deviceID); err != nil {
-			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-				return rollbackErr
-			}
+		if deviceID == 0 {
+			err = tx.QueryRow(ctx, insertDeviceQuery, deviceArgs).Scan(&deviceID)
+		} else {
+			_, err = tx.Exec(ctx, updateDeviceQuery, deviceArgs)
+		}
+		if err != nil {
+			return err
+		}
+
+		deviceStateID, err := storeDeviceState(ctx, tx, deviceID, vendorID, osID, device)
+		if err != nil {
 			return err
 		}
 
-		deviceStateArgs := pgx.NamedArgs{
-			"snapshot_id":            snapshotID,
-			"device_id":              deviceID,
-			"is_snapshot_successful": device.IsSnapshotSuccessful,
+		snapshotDeviceArgs := pgx.NamedArgs{
+			"snapshot_id":     snapshotID,
+			"device_id":       deviceID,
+			"device_state_id": deviceStateID,
 		}
-		var deviceStateID int
-		if err := tx.QueryRow(ctx, insertDeviceStateQuery, deviceStateArgs).Scan(&deviceStateID); err != nil {
-			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-				return rollbackErr
-			}
+		if _, err := tx.Exec(ctx, insertSnapshotDeviceQuery, snapshotDeviceArgs); err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
+
+// matchDevices returns ids of the stored devices matching the devices by the identity rules, or 0 for new devices.
+// The devices lock is held until the transaction ends.
+func matchDevices(ctx context.Context, tx pgx.Tx, identity repository.Identity, devices []model.Device) ([]int, error) {
+	lockArgs := pgx.NamedArgs{
+		"key": devicesLockKey,
+	}
+	if _, err := tx.Exec(ctx, lockDevicesQuery, lockArgs); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, selectDeviceIdentitiesQuery)
+	if err != nil {
+		return nil, err
+	}
+	dbIdentities, err := pgx.CollectRows(rows, pgx.RowToStructByName[dbDeviceIdentity])
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make([]repository.StoredDevice, len(dbIdentities))
+	for i, d := range dbIdentities {
+		stored[i] = repository.StoredDevice{
+			ID:       int(d.ID.Int64),
+			Hostname: d.Hostname.String,
+			Serial:   d.SerialNumber.String,
+		}
+	}
+
+	return identity.Match(stored, devices), nil
+}
 
-		for _, iface := range device.Interfaces {
-			ifaceArgs := pgx.NamedArgs{
-				"device_id": deviceID,
-				"name":      iface.Name,
-			}
-			var ifaceID int
-			if err := tx.QueryRow(ctx, insertInterfaceQuery, ifaceArgs).Scan(&ifaceID); err != nil {
-				if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-					return rollbackErr
-				}
-				return err
-			}
-
-			ifaceStateArgs := pgx.NamedArgs{
-				"interface_id":    ifaceID,
-				"device_state_id": deviceStateID,
-				"is_up":           iface.IsUp,
-				"ip":              iface.IP,
-				"mtu":             iface.MTU,
-			}
-			if _, err := tx.Exec(ctx, insertInterfaceStateQuery, ifaceStateArgs); err != nil {
-				if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
-					return rollbackErr
-				}
-				return err
-			}
+// storeDeviceState returns the id of the device state equal to the state of [device].
+// If there is no such state, it is inserted together with warnings and interface states.
+func storeDeviceState(ctx context.Context, tx pgx.Tx, deviceID, vendorID, osID int, device model.Device) (int, error) {
+	deviceStateArgs := pgx.NamedArgs{
+		"device_id":              deviceID,
+		"vendor_id":              vendorID,
+		"operating_system_id":    osID,
+		"hostname":               device.Hostname,
+		"serial_number":          device.Serial,
+		"is_snapshot_successful": device.IsSnapshotSuccessful,
+		"status":                 string(device.Status),
+		"state_hash":             repository.StateHash(device),
+		"running_config":         device.RunningConfig,
+	}
+	var deviceStateID int
+	err := tx.QueryRow(ctx, insertDeviceStateQuery, deviceStateArgs).Scan(&deviceStateID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = tx.QueryRow(ctx, selectDeviceStateQuery, deviceStateArgs).Scan(&deviceStateID)
+		return deviceStateID, err
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	for _, warning := range device.Warnings {
+		warningArgs := pgx.NamedArgs{
+			"device_state_id": deviceStateID,
+			"command":         warning.Command,
+			"field":           warning.Field,
+			"message":         warning.Message,
+		}
+		if _, err := tx.Exec(ctx, insertDeviceWarningQuery, warningArgs); err != nil {
+			return 0, err
 		}
 	}
 
-	return tx.Commit(ctx)
+	for _, iface := range device.Interfaces {
+		ifaceArgs := pgx.NamedArgs{
+			"device_id": deviceID,
+			"name":      iface.Name,
+		}
+		var ifaceID int
+		if err := tx.QueryRow(ctx, insertInterfaceQuery, ifaceArgs).Scan(&ifaceID); err != nil {
+			return 0, err
+		}
+
+		ifaceStateArgs := pgx.NamedArgs{
+			"interface_id":       ifaceID,
+			"device_state_id":    deviceStateID,
+			"is_up":              iface.IsUp,
+			"ip":                 iface.IP,
+			"mtu":                iface.MTU,
+			"description":        iface.Description,
+			"last_change":        toDBFromLastChange(iface.LastChange),
+			"neighbor_hostname":  iface.NeighborHostname,
+			"neighbor_interface": iface.NeighborInterface,
+		}
+		if _, err := tx.Exec(ctx, insertInterfaceStateQuery, ifaceStateArgs); err != nil {
+			return 0, err
+		}
+	}
+
+	return deviceStateID, nil
 }
 
 // GetNTimestamps implements the [Repository] interface.
@@ -180,7 +296,53 @@ func (p *postgreSQL) GetNTimestamps(ctx context.Context, n int) ([]model.Snapsho
 	args := pgx.NamedArgs{
 		"limit": n,
 	}
-	rows, err := p.db.Query(ctx, selectTimestampsQuery, args)
+
+	return p.getTimestamps(ctx, selectTimestampsQuery, args)
+}
+
+// GetTimestampsBefore implements the [Repository] interface.
+func (p *postgreSQL) GetTimestampsBefore(ctx context.Context, before time.Time, n int) ([]model.Snapshot, error) {
+	p.logger.Sugar().Infof("Getting %d timestamps before %s from the database", n, before)
+
+	args := pgx.NamedArgs{
+		"before": before,
+		"limit":  n,
+	}
+
+	return p.getTimestamps(ctx, selectTimestampsBeforeQuery, args)
+}
+
+// GetTimestampsBetween implements the [Repository] interface.
+func (p *postgreSQL) GetTimestampsBetween(ctx context.Context, from, to time.Time) ([]model.Snapshot, error) {
+	p.logger.Sugar().Infof("Getting timestamps from %s to %s from the database", from, to)
+
+	args := pgx.NamedArgs{
+		"from": from,
+		"to":   to,
+	}
+
+	return p.getTimestamps(ctx, selectTimestampsBetweenQuery, args)
+}
+
+// GetClosestTimestamp implements the [Repository] interface.
+// Only the last snapshot before and the first snapshot after [at] are compared.
+func (p *postgreSQL) GetClosestTimestamp(ctx context.Context, at time.Time) (model.Snapshot, error) {
+	p.logger.Sugar().Infof("Getting the timestamp closest to %s from the database", at)
+
+	args := pgx.NamedArgs{
+		"at": at,
+	}
+	candidates, err := p.getTimestamps(ctx, selectClosestTimestampsQuery, args)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	return repository.ClosestTimestamp(candidates, at), nil
+}
+
+// getTimestamps returns snapshot ids and timestamps selected by the query.
+func (p *postgreSQL) getTimestamps(ctx context.Context, query string, args pgx.NamedArgs) ([]model.Snapshot, error) {
+	rows, err := p.db.Query(ctx, query, args)
 	if err != nil {
 		return nil, err
 	}
@@ -206,8 +368,26 @@ func (p *postgreSQL) GetNTimestamps(ctx context.Context, n int) ([]model.Snapsho
 func (p *postgreSQL) GetSnapshot(ctx context.Context, id int) (model.Snapshot, error) {
 	p.logger.Info("Getting a snapshot from the database")
 
+	return p.getSnapshot(ctx, id, repository.DeviceFilter{})
+}
+
+// GetFilteredSnapshot implements the [Repository] interface.
+func (p *postgreSQL) GetFilteredSnapshot(ctx context.Context, id int, filter repository.DeviceFilter) (model.Snapshot, error) {
+	p.logger.Sugar().Infof("Getting a snapshot filtered by %+v from the database", filter)
+
+	return p.getSnapshot(ctx, id, filter)
+}
+
+// getSnapshot returns a snapshot with the devices matching the filter.
+// Warnings and running configurations are selected for every device of the snapshot;
+// those of filtered out devices are not attached.
+func (p *postgreSQL) getSnapshot(ctx context.Context, id int, filter repository.DeviceFilter) (model.Snapshot, error) {
 	args := pgx.NamedArgs{
-		"id": int(id),
+		"id":         id,
+		"hostname":   filter.Hostname,
+		"vendor":     filter.Vendor,
+		"os_name":    filter.OSName,
+		"os_version": filter.OSVersion,
 	}
 	rows, err := p.db.Query(ctx, selectSnapshotQuery, args)
 	if err != nil {
@@ -219,20 +399,471 @@ func (p *postgreSQL) GetSnapshot(ctx context.Context, id int) (model.Snapshot, e
 	if err != nil {
 		return model.Snapshot{}, err
 	}
+	if len(dbSnapshotParts) == 0 {
+		return model.Snapshot{}, nil
+	}
+
+	warningRows, err := p.db.Query(ctx, selectWarningsQuery, args)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+	defer warningRows.Close()
+
+	dbWarnings, err := pgx.CollectRows(warningRows, pgx.RowToStructByName[dbWarning])
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	configRows, err := p.db.Query(ctx, selectConfigsQuery, args)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+	defer configRows.Close()
+
+	dbConfigs, err := pgx.CollectRows(configRows, pgx.RowToStructByName[dbConfig])
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	return toSnapshotFromDB(dbSnapshotParts, dbWarnings, dbConfigs), nil
+}
+
+// GetDeviceHistory implements the [Repository] interface.
+// The queries run in a read-only transaction, so snapshots and device states are consistent.
+func (p *postgreSQL) GetDeviceHistory(ctx context.Context, hostname string, from, to time.Time) ([]model.Snapshot, error) {
+	p.logger.Sugar().Infof("Getting the history of %s from the database", hostname)
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly, IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		return nil, err
+	}
+
+	histories, err := getDevicesHistory(ctx, tx, hostname, from, to)
+	if err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			return nil, rollbackErr
+		}
+		return nil, err
+	}
+
+	history, ok := histories[hostname]
+	if !ok {
+		history = []model.Snapshot{}
+	}
+
+	return history, tx.Commit(ctx)
+}
+
+// GetDevicesHistory implements the [Repository] interface.
+// The queries run in a read-only transaction, so snapshots and device states are consistent.
+func (p *postgreSQL) GetDevicesHistory(ctx context.Context, from, to time.Time) (map[string][]model.Snapshot, error) {
+	p.logger.Info("Getting the history of all devices from the database")
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly, IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		return nil, err
+	}
+
+	histories, err := getDevicesHistory(ctx, tx, "", from, to)
+	if err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			return nil, rollbackErr
+		}
+		return nil, err
+	}
 
-	return toSnapshotFromDB(dbSnapshotParts), nil
+	return histories, tx.Commit(ctx)
+}
+
+// getDevicesHistory selects snapshots with devices whose current hostname is [hostname], or with any device if it is empty,
+// and the device states they refer to within the transaction. Histories are returned by current hostname.
+func getDevicesHistory(ctx context.Context, tx pgx.Tx, hostname string, from, to time.Time) (map[string][]model.Snapshot, error) {
+	args := pgx.NamedArgs{
+		"hostname": hostname,
+		"from":     from,
+		"to":       to,
+	}
+
+	rows, err := tx.Query(ctx, selectDeviceSnapshotsQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	dbSnapshots, err := pgx.CollectRows(rows, pgx.RowToStructByName[dbDeviceSnapshot])
+	if err != nil {
+		return nil, err
+	}
+	if len(dbSnapshots) == 0 {
+		return map[string][]model.Snapshot{}, nil
+	}
+
+	rows, err = tx.Query(ctx, selectDeviceStatesQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	dbParts, err := pgx.CollectRows(rows, pgx.RowToStructByName[dbDeviceStatePart])
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(ctx, selectDeviceStateWarningsQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	dbWarnings, err := pgx.CollectRows(rows, pgx.RowToStructByName[dbDeviceStateWarning])
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.Query(ctx, selectDeviceStateConfigsQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	dbConfigs, err := pgx.CollectRows(rows, pgx.RowToStructByName[dbDeviceStateConfig])
+	if err != nil {
+		return nil, err
+	}
+
+	return toDeviceHistoryFromDB(dbSnapshots, dbParts, dbWarnings, dbConfigs), nil
+}
+
+// ListDevices implements the [Repository] interface.
+func (p *postgreSQL) ListDevices(ctx context.Context) ([]repository.StoredDevice, error) {
+	p.logger.Info("Listing devices in the database")
+
+	rows, err := p.db.Query(ctx, selectDevicesQuery)
+	if err != nil {
+		return nil, err
+	}
+	dbDevices, err := pgx.CollectRows(rows, pgx.RowToStructByName[dbDevice])
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]repository.StoredDevice, len(dbDevices))
+	for i, d := range dbDevices {
+		devices[i] = toStoredDeviceFromDB(d)
+	}
+
+	return devices, nil
+}
+
+// ListAddresses implements the [Repository] interface.
+func (p *postgreSQL) ListAddresses(ctx context.Context) ([]repository.StoredAddress, error) {
+	p.logger.Info("Listing addresses in the database")
+
+	rows, err := p.db.Query(ctx, selectAddressesQuery)
+	if err != nil {
+		return nil, err
+	}
+	dbAddresses, err := pgx.CollectRows(rows, pgx.RowToStructByName[dbAddress])
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]repository.StoredAddress, len(dbAddresses))
+	for i, a := range dbAddresses {
+		addresses[i] = toStoredAddressFromDB(a)
+	}
+
+	return addresses, nil
+}
+
+// RenameDevice implements the [Repository] interface.
+func (p *postgreSQL) RenameDevice(ctx context.Context, id int, hostname string) error {
+	p.logger.Sugar().Infof("Renaming device %d to %s in the database", id, hostname)
+
+	args := pgx.NamedArgs{
+		"id":       id,
+		"hostname": hostname,
+	}
+	tag, err := p.db.Exec(ctx, renameDeviceQuery, args)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+// MergeDevices implements the [Repository] interface.
+func (p *postgreSQL) MergeDevices(ctx context.Context, from, into int) error {
+	p.logger.Sugar().Infof("Merging device %d into %d in the database", from, into)
+
+	if from == into {
+		return repository.ErrSameDevice
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	if err := mergeDevices(ctx, tx, from, into); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// mergeDevices merges device [from] into device [into] within the transaction.
+// The devices lock is taken, so no snapshot is stored with either device meanwhile.
+func mergeDevices(ctx context.Context, tx pgx.Tx, from, into int) error {
+	lockArgs := pgx.NamedArgs{
+		"key": devicesLockKey,
+	}
+	if _, err := tx.Exec(ctx, lockDevicesQuery, lockArgs); err != nil {
+		return err
+	}
+
+	args := pgx.NamedArgs{
+		"from": from,
+		"into": into,
+	}
+
+	var found int
+	if err := tx.QueryRow(ctx, countMergedDevicesQuery, args).Scan(&found); err != nil {
+		return err
+	}
+	if found != 2 {
+		return repository.ErrDeviceNotFound
+	}
+
+	var overlapping int
+	if err := tx.QueryRow(ctx, countOverlappingSnapshotsQuery, args).Scan(&overlapping); err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return repository.ErrDevicesOverlap
+	}
+
+	for _, query := range []string{
+		remapDuplicateDeviceStatesQuery,
+		deleteDuplicateDeviceStatesQuery,
+		remapDuplicateInterfacesQuery,
+		deleteDuplicateInterfacesQuery,
+		moveInterfacesQuery,
+		moveDeviceStatesQuery,
+		moveSnapshotDevicesQuery,
+		updateMergedDeviceQuery,
+		deleteMergedDeviceQuery,
+	} {
+		if _, err := tx.Exec(ctx, query, args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StoreFindings implements the [Repository] interface.
+func (p *postgreSQL) StoreFindings(ctx context.Context, timestamp time.Time, analysis string, findings []repository.Finding) error {
+	p.logger.Sugar().Infof("Storing %d findings of %s in the database", len(findings), analysis)
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	if err := storeFindings(ctx, tx, timestamp, analysis, findings); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// storeFindings replaces the findings of the analysis of the snapshot within the transaction.
+func storeFindings(ctx context.Context, tx pgx.Tx, timestamp time.Time, analysis string, findings []repository.Finding) error {
+	timestampArgs := pgx.NamedArgs{
+		"timestamp": timestamp,
+	}
+	var snapshotID int
+	err := tx.QueryRow(ctx, selectSnapshotIDByTimestampQuery, timestampArgs).Scan(&snapshotID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrSnapshotNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// Findings are inserted with a single query as arrays of their fields.
+	var (
+		hostnames      = make([]string, len(findings))
+		interfaceNames = make([]string, len(findings))
+		checkNames     = make([]string, len(findings))
+		severities     = make([]string, len(findings))
+		messages       = make([]string, len(findings))
+	)
+	for i, finding := range findings {
+		hostnames[i] = finding.Hostname
+		interfaceNames[i] = finding.Interface
+		checkNames[i] = finding.Check
+		severities[i] = finding.Severity
+		messages[i] = finding.Message
+	}
+
+	args := pgx.NamedArgs{
+		"snapshot_id":     snapshotID,
+		"analysis":        analysis,
+		"hostnames":       hostnames,
+		"interface_names": interfaceNames,
+		"check_names":     checkNames,
+		"severities":      severities,
+		"messages":        messages,
+	}
+	if _, err := tx.Exec(ctx, deleteFindingsQuery, args); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, insertFindingsQuery, args); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetFindings implements the [Repository] interface.
+func (p *postgreSQL) GetFindings(ctx context.Context, filter repository.FindingFilter) ([]repository.Finding, error) {
+	p.logger.Info("Getting findings from the database")
+
+	args := pgx.NamedArgs{
+		"snapshot_id": filter.SnapshotID,
+		"analysis":    filter.Analysis,
+		"hostname":    filter.Hostname,
+		"from":        pgtype.Timestamptz{Time: filter.From, Valid: !filter.From.IsZero()},
+		"to":          pgtype.Timestamptz{Time: filter.To, Valid: !filter.To.IsZero()},
+	}
+	rows, err := p.db.Query(ctx, selectFindingsQuery, args)
+	if err != nil {
+		return nil, err
+	}
+	dbFindings, err := pgx.CollectRows(rows, pgx.RowToStructByName[dbFinding])
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]repository.Finding, len(dbFindings))
+	for i, f := range dbFindings {
+		findings[i] = toFindingFromDB(f)
+	}
+
+	return findings, nil
 }
 
 // DeleteSnapshot implements the [Repository] interface.
+// Device states no longer referenced by any snapshot are deleted too.
 func (p *postgreSQL) DeleteSnapshot(ctx context.Context, id int) error {
 	p.logger.Info("Deleting a snapshot from the database")
 
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+
+	if err := deleteSnapshot(ctx, tx, id); err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// deleteSnapshot deletes the snapshot and device states used only by it within the transaction.
+func deleteSnapshot(ctx context.Context, tx pgx.Tx, id int) error {
 	args := pgx.NamedArgs{
-		"id": int(id),
+		"id": id,
+	}
+	rows, err := tx.Query(ctx, selectSnapshotDeviceStatesQuery, args)
+	if err != nil {
+		return err
+	}
+	deviceStateIDs, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return err
 	}
-	if _, err := p.db.Exec(ctx, deleteSnapshotQuery, args); err != nil {
+
+	if _, err := tx.Exec(ctx, deleteSnapshotQuery, args); err != nil {
+		return err
+	}
+
+	deviceStatesArgs := pgx.NamedArgs{
+		"ids": deviceStateIDs,
+	}
+	if _, err := tx.Exec(ctx, deleteUnusedDeviceStatesQuery, deviceStatesArgs); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// PruneSnapshots implements the [Repository] interface.
+// A dry run deletes rows within a transaction that is rolled back.
+func (p *postgreSQL) PruneSnapshots(ctx context.Context, ids []int, dryRun bool) (repository.PruneResult, error) {
+	p.logger.Sugar().Infof("Pruning %d snapshots from the database", len(ids))
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repository.PruneResult{}, err
+	}
+
+	result, err := pruneSnapshots(ctx, tx, ids)
+	if err != nil {
+		if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+			return repository.PruneResult{}, rollbackErr
+		}
+		return repository.PruneResult{}, err
+	}
+
+	if dryRun {
+		return result, tx.Rollback(ctx)
+	}
+
+	return result, tx.Commit(ctx)
+}
+
+// pruneSnapshots deletes snapshots and orphaned rows within the transaction.
+// Interfaces are deleted before devices, so they are not deleted by cascade and are counted.
+// The devices lock is taken, so a snapshot stored meanwhile does not reuse the orphaned rows being deleted.
+func pruneSnapshots(ctx context.Context, tx pgx.Tx, ids []int) (repository.PruneResult, error) {
+	var result repository.PruneResult
+
+	lockArgs := pgx.NamedArgs{
+		"key": devicesLockKey,
+	}
+	if _, err := tx.Exec(ctx, lockDevicesQuery, lockArgs); err != nil {
+		return repository.PruneResult{}, err
+	}
+
+	queries := []struct {
+		query string
+		count *int
+	}{
+		{deleteSnapshotsQuery, &result.Snapshots},
+		{deleteOrphanedDeviceStatesQuery, &result.DeviceStates},
+		{deleteOrphanedInterfacesQuery, &result.Interfaces},
+		{deleteOrphanedDevicesQuery, &result.Devices},
+		{deleteOrphanedVendorsQuery, &result.Vendors},
+		{deleteOrphanedOperatingSystemsQuery, &result.OperatingSystems},
+	}
+
+	args := pgx.NamedArgs{
+		"ids": ids,
+	}
+	for _, q := range queries {
+		tag, err := tx.Exec(ctx, q.query, args)
+		if err != nil {
+			return repository.PruneResult{}, err
+		}
+		*q.count = int(tag.RowsAffected())
+	}
+
+	return result, nil
+}
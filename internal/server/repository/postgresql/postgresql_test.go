@@ -0,0 +1,190 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/migrations"
+	"github.com/sudeeya/net-monitor/internal/server/repository/repositorytest"
+)
+
+// dsnEnv names the variable with the DSN of a PostgreSQL database used by tests.
+// Tests needing the database are skipped if it is not set.
+// Every test works in its own schema, which is dropped when the test ends.
+const dsnEnv = "NET_MONITOR_TEST_POSTGRES_DSN"
+
+// schemaCount makes schema names unique within a test run.
+var schemaCount atomic.Int64
+
+// testDSN returns the DSN of the test database or skips the test if it is not set.
+func testDSN(tb testing.TB) string {
+	tb.Helper()
+
+	dsn := os.Getenv(dsnEnv)
+	if dsn == "" {
+		tb.Skipf("%s is not set", dsnEnv)
+	}
+
+	return dsn
+}
+
+// newTestPostgreSQL returns a repository working in a new schema of the test database.
+// The schema is not migrated.
+func newTestPostgreSQL(tb testing.TB, bulkThreshold int, identity repository.Identity) *postgreSQL {
+	tb.Helper()
+
+	dsn := testDSN(tb)
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	schema := fmt.Sprintf("net_monitor_test_%d_%d", time.Now().UnixNano(), schemaCount.Add(1))
+	if _, err := conn.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		conn.Close(ctx)
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if _, err := conn.Exec(ctx, "DROP SCHEMA "+schema+" CASCADE"); err != nil {
+			tb.Error(err)
+		}
+		conn.Close(ctx)
+	})
+
+	p, err := NewPostgreSQL(zap.NewNop(), withSearchPath(dsn, schema), bulkThreshold, identity)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if err := p.Close(); err != nil {
+			tb.Error(err)
+		}
+	})
+
+	return p
+}
+
+// withSearchPath adds the search_path run-time parameter to a URL or keyword/value DSN.
+func withSearchPath(dsn, schema string) string {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " search_path=" + schema
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+
+	return dsn + separator + "search_path=" + url.QueryEscape(schema)
+}
+
+// bulkThresholds selects the write path: 0 stores snapshots row by row and 1 stores every snapshot through staging tables.
+var bulkThresholds = []struct {
+	name      string
+	threshold int
+}{
+	{"row", 0},
+	{"bulk", 1},
+}
+
+func TestRepository(t *testing.T) {
+	testDSN(t)
+
+	for _, path := range bulkThresholds {
+		t.Run(path.name, func(t *testing.T) {
+			repositorytest.Run(t, func(t *testing.T, identity repository.Identity) repository.Repository {
+				p := newTestPostgreSQL(t, path.threshold, identity)
+				if err := p.Migrator().Up(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+
+				return p
+			})
+		})
+	}
+}
+
+func BenchmarkStoreSnapshot(b *testing.B) {
+	testDSN(b)
+
+	for _, path := range bulkThresholds {
+		for _, devices := range []int{100, 1000} {
+			b.Run(fmt.Sprintf("%s/devices=%d", path.name, devices), func(b *testing.B) {
+				p := newTestPostgreSQL(b, path.threshold, repository.DefaultIdentity())
+				if err := p.Migrator().Up(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+
+				if err := repositorytest.BenchmarkStoreSnapshot(b, p, devices, 48, 0.05); err != nil {
+					b.Fatal(err)
+				}
+			})
+		}
+	}
+}
+
+func TestMigrationFiles(t *testing.T) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := migrations.Load(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) == 0 {
+		t.Fatal("no migrations are embedded")
+	}
+
+	// Embedded migrations are numbered without gaps, so a missing file is noticed.
+	for i, m := range loaded {
+		if m.Version != i+1 {
+			t.Fatalf("migration %d_%s, want version %d", m.Version, m.Name, i+1)
+		}
+	}
+}
+
+func TestMigrationsRollBack(t *testing.T) {
+	p := newTestPostgreSQL(t, 0, repository.DefaultIdentity())
+
+	ctx := context.Background()
+	if err := p.Migrator().Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := p.Migrator().Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Migrator().Down(ctx, len(statuses)); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err = p.Migrator().Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.Applied {
+			t.Errorf("migration %d is still applied", status.Version)
+		}
+	}
+
+	// The schema is recreated after rolling back every migration.
+	if err := p.Migrator().Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
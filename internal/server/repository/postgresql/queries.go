@@ -1,67 +1,37 @@
 package postgresql
 
-// SQL queries to create tables needed to store snapshots.
+// SQL queries to track applied schema migrations.
+// Tables storing snapshots are created by migrations in the migrations directory.
 const (
-	createTableSnapshotsQuery = `
-CREATE TABLE IF NOT EXISTS snapshots (
-	id SERIAL PRIMARY KEY,
-	timestamp TIMESTAMPTZ UNIQUE NOT NULL
+	createTableSchemaMigrationsQuery = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 `
 
-	createTableVendorsQuery = `
-CREATE TABLE IF NOT EXISTS vendors (
-	id SERIAL PRIMARY KEY,
-	name TEXT UNIQUE NOT NULL
-);
+	selectSchemaMigrationsQuery = `
+SELECT version, applied_at
+FROM schema_migrations;
 `
 
-	createTableOperatingSystemsQuery = `
-CREATE TABLE IF NOT EXISTS operating_systems (
-	id SERIAL PRIMARY KEY,
-	name TEXT NOT NULL,
-	version TEXT,
-	UNIQUE (name, version)
-);
+	insertSchemaMigrationQuery = `
+INSERT INTO schema_migrations (version, name)
+VALUES (@version, @name);
 `
 
-	createTableDevicesQuery = `
-CREATE TABLE IF NOT EXISTS devices (
-	id SERIAL PRIMARY KEY,
-	vendor_id INT REFERENCES vendors(id) ON DELETE RESTRICT,
-	operating_system_id INT REFERENCES operating_systems(id) ON DELETE RESTRICT,
-	hostname TEXT UNIQUE NOT NULL,
-	serial_number TEXT
-);
+	deleteSchemaMigrationQuery = `
+DELETE FROM schema_migrations
+WHERE version = @version;
 `
 
-	createTableDeviceStatesQuery = `
-CREATE TABLE IF NOT EXISTS device_states (
-	id SERIAL PRIMARY KEY,
-	snapshot_id INT REFERENCES snapshots(id) ON DELETE CASCADE,
-	device_id INT REFERENCES devices(id) ON DELETE RESTRICT,
-	is_snapshot_successful BOOLEAN NOT NULL
-);
+	lockMigrationsQuery = `
+SELECT pg_advisory_lock(@key);
 `
 
-	createTableInterfacesQuery = `
-CREATE TABLE IF NOT EXISTS interfaces (
-	id SERIAL PRIMARY KEY,
-	device_id INT REFERENCES devices(id) ON DELETE CASCADE,
-	name TEXT NOT NULL,
-	UNIQUE (device_id, name)
-);
-`
-
-	createTableInterfaceStatesQuery = `
-CREATE TABLE IF NOT EXISTS interface_states (
-	id SERIAL PRIMARY KEY,
-	interface_id INT REFERENCES interfaces(id) ON DELETE CASCADE,
-	device_state_id INT REFERENCES device_states(id) ON DELETE CASCADE,
-	is_up BOOLEAN NOT NULL,
-	ip INET,
-	mtu INT
-);
+	unlockMigrationsQuery = `
+SELECT pg_advisory_unlock(@key);
 `
 )
 
@@ -101,27 +71,63 @@ UNION
 SELECT id
 FROM operating_systems 
 WHERE name = @os AND version = @version;
+`
+
+	lockDevicesQuery = `
+SELECT pg_advisory_xact_lock(@key);
+`
+
+	selectDeviceIdentitiesQuery = `
+SELECT id, hostname, serial_number
+FROM devices;
 `
 
 	insertDeviceQuery = `
-WITH insert_device AS (
-	INSERT INTO devices (vendor_id, operating_system_id, hostname, serial_number)
-	VALUES (@vendor_id, @operating_system_id, @hostname, @serial_number)
-	ON CONFLICT (hostname) DO NOTHING
-	RETURNING id
-)
-SELECT id 
-FROM insert_device
-UNION
-SELECT id
-FROM devices 
-WHERE hostname = @hostname;
+INSERT INTO devices (vendor_id, operating_system_id, hostname, serial_number)
+VALUES (@vendor_id, @operating_system_id, @hostname, @serial_number)
+RETURNING id;
+`
+
+	updateDeviceQuery = `
+UPDATE devices
+SET
+	vendor_id = @vendor_id,
+	operating_system_id = @operating_system_id,
+	hostname = @hostname,
+	serial_number = @serial_number
+WHERE
+	id = @id
+	AND (vendor_id, operating_system_id, hostname, serial_number)
+		IS DISTINCT FROM (@vendor_id::INT, @operating_system_id::INT, @hostname::TEXT, @serial_number::TEXT);
 `
 
 	insertDeviceStateQuery = `
-INSERT INTO device_states (snapshot_id, device_id, is_snapshot_successful)
-VALUES (@snapshot_id, @device_id, @is_snapshot_successful)
+INSERT INTO device_states (
+	device_id, vendor_id, operating_system_id, hostname, serial_number,
+	is_snapshot_successful, status, state_hash, running_config
+)
+VALUES (
+	@device_id, @vendor_id, @operating_system_id, @hostname, @serial_number,
+	@is_snapshot_successful, @status, @state_hash, @running_config
+)
+ON CONFLICT (device_id, state_hash) DO NOTHING
 RETURNING id;
+`
+
+	selectDeviceStateQuery = `
+SELECT id
+FROM device_states
+WHERE device_id = @device_id AND state_hash = @state_hash;
+`
+
+	insertSnapshotDeviceQuery = `
+INSERT INTO snapshot_devices (snapshot_id, device_id, device_state_id)
+VALUES (@snapshot_id, @device_id, @device_state_id);
+`
+
+	insertDeviceWarningQuery = `
+INSERT INTO device_warnings (device_state_id, command, field, message)
+VALUES (@device_state_id, @command, @field, @message);
 `
 
 	insertInterfaceQuery = `
@@ -140,22 +146,56 @@ WHERE device_id = @device_id AND name = @name;
 `
 
 	insertInterfaceStateQuery = `
-INSERT INTO interface_states (interface_id, device_state_id, is_up, ip, mtu)
-VALUES (@interface_id, @device_state_id, @is_up, @ip, @mtu);
+INSERT INTO interface_states (interface_id, device_state_id, is_up, ip, mtu, description, last_change, neighbor_hostname, neighbor_interface)
+VALUES (@interface_id, @device_state_id, @is_up, @ip, @mtu, @description, @last_change, @neighbor_hostname, @neighbor_interface);
 `
 )
 
-// SQL query to get snapshot ids and timestamps.
+// SQL queries to get snapshot ids and timestamps.
 const (
 	selectTimestampsQuery = `
 SELECT id, timestamp
 FROM snapshots
 ORDER BY timestamp DESC
 LIMIT @limit;
+`
+
+	selectTimestampsBeforeQuery = `
+SELECT id, timestamp
+FROM snapshots
+WHERE timestamp < @before
+ORDER BY timestamp DESC
+LIMIT @limit;
+`
+
+	selectTimestampsBetweenQuery = `
+SELECT id, timestamp
+FROM snapshots
+WHERE timestamp BETWEEN @from AND @to
+ORDER BY timestamp DESC;
+`
+
+	selectClosestTimestampsQuery = `
+(
+	SELECT id, timestamp
+	FROM snapshots
+	WHERE timestamp <= @at
+	ORDER BY timestamp DESC
+	LIMIT 1
+)
+UNION ALL
+(
+	SELECT id, timestamp
+	FROM snapshots
+	WHERE timestamp > @at
+	ORDER BY timestamp ASC
+	LIMIT 1
+);
 `
 )
 
 // SQL query to get a snapshot.
+// Devices have the attributes of their states. Empty filter arguments match any device.
 const (
 	selectSnapshotQuery = `
 SELECT
@@ -164,32 +204,601 @@ SELECT
 	v.name AS vendor_name,
 	o.name AS os_name,
 	o.version AS os_version,
-	d.id AS device_id,
-	d.hostname,
-	d.serial_number,
+	s_d.device_id,
+	d_s.hostname,
+	d_s.serial_number,
 	d_s.is_snapshot_successful,
+	d_s.status,
 	i.name AS interface_name,
 	i_s.is_up,
 	i_s.ip,
-	i_s.mtu
+	i_s.mtu,
+	i_s.description,
+	i_s.last_change,
+	i_s.neighbor_hostname,
+	i_s.neighbor_interface
 FROM
-	devices AS d
-	JOIN vendors AS v ON v.id = d.vendor_id
-	JOIN operating_systems AS o ON o.id = d.operating_system_id
-	JOIN device_states AS d_s ON d.id = d_s.device_id
-	JOIN snapshots AS s ON s.id = d_s.snapshot_id
-	LEFT JOIN interfaces AS i ON d.id = i.device_id
-	LEFT JOIN interface_states AS i_s ON i.id = i_s.interface_id AND d_s.id = i_s.device_state_id
+	snapshots AS s
+	JOIN snapshot_devices AS s_d ON s.id = s_d.snapshot_id
+	JOIN device_states AS d_s ON d_s.id = s_d.device_state_id
+	JOIN vendors AS v ON v.id = d_s.vendor_id
+	JOIN operating_systems AS o ON o.id = d_s.operating_system_id
+	LEFT JOIN interface_states AS i_s ON d_s.id = i_s.device_state_id
+	LEFT JOIN interfaces AS i ON i.id = i_s.interface_id
 WHERE
 	s.id = @id
-ORDER BY device_id ASC;
+	AND (@hostname::TEXT = '' OR d_s.hostname = @hostname)
+	AND (@vendor::TEXT = '' OR v.name = @vendor)
+	AND (@os_name::TEXT = '' OR o.name = @os_name)
+	AND (@os_version::TEXT = '' OR o.version = @os_version)
+ORDER BY s_d.device_id ASC, i_s.id ASC;
 `
 )
 
-// SQL query to delete a snapshot.
+// SQL query to get warnings of a snapshot.
 const (
+	selectWarningsQuery = `
+SELECT
+	s_d.device_id,
+	w.command,
+	w.field,
+	w.message
+FROM
+	device_warnings AS w
+	JOIN snapshot_devices AS s_d ON s_d.device_state_id = w.device_state_id
+WHERE
+	s_d.snapshot_id = @id
+ORDER BY w.id ASC;
+`
+)
+
+// SQL query to get running configurations of a snapshot.
+// Configurations are selected apart from interfaces, so that each of them is returned once.
+const (
+	selectConfigsQuery = `
+SELECT
+	s_d.device_id,
+	d_s.running_config
+FROM
+	snapshot_devices AS s_d
+	JOIN device_states AS d_s ON d_s.id = s_d.device_state_id
+WHERE
+	s_d.snapshot_id = @id
+	AND d_s.running_config != '';
+`
+)
+
+// SQL queries to get the history of devices with the current hostname, or of all devices if it is empty.
+// Snapshots refer to device states, which are selected once however many snapshots share them.
+const (
+	selectDeviceSnapshotsQuery = `
+SELECT
+	s.id,
+	s.timestamp,
+	s_d.device_state_id,
+	d.hostname
+FROM
+	snapshot_devices AS s_d
+	JOIN snapshots AS s ON s.id = s_d.snapshot_id
+	JOIN devices AS d ON d.id = s_d.device_id
+WHERE
+	(@hostname::TEXT = '' OR d.hostname = @hostname)
+	AND s.timestamp BETWEEN @from AND @to
+ORDER BY s.timestamp ASC;
+`
+
+	selectDeviceStatesQuery = `
+SELECT
+	d_s.id AS device_state_id,
+	v.name AS vendor_name,
+	o.name AS os_name,
+	o.version AS os_version,
+	d_s.hostname,
+	d_s.serial_number,
+	d_s.is_snapshot_successful,
+	d_s.status,
+	i.name AS interface_name,
+	i_s.is_up,
+	i_s.ip,
+	i_s.mtu,
+	i_s.description,
+	i_s.last_change,
+	i_s.neighbor_hostname,
+	i_s.neighbor_interface
+FROM
+	devices AS d
+	JOIN device_states AS d_s ON d_s.device_id = d.id
+	JOIN vendors AS v ON v.id = d_s.vendor_id
+	JOIN operating_systems AS o ON o.id = d_s.operating_system_id
+	LEFT JOIN interface_states AS i_s ON d_s.id = i_s.device_state_id
+	LEFT JOIN interfaces AS i ON i.id = i_s.interface_id
+WHERE
+	(@hostname::TEXT = '' OR d.hostname = @hostname)
+	AND d_s.id IN (
+		SELECT s_d.device_state_id
+		FROM
+			snapshot_devices AS s_d
+			JOIN snapshots AS s ON s.id = s_d.snapshot_id
+		WHERE
+			s_d.device_id = d.id
+			AND s.timestamp BETWEEN @from AND @to
+	)
+ORDER BY d_s.id ASC, i_s.id ASC;
+`
+
+	selectDeviceStateWarningsQuery = `
+SELECT
+	w.device_state_id,
+	w.command,
+	w.field,
+	w.message
+FROM
+	device_warnings AS w
+	JOIN device_states AS d_s ON d_s.id = w.device_state_id
+	JOIN devices AS d ON d.id = d_s.device_id
+WHERE
+	(@hostname::TEXT = '' OR d.hostname = @hostname)
+	AND d_s.id IN (
+		SELECT s_d.device_state_id
+		FROM
+			snapshot_devices AS s_d
+			JOIN snapshots AS s ON s.id = s_d.snapshot_id
+		WHERE
+			s_d.device_id = d.id
+			AND s.timestamp BETWEEN @from AND @to
+	)
+ORDER BY w.id ASC;
+`
+
+	selectDeviceStateConfigsQuery = `
+SELECT
+	d_s.id AS device_state_id,
+	d_s.running_config
+FROM
+	devices AS d
+	JOIN device_states AS d_s ON d_s.device_id = d.id
+WHERE
+	(@hostname::TEXT = '' OR d.hostname = @hostname)
+	AND d_s.running_config != ''
+	AND d_s.id IN (
+		SELECT s_d.device_state_id
+		FROM
+			snapshot_devices AS s_d
+			JOIN snapshots AS s ON s.id = s_d.snapshot_id
+		WHERE
+			s_d.device_id = d.id
+			AND s.timestamp BETWEEN @from AND @to
+	);
+`
+)
+
+// SQL queries to delete a snapshot and device states used only by it.
+const (
+	selectSnapshotDeviceStatesQuery = `
+SELECT device_state_id
+FROM snapshot_devices
+WHERE snapshot_id = @id;
+`
+
 	deleteSnapshotQuery = `
 DELETE FROM snapshots
 WHERE id = @id;
+`
+
+	deleteUnusedDeviceStatesQuery = `
+DELETE FROM device_states AS d_s
+WHERE
+	d_s.id = ANY(@ids)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM snapshot_devices AS s_d
+		WHERE s_d.device_state_id = d_s.id
+	);
+`
+)
+
+// SQL queries to prune snapshots and rows no longer referenced by any snapshot.
+const (
+	deleteSnapshotsQuery = `
+DELETE FROM snapshots
+WHERE id = ANY(@ids);
+`
+
+	deleteOrphanedDeviceStatesQuery = `
+DELETE FROM device_states AS d_s
+WHERE NOT EXISTS (
+	SELECT 1
+	FROM snapshot_devices AS s_d
+	WHERE s_d.device_state_id = d_s.id
+);
+`
+
+	deleteOrphanedInterfacesQuery = `
+DELETE FROM interfaces AS i
+WHERE NOT EXISTS (
+	SELECT 1
+	FROM interface_states AS i_s
+	WHERE i_s.interface_id = i.id
+);
+`
+
+	deleteOrphanedDevicesQuery = `
+DELETE FROM devices AS d
+WHERE NOT EXISTS (
+	SELECT 1
+	FROM snapshot_devices AS s_d
+	WHERE s_d.device_id = d.id
+);
+`
+
+	deleteOrphanedVendorsQuery = `
+DELETE FROM vendors AS v
+WHERE
+	NOT EXISTS (
+		SELECT 1
+		FROM devices AS d
+		WHERE d.vendor_id = v.id
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM device_states AS d_s
+		WHERE d_s.vendor_id = v.id
+	);
+`
+
+	deleteOrphanedOperatingSystemsQuery = `
+DELETE FROM operating_systems AS o
+WHERE
+	NOT EXISTS (
+		SELECT 1
+		FROM devices AS d
+		WHERE d.operating_system_id = o.id
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM device_states AS d_s
+		WHERE d_s.operating_system_id = o.id
+	);
+`
+)
+
+// SQL queries for inserting a large snapshot through staging tables.
+// Staging tables are dropped when the transaction ends.
+// Device ids are assigned before staging, and ids of new devices are taken from the sequence.
+// A staged device state is new if it was inserted by this snapshot;
+// warnings and interface states are inserted for new device states only.
+const (
+	createStagingTablesQuery = `
+CREATE TEMPORARY TABLE staged_devices (
+	position INT PRIMARY KEY,
+	hostname TEXT NOT NULL,
+	vendor TEXT,
+	os TEXT,
+	version TEXT,
+	serial_number TEXT,
+	is_snapshot_successful BOOLEAN NOT NULL,
+	status TEXT,
+	state_hash TEXT NOT NULL,
+	running_config TEXT NOT NULL,
+	device_id INT NOT NULL,
+	is_new_device BOOLEAN NOT NULL,
+	vendor_id INT,
+	operating_system_id INT,
+	device_state_id INT,
+	is_new BOOLEAN NOT NULL DEFAULT false
+) ON COMMIT DROP;
+
+CREATE TEMPORARY TABLE staged_warnings (
+	position INT NOT NULL,
+	warning_idx INT NOT NULL,
+	command TEXT NOT NULL,
+	field TEXT NOT NULL,
+	message TEXT NOT NULL
+) ON COMMIT DROP;
+
+CREATE TEMPORARY TABLE staged_interfaces (
+	position INT NOT NULL,
+	interface_idx INT NOT NULL,
+	name TEXT NOT NULL,
+	is_up BOOLEAN NOT NULL,
+	ip INET,
+	mtu INT,
+	description TEXT NOT NULL,
+	last_change TIMESTAMPTZ,
+	neighbor_hostname TEXT NOT NULL,
+	neighbor_interface TEXT NOT NULL
+) ON COMMIT DROP;
+`
+
+	allocateDeviceIDsQuery = `
+SELECT nextval(pg_get_serial_sequence('devices', 'id'))
+FROM generate_series(1, @count);
+`
+
+	upsertStagedVendorsQuery = `
+INSERT INTO vendors (name)
+SELECT DISTINCT vendor
+FROM staged_devices
+ON CONFLICT (name) DO NOTHING;
+`
+
+	upsertStagedOperatingSystemsQuery = `
+INSERT INTO operating_systems (name, version)
+SELECT DISTINCT os, version
+FROM staged_devices
+ON CONFLICT (name, version) DO NOTHING;
+`
+
+	updateStagedAttributeIDsQuery = `
+UPDATE staged_devices AS s_d
+SET vendor_id = v.id, operating_system_id = o.id
+FROM vendors AS v, operating_systems AS o
+WHERE v.name = s_d.vendor AND o.name = s_d.os AND o.version = s_d.version;
+`
+
+	insertStagedDevicesQuery = `
+INSERT INTO devices (id, vendor_id, operating_system_id, hostname, serial_number)
+SELECT device_id, vendor_id, operating_system_id, hostname, serial_number
+FROM staged_devices
+WHERE is_new_device
+ORDER BY position;
+`
+
+	updateStagedDevicesQuery = `
+UPDATE devices AS d
+SET
+	vendor_id = s_d.vendor_id,
+	operating_system_id = s_d.operating_system_id,
+	hostname = s_d.hostname,
+	serial_number = s_d.serial_number
+FROM staged_devices AS s_d
+WHERE
+	d.id = s_d.device_id
+	AND NOT s_d.is_new_device
+	AND (d.vendor_id, d.operating_system_id, d.hostname, d.serial_number)
+		IS DISTINCT FROM (s_d.vendor_id, s_d.operating_system_id, s_d.hostname, s_d.serial_number);
+`
+
+	insertStagedDeviceStatesQuery = `
+WITH inserted AS (
+	INSERT INTO device_states (
+		device_id, vendor_id, operating_system_id, hostname, serial_number,
+		is_snapshot_successful, status, state_hash, running_config
+	)
+	SELECT
+		device_id, vendor_id, operating_system_id, hostname, serial_number,
+		is_snapshot_successful, status, state_hash, running_config
+	FROM staged_devices
+	ORDER BY position
+	ON CONFLICT (device_id, state_hash) DO NOTHING
+	RETURNING id, device_id, state_hash
+)
+UPDATE staged_devices AS s_d
+SET device_state_id = i.id, is_new = true
+FROM inserted AS i
+WHERE i.device_id = s_d.device_id AND i.state_hash = s_d.state_hash;
+`
+
+	updateStagedDeviceStateIDsQuery = `
+UPDATE staged_devices AS s_d
+SET device_state_id = d_s.id
+FROM device_states AS d_s
+WHERE
+	s_d.device_state_id IS NULL
+	AND d_s.device_id = s_d.device_id
+	AND d_s.state_hash = s_d.state_hash;
+`
+
+	insertStagedWarningsQuery = `
+INSERT INTO device_warnings (device_state_id, command, field, message)
+SELECT s_d.device_state_id, s_w.command, s_w.field, s_w.message
+FROM
+	staged_warnings AS s_w
+	JOIN staged_devices AS s_d ON s_d.position = s_w.position
+WHERE s_d.is_new
+ORDER BY s_w.position, s_w.warning_idx;
+`
+
+	upsertStagedInterfacesQuery = `
+INSERT INTO interfaces (device_id, name)
+SELECT DISTINCT s_d.device_id, s_i.name
+FROM
+	staged_interfaces AS s_i
+	JOIN staged_devices AS s_d ON s_d.position = s_i.position
+WHERE s_d.is_new
+ON CONFLICT (device_id, name) DO NOTHING;
+`
+
+	insertStagedInterfaceStatesQuery = `
+INSERT INTO interface_states (interface_id, device_state_id, is_up, ip, mtu, description, last_change, neighbor_hostname, neighbor_interface)
+SELECT
+	i.id, s_d.device_state_id, s_i.is_up, s_i.ip, s_i.mtu, s_i.description, s_i.last_change,
+	s_i.neighbor_hostname, s_i.neighbor_interface
+FROM
+	staged_interfaces AS s_i
+	JOIN staged_devices AS s_d ON s_d.position = s_i.position
+	JOIN interfaces AS i ON i.device_id = s_d.device_id AND i.name = s_i.name
+WHERE s_d.is_new
+ORDER BY s_i.position, s_i.interface_idx;
+`
+
+	insertStagedSnapshotDevicesQuery = `
+INSERT INTO snapshot_devices (snapshot_id, device_id, device_state_id)
+SELECT @snapshot_id, device_id, device_state_id
+FROM staged_devices;
+`
+)
+
+// SQL queries to list and rename devices.
+const (
+	selectDevicesQuery = `
+SELECT
+	d.id,
+	d.hostname,
+	d.serial_number,
+	v.name AS vendor_name,
+	o.name AS os_name,
+	o.version AS os_version,
+	MIN(s.timestamp) AS first_seen,
+	MAX(s.timestamp) AS last_seen,
+	COUNT(s.id) AS snapshots
+FROM
+	devices AS d
+	LEFT JOIN vendors AS v ON v.id = d.vendor_id
+	LEFT JOIN operating_systems AS o ON o.id = d.operating_system_id
+	LEFT JOIN snapshot_devices AS s_d ON s_d.device_id = d.id
+	LEFT JOIN snapshots AS s ON s.id = s_d.snapshot_id
+GROUP BY d.id, v.name, o.name, o.version
+ORDER BY d.id ASC;
+`
+
+	selectAddressesQuery = `
+SELECT
+	d.id AS device_id,
+	d.hostname,
+	i.name AS interface_name,
+	i_s.ip,
+	MIN(s.timestamp) AS first_seen,
+	MAX(s.timestamp) AS last_seen
+FROM
+	snapshot_devices AS s_d
+	JOIN snapshots AS s ON s.id = s_d.snapshot_id
+	JOIN devices AS d ON d.id = s_d.device_id
+	JOIN interface_states AS i_s ON i_s.device_state_id = s_d.device_state_id
+	JOIN interfaces AS i ON i.id = i_s.interface_id
+WHERE i_s.ip IS NOT NULL
+GROUP BY d.id, i.name, i_s.ip
+ORDER BY d.id ASC, i.name ASC, i_s.ip ASC;
+`
+
+	renameDeviceQuery = `
+UPDATE devices
+SET hostname = @hostname
+WHERE id = @id;
+`
+)
+
+// SQL queries to merge device @from into device @into.
+// States and interfaces of @from equal to those of @into are replaced with them, the rest are moved.
+const (
+	countMergedDevicesQuery = `
+SELECT count(*)
+FROM devices
+WHERE id IN (@from, @into);
+`
+
+	countOverlappingSnapshotsQuery = `
+SELECT count(*)
+FROM
+	snapshot_devices AS f
+	JOIN snapshot_devices AS i ON i.snapshot_id = f.snapshot_id
+WHERE f.device_id = @from AND i.device_id = @into;
+`
+
+	remapDuplicateDeviceStatesQuery = `
+UPDATE snapshot_devices AS s_d
+SET device_state_id = i.id
+FROM
+	device_states AS f
+	JOIN device_states AS i ON i.state_hash = f.state_hash AND i.device_id = @into
+WHERE f.device_id = @from AND s_d.device_state_id = f.id;
+`
+
+	deleteDuplicateDeviceStatesQuery = `
+DELETE FROM device_states AS f
+USING device_states AS i
+WHERE f.device_id = @from AND i.device_id = @into AND i.state_hash = f.state_hash;
+`
+
+	remapDuplicateInterfacesQuery = `
+UPDATE interface_states AS i_s
+SET interface_id = i.id
+FROM
+	interfaces AS f
+	JOIN interfaces AS i ON i.name = f.name AND i.device_id = @into
+WHERE f.device_id = @from AND i_s.interface_id = f.id;
+`
+
+	deleteDuplicateInterfacesQuery = `
+DELETE FROM interfaces AS f
+USING interfaces AS i
+WHERE f.device_id = @from AND i.device_id = @into AND i.name = f.name;
+`
+
+	moveInterfacesQuery = `
+UPDATE interfaces
+SET device_id = @into
+WHERE device_id = @from;
+`
+
+	moveDeviceStatesQuery = `
+UPDATE device_states
+SET device_id = @into
+WHERE device_id = @from;
+`
+
+	moveSnapshotDevicesQuery = `
+UPDATE snapshot_devices
+SET device_id = @into
+WHERE device_id = @from;
+`
+
+	updateMergedDeviceQuery = `
+UPDATE devices AS d
+SET
+	vendor_id = latest.vendor_id,
+	operating_system_id = latest.operating_system_id,
+	hostname = latest.hostname,
+	serial_number = latest.serial_number
+FROM (
+	SELECT d_s.vendor_id, d_s.operating_system_id, d_s.hostname, d_s.serial_number
+	FROM
+		snapshot_devices AS s_d
+		JOIN snapshots AS s ON s.id = s_d.snapshot_id
+		JOIN device_states AS d_s ON d_s.id = s_d.device_state_id
+	WHERE s_d.device_id = @into
+	ORDER BY s.timestamp DESC
+	LIMIT 1
+) AS latest
+WHERE d.id = @into;
+`
+
+	deleteMergedDeviceQuery = `
+DELETE FROM devices
+WHERE id = @from;
+`
+)
+
+// SQL queries to store and read findings of analyses.
+const (
+	selectSnapshotIDByTimestampQuery = `
+SELECT id
+FROM snapshots
+WHERE timestamp = @timestamp;
+`
+
+	deleteFindingsQuery = `
+DELETE FROM findings
+WHERE snapshot_id = @snapshot_id AND analysis = @analysis;
+`
+
+	insertFindingsQuery = `
+INSERT INTO findings (snapshot_id, analysis, hostname, interface_name, check_name, severity, message)
+SELECT @snapshot_id, @analysis, f.hostname, f.interface_name, f.check_name, f.severity, f.message
+FROM unnest(
+	@hostnames::TEXT[], @interface_names::TEXT[], @check_names::TEXT[], @severities::TEXT[], @messages::TEXT[]
+) AS f (hostname, interface_name, check_name, severity, message);
+`
+
+	selectFindingsQuery = `
+SELECT f.snapshot_id, s.timestamp, f.analysis, f.hostname, f.interface_name, f.check_name, f.severity, f.message
+FROM findings AS f
+JOIN snapshots AS s ON s.id = f.snapshot_id
+WHERE (@snapshot_id::INT = 0 OR f.snapshot_id = @snapshot_id)
+	AND (@analysis::TEXT = '' OR f.analysis = @analysis)
+	AND (@hostname::TEXT = '' OR f.hostname = @hostname)
+	AND (@from::TIMESTAMPTZ IS NULL OR s.timestamp >= @from)
+	AND (@to::TIMESTAMPTZ IS NULL OR s.timestamp <= @to)
+ORDER BY s.timestamp ASC, f.analysis ASC, f.hostname ASC, f.interface_name ASC, f.check_name ASC, f.id ASC;
 `
 )
@@ -0,0 +1,51 @@
+package postgresql
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer starts spans of stored snapshots and their queries.
+var tracer = otel.Tracer("github.com/sudeeya/net-monitor/internal/server/repository/postgresql")
+
+var _ pgx.QueryTracer = queryTracer{}
+
+// queryTracer implements the [pgx.QueryTracer] interface by starting a span for every query of a traced operation.
+// Queries run outside of a span, such as those of HTTP pages, are not traced; nor are batches and copies.
+type queryTracer struct{}
+
+// querySpanKey is the context key of the span of a query.
+type querySpanKey struct{}
+
+// TraceQueryStart implements the [pgx.QueryTracer] interface.
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	ctx, span := tracer.Start(ctx, "postgresql.Query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBQueryText(data.SQL)),
+	)
+
+	return context.WithValue(ctx, querySpanKey{}, span)
+}
+
+// TraceQueryEnd implements the [pgx.QueryTracer] interface.
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span, ok := ctx.Value(querySpanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
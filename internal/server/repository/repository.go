@@ -3,10 +3,22 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/sudeeya/net-monitor/internal/pkg/model"
 )
 
+// Errors returned when managing devices.
+var (
+	ErrDeviceNotFound = errors.New("device not found")
+	ErrSameDevice     = errors.New("device cannot be merged into itself")
+	ErrDevicesOverlap = errors.New("devices are in the same snapshot")
+)
+
+// ErrSnapshotNotFound is returned when storing data for a snapshot that does not exist.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
 // Repository describes interaction with an object storing snapshots.
 type Repository interface {
 	// StoreSnapshot stores a snapshot into Repository.
@@ -21,6 +33,124 @@ type Repository interface {
 	// If n is greater than the number of snapshots in the repository, returns all timestamps.
 	GetNTimestamps(ctx context.Context, n int) ([]model.Snapshot, error)
 
+	// GetTimestampsBefore returns the last n snapshot ids and timestamps taken strictly before [before].
+	// Like GetNTimestamps, timestamps are sorted from newest to oldest.
+	GetTimestampsBefore(ctx context.Context, before time.Time, n int) ([]model.Snapshot, error)
+
+	// GetTimestampsBetween returns ids and timestamps of snapshots taken from [from] to [to] inclusive,
+	// sorted from newest to oldest.
+	GetTimestampsBetween(ctx context.Context, from, to time.Time) ([]model.Snapshot, error)
+
+	// GetClosestTimestamp returns the id and timestamp of the snapshot taken closest to [at].
+	// If two snapshots are equally close, the earlier one is returned.
+	// Returns an empty snapshot if there are no snapshots.
+	GetClosestTimestamp(ctx context.Context, at time.Time) (model.Snapshot, error)
+
+	// GetFilteredSnapshot returns a snapshot by its id with only the devices matching the filter.
+	// Like GetSnapshot, returns an empty snapshot if no device matches.
+	GetFilteredSnapshot(ctx context.Context, timestampID int, filter DeviceFilter) (model.Snapshot, error)
+
+	// GetDeviceHistory returns snapshots taken from [from] to [to] inclusive that contain a device
+	// whose current hostname is [hostname], each with this device only, sorted from oldest to newest.
+	// Devices keep the attributes they had in each snapshot.
+	// Snapshots in which the device did not change may share interface and warning slices.
+	GetDeviceHistory(ctx context.Context, hostname string, from, to time.Time) ([]model.Snapshot, error)
+
+	// GetDevicesHistory returns the histories of all devices in snapshots taken from [from] to [to] inclusive
+	// by their current hostnames, each as GetDeviceHistory returns it, reading them at once.
+	GetDevicesHistory(ctx context.Context, from, to time.Time) (map[string][]model.Snapshot, error)
+
+	// ListDevices returns all stored devices sorted by id.
+	ListDevices(ctx context.Context) ([]StoredDevice, error)
+
+	// ListAddresses returns every address that an interface had in stored snapshots,
+	// sorted by device id and interface name.
+	ListAddresses(ctx context.Context) ([]StoredAddress, error)
+
+	// RenameDevice sets the current hostname of the device, so that the next snapshot
+	// in which the device has this hostname is matched with it.
+	// Returns [ErrDeviceNotFound] if there is no such device.
+	RenameDevice(ctx context.Context, id int, hostname string) error
+
+	// MergeDevices moves all snapshots, states and interfaces of device [from] to device [into] and deletes [from].
+	// The current attributes of [into] become those of its latest snapshot.
+	// Returns [ErrDevicesOverlap] if a snapshot contains both devices.
+	MergeDevices(ctx context.Context, from, into int) error
+
+	// StoreFindings replaces the findings of the analysis of the snapshot taken at [timestamp].
+	// Findings are deleted together with their snapshot.
+	// Returns [ErrSnapshotNotFound] if there is no such snapshot.
+	StoreFindings(ctx context.Context, timestamp time.Time, analysis string, findings []Finding) error
+
+	// GetFindings returns findings matching the filter,
+	// sorted by snapshot timestamp, analysis, hostname, interface and check.
+	GetFindings(ctx context.Context, filter FindingFilter) ([]Finding, error)
+
 	// DeleteSnapshot deletes a snapshot from Repository by its id.
+	// Device states used by this snapshot only are deleted too.
 	DeleteSnapshot(ctx context.Context, timestampID int) error
+
+	// PruneSnapshots deletes snapshots by their ids together with device states, devices, interfaces,
+	// vendors and operating systems no longer referenced by any snapshot.
+	// If dryRun is set, nothing is deleted, but the result is the same as if it were.
+	PruneSnapshots(ctx context.Context, timestampIDs []int, dryRun bool) (PruneResult, error)
+}
+
+// DeviceFilter describes devices to return from a snapshot.
+// Empty fields match any device. Fields are matched against the device attributes in the snapshot.
+type DeviceFilter struct {
+	Hostname  string
+	Vendor    string
+	OSName    string
+	OSVersion string
+}
+
+// Match reports whether the device matches the filter.
+func (f DeviceFilter) Match(device model.Device) bool {
+	return (f.Hostname == "" || f.Hostname == device.Hostname) &&
+		(f.Vendor == "" || f.Vendor == device.Vendor) &&
+		(f.OSName == "" || f.OSName == device.OSName) &&
+		(f.OSVersion == "" || f.OSVersion == device.OSVersion)
+}
+
+// PruneResult describes the number of deleted rows of each kind.
+type PruneResult struct {
+	Snapshots        int
+	DeviceStates     int
+	Devices          int
+	Interfaces       int
+	Vendors          int
+	OperatingSystems int
+}
+
+// Closer is implemented by repositories holding a database connection or file.
+type Closer interface {
+	// Close releases the database. The repository must not be used afterwards.
+	Close() error
+}
+
+// Migratable is implemented by repositories whose schema is managed by versioned migrations.
+type Migratable interface {
+	// Migrator returns an object that migrates the repository schema.
+	Migrator() Migrator
+}
+
+// Migrator describes interaction with an object migrating a repository schema.
+type Migrator interface {
+	// Up applies all pending migrations in ascending order.
+	Up(ctx context.Context) error
+
+	// Down rolls back the last n applied migrations in descending order.
+	Down(ctx context.Context, n int) error
+
+	// Status returns all known migrations and whether they are applied.
+	Status(ctx context.Context) ([]MigrationStatus, error)
+}
+
+// MigrationStatus describes a migration and its state in the repository.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
 }
@@ -0,0 +1,1337 @@
+// Package repositorytest provides a conformance test suite for [Repository] implementations.
+//
+// Every implementation is expected to behave exactly like the PostgreSQL one.
+// An implementation is checked by calling [Run] from its tests:
+//
+//	func TestConformance(t *testing.T) {
+//		repositorytest.Run(t, func(t *testing.T, identity repository.Identity) repository.Repository {
+//			return newEmptyRepository(t, identity)
+//		})
+//	}
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+)
+
+// NewRepository returns an empty repository matching devices by the [identity] rules for a single test.
+type NewRepository func(t *testing.T, identity repository.Identity) repository.Repository
+
+// baseTime is the timestamp of the first snapshot used by tests.
+// Timestamps are truncated to microseconds, the precision of PostgreSQL.
+var baseTime = time.Date(2024, time.October, 1, 3, 0, 0, 0, time.UTC)
+
+// Run runs the conformance test suite against repositories returned by [newRepo].
+func Run(t *testing.T, newRepo NewRepository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo repository.Repository)
+	}{
+		{"StoreAndGetSnapshot", testStoreAndGetSnapshot},
+		{"GetNTimestamps", testGetNTimestamps},
+		{"GetTimestampsBefore", testGetTimestampsBefore},
+		{"GetTimestampsBetween", testGetTimestampsBetween},
+		{"GetClosestTimestamp", testGetClosestTimestamp},
+		{"GetFilteredSnapshot", testGetFilteredSnapshot},
+		{"GetDeviceHistory", testGetDeviceHistory},
+		{"GetDevicesHistory", testGetDevicesHistory},
+		{"DeleteSnapshot", testDeleteSnapshot},
+		{"InterfacesPerSnapshot", testInterfacesPerSnapshot},
+		{"GetMissingSnapshot", testGetMissingSnapshot},
+		{"DeleteMissingSnapshot", testDeleteMissingSnapshot},
+		{"DuplicateTimestamp", testDuplicateTimestamp},
+		{"DeviceAttributesPerSnapshot", testDeviceAttributesPerSnapshot},
+		{"StoredSnapshotIsIndependent", testStoredSnapshotIsIndependent},
+		{"PruneSnapshots", testPruneSnapshots},
+		{"SharedDeviceStates", testSharedDeviceStates},
+		{"RenamedDeviceBySerial", testRenamedDeviceBySerial},
+		{"ChassisSwap", testChassisSwap},
+		{"RenameDevice", testRenameDevice},
+		{"StoreAndGetFindings", testStoreAndGetFindings},
+		{"FindingsOfDeletedSnapshot", testFindingsOfDeletedSnapshot},
+		{"ListAddresses", testListAddresses},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepo(t, repository.DefaultIdentity()))
+		})
+	}
+
+	identityTests := []struct {
+		name     string
+		identity repository.Identity
+		test     func(t *testing.T, repo repository.Repository)
+	}{
+		{
+			"IgnoredSerials",
+			repository.Identity{Mode: repository.IdentitySerial, IgnoredSerials: []string{"Sim Serial No."}},
+			testIgnoredSerials,
+		},
+		{"HostnameIdentity", repository.Identity{Mode: repository.IdentityHostname}, testHostnameIdentity},
+		{"MergeDevices", repository.Identity{Mode: repository.IdentityHostname}, testMergeDevices},
+	}
+
+	for _, tt := range identityTests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepo(t, tt.identity))
+		})
+	}
+}
+
+// Snapshot returns a snapshot taken [n] minutes after the base time.
+// It contains a healthy device, a degraded device with warnings and an unreachable device.
+func Snapshot(n int) model.Snapshot {
+	return model.Snapshot{
+		Timestamp: baseTime.Add(time.Duration(n) * time.Minute),
+		Devices: []model.Device{
+			{
+				Hostname:             "srl1",
+				Vendor:               "Nokia",
+				OSName:               "SR Linux",
+				OSVersion:            "v24.10.1",
+				Serial:               "Sim Serial No.",
+				IsSnapshotSuccessful: true,
+				Status:               model.StatusSuccess,
+				Interfaces: []model.Interface{
+					{
+						Name:              "ethernet-1/1",
+						IsUp:              true,
+						IP:                netip.MustParsePrefix("10.0.0.1/31"),
+						MTU:               9214,
+						Description:       "to srl2 ethernet-1/1",
+						LastChange:        baseTime.Add(-time.Hour),
+						NeighborHostname:  "srl2",
+						NeighborInterface: "ethernet-1/1",
+					},
+					{Name: "ethernet-1/2", IsUp: false, MTU: 9214},
+					{Name: "mgmt0", IsUp: true, IP: netip.MustParsePrefix("172.20.20.2/24"), MTU: 1514},
+				},
+				RunningConfig: "set / system ntp server 10.0.0.1 admin-state enable\nset / system ssh-server mgmt admin-state enable\n",
+			},
+			{
+				Hostname:             "srl2",
+				Vendor:               "Nokia",
+				OSName:               "SR Linux",
+				OSVersion:            "v24.7.2",
+				Serial:               "Sim Serial No. 2",
+				IsSnapshotSuccessful: true,
+				Status:               model.StatusDegraded,
+				Warnings: []model.Warning{
+					{Command: "show interface detail", Field: "MTU (ethernet-1/1)", Message: "invalid syntax"},
+					{Command: "show version", Message: "timeout"},
+				},
+				Interfaces: []model.Interface{
+					{Name: "ethernet-1/1", IsUp: true, IP: netip.MustParsePrefix("10.0.0.0/31")},
+				},
+			},
+			{
+				Hostname:             "srl3",
+				Vendor:               "Nokia",
+				OSName:               "nokia_srlinux",
+				IsSnapshotSuccessful: false,
+				Status:               model.StatusFailure,
+			},
+		},
+	}
+}
+
+// Fleet returns a snapshot of [devices] healthy devices with [interfaces] interfaces each.
+func Fleet(devices, interfaces int) model.Snapshot {
+	snapshot := model.Snapshot{
+		Timestamp: baseTime,
+		Devices:   make([]model.Device, devices),
+	}
+
+	for deviceIdx := range snapshot.Devices {
+		ifaces := make([]model.Interface, interfaces)
+		for ifaceIdx := range ifaces {
+			ifaces[ifaceIdx] = model.Interface{
+				Name: fmt.Sprintf("ethernet-1/%d", ifaceIdx+1),
+				IsUp: ifaceIdx%4 != 0,
+				IP:   netip.PrefixFrom(netip.AddrFrom4([4]byte{10, byte(deviceIdx >> 8), byte(deviceIdx), byte(ifaceIdx * 2)}), 31),
+				MTU:  9214,
+			}
+		}
+
+		snapshot.Devices[deviceIdx] = model.Device{
+			Hostname:             fmt.Sprintf("leaf%04d", deviceIdx+1),
+			Vendor:               "Nokia",
+			OSName:               "SR Linux",
+			OSVersion:            "v24.10.1",
+			Serial:               fmt.Sprintf("SN%06d", deviceIdx+1),
+			IsSnapshotSuccessful: true,
+			Status:               model.StatusSuccess,
+			Interfaces:           ifaces,
+		}
+	}
+
+	return snapshot
+}
+
+// BenchmarkStoreSnapshot measures storing snapshots of a fleet of [devices] devices with [interfaces] interfaces each.
+// In every snapshot, the share [changed] of devices differs from the previous snapshot.
+// Snapshots are taken a minute apart after the latest snapshot in the repository,
+// so the benchmark can be run again against the same database.
+// Besides time per snapshot, it reports time per 1,000 devices.
+//
+// The benchmark stops at the first error and returns it instead of failing [b],
+// so it can be run by [testing.Benchmark] outside of tests.
+func BenchmarkStoreSnapshot(b *testing.B, repo repository.Repository, devices, interfaces int, changed float64) error {
+	ctx := context.Background()
+	fleet := Fleet(devices, interfaces)
+	changedDevices := int(changed * float64(devices))
+
+	start := baseTime
+	latest, err := repo.GetNTimestamps(ctx, 1)
+	if err != nil {
+		return fmt.Errorf("GetNTimestamps: %w", err)
+	}
+	if len(latest) > 0 && !latest[0].Timestamp.Before(start) {
+		start = latest[0].Timestamp.Truncate(time.Minute).Add(time.Minute)
+	}
+	// Minutes since the base time number the snapshots across runs.
+	first := int(start.Sub(baseTime) / time.Minute)
+
+	b.ResetTimer()
+	for i := range b.N {
+		b.StopTimer()
+		n := first + i
+
+		snapshot := model.Snapshot{
+			Timestamp: baseTime.Add(time.Duration(n) * time.Minute),
+			Devices:   append([]model.Device(nil), fleet.Devices...),
+		}
+
+		// A new MTU makes a state that has never been stored before.
+		for j := range changedDevices {
+			device := &snapshot.Devices[(n*changedDevices+j)%devices]
+			if len(device.Interfaces) == 0 {
+				continue
+			}
+			device.Interfaces = append([]model.Interface(nil), device.Interfaces...)
+			device.Interfaces[0].MTU = int64(1500 + n)
+		}
+		b.StartTimer()
+
+		if err := repo.StoreSnapshot(ctx, snapshot); err != nil {
+			b.StopTimer()
+			return fmt.Errorf("StoreSnapshot: %w", err)
+		}
+	}
+	b.StopTimer()
+
+	if devices > 0 {
+		perDevice := float64(b.Elapsed()) / float64(b.N) / float64(devices)
+		b.ReportMetric(perDevice*1000/float64(time.Millisecond), "ms/1000devices")
+	}
+
+	return nil
+}
+
+// Store stores the snapshot and returns its id.
+func Store(t testing.TB, repo repository.Repository, snapshot model.Snapshot) int {
+	t.Helper()
+
+	ctx := context.Background()
+	if err := repo.StoreSnapshot(ctx, snapshot); err != nil {
+		t.Fatalf("StoreSnapshot: %v", err)
+	}
+
+	timestamps, err := repo.GetNTimestamps(ctx, 1<<20)
+	if err != nil {
+		t.Fatalf("GetNTimestamps: %v", err)
+	}
+
+	for _, timestamp := range timestamps {
+		if timestamp.Timestamp.Equal(snapshot.Timestamp) {
+			return timestamp.ID
+		}
+	}
+
+	t.Fatalf("stored snapshot %s is not listed", snapshot.Timestamp)
+
+	return 0
+}
+
+// AssertSnapshotEqual fails the test if snapshots differ.
+// Device order, snapshot ids and nil versus empty slices are not significant.
+func AssertSnapshotEqual(t testing.TB, want, got model.Snapshot) {
+	t.Helper()
+
+	if !want.Timestamp.Equal(got.Timestamp) {
+		t.Errorf("timestamp: want %s, got %s", want.Timestamp, got.Timestamp)
+	}
+
+	wantDevices := normalizeDevices(want.Devices)
+	gotDevices := normalizeDevices(got.Devices)
+	if !reflect.DeepEqual(wantDevices, gotDevices) {
+		t.Errorf("devices:\nwant %+v\ngot  %+v", wantDevices, gotDevices)
+	}
+}
+
+// normalizeDevices returns a copy of devices sorted by hostname with nil slices replaced by empty ones
+// and last changes of interfaces in UTC.
+func normalizeDevices(devices []model.Device) []model.Device {
+	normalized := make([]model.Device, len(devices))
+	for deviceIdx, device := range devices {
+		ifaces := make([]model.Interface, len(device.Interfaces))
+		for ifaceIdx, iface := range device.Interfaces {
+			if !iface.LastChange.IsZero() {
+				iface.LastChange = iface.LastChange.UTC()
+			}
+			ifaces[ifaceIdx] = iface
+		}
+		device.Interfaces = ifaces
+		if device.Warnings == nil {
+			device.Warnings = []model.Warning{}
+		}
+		normalized[deviceIdx] = device
+	}
+
+	sort.Slice(normalized, func(i, j int) bool {
+		return normalized[i].Hostname < normalized[j].Hostname
+	})
+
+	return normalized
+}
+
+func testStoreAndGetSnapshot(t *testing.T, repo repository.Repository) {
+	want := Snapshot(0)
+	id := Store(t, repo, want)
+
+	got, err := repo.GetSnapshot(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+
+	if got.ID != id {
+		t.Errorf("id: want %d, got %d", id, got.ID)
+	}
+	AssertSnapshotEqual(t, want, got)
+}
+
+func testGetNTimestamps(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	// Snapshots are stored out of order to check sorting by timestamp.
+	for _, n := range []int{10, 0, 20} {
+		Store(t, repo, Snapshot(n))
+	}
+
+	timestamps, err := repo.GetNTimestamps(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetNTimestamps: %v", err)
+	}
+
+	if len(timestamps) != 2 {
+		t.Fatalf("want 2 timestamps, got %d", len(timestamps))
+	}
+	if !timestamps[0].Timestamp.Equal(Snapshot(20).Timestamp) || !timestamps[1].Timestamp.Equal(Snapshot(10).Timestamp) {
+		t.Errorf("want the latest timestamps in descending order, got %+v", timestamps)
+	}
+	for _, timestamp := range timestamps {
+		if len(timestamp.Devices) != 0 {
+			t.Errorf("timestamps must not contain devices, got %+v", timestamp)
+		}
+	}
+
+	timestamps, err = repo.GetNTimestamps(ctx, 100)
+	if err != nil {
+		t.Fatalf("GetNTimestamps: %v", err)
+	}
+	if len(timestamps) != 3 {
+		t.Errorf("want all 3 timestamps, got %d", len(timestamps))
+	}
+}
+
+// assertTimestamps fails the test if timestamps are not those of snapshots returned by [Snapshot] for [minutes].
+func assertTimestamps(t testing.TB, minutes []int, got []model.Snapshot) {
+	t.Helper()
+
+	want := make([]time.Time, len(minutes))
+	for i, n := range minutes {
+		want[i] = Snapshot(n).Timestamp
+	}
+
+	gotTimes := make([]time.Time, len(got))
+	for i, timestamp := range got {
+		gotTimes[i] = timestamp.Timestamp
+		if len(timestamp.Devices) != 0 {
+			t.Errorf("timestamps must not contain devices, got %+v", timestamp)
+		}
+	}
+
+	if len(want) != len(gotTimes) {
+		t.Fatalf("timestamps: want %v, got %v", want, gotTimes)
+	}
+	for i := range want {
+		if !want[i].Equal(gotTimes[i]) {
+			t.Fatalf("timestamps: want %v, got %v", want, gotTimes)
+		}
+	}
+}
+
+func testGetTimestampsBefore(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	for _, n := range []int{10, 0, 30, 20} {
+		Store(t, repo, Snapshot(n))
+	}
+
+	timestamps, err := repo.GetTimestampsBefore(ctx, Snapshot(30).Timestamp, 2)
+	if err != nil {
+		t.Fatalf("GetTimestampsBefore: %v", err)
+	}
+	assertTimestamps(t, []int{20, 10}, timestamps)
+
+	// Paging continues from the oldest returned timestamp.
+	timestamps, err = repo.GetTimestampsBefore(ctx, timestamps[1].Timestamp, 2)
+	if err != nil {
+		t.Fatalf("GetTimestampsBefore: %v", err)
+	}
+	assertTimestamps(t, []int{0}, timestamps)
+
+	timestamps, err = repo.GetTimestampsBefore(ctx, Snapshot(0).Timestamp, 2)
+	if err != nil {
+		t.Fatalf("GetTimestampsBefore: %v", err)
+	}
+	assertTimestamps(t, []int{}, timestamps)
+}
+
+func testGetTimestampsBetween(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	for _, n := range []int{10, 0, 30, 20} {
+		Store(t, repo, Snapshot(n))
+	}
+
+	timestamps, err := repo.GetTimestampsBetween(ctx, Snapshot(10).Timestamp, Snapshot(25).Timestamp)
+	if err != nil {
+		t.Fatalf("GetTimestampsBetween: %v", err)
+	}
+	assertTimestamps(t, []int{20, 10}, timestamps)
+
+	timestamps, err = repo.GetTimestampsBetween(ctx, Snapshot(11).Timestamp, Snapshot(19).Timestamp)
+	if err != nil {
+		t.Fatalf("GetTimestampsBetween: %v", err)
+	}
+	assertTimestamps(t, []int{}, timestamps)
+}
+
+func testGetClosestTimestamp(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	got, err := repo.GetClosestTimestamp(ctx, baseTime)
+	if err != nil {
+		t.Fatalf("GetClosestTimestamp: %v", err)
+	}
+	if got.ID != 0 {
+		t.Errorf("want no snapshot in an empty repository, got %+v", got)
+	}
+
+	for _, n := range []int{0, 10, 20} {
+		Store(t, repo, Snapshot(n))
+	}
+
+	tests := []struct {
+		at   time.Time
+		want int
+	}{
+		{baseTime.Add(-time.Hour), 0},
+		{baseTime.Add(3 * time.Minute), 0},
+		{baseTime.Add(7 * time.Minute), 10},
+		{baseTime.Add(10 * time.Minute), 10},
+		// Equally close snapshots resolve to the earlier one.
+		{baseTime.Add(15 * time.Minute), 10},
+		{baseTime.Add(time.Hour), 20},
+	}
+	for _, tt := range tests {
+		got, err := repo.GetClosestTimestamp(ctx, tt.at)
+		if err != nil {
+			t.Fatalf("GetClosestTimestamp: %v", err)
+		}
+		if got.ID == 0 || !got.Timestamp.Equal(Snapshot(tt.want).Timestamp) {
+			t.Errorf("closest to %s: want %s, got %+v", tt.at, Snapshot(tt.want).Timestamp, got)
+		}
+	}
+}
+
+func testGetFilteredSnapshot(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	id := Store(t, repo, Snapshot(0))
+	devices := Snapshot(0).Devices
+
+	tests := []struct {
+		name   string
+		filter repository.DeviceFilter
+		want   []model.Device
+	}{
+		{"Empty", repository.DeviceFilter{}, devices},
+		{"Hostname", repository.DeviceFilter{Hostname: "srl2"}, devices[1:2]},
+		{"Vendor", repository.DeviceFilter{Vendor: "Nokia"}, devices},
+		{"OSName", repository.DeviceFilter{OSName: "SR Linux"}, devices[:2]},
+		{"OSVersion", repository.DeviceFilter{OSName: "SR Linux", OSVersion: "v24.10.1"}, devices[:1]},
+		{"Combined", repository.DeviceFilter{Hostname: "srl3", OSName: "SR Linux"}, nil},
+		{"Unknown", repository.DeviceFilter{Vendor: "Arista"}, nil},
+	}
+	for _, tt := range tests {
+		got, err := repo.GetFilteredSnapshot(ctx, id, tt.filter)
+		if err != nil {
+			t.Fatalf("GetFilteredSnapshot: %v", err)
+		}
+
+		if len(tt.want) == 0 {
+			if got.ID != 0 || len(got.Devices) != 0 {
+				t.Errorf("%s: want an empty snapshot, got %+v", tt.name, got)
+			}
+			continue
+		}
+
+		if got.ID != id {
+			t.Errorf("%s: id: want %d, got %d", tt.name, id, got.ID)
+		}
+		AssertSnapshotEqual(t, model.Snapshot{Timestamp: Snapshot(0).Timestamp, Devices: tt.want}, got)
+	}
+}
+
+func testGetDeviceHistory(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	// The interface goes down in the second snapshot and stays down in the third one,
+	// and the device is missing from the fourth snapshot.
+	snapshots := []model.Snapshot{Snapshot(0), Snapshot(10), Snapshot(20), Snapshot(30)}
+	snapshots[1].Devices[0].Interfaces[0].IsUp = false
+	snapshots[2].Devices[0].Interfaces[0].IsUp = false
+	snapshots[3].Devices = snapshots[3].Devices[1:]
+	for _, snapshot := range snapshots {
+		Store(t, repo, snapshot)
+	}
+
+	history, err := repo.GetDeviceHistory(ctx, "srl1", baseTime, baseTime.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetDeviceHistory: %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("want 3 snapshots with the device, got %d", len(history))
+	}
+	for i, snapshot := range history {
+		if snapshot.ID == 0 {
+			t.Errorf("snapshot %d: want an id", i)
+		}
+		AssertSnapshotEqual(t, model.Snapshot{
+			Timestamp: snapshots[i].Timestamp,
+			Devices:   snapshots[i].Devices[:1],
+		}, snapshot)
+	}
+
+	history, err = repo.GetDeviceHistory(ctx, "srl2", Snapshot(5).Timestamp, Snapshot(30).Timestamp)
+	if err != nil {
+		t.Fatalf("GetDeviceHistory: %v", err)
+	}
+	if len(history) != 3 || !history[0].Timestamp.Equal(Snapshot(10).Timestamp) {
+		t.Errorf("want 3 snapshots from the second one, got %+v", history)
+	}
+	AssertSnapshotEqual(t, model.Snapshot{
+		Timestamp: Snapshot(10).Timestamp,
+		Devices:   Snapshot(10).Devices[1:2],
+	}, history[0])
+
+	history, err = repo.GetDeviceHistory(ctx, "unknown", baseTime, baseTime.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetDeviceHistory: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("want no history of an unknown device, got %+v", history)
+	}
+}
+
+func testGetDevicesHistory(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	// The interface flaps between the second and the third snapshot, so only its last change differs,
+	// and the third device is missing from the last snapshot.
+	snapshots := []model.Snapshot{Snapshot(0), Snapshot(10), Snapshot(20), Snapshot(30)}
+	snapshots[2].Devices[0].Interfaces[0].LastChange = Snapshot(15).Timestamp
+	snapshots[3].Devices[0].Interfaces[0].LastChange = Snapshot(15).Timestamp
+	snapshots[3].Devices = snapshots[3].Devices[:2]
+	for _, snapshot := range snapshots {
+		Store(t, repo, snapshot)
+	}
+
+	histories, err := repo.GetDevicesHistory(ctx, Snapshot(10).Timestamp, Snapshot(30).Timestamp)
+	if err != nil {
+		t.Fatalf("GetDevicesHistory: %v", err)
+	}
+
+	wantCounts := map[string]int{"srl1": 3, "srl2": 3, "srl3": 2}
+	if len(histories) != len(wantCounts) {
+		t.Errorf("want histories of %d devices, got %d", len(wantCounts), len(histories))
+	}
+	for hostname, count := range wantCounts {
+		history := histories[hostname]
+		if len(history) != count {
+			t.Errorf("%s: want %d snapshots, got %d", hostname, count, len(history))
+			continue
+		}
+
+		// Histories are the same as those of the devices read one by one.
+		want, err := repo.GetDeviceHistory(ctx, hostname, Snapshot(10).Timestamp, Snapshot(30).Timestamp)
+		if err != nil {
+			t.Fatalf("GetDeviceHistory: %v", err)
+		}
+		for i := range history {
+			if history[i].ID != want[i].ID {
+				t.Errorf("%s: snapshot %d: want id %d, got %d", hostname, i, want[i].ID, history[i].ID)
+			}
+			AssertSnapshotEqual(t, want[i], history[i])
+		}
+	}
+	AssertSnapshotEqual(t, model.Snapshot{
+		Timestamp: Snapshot(20).Timestamp,
+		Devices:   snapshots[2].Devices[:1],
+	}, histories["srl1"][1])
+
+	histories, err = repo.GetDevicesHistory(ctx, Snapshot(40).Timestamp, Snapshot(50).Timestamp)
+	if err != nil {
+		t.Fatalf("GetDevicesHistory: %v", err)
+	}
+	if len(histories) != 0 {
+		t.Errorf("want no histories after the last snapshot, got %+v", histories)
+	}
+}
+
+func testDeleteSnapshot(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	deletedID := Store(t, repo, Snapshot(0))
+	keptID := Store(t, repo, Snapshot(10))
+
+	if err := repo.DeleteSnapshot(ctx, deletedID); err != nil {
+		t.Fatalf("DeleteSnapshot: %v", err)
+	}
+
+	timestamps, err := repo.GetNTimestamps(ctx, 100)
+	if err != nil {
+		t.Fatalf("GetNTimestamps: %v", err)
+	}
+	if len(timestamps) != 1 || timestamps[0].ID != keptID {
+		t.Errorf("want only snapshot %d, got %+v", keptID, timestamps)
+	}
+
+	deleted, err := repo.GetSnapshot(ctx, deletedID)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	if len(deleted.Devices) != 0 {
+		t.Errorf("deleted snapshot still has devices: %+v", deleted)
+	}
+
+	kept, err := repo.GetSnapshot(ctx, keptID)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	AssertSnapshotEqual(t, Snapshot(10), kept)
+}
+
+func testInterfacesPerSnapshot(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	first := Snapshot(0)
+	firstID := Store(t, repo, first)
+
+	// The second snapshot loses an interface and changes the state of another one.
+	second := Snapshot(10)
+	second.Devices[0].Interfaces = []model.Interface{
+		{Name: "ethernet-1/1", IsUp: false, IP: netip.MustParsePrefix("10.0.0.1/31"), MTU: 1500},
+		{Name: "mgmt0", IsUp: true, IP: netip.MustParsePrefix("172.20.20.2/24"), MTU: 1514},
+	}
+	secondID := Store(t, repo, second)
+
+	got, err := repo.GetSnapshot(ctx, secondID)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	AssertSnapshotEqual(t, second, got)
+
+	got, err = repo.GetSnapshot(ctx, firstID)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	AssertSnapshotEqual(t, first, got)
+}
+
+func testGetMissingSnapshot(t *testing.T, repo repository.Repository) {
+	Store(t, repo, Snapshot(0))
+
+	got, err := repo.GetSnapshot(context.Background(), 1<<20)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	if len(got.Devices) != 0 {
+		t.Errorf("want no devices, got %+v", got)
+	}
+}
+
+func testDeleteMissingSnapshot(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	id := Store(t, repo, Snapshot(0))
+
+	if err := repo.DeleteSnapshot(ctx, 1<<20); err != nil {
+		t.Fatalf("DeleteSnapshot: %v", err)
+	}
+
+	got, err := repo.GetSnapshot(ctx, id)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	AssertSnapshotEqual(t, Snapshot(0), got)
+}
+
+func testDuplicateTimestamp(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	Store(t, repo, Snapshot(0))
+
+	if err := repo.StoreSnapshot(ctx, Snapshot(0)); err == nil {
+		t.Fatal("want an error storing a snapshot with an existing timestamp")
+	}
+
+	timestamps, err := repo.GetNTimestamps(ctx, 100)
+	if err != nil {
+		t.Fatalf("GetNTimestamps: %v", err)
+	}
+	if len(timestamps) != 1 {
+		t.Errorf("want 1 timestamp after a failed store, got %d", len(timestamps))
+	}
+}
+
+func testDeviceAttributesPerSnapshot(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	first := Snapshot(0)
+	firstID := Store(t, repo, first)
+
+	second := Snapshot(10)
+	second.Devices[0].Vendor = "Nokia Networks"
+	second.Devices[0].OSVersion = "v25.3.1"
+	secondID := Store(t, repo, second)
+
+	for _, tt := range []struct {
+		id   int
+		want model.Snapshot
+	}{
+		{firstID, first},
+		{secondID, second},
+	} {
+		got, err := repo.GetSnapshot(ctx, tt.id)
+		if err != nil {
+			t.Fatalf("GetSnapshot: %v", err)
+		}
+		AssertSnapshotEqual(t, tt.want, got)
+	}
+
+	got, err := repo.GetFilteredSnapshot(ctx, firstID, repository.DeviceFilter{OSVersion: "v25.3.1"})
+	if err != nil {
+		t.Fatalf("GetFilteredSnapshot: %v", err)
+	}
+	if len(got.Devices) != 0 {
+		t.Errorf("devices are filtered by their attributes in the snapshot, got %+v", got.Devices)
+	}
+
+	devices := listDevices(t, repo)
+	if len(devices) != 3 || devices[0].Vendor != "Nokia Networks" || devices[0].OSVersion != "v25.3.1" {
+		t.Errorf("want 3 devices with the attributes of the latest snapshot, got %+v", devices)
+	}
+}
+
+func testStoredSnapshotIsIndependent(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	stored := Snapshot(0)
+	id := Store(t, repo, stored)
+
+	stored.Devices[0].Interfaces[0].IsUp = false
+	stored.Devices[1].Warnings[0].Message = "changed"
+
+	got, err := repo.GetSnapshot(ctx, id)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	AssertSnapshotEqual(t, Snapshot(0), got)
+
+	got.Devices[0].Interfaces = nil
+
+	got, err = repo.GetSnapshot(ctx, id)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	AssertSnapshotEqual(t, Snapshot(0), got)
+}
+
+func testPruneSnapshots(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	// The unreachable device, its vendor and operating system, and the management interface
+	// are referenced by the first snapshot only.
+	first := Snapshot(0)
+	first.Devices[2].Vendor = "Nokia Legacy"
+	firstID := Store(t, repo, first)
+
+	second := Snapshot(10)
+	second.Devices = second.Devices[:2]
+	second.Devices[0].Interfaces = second.Devices[0].Interfaces[:2]
+	secondID := Store(t, repo, second)
+
+	want := repository.PruneResult{
+		Snapshots:        1,
+		DeviceStates:     2,
+		Devices:          1,
+		Interfaces:       1,
+		Vendors:          1,
+		OperatingSystems: 1,
+	}
+
+	got, err := repo.PruneSnapshots(ctx, []int{firstID}, true)
+	if err != nil {
+		t.Fatalf("PruneSnapshots dry run: %v", err)
+	}
+	if got != want {
+		t.Errorf("dry run: want %+v, got %+v", want, got)
+	}
+
+	timestamps, err := repo.GetNTimestamps(ctx, 100)
+	if err != nil {
+		t.Fatalf("GetNTimestamps: %v", err)
+	}
+	if len(timestamps) != 2 {
+		t.Fatalf("dry run must not delete snapshots, got %+v", timestamps)
+	}
+
+	got, err = repo.PruneSnapshots(ctx, []int{firstID}, false)
+	if err != nil {
+		t.Fatalf("PruneSnapshots: %v", err)
+	}
+	if got != want {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+
+	timestamps, err = repo.GetNTimestamps(ctx, 100)
+	if err != nil {
+		t.Fatalf("GetNTimestamps: %v", err)
+	}
+	if len(timestamps) != 1 || timestamps[0].ID != secondID {
+		t.Errorf("want only snapshot %d, got %+v", secondID, timestamps)
+	}
+
+	kept, err := repo.GetSnapshot(ctx, secondID)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	AssertSnapshotEqual(t, second, kept)
+
+	got, err = repo.PruneSnapshots(ctx, nil, false)
+	if err != nil {
+		t.Fatalf("PruneSnapshots: %v", err)
+	}
+	if got != (repository.PruneResult{}) {
+		t.Errorf("nothing left to prune, got %+v", got)
+	}
+
+	// A pruned device is stored anew with its current attributes.
+	third := Snapshot(20)
+	third.Devices[2].Serial = "Replaced Serial No."
+	thirdID := Store(t, repo, third)
+
+	stored, err := repo.GetSnapshot(ctx, thirdID)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	AssertSnapshotEqual(t, third, stored)
+}
+
+func testSharedDeviceStates(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	// The first device changes in the second snapshot and changes back in the third one.
+	// Other devices do not change.
+	first := Snapshot(0)
+	second := Snapshot(10)
+	second.Devices[0].Interfaces[1].IsUp = true
+	third := Snapshot(20)
+
+	firstID := Store(t, repo, first)
+	secondID := Store(t, repo, second)
+	thirdID := Store(t, repo, third)
+
+	if err := repo.DeleteSnapshot(ctx, firstID); err != nil {
+		t.Fatalf("DeleteSnapshot: %v", err)
+	}
+
+	for _, tt := range []struct {
+		id   int
+		want model.Snapshot
+	}{
+		{secondID, second},
+		{thirdID, third},
+	} {
+		got, err := repo.GetSnapshot(ctx, tt.id)
+		if err != nil {
+			t.Fatalf("GetSnapshot: %v", err)
+		}
+		AssertSnapshotEqual(t, tt.want, got)
+	}
+
+	want := repository.PruneResult{
+		Snapshots:        2,
+		DeviceStates:     4,
+		Devices:          3,
+		Interfaces:       4,
+		Vendors:          1,
+		OperatingSystems: 3,
+	}
+
+	got, err := repo.PruneSnapshots(ctx, []int{secondID, thirdID}, true)
+	if err != nil {
+		t.Fatalf("PruneSnapshots: %v", err)
+	}
+	if got != want {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+}
+
+// listDevices returns stored devices sorted by id.
+func listDevices(t testing.TB, repo repository.Repository) []repository.StoredDevice {
+	t.Helper()
+
+	devices, err := repo.ListDevices(context.Background())
+	if err != nil {
+		t.Fatalf("ListDevices: %v", err)
+	}
+
+	return devices
+}
+
+// deviceByHostname returns the stored device with the hostname.
+func deviceByHostname(t testing.TB, devices []repository.StoredDevice, hostname string) repository.StoredDevice {
+	t.Helper()
+
+	for _, device := range devices {
+		if device.Hostname == hostname {
+			return device
+		}
+	}
+	t.Fatalf("no device %s in %+v", hostname, devices)
+
+	return repository.StoredDevice{}
+}
+
+// assertHistoryHostnames fails the test if the device history does not have the hostnames in this order.
+func assertHistoryHostnames(t testing.TB, repo repository.Repository, hostname string, want []string) {
+	t.Helper()
+
+	history, err := repo.GetDeviceHistory(context.Background(), hostname, baseTime, baseTime.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetDeviceHistory: %v", err)
+	}
+
+	got := make([]string, len(history))
+	for i, snapshot := range history {
+		got[i] = snapshot.Devices[0].Hostname
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("history of %s: want hostnames %v, got %v", hostname, want, got)
+	}
+}
+
+func testRenamedDeviceBySerial(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	first := Snapshot(0)
+	firstID := Store(t, repo, first)
+
+	second := Snapshot(10)
+	second.Devices[0].Hostname = "leaf1"
+	Store(t, repo, second)
+
+	devices := listDevices(t, repo)
+	if len(devices) != 3 {
+		t.Fatalf("a renamed device is the same device, got %+v", devices)
+	}
+
+	leaf := deviceByHostname(t, devices, "leaf1")
+	if leaf.Serial != "Sim Serial No." || leaf.Snapshots != 2 ||
+		!leaf.FirstSeen.Equal(first.Timestamp) || !leaf.LastSeen.Equal(second.Timestamp) {
+		t.Errorf("unexpected renamed device %+v", leaf)
+	}
+
+	assertHistoryHostnames(t, repo, "leaf1", []string{"srl1", "leaf1"})
+	assertHistoryHostnames(t, repo, "srl1", []string{})
+
+	got, err := repo.GetSnapshot(ctx, firstID)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	AssertSnapshotEqual(t, first, got)
+}
+
+func testChassisSwap(t *testing.T, repo repository.Repository) {
+	Store(t, repo, Snapshot(0))
+
+	second := Snapshot(10)
+	second.Devices[0].Serial = "Replacement Serial No."
+	Store(t, repo, second)
+
+	devices := listDevices(t, repo)
+	if len(devices) != 4 {
+		t.Fatalf("a device with a new serial number is a new device, got %+v", devices)
+	}
+
+	// Both chassis have the hostname, so the history covers both.
+	assertHistoryHostnames(t, repo, "srl1", []string{"srl1", "srl1"})
+
+	// The unreachable device has no serial number and is matched by hostname.
+	if srl3 := deviceByHostname(t, devices, "srl3"); srl3.Snapshots != 2 {
+		t.Errorf("want the device without a serial number in 2 snapshots, got %+v", srl3)
+	}
+}
+
+func testIgnoredSerials(t *testing.T, repo repository.Repository) {
+	Store(t, repo, Snapshot(0))
+
+	second := Snapshot(10)
+	second.Devices[0].Hostname = "leaf1"
+	Store(t, repo, second)
+
+	if devices := listDevices(t, repo); len(devices) != 4 {
+		t.Errorf("an ignored serial number does not identify a device, got %+v", devices)
+	}
+
+	// Devices sharing a serial number within a snapshot are matched by hostname.
+	third := Snapshot(20)
+	third.Devices[0].Hostname = "leaf1"
+	third.Devices[2].Serial = "Sim Serial No. 2"
+	Store(t, repo, third)
+
+	if devices := listDevices(t, repo); len(devices) != 4 {
+		t.Errorf("want no new devices, got %+v", devices)
+	}
+}
+
+func testHostnameIdentity(t *testing.T, repo repository.Repository) {
+	Store(t, repo, Snapshot(0))
+
+	second := Snapshot(10)
+	second.Devices[0].Serial = "Replacement Serial No."
+	Store(t, repo, second)
+
+	devices := listDevices(t, repo)
+	if len(devices) != 3 {
+		t.Fatalf("a new serial number is an attribute change, got %+v", devices)
+	}
+	if srl1 := deviceByHostname(t, devices, "srl1"); srl1.Serial != "Replacement Serial No." {
+		t.Errorf("want the current serial number, got %+v", srl1)
+	}
+
+	third := Snapshot(20)
+	third.Devices[0].Hostname = "leaf1"
+	Store(t, repo, third)
+
+	if devices := listDevices(t, repo); len(devices) != 4 {
+		t.Errorf("a device with a new hostname is a new device, got %+v", devices)
+	}
+}
+
+func testRenameDevice(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	Store(t, repo, Snapshot(0))
+	srl3 := deviceByHostname(t, listDevices(t, repo), "srl3")
+
+	if err := repo.RenameDevice(ctx, srl3.ID, "srl3-new"); err != nil {
+		t.Fatalf("RenameDevice: %v", err)
+	}
+
+	second := Snapshot(10)
+	second.Devices[2].Hostname = "srl3-new"
+	Store(t, repo, second)
+
+	devices := listDevices(t, repo)
+	if len(devices) != 3 {
+		t.Fatalf("want the renamed device to match, got %+v", devices)
+	}
+	if renamed := deviceByHostname(t, devices, "srl3-new"); renamed.ID != srl3.ID || renamed.Snapshots != 2 {
+		t.Errorf("want device %d in 2 snapshots, got %+v", srl3.ID, renamed)
+	}
+
+	if err := repo.RenameDevice(ctx, srl3.ID+100, "missing"); !errors.Is(err, repository.ErrDeviceNotFound) {
+		t.Errorf("want ErrDeviceNotFound, got %v", err)
+	}
+}
+
+func testMergeDevices(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	// The first device is renamed before the second snapshot, so it is stored as a new device
+	// with the same state and interfaces.
+	first := Snapshot(0)
+	firstID := Store(t, repo, first)
+	original := deviceByHostname(t, listDevices(t, repo), "srl1")
+	if err := repo.RenameDevice(ctx, original.ID, "spare"); err != nil {
+		t.Fatalf("RenameDevice: %v", err)
+	}
+
+	second := Snapshot(10)
+	secondID := Store(t, repo, second)
+	devices := listDevices(t, repo)
+	if len(devices) != 4 {
+		t.Fatalf("want a new device, got %+v", devices)
+	}
+	duplicate := deviceByHostname(t, devices, "srl1")
+	srl2 := deviceByHostname(t, devices, "srl2")
+
+	for _, tt := range []struct {
+		name       string
+		from, into int
+		want       error
+	}{
+		{"Same", original.ID, original.ID, repository.ErrSameDevice},
+		{"Missing", original.ID, duplicate.ID + 100, repository.ErrDeviceNotFound},
+		{"Overlap", duplicate.ID, srl2.ID, repository.ErrDevicesOverlap},
+	} {
+		if err := repo.MergeDevices(ctx, tt.from, tt.into); !errors.Is(err, tt.want) {
+			t.Errorf("%s: want %v, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	if err := repo.MergeDevices(ctx, duplicate.ID, original.ID); err != nil {
+		t.Fatalf("MergeDevices: %v", err)
+	}
+
+	devices = listDevices(t, repo)
+	if len(devices) != 3 {
+		t.Fatalf("want the duplicate deleted, got %+v", devices)
+	}
+	merged := deviceByHostname(t, devices, "srl1")
+	if merged.ID != original.ID || merged.Snapshots != 2 {
+		t.Errorf("want device %d with the latest hostname in 2 snapshots, got %+v", original.ID, merged)
+	}
+	assertHistoryHostnames(t, repo, "srl1", []string{"srl1", "srl1"})
+
+	for _, tt := range []struct {
+		id   int
+		want model.Snapshot
+	}{
+		{firstID, first},
+		{secondID, second},
+	} {
+		got, err := repo.GetSnapshot(ctx, tt.id)
+		if err != nil {
+			t.Fatalf("GetSnapshot: %v", err)
+		}
+		AssertSnapshotEqual(t, tt.want, got)
+	}
+
+	// States and interfaces of the duplicate were the same as those of the original, so they are shared.
+	want := repository.PruneResult{Snapshots: 1}
+	got, err := repo.PruneSnapshots(ctx, []int{firstID}, true)
+	if err != nil {
+		t.Fatalf("PruneSnapshots: %v", err)
+	}
+	if got != want {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+
+	Store(t, repo, Snapshot(20))
+	if devices := listDevices(t, repo); len(devices) != 3 {
+		t.Errorf("want the merged device to match, got %+v", devices)
+	}
+}
+
+func testStoreAndGetFindings(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	firstID := Store(t, repo, Snapshot(0))
+	secondID := Store(t, repo, Snapshot(10))
+
+	first := []repository.Finding{
+		{Hostname: "srl2", Check: "ntp", Severity: "major", Message: "no NTP server"},
+		{Hostname: "srl1", Interface: "ethernet-1/1", Check: "mtu", Severity: "minor", Message: "MTU is 1500"},
+	}
+	if err := repo.StoreFindings(ctx, Snapshot(0).Timestamp, "compliance", first); err != nil {
+		t.Fatalf("StoreFindings: %v", err)
+	}
+	// Findings are replaced per analysis.
+	if err := repo.StoreFindings(ctx, Snapshot(10).Timestamp, "compliance", first); err != nil {
+		t.Fatalf("StoreFindings: %v", err)
+	}
+	if err := repo.StoreFindings(ctx, Snapshot(10).Timestamp, "compliance", first[:1]); err != nil {
+		t.Fatalf("StoreFindings: %v", err)
+	}
+	if err := repo.StoreFindings(ctx, Snapshot(10).Timestamp, "ip", first[1:]); err != nil {
+		t.Fatalf("StoreFindings: %v", err)
+	}
+
+	if err := repo.StoreFindings(ctx, Snapshot(5).Timestamp, "compliance", first); !errors.Is(err, repository.ErrSnapshotNotFound) {
+		t.Errorf("want ErrSnapshotNotFound, got %v", err)
+	}
+
+	type key struct {
+		snapshotID int
+		analysis   string
+		hostname   string
+		check      string
+	}
+	tests := []struct {
+		name   string
+		filter repository.FindingFilter
+		want   []key
+	}{
+		{"All", repository.FindingFilter{}, []key{
+			{firstID, "compliance", "srl1", "mtu"},
+			{firstID, "compliance", "srl2", "ntp"},
+			{secondID, "compliance", "srl2", "ntp"},
+			{secondID, "ip", "srl1", "mtu"},
+		}},
+		{"Snapshot", repository.FindingFilter{SnapshotID: secondID}, []key{
+			{secondID, "compliance", "srl2", "ntp"},
+			{secondID, "ip", "srl1", "mtu"},
+		}},
+		{"Analysis", repository.FindingFilter{Analysis: "ip"}, []key{
+			{secondID, "ip", "srl1", "mtu"},
+		}},
+		{"Hostname", repository.FindingFilter{Hostname: "srl1", Analysis: "compliance"}, []key{
+			{firstID, "compliance", "srl1", "mtu"},
+		}},
+		{"Range", repository.FindingFilter{From: Snapshot(5).Timestamp, To: Snapshot(10).Timestamp, Hostname: "srl2"}, []key{
+			{secondID, "compliance", "srl2", "ntp"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := repo.GetFindings(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("GetFindings: %v", err)
+			}
+
+			got := make([]key, len(findings))
+			for i, f := range findings {
+				got[i] = key{f.SnapshotID, f.Analysis, f.Hostname, f.Check}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("want %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	findings, err := repo.GetFindings(ctx, repository.FindingFilter{SnapshotID: firstID, Hostname: "srl1"})
+	if err != nil {
+		t.Fatalf("GetFindings: %v", err)
+	}
+	want := repository.Finding{
+		SnapshotID: firstID,
+		Timestamp:  Snapshot(0).Timestamp,
+		Analysis:   "compliance",
+		Hostname:   "srl1",
+		Interface:  "ethernet-1/1",
+		Check:      "mtu",
+		Severity:   "minor",
+		Message:    "MTU is 1500",
+	}
+	if len(findings) != 1 || !findings[0].Timestamp.Equal(want.Timestamp) {
+		t.Fatalf("want %+v, got %+v", want, findings)
+	}
+	findings[0].Timestamp = want.Timestamp
+	if findings[0] != want {
+		t.Errorf("want %+v, got %+v", want, findings[0])
+	}
+}
+
+func testFindingsOfDeletedSnapshot(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	deletedID := Store(t, repo, Snapshot(0))
+	prunedID := Store(t, repo, Snapshot(10))
+	Store(t, repo, Snapshot(20))
+
+	for _, n := range []int{0, 10, 20} {
+		findings := []repository.Finding{{Hostname: "srl1", Check: "ntp", Severity: "major", Message: "no NTP server"}}
+		if err := repo.StoreFindings(ctx, Snapshot(n).Timestamp, "compliance", findings); err != nil {
+			t.Fatalf("StoreFindings: %v", err)
+		}
+	}
+
+	if err := repo.DeleteSnapshot(ctx, deletedID); err != nil {
+		t.Fatalf("DeleteSnapshot: %v", err)
+	}
+	if _, err := repo.PruneSnapshots(ctx, []int{prunedID}, false); err != nil {
+		t.Fatalf("PruneSnapshots: %v", err)
+	}
+
+	findings, err := repo.GetFindings(ctx, repository.FindingFilter{})
+	if err != nil {
+		t.Fatalf("GetFindings: %v", err)
+	}
+	if len(findings) != 1 || !findings[0].Timestamp.Equal(Snapshot(20).Timestamp) {
+		t.Errorf("want only findings of the kept snapshot, got %+v", findings)
+	}
+}
+
+func testListAddresses(t *testing.T, repo repository.Repository) {
+	ctx := context.Background()
+
+	Store(t, repo, Snapshot(0))
+	Store(t, repo, Snapshot(10))
+
+	// The address of srl2 changes in the last snapshot.
+	changed := Snapshot(20)
+	changed.Devices[1].Interfaces[0].IP = netip.MustParsePrefix("10.0.1.0/31")
+	Store(t, repo, changed)
+
+	addresses, err := repo.ListAddresses(ctx)
+	if err != nil {
+		t.Fatalf("ListAddresses: %v", err)
+	}
+
+	type seen struct {
+		hostname  string
+		iface     string
+		ip        string
+		firstSeen int
+		lastSeen  int
+	}
+	want := []seen{
+		{"srl1", "ethernet-1/1", "10.0.0.1/31", 0, 20},
+		{"srl1", "mgmt0", "172.20.20.2/24", 0, 20},
+		{"srl2", "ethernet-1/1", "10.0.0.0/31", 0, 10},
+		{"srl2", "ethernet-1/1", "10.0.1.0/31", 20, 20},
+	}
+	got := make([]seen, len(addresses))
+	for i, a := range addresses {
+		got[i] = seen{
+			hostname:  a.Hostname,
+			iface:     a.Interface,
+			ip:        a.IP.String(),
+			firstSeen: int(a.FirstSeen.Sub(baseTime) / time.Minute),
+			lastSeen:  int(a.LastSeen.Sub(baseTime) / time.Minute),
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+	if len(addresses) == len(want) && addresses[0].DeviceID == addresses[2].DeviceID {
+		t.Errorf("addresses of different devices have the same device id %d", addresses[0].DeviceID)
+	}
+}
@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/migrations"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var _ migrations.Driver = (*migrationDriver)(nil)
+
+// migrationDriver implements the [Driver] interface.
+type migrationDriver struct {
+	db *sql.DB
+}
+
+// Migrator implements the [Migratable] interface.
+func (s *sqLite) Migrator() repository.Migrator {
+	return s.migrator
+}
+
+// newMigrator returns an object that migrates the schema with the embedded migrations.
+func newMigrator(s *sqLite) (repository.Migrator, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return migrations.NewMigrator(s.logger, &migrationDriver{db: s.db}, files)
+}
+
+// Lock implements the [Driver] interface.
+// SQLite has no advisory locks. Instead, a migration transaction starts by recording its version,
+// which takes the database write lock, and is skipped if another server has already recorded it.
+func (d *migrationDriver) Lock(_ context.Context) (func(), error) {
+	return func() {}, nil
+}
+
+// Init implements the [Driver] interface.
+func (d *migrationDriver) Init(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, createTableSchemaMigrationsQuery)
+	return err
+}
+
+// Applied implements the [Driver] interface.
+func (d *migrationDriver) Applied(ctx context.Context) (map[int]time.Time, error) {
+	rows, err := d.db.QueryContext(ctx, selectSchemaMigrationsQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt int64
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = toTimeFromDB(appliedAt)
+	}
+
+	return applied, rows.Err()
+}
+
+// Apply implements the [Driver] interface.
+func (d *migrationDriver) Apply(ctx context.Context, m migrations.Migration) error {
+	return d.migrate(ctx, m.Up, insertSchemaMigrationQuery,
+		sql.Named("version", m.Version),
+		sql.Named("name", m.Name),
+		sql.Named("applied_at", toDBFromTime(time.Now())),
+	)
+}
+
+// Revert implements the [Driver] interface.
+func (d *migrationDriver) Revert(ctx context.Context, m migrations.Migration) error {
+	return d.migrate(ctx, m.Down, deleteSchemaMigrationQuery,
+		sql.Named("version", m.Version),
+	)
+}
+
+// migrate updates schema_migrations and runs the migration query in a single transaction.
+// If the record is not changed, another server has already run the migration and the query is skipped.
+// Foreign keys are disabled while the migration runs, so it can rebuild tables other tables refer to,
+// and are checked before the transaction is committed.
+func (d *migrationDriver) migrate(ctx context.Context, query, recordQuery string, recordArgs ...any) error {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The pragma has no effect within a transaction.
+	if _, err := conn.ExecContext(ctx, disableForeignKeysQuery); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), enableForeignKeysQuery)
+	}()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, recordQuery, recordArgs...)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	if changed, err := result.RowsAffected(); err != nil || changed == 0 {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	if err := checkForeignKeys(ctx, tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// checkForeignKeys returns an error if a row refers to a missing row.
+func checkForeignKeys(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, checkForeignKeysQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var (
+			table, parent string
+			rowID         sql.NullInt64
+			fkID          int
+		)
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return err
+		}
+		return fmt.Errorf("row %d of %s refers to a missing row of %s", rowID.Int64, table, parent)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS interface_states;
+DROP TABLE IF EXISTS interfaces;
+DROP TABLE IF EXISTS device_warnings;
+DROP TABLE IF EXISTS device_states;
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS operating_systems;
+DROP TABLE IF EXISTS vendors;
+DROP TABLE IF EXISTS snapshots;
@@ -0,0 +1,59 @@
+-- Timestamps are stored as Unix time in nanoseconds.
+-- Tables are created only if missing, so databases created before migrations were introduced are adopted as is.
+
+CREATE TABLE IF NOT EXISTS snapshots (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp INTEGER UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS vendors (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS operating_systems (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	version TEXT,
+	UNIQUE (name, version)
+);
+
+CREATE TABLE IF NOT EXISTS devices (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	vendor_id INTEGER REFERENCES vendors(id) ON DELETE RESTRICT,
+	operating_system_id INTEGER REFERENCES operating_systems(id) ON DELETE RESTRICT,
+	hostname TEXT UNIQUE NOT NULL,
+	serial_number TEXT
+);
+
+CREATE TABLE IF NOT EXISTS device_states (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	snapshot_id INTEGER REFERENCES snapshots(id) ON DELETE CASCADE,
+	device_id INTEGER REFERENCES devices(id) ON DELETE RESTRICT,
+	is_snapshot_successful BOOLEAN NOT NULL,
+	status TEXT
+);
+
+CREATE TABLE IF NOT EXISTS device_warnings (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	device_state_id INTEGER REFERENCES device_states(id) ON DELETE CASCADE,
+	command TEXT NOT NULL,
+	field TEXT NOT NULL,
+	message TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS interfaces (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	device_id INTEGER REFERENCES devices(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	UNIQUE (device_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS interface_states (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	interface_id INTEGER REFERENCES interfaces(id) ON DELETE CASCADE,
+	device_state_id INTEGER REFERENCES device_states(id) ON DELETE CASCADE,
+	is_up BOOLEAN NOT NULL,
+	ip TEXT,
+	mtu INTEGER
+);
@@ -0,0 +1,28 @@
+-- Device states are shared by all snapshots in which the device did not change.
+-- A snapshot refers to device states through snapshot_devices.
+
+CREATE TABLE snapshot_devices (
+	snapshot_id INTEGER REFERENCES snapshots(id) ON DELETE CASCADE,
+	device_id INTEGER REFERENCES devices(id) ON DELETE RESTRICT,
+	device_state_id INTEGER NOT NULL REFERENCES device_states(id) ON DELETE RESTRICT,
+	PRIMARY KEY (snapshot_id, device_id)
+);
+
+CREATE INDEX snapshot_devices_device_state_id_idx ON snapshot_devices (device_state_id);
+
+INSERT INTO snapshot_devices (snapshot_id, device_id, device_state_id)
+SELECT snapshot_id, device_id, id
+FROM device_states
+WHERE snapshot_id IS NOT NULL;
+
+-- SQLite cannot drop a column referencing another table, so snapshot_id is cleared and no longer used.
+UPDATE device_states
+SET snapshot_id = NULL;
+
+-- Existing states are kept as is and have no hash, so they are not shared.
+ALTER TABLE device_states
+ADD COLUMN state_hash TEXT;
+
+CREATE UNIQUE INDEX device_states_device_id_state_hash_idx ON device_states (device_id, state_hash);
+CREATE INDEX interface_states_device_state_id_idx ON interface_states (device_state_id);
+CREATE INDEX device_warnings_device_state_id_idx ON device_warnings (device_state_id);
@@ -0,0 +1,40 @@
+-- Fails if several devices have the same hostname; merge them first.
+-- Device states lose the attributes they were stored with.
+
+CREATE TABLE devices_rebuilt (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	vendor_id INTEGER REFERENCES vendors(id) ON DELETE RESTRICT,
+	operating_system_id INTEGER REFERENCES operating_systems(id) ON DELETE RESTRICT,
+	hostname TEXT UNIQUE NOT NULL,
+	serial_number TEXT
+);
+
+INSERT INTO devices_rebuilt (id, vendor_id, operating_system_id, hostname, serial_number)
+SELECT id, vendor_id, operating_system_id, hostname, serial_number
+FROM devices;
+
+DROP TABLE devices;
+
+ALTER TABLE devices_rebuilt
+RENAME TO devices;
+
+-- SQLite cannot drop a column referencing another table, so device states are rebuilt too.
+CREATE TABLE device_states_rebuilt (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	snapshot_id INTEGER REFERENCES snapshots(id) ON DELETE CASCADE,
+	device_id INTEGER REFERENCES devices(id) ON DELETE RESTRICT,
+	is_snapshot_successful BOOLEAN NOT NULL,
+	status TEXT,
+	state_hash TEXT
+);
+
+INSERT INTO device_states_rebuilt (id, snapshot_id, device_id, is_snapshot_successful, status, state_hash)
+SELECT id, snapshot_id, device_id, is_snapshot_successful, status, state_hash
+FROM device_states;
+
+DROP TABLE device_states;
+
+ALTER TABLE device_states_rebuilt
+RENAME TO device_states;
+
+CREATE UNIQUE INDEX device_states_device_id_state_hash_idx ON device_states (device_id, state_hash);
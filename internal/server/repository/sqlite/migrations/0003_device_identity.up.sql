@@ -0,0 +1,45 @@
+-- A hostname may belong to several devices, for example after a chassis swap, so it is no longer unique.
+-- Device states store the attributes a device had in a snapshot; devices keep the current ones.
+-- Existing states keep hashes computed without attributes, so the next snapshot stores new states once.
+
+-- SQLite cannot drop a constraint, so the table is rebuilt; migrations run with foreign keys disabled.
+CREATE TABLE devices_rebuilt (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	vendor_id INTEGER REFERENCES vendors(id) ON DELETE RESTRICT,
+	operating_system_id INTEGER REFERENCES operating_systems(id) ON DELETE RESTRICT,
+	hostname TEXT NOT NULL,
+	serial_number TEXT
+);
+
+INSERT INTO devices_rebuilt (id, vendor_id, operating_system_id, hostname, serial_number)
+SELECT id, vendor_id, operating_system_id, hostname, serial_number
+FROM devices;
+
+DROP TABLE devices;
+
+ALTER TABLE devices_rebuilt
+RENAME TO devices;
+
+CREATE INDEX devices_hostname_idx ON devices (hostname);
+CREATE INDEX devices_serial_number_idx ON devices (serial_number);
+
+ALTER TABLE device_states
+ADD COLUMN vendor_id INTEGER REFERENCES vendors(id) ON DELETE RESTRICT;
+
+ALTER TABLE device_states
+ADD COLUMN operating_system_id INTEGER REFERENCES operating_systems(id) ON DELETE RESTRICT;
+
+ALTER TABLE device_states
+ADD COLUMN hostname TEXT;
+
+ALTER TABLE device_states
+ADD COLUMN serial_number TEXT;
+
+UPDATE device_states
+SET
+	vendor_id = d.vendor_id,
+	operating_system_id = d.operating_system_id,
+	hostname = d.hostname,
+	serial_number = d.serial_number
+FROM devices AS d
+WHERE d.id = device_states.device_id;
@@ -0,0 +1 @@
+ALTER TABLE interface_states DROP COLUMN description;
@@ -0,0 +1,2 @@
+-- Last changes are stored as Unix nanoseconds. Interfaces of existing states have no last change.
+ALTER TABLE interface_states ADD COLUMN last_change INTEGER;
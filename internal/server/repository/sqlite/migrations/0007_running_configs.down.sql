@@ -0,0 +1 @@
+ALTER TABLE device_states DROP COLUMN running_config;
@@ -0,0 +1,2 @@
+-- Existing states have no running configuration.
+ALTER TABLE device_states ADD COLUMN running_config TEXT NOT NULL DEFAULT '';
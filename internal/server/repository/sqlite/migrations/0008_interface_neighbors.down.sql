@@ -0,0 +1,2 @@
+ALTER TABLE interface_states DROP COLUMN neighbor_interface;
+ALTER TABLE interface_states DROP COLUMN neighbor_hostname;
@@ -0,0 +1,3 @@
+-- Interfaces of existing states have no neighbor.
+ALTER TABLE interface_states ADD COLUMN neighbor_hostname TEXT NOT NULL DEFAULT '';
+ALTER TABLE interface_states ADD COLUMN neighbor_interface TEXT NOT NULL DEFAULT '';
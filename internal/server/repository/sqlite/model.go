@@ -0,0 +1,116 @@
+package sqlite
+
+import "database/sql"
+
+// dbSnapshotPart is an auxiliary structure into which the database response is written.
+type dbSnapshotPart struct {
+	ID                   sql.NullInt64
+	Timestamp            sql.NullInt64
+	VendorName           sql.NullString
+	OSName               sql.NullString
+	OSVersion            sql.NullString
+	DeviceID             sql.NullInt64
+	Hostname             sql.NullString
+	SerialNumber         sql.NullString
+	IsSnapshotSuccessful sql.NullBool
+	Status               sql.NullString
+	InterfaceName        sql.NullString
+	IsUp                 sql.NullBool
+	IP                   sql.NullString
+	MTU                  sql.NullInt64
+	Description          sql.NullString
+	LastChange           sql.NullInt64
+	NeighborHostname     sql.NullString
+	NeighborInterface    sql.NullString
+}
+
+// dbWarning is an auxiliary structure into which the database response is written.
+type dbWarning struct {
+	DeviceID sql.NullInt64
+	Command  sql.NullString
+	Field    sql.NullString
+	Message  sql.NullString
+}
+
+// dbConfig is an auxiliary structure into which the database response is written.
+type dbConfig struct {
+	DeviceID      sql.NullInt64
+	RunningConfig sql.NullString
+}
+
+// dbDeviceSnapshot is an auxiliary structure into which the database response is written.
+type dbDeviceSnapshot struct {
+	ID            sql.NullInt64
+	Timestamp     sql.NullInt64
+	DeviceStateID sql.NullInt64
+	Hostname      sql.NullString
+}
+
+// dbDeviceStatePart is an auxiliary structure into which the database response is written.
+type dbDeviceStatePart struct {
+	DeviceStateID        sql.NullInt64
+	VendorName           sql.NullString
+	OSName               sql.NullString
+	OSVersion            sql.NullString
+	Hostname             sql.NullString
+	SerialNumber         sql.NullString
+	IsSnapshotSuccessful sql.NullBool
+	Status               sql.NullString
+	InterfaceName        sql.NullString
+	IsUp                 sql.NullBool
+	IP                   sql.NullString
+	MTU                  sql.NullInt64
+	Description          sql.NullString
+	LastChange           sql.NullInt64
+	NeighborHostname     sql.NullString
+	NeighborInterface    sql.NullString
+}
+
+// dbDeviceStateWarning is an auxiliary structure into which the database response is written.
+type dbDeviceStateWarning struct {
+	DeviceStateID sql.NullInt64
+	Command       sql.NullString
+	Field         sql.NullString
+	Message       sql.NullString
+}
+
+// dbDeviceStateConfig is an auxiliary structure into which the database response is written.
+type dbDeviceStateConfig struct {
+	DeviceStateID sql.NullInt64
+	RunningConfig sql.NullString
+}
+
+// dbDevice is an auxiliary structure into which the database response is written.
+type dbDevice struct {
+	ID           sql.NullInt64
+	Hostname     sql.NullString
+	SerialNumber sql.NullString
+	VendorName   sql.NullString
+	OSName       sql.NullString
+	OSVersion    sql.NullString
+	FirstSeen    sql.NullInt64
+	LastSeen     sql.NullInt64
+	Snapshots    sql.NullInt64
+}
+
+// dbAddress is an auxiliary structure into which the database response is written.
+type dbAddress struct {
+	DeviceID      sql.NullInt64
+	Hostname      sql.NullString
+	InterfaceName sql.NullString
+	IP            sql.NullString
+	FirstSeen     sql.NullInt64
+	LastSeen      sql.NullInt64
+}
+
+// dbFinding is an auxiliary structure into which the database response is written.
+type dbFinding struct {
+	SnapshotID    sql.NullInt64
+	Timestamp     sql.NullInt64
+	Analysis      sql.NullString
+	Hostname      sql.NullString
+	InterfaceName sql.NullString
+	CheckName     sql.NullString
+	Severity      sql.NullString
+	Message       sql.NullString
+}
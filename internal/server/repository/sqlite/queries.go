@@ -0,0 +1,656 @@
+package sqlite
+
+// SQL queries to track applied schema migrations.
+// Tables storing snapshots are created by migrations in the migrations directory.
+// Timestamps are stored as Unix time in nanoseconds.
+const (
+	createTableSchemaMigrationsQuery = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at INTEGER NOT NULL
+);
+`
+
+	selectSchemaMigrationsQuery = `
+SELECT version, applied_at
+FROM schema_migrations;
+`
+
+	insertSchemaMigrationQuery = `
+INSERT INTO schema_migrations (version, name, applied_at)
+VALUES (@version, @name, @applied_at)
+ON CONFLICT (version) DO NOTHING;
+`
+
+	deleteSchemaMigrationQuery = `
+DELETE FROM schema_migrations
+WHERE version = @version;
+`
+
+	disableForeignKeysQuery = `
+PRAGMA foreign_keys = OFF;
+`
+
+	enableForeignKeysQuery = `
+PRAGMA foreign_keys = ON;
+`
+
+	checkForeignKeysQuery = `
+PRAGMA foreign_key_check;
+`
+)
+
+// SQL queries for inserting a snapshot.
+// Rows that may already exist are inserted first and then selected,
+// because RETURNING yields no rows when a conflict is ignored.
+const (
+	insertSnapshotQuery = `
+INSERT INTO snapshots (timestamp)
+VALUES (@timestamp)
+RETURNING id;
+`
+
+	insertVendorQuery = `
+INSERT INTO vendors (name)
+VALUES (@vendor)
+ON CONFLICT (name) DO NOTHING;
+`
+
+	selectVendorQuery = `
+SELECT id
+FROM vendors
+WHERE name = @vendor;
+`
+
+	insertOperatingSystemQuery = `
+INSERT INTO operating_systems (name, version)
+VALUES (@os, @version)
+ON CONFLICT (name, version) DO NOTHING;
+`
+
+	selectOperatingSystemQuery = `
+SELECT id
+FROM operating_systems
+WHERE name = @os AND version = @version;
+`
+
+	selectDeviceIdentitiesQuery = `
+SELECT id, hostname, serial_number
+FROM devices;
+`
+
+	insertDeviceQuery = `
+INSERT INTO devices (vendor_id, operating_system_id, hostname, serial_number)
+VALUES (@vendor_id, @operating_system_id, @hostname, @serial_number)
+RETURNING id;
+`
+
+	updateDeviceQuery = `
+UPDATE devices
+SET
+	vendor_id = @vendor_id,
+	operating_system_id = @operating_system_id,
+	hostname = @hostname,
+	serial_number = @serial_number
+WHERE id = @id;
+`
+
+	insertDeviceStateQuery = `
+INSERT INTO device_states (
+	device_id, vendor_id, operating_system_id, hostname, serial_number,
+	is_snapshot_successful, status, state_hash, running_config
+)
+VALUES (
+	@device_id, @vendor_id, @operating_system_id, @hostname, @serial_number,
+	@is_snapshot_successful, @status, @state_hash, @running_config
+)
+ON CONFLICT (device_id, state_hash) DO NOTHING
+RETURNING id;
+`
+
+	selectDeviceStateQuery = `
+SELECT id
+FROM device_states
+WHERE device_id = @device_id AND state_hash = @state_hash;
+`
+
+	insertSnapshotDeviceQuery = `
+INSERT INTO snapshot_devices (snapshot_id, device_id, device_state_id)
+VALUES (@snapshot_id, @device_id, @device_state_id);
+`
+
+	insertDeviceWarningQuery = `
+INSERT INTO device_warnings (device_state_id, command, field, message)
+VALUES (@device_state_id, @command, @field, @message);
+`
+
+	insertInterfaceQuery = `
+INSERT INTO interfaces (device_id, name)
+VALUES (@device_id, @name)
+ON CONFLICT (device_id, name) DO NOTHING;
+`
+
+	selectInterfaceQuery = `
+SELECT id
+FROM interfaces
+WHERE device_id = @device_id AND name = @name;
+`
+
+	insertInterfaceStateQuery = `
+INSERT INTO interface_states (interface_id, device_state_id, is_up, ip, mtu, description, last_change, neighbor_hostname, neighbor_interface)
+VALUES (@interface_id, @device_state_id, @is_up, @ip, @mtu, @description, @last_change, @neighbor_hostname, @neighbor_interface);
+`
+)
+
+// SQL queries to get snapshot ids and timestamps.
+const (
+	selectTimestampsQuery = `
+SELECT id, timestamp
+FROM snapshots
+ORDER BY timestamp DESC
+LIMIT @limit;
+`
+
+	selectTimestampsBeforeQuery = `
+SELECT id, timestamp
+FROM snapshots
+WHERE timestamp < @before
+ORDER BY timestamp DESC
+LIMIT @limit;
+`
+
+	selectTimestampsBetweenQuery = `
+SELECT id, timestamp
+FROM snapshots
+WHERE timestamp BETWEEN @from AND @to
+ORDER BY timestamp DESC;
+`
+
+	selectClosestTimestampsQuery = `
+SELECT id, timestamp
+FROM (
+	SELECT id, timestamp
+	FROM snapshots
+	WHERE timestamp <= @at
+	ORDER BY timestamp DESC
+	LIMIT 1
+)
+UNION ALL
+SELECT id, timestamp
+FROM (
+	SELECT id, timestamp
+	FROM snapshots
+	WHERE timestamp > @at
+	ORDER BY timestamp ASC
+	LIMIT 1
+);
+`
+)
+
+// SQL query to get a snapshot.
+// Devices have the attributes of their states. Empty filter arguments match any device.
+const (
+	selectSnapshotQuery = `
+SELECT
+	s.id,
+	s.timestamp,
+	v.name AS vendor_name,
+	o.name AS os_name,
+	o.version AS os_version,
+	s_d.device_id,
+	d_s.hostname,
+	d_s.serial_number,
+	d_s.is_snapshot_successful,
+	d_s.status,
+	i.name AS interface_name,
+	i_s.is_up,
+	i_s.ip,
+	i_s.mtu,
+	i_s.description,
+	i_s.last_change,
+	i_s.neighbor_hostname,
+	i_s.neighbor_interface
+FROM
+	snapshots AS s
+	JOIN snapshot_devices AS s_d ON s.id = s_d.snapshot_id
+	JOIN device_states AS d_s ON d_s.id = s_d.device_state_id
+	JOIN vendors AS v ON v.id = d_s.vendor_id
+	JOIN operating_systems AS o ON o.id = d_s.operating_system_id
+	LEFT JOIN interface_states AS i_s ON d_s.id = i_s.device_state_id
+	LEFT JOIN interfaces AS i ON i.id = i_s.interface_id
+WHERE
+	s.id = @id
+	AND (@hostname = '' OR d_s.hostname = @hostname)
+	AND (@vendor = '' OR v.name = @vendor)
+	AND (@os_name = '' OR o.name = @os_name)
+	AND (@os_version = '' OR o.version = @os_version)
+ORDER BY s_d.device_id ASC, i_s.id ASC;
+`
+)
+
+// SQL query to get warnings of a snapshot.
+const (
+	selectWarningsQuery = `
+SELECT
+	s_d.device_id,
+	w.command,
+	w.field,
+	w.message
+FROM
+	device_warnings AS w
+	JOIN snapshot_devices AS s_d ON s_d.device_state_id = w.device_state_id
+WHERE
+	s_d.snapshot_id = @id
+ORDER BY w.id ASC;
+`
+)
+
+// SQL query to get running configurations of a snapshot.
+// Configurations are selected apart from interfaces, so that each of them is returned once.
+const (
+	selectConfigsQuery = `
+SELECT
+	s_d.device_id,
+	d_s.running_config
+FROM
+	snapshot_devices AS s_d
+	JOIN device_states AS d_s ON d_s.id = s_d.device_state_id
+WHERE
+	s_d.snapshot_id = @id
+	AND d_s.running_config != '';
+`
+)
+
+// SQL queries to get the history of devices with the current hostname, or of all devices if it is empty.
+// Snapshots refer to device states, which are selected once however many snapshots share them.
+const (
+	selectDeviceSnapshotsQuery = `
+SELECT
+	s.id,
+	s.timestamp,
+	s_d.device_state_id,
+	d.hostname
+FROM
+	snapshot_devices AS s_d
+	JOIN snapshots AS s ON s.id = s_d.snapshot_id
+	JOIN devices AS d ON d.id = s_d.device_id
+WHERE
+	(@hostname = '' OR d.hostname = @hostname)
+	AND s.timestamp BETWEEN @from AND @to
+ORDER BY s.timestamp ASC;
+`
+
+	selectDeviceStatesQuery = `
+SELECT
+	d_s.id AS device_state_id,
+	v.name AS vendor_name,
+	o.name AS os_name,
+	o.version AS os_version,
+	d_s.hostname,
+	d_s.serial_number,
+	d_s.is_snapshot_successful,
+	d_s.status,
+	i.name AS interface_name,
+	i_s.is_up,
+	i_s.ip,
+	i_s.mtu,
+	i_s.description,
+	i_s.last_change,
+	i_s.neighbor_hostname,
+	i_s.neighbor_interface
+FROM
+	devices AS d
+	JOIN device_states AS d_s ON d_s.device_id = d.id
+	JOIN vendors AS v ON v.id = d_s.vendor_id
+	JOIN operating_systems AS o ON o.id = d_s.operating_system_id
+	LEFT JOIN interface_states AS i_s ON d_s.id = i_s.device_state_id
+	LEFT JOIN interfaces AS i ON i.id = i_s.interface_id
+WHERE
+	(@hostname = '' OR d.hostname = @hostname)
+	AND d_s.id IN (
+		SELECT s_d.device_state_id
+		FROM
+			snapshot_devices AS s_d
+			JOIN snapshots AS s ON s.id = s_d.snapshot_id
+		WHERE
+			s_d.device_id = d.id
+			AND s.timestamp BETWEEN @from AND @to
+	)
+ORDER BY d_s.id ASC, i_s.id ASC;
+`
+
+	selectDeviceStateWarningsQuery = `
+SELECT
+	w.device_state_id,
+	w.command,
+	w.field,
+	w.message
+FROM
+	device_warnings AS w
+	JOIN device_states AS d_s ON d_s.id = w.device_state_id
+	JOIN devices AS d ON d.id = d_s.device_id
+WHERE
+	(@hostname = '' OR d.hostname = @hostname)
+	AND d_s.id IN (
+		SELECT s_d.device_state_id
+		FROM
+			snapshot_devices AS s_d
+			JOIN snapshots AS s ON s.id = s_d.snapshot_id
+		WHERE
+			s_d.device_id = d.id
+			AND s.timestamp BETWEEN @from AND @to
+	)
+ORDER BY w.id ASC;
+`
+
+	selectDeviceStateConfigsQuery = `
+SELECT
+	d_s.id AS device_state_id,
+	d_s.running_config
+FROM
+	devices AS d
+	JOIN device_states AS d_s ON d_s.device_id = d.id
+WHERE
+	(@hostname = '' OR d.hostname = @hostname)
+	AND d_s.running_config != ''
+	AND d_s.id IN (
+		SELECT s_d.device_state_id
+		FROM
+			snapshot_devices AS s_d
+			JOIN snapshots AS s ON s.id = s_d.snapshot_id
+		WHERE
+			s_d.device_id = d.id
+			AND s.timestamp BETWEEN @from AND @to
+	);
+`
+)
+
+// SQL queries to delete a snapshot and device states used only by it.
+const (
+	selectSnapshotDeviceStatesQuery = `
+SELECT device_state_id
+FROM snapshot_devices
+WHERE snapshot_id = @id;
+`
+
+	deleteSnapshotQuery = `
+DELETE FROM snapshots
+WHERE id = @id;
+`
+
+	deleteUnusedDeviceStatesQuery = `
+DELETE FROM device_states
+WHERE
+	id IN (SELECT value FROM json_each(@ids))
+	AND NOT EXISTS (
+		SELECT 1
+		FROM snapshot_devices AS s_d
+		WHERE s_d.device_state_id = device_states.id
+	);
+`
+)
+
+// SQL queries to prune snapshots and rows no longer referenced by any snapshot.
+const (
+	deleteSnapshotsQuery = `
+DELETE FROM snapshots
+WHERE id IN (SELECT value FROM json_each(@ids));
+`
+
+	deleteOrphanedDeviceStatesQuery = `
+DELETE FROM device_states
+WHERE NOT EXISTS (
+	SELECT 1
+	FROM snapshot_devices AS s_d
+	WHERE s_d.device_state_id = device_states.id
+);
+`
+
+	deleteOrphanedInterfacesQuery = `
+DELETE FROM interfaces
+WHERE NOT EXISTS (
+	SELECT 1
+	FROM interface_states AS i_s
+	WHERE i_s.interface_id = interfaces.id
+);
+`
+
+	deleteOrphanedDevicesQuery = `
+DELETE FROM devices
+WHERE NOT EXISTS (
+	SELECT 1
+	FROM snapshot_devices AS s_d
+	WHERE s_d.device_id = devices.id
+);
+`
+
+	deleteOrphanedVendorsQuery = `
+DELETE FROM vendors
+WHERE
+	NOT EXISTS (
+		SELECT 1
+		FROM devices AS d
+		WHERE d.vendor_id = vendors.id
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM device_states AS d_s
+		WHERE d_s.vendor_id = vendors.id
+	);
+`
+
+	deleteOrphanedOperatingSystemsQuery = `
+DELETE FROM operating_systems
+WHERE
+	NOT EXISTS (
+		SELECT 1
+		FROM devices AS d
+		WHERE d.operating_system_id = operating_systems.id
+	)
+	AND NOT EXISTS (
+		SELECT 1
+		FROM device_states AS d_s
+		WHERE d_s.operating_system_id = operating_systems.id
+	);
+`
+)
+
+// SQL queries to list and rename devices.
+const (
+	selectDevicesQuery = `
+SELECT
+	d.id,
+	d.hostname,
+	d.serial_number,
+	v.name AS vendor_name,
+	o.name AS os_name,
+	o.version AS os_version,
+	MIN(s.timestamp) AS first_seen,
+	MAX(s.timestamp) AS last_seen,
+	COUNT(s.id) AS snapshots
+FROM
+	devices AS d
+	LEFT JOIN vendors AS v ON v.id = d.vendor_id
+	LEFT JOIN operating_systems AS o ON o.id = d.operating_system_id
+	LEFT JOIN snapshot_devices AS s_d ON s_d.device_id = d.id
+	LEFT JOIN snapshots AS s ON s.id = s_d.snapshot_id
+GROUP BY d.id, d.hostname, d.serial_number, v.name, o.name, o.version
+ORDER BY d.id ASC;
+`
+
+	selectAddressesQuery = `
+SELECT
+	d.id AS device_id,
+	d.hostname,
+	i.name AS interface_name,
+	i_s.ip,
+	MIN(s.timestamp) AS first_seen,
+	MAX(s.timestamp) AS last_seen
+FROM
+	snapshot_devices AS s_d
+	JOIN snapshots AS s ON s.id = s_d.snapshot_id
+	JOIN devices AS d ON d.id = s_d.device_id
+	JOIN interface_states AS i_s ON i_s.device_state_id = s_d.device_state_id
+	JOIN interfaces AS i ON i.id = i_s.interface_id
+WHERE i_s.ip IS NOT NULL AND i_s.ip != ''
+GROUP BY d.id, d.hostname, i.name, i_s.ip
+ORDER BY d.id ASC, i.name ASC, i_s.ip ASC;
+`
+
+	renameDeviceQuery = `
+UPDATE devices
+SET hostname = @hostname
+WHERE id = @id;
+`
+)
+
+// SQL queries to merge device @from into device @into.
+// States and interfaces of @from equal to those of @into are replaced with them, the rest are moved.
+const (
+	countMergedDevicesQuery = `
+SELECT COUNT(*)
+FROM devices
+WHERE id IN (@from, @into);
+`
+
+	countOverlappingSnapshotsQuery = `
+SELECT COUNT(*)
+FROM
+	snapshot_devices AS f
+	JOIN snapshot_devices AS i ON i.snapshot_id = f.snapshot_id
+WHERE f.device_id = @from AND i.device_id = @into;
+`
+
+	remapDuplicateDeviceStatesQuery = `
+UPDATE snapshot_devices
+SET device_state_id = (
+	SELECT i.id
+	FROM
+		device_states AS f
+		JOIN device_states AS i ON i.state_hash = f.state_hash AND i.device_id = @into
+	WHERE f.id = snapshot_devices.device_state_id
+)
+WHERE device_state_id IN (
+	SELECT f.id
+	FROM
+		device_states AS f
+		JOIN device_states AS i ON i.state_hash = f.state_hash AND i.device_id = @into
+	WHERE f.device_id = @from
+);
+`
+
+	deleteDuplicateDeviceStatesQuery = `
+DELETE FROM device_states
+WHERE
+	device_id = @from
+	AND state_hash IN (
+		SELECT state_hash
+		FROM device_states
+		WHERE device_id = @into
+	);
+`
+
+	remapDuplicateInterfacesQuery = `
+UPDATE interface_states
+SET interface_id = (
+	SELECT i.id
+	FROM
+		interfaces AS f
+		JOIN interfaces AS i ON i.name = f.name AND i.device_id = @into
+	WHERE f.id = interface_states.interface_id
+)
+WHERE interface_id IN (
+	SELECT f.id
+	FROM
+		interfaces AS f
+		JOIN interfaces AS i ON i.name = f.name AND i.device_id = @into
+	WHERE f.device_id = @from
+);
+`
+
+	deleteDuplicateInterfacesQuery = `
+DELETE FROM interfaces
+WHERE
+	device_id = @from
+	AND name IN (
+		SELECT name
+		FROM interfaces
+		WHERE device_id = @into
+	);
+`
+
+	moveInterfacesQuery = `
+UPDATE interfaces
+SET device_id = @into
+WHERE device_id = @from;
+`
+
+	moveDeviceStatesQuery = `
+UPDATE device_states
+SET device_id = @into
+WHERE device_id = @from;
+`
+
+	moveSnapshotDevicesQuery = `
+UPDATE snapshot_devices
+SET device_id = @into
+WHERE device_id = @from;
+`
+
+	updateMergedDeviceQuery = `
+UPDATE devices
+SET (vendor_id, operating_system_id, hostname, serial_number) = (
+	SELECT d_s.vendor_id, d_s.operating_system_id, d_s.hostname, d_s.serial_number
+	FROM
+		snapshot_devices AS s_d
+		JOIN snapshots AS s ON s.id = s_d.snapshot_id
+		JOIN device_states AS d_s ON d_s.id = s_d.device_state_id
+	WHERE s_d.device_id = @into
+	ORDER BY s.timestamp DESC
+	LIMIT 1
+)
+WHERE
+	id = @into
+	AND EXISTS (
+		SELECT 1
+		FROM snapshot_devices AS s_d
+		WHERE s_d.device_id = @into
+	);
+`
+
+	deleteMergedDeviceQuery = `
+DELETE FROM devices
+WHERE id = @from;
+`
+)
+
+// SQL queries to store and read findings of analyses.
+const (
+	selectSnapshotIDByTimestampQuery = `
+SELECT id
+FROM snapshots
+WHERE timestamp = @timestamp;
+`
+
+	deleteFindingsQuery = `
+DELETE FROM findings
+WHERE snapshot_id = @snapshot_id AND analysis = @analysis;
+`
+
+	insertFindingQuery = `
+INSERT INTO findings (snapshot_id, analysis, hostname, interface_name, check_name, severity, message)
+VALUES (@snapshot_id, @analysis, @hostname, @interface_name, @check_name, @severity, @message);
+`
+
+	selectFindingsQuery = `
+SELECT f.snapshot_id, s.timestamp, f.analysis, f.hostname, f.interface_name, f.check_name, f.severity, f.message
+FROM findings AS f
+JOIN snapshots AS s ON s.id = f.snapshot_id
+WHERE (@snapshot_id = 0 OR f.snapshot_id = @snapshot_id)
+	AND (@analysis = '' OR f.analysis = @analysis)
+	AND (@hostname = '' OR f.hostname = @hostname)
+	AND s.timestamp BETWEEN @from AND @to
+ORDER BY s.timestamp ASC, f.analysis ASC, f.hostname ASC, f.interface_name ASC, f.check_name ASC, f.id ASC;
+`
+)
@@ -0,0 +1,1002 @@
+// Package sqlite defines object that stores snapshots in SQLite database.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+)
+
+const (
+	limitInSeconds = 5
+	driverName     = "sqlite"
+)
+
+// Pragmas applied to every connection unless the DSN sets its own.
+// Foreign keys are needed for cascade deletion.
+var defaultPragmas = []string{
+	"_pragma=foreign_keys(1)",
+	"_pragma=busy_timeout(5000)",
+	"_pragma=journal_mode(WAL)",
+}
+
+// tracer starts spans of stored snapshots.
+var tracer = otel.Tracer("github.com/sudeeya/net-monitor/internal/server/repository/sqlite")
+
+var (
+	_ repository.Repository = (*sqLite)(nil)
+	_ repository.Migratable = (*sqLite)(nil)
+	_ repository.Closer     = (*sqLite)(nil)
+)
+
+// sqLite implements the [Repository], [Migratable] and [Closer] interfaces.
+type sqLite struct {
+	logger   *zap.Logger
+	db       *sql.DB
+	migrator repository.Migrator
+	identity repository.Identity
+}
+
+// NewSQLite returns sqLite object to interact with SQLite database.
+// The function opens the database file specified by [dsn], for example "file:net-monitor.db".
+// Devices of stored snapshots are matched with known devices by the [identity] rules.
+// The schema is not changed; it is created and updated by the [Migrator].
+func NewSQLite(logger *zap.Logger, dsn string, identity repository.Identity) (*sqLite, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), limitInSeconds*time.Second)
+	defer cancel()
+
+	logger.Info("Opening the database")
+	db, err := sql.Open(driverName, withDefaultPragmas(dsn))
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer, and every connection to ":memory:" opens a separate database.
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		return nil, err
+	}
+
+	s := &sqLite{
+		logger:   logger,
+		db:       db,
+		identity: identity,
+	}
+
+	s.migrator, err = newMigrator(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// withDefaultPragmas adds default pragmas to the DSN if it does not set any.
+func withDefaultPragmas(dsn string) string {
+	if strings.Contains(dsn, "_pragma=") {
+		return dsn
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+
+	return dsn + separator + strings.Join(defaultPragmas, "&")
+}
+
+// Close implements the [Closer] interface.
+func (s *sqLite) Close() error {
+	s.logger.Info("Closing the database")
+	return s.db.Close()
+}
+
+// StoreSnapshot implements the [Repository] interface.
+// A device state is written only if it differs from the states already stored for the device.
+func (s *sqLite) StoreSnapshot(ctx context.Context, snapshot model.Snapshot) (err error) {
+	s.logger.Info("Storing a snapshot to the database")
+
+	ctx, span := tracer.Start(ctx, "sqlite.StoreSnapshot", trace.WithAttributes(
+		semconv.DBSystemSqlite,
+		attribute.Int("snapshot.devices", len(snapshot.Devices)),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := storeSnapshot(ctx, tx, s.identity, snapshot); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// storeSnapshot inserts the snapshot within the transaction.
+// Devices are matched with stored devices by the identity rules; stored devices get the attributes of the snapshot.
+func storeSnapshot(ctx context.Context, tx *sql.Tx, identity repository.Identity, snapshot model.Snapshot) error {
+	var snapshotID int
+	if err := tx.QueryRowContext(ctx, insertSnapshotQuery,
+		sql.Named("timestamp", toDBFromTime(snapshot.Timestamp)),
+	).Scan(&snapshotID); err != nil {
+		return err
+	}
+
+	stored, err := selectDeviceIdentities(ctx, tx)
+	if err != nil {
+		return err
+	}
+	deviceIDs := identity.Match(stored, snapshot.Devices)
+
+	for deviceIdx, device := range snapshot.Devices {
+		vendorID, err := insertAndSelectID(ctx, tx, insertVendorQuery, selectVendorQuery,
+			sql.Named("vendor", device.Vendor),
+		)
+		if err != nil {
+			return err
+		}
+
+		osID, err := insertAndSelectID(ctx, tx, insertOperatingSystemQuery, selectOperatingSystemQuery,
+			sql.Named("os", device.OSName),
+			sql.Named("version", device.OSVersion),
+		)
+		if err != nil {
+			return err
+		}
+
+		deviceID := deviceIDs[deviceIdx]
+		deviceArgs := []any{
+			sql.Named("id", deviceID),
+			sql.Named("vendor_id", vendorID),
+			sql.Named("operating_system_id", osID),
+			sql.Named("hostname", device.Hostname),
+			sql.Named("serial_number", device.Serial),
+		}
+		if deviceID == 0 {
+			err = tx.QueryRowContext(ctx, insertDeviceQuery, deviceArgs...).Scan(&deviceID)
+		} else {
+			_, err = tx.ExecContext(ctx, updateDeviceQuery, deviceArgs...)
+		}
+		if err != nil {
+			return err
+		}
+
+		deviceStateID, err := storeDeviceState(ctx, tx, deviceID, vendorID, osID, device)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, insertSnapshotDeviceQuery,
+			sql.Named("snapshot_id", snapshotID),
+			sql.Named("device_id", deviceID),
+			sql.Named("device_state_id", deviceStateID),
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// selectDeviceIdentities returns ids, hostnames and serial numbers of stored devices.
+func selectDeviceIdentities(ctx context.Context, tx *sql.Tx) ([]repository.StoredDevice, error) {
+	rows, err := tx.QueryContext(ctx, selectDeviceIdentitiesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := make([]repository.StoredDevice, 0)
+	for rows.Next() {
+		var (
+			device repository.StoredDevice
+			serial sql.NullString
+		)
+		if err := rows.Scan(&device.ID, &device.Hostname, &serial); err != nil {
+			return nil, err
+		}
+		device.Serial = serial.String
+		devices = append(devices, device)
+	}
+
+	return devices, rows.Err()
+}
+
+// storeDeviceState returns the id of the device state equal to the state of [device].
+// If there is no such state, it is inserted together with warnings and interface states.
+func storeDeviceState(ctx context.Context, tx *sql.Tx, deviceID, vendorID, osID int, device model.Device) (int, error) {
+	deviceStateArgs := []any{
+		sql.Named("device_id", deviceID),
+		sql.Named("vendor_id", vendorID),
+		sql.Named("operating_system_id", osID),
+		sql.Named("hostname", device.Hostname),
+		sql.Named("serial_number", device.Serial),
+		sql.Named("is_snapshot_successful", device.IsSnapshotSuccessful),
+		sql.Named("status", string(device.Status)),
+		sql.Named("state_hash", repository.StateHash(device)),
+		sql.Named("running_config", device.RunningConfig),
+	}
+	var deviceStateID int
+	err := tx.QueryRowContext(ctx, insertDeviceStateQuery, deviceStateArgs...).Scan(&deviceStateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = tx.QueryRowContext(ctx, selectDeviceStateQuery, deviceStateArgs...).Scan(&deviceStateID)
+		return deviceStateID, err
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	for _, warning := range device.Warnings {
+		if _, err := tx.ExecContext(ctx, insertDeviceWarningQuery,
+			sql.Named("device_state_id", deviceStateID),
+			sql.Named("command", warning.Command),
+			sql.Named("field", warning.Field),
+			sql.Named("message", warning.Message),
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, iface := range device.Interfaces {
+		ifaceID, err := insertAndSelectID(ctx, tx, insertInterfaceQuery, selectInterfaceQuery,
+			sql.Named("device_id", deviceID),
+			sql.Named("name", iface.Name),
+		)
+		if err != nil {
+			return 0, err
+		}
+
+		if _, err := tx.ExecContext(ctx, insertInterfaceStateQuery,
+			sql.Named("interface_id", ifaceID),
+			sql.Named("device_state_id", deviceStateID),
+			sql.Named("is_up", iface.IsUp),
+			sql.Named("ip", toDBFromPrefix(iface.IP)),
+			sql.Named("mtu", iface.MTU),
+			sql.Named("description", iface.Description),
+			sql.Named("last_change", toDBFromLastChange(iface.LastChange)),
+			sql.Named("neighbor_hostname", iface.NeighborHostname),
+			sql.Named("neighbor_interface", iface.NeighborInterface),
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	return deviceStateID, nil
+}
+
+// insertAndSelectID inserts a row unless it already exists and returns its id.
+// Both queries receive all arguments; SQLite ignores named arguments a query does not use.
+func insertAndSelectID(ctx context.Context, tx *sql.Tx, insertQuery, selectQuery string, args ...any) (int, error) {
+	if _, err := tx.ExecContext(ctx, insertQuery, args...); err != nil {
+		return 0, err
+	}
+
+	var id int
+	if err := tx.QueryRowContext(ctx, selectQuery, args...).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// GetNTimestamps implements the [Repository] interface.
+func (s *sqLite) GetNTimestamps(ctx context.Context, n int) ([]model.Snapshot, error) {
+	s.logger.Sugar().Infof("Getting the last %d timestamps from the database", n)
+
+	return s.getTimestamps(ctx, selectTimestampsQuery, sql.Named("limit", n))
+}
+
+// GetTimestampsBefore implements the [Repository] interface.
+func (s *sqLite) GetTimestampsBefore(ctx context.Context, before time.Time, n int) ([]model.Snapshot, error) {
+	s.logger.Sugar().Infof("Getting %d timestamps before %s from the database", n, before)
+
+	return s.getTimestamps(ctx, selectTimestampsBeforeQuery,
+		sql.Named("before", toDBFromTime(before)),
+		sql.Named("limit", n),
+	)
+}
+
+// GetTimestampsBetween implements the [Repository] interface.
+func (s *sqLite) GetTimestampsBetween(ctx context.Context, from, to time.Time) ([]model.Snapshot, error) {
+	s.logger.Sugar().Infof("Getting timestamps from %s to %s from the database", from, to)
+
+	return s.getTimestamps(ctx, selectTimestampsBetweenQuery,
+		sql.Named("from", toDBFromTime(from)),
+		sql.Named("to", toDBFromTime(to)),
+	)
+}
+
+// GetClosestTimestamp implements the [Repository] interface.
+// Only the last snapshot before and the first snapshot after [at] are compared.
+func (s *sqLite) GetClosestTimestamp(ctx context.Context, at time.Time) (model.Snapshot, error) {
+	s.logger.Sugar().Infof("Getting the timestamp closest to %s from the database", at)
+
+	candidates, err := s.getTimestamps(ctx, selectClosestTimestampsQuery, sql.Named("at", toDBFromTime(at)))
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	return repository.ClosestTimestamp(candidates, at), nil
+}
+
+// getTimestamps returns snapshot ids and timestamps selected by the query.
+func (s *sqLite) getTimestamps(ctx context.Context, query string, args ...any) ([]model.Snapshot, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	timestamps := make([]model.Snapshot, 0)
+	for rows.Next() {
+		var (
+			id        int
+			timestamp int64
+		)
+		if err := rows.Scan(&id, &timestamp); err != nil {
+			return nil, err
+		}
+
+		timestamps = append(timestamps, model.Snapshot{
+			ID:        id,
+			Timestamp: toTimeFromDB(timestamp),
+		})
+	}
+
+	return timestamps, rows.Err()
+}
+
+// GetSnapshot implements the [Repository] interface.
+func (s *sqLite) GetSnapshot(ctx context.Context, id int) (model.Snapshot, error) {
+	s.logger.Info("Getting a snapshot from the database")
+
+	return s.getSnapshot(ctx, id, repository.DeviceFilter{})
+}
+
+// GetFilteredSnapshot implements the [Repository] interface.
+func (s *sqLite) GetFilteredSnapshot(ctx context.Context, id int, filter repository.DeviceFilter) (model.Snapshot, error) {
+	s.logger.Sugar().Infof("Getting a snapshot filtered by %+v from the database", filter)
+
+	return s.getSnapshot(ctx, id, filter)
+}
+
+// getSnapshot returns a snapshot with the devices matching the filter.
+// Warnings and running configurations are selected for every device of the snapshot;
+// those of filtered out devices are not attached.
+func (s *sqLite) getSnapshot(ctx context.Context, id int, filter repository.DeviceFilter) (model.Snapshot, error) {
+	rows, err := s.db.QueryContext(ctx, selectSnapshotQuery,
+		sql.Named("id", id),
+		sql.Named("hostname", filter.Hostname),
+		sql.Named("vendor", filter.Vendor),
+		sql.Named("os_name", filter.OSName),
+		sql.Named("os_version", filter.OSVersion),
+	)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+	defer rows.Close()
+
+	parts := make([]dbSnapshotPart, 0)
+	for rows.Next() {
+		var p dbSnapshotPart
+		if err := rows.Scan(
+			&p.ID,
+			&p.Timestamp,
+			&p.VendorName,
+			&p.OSName,
+			&p.OSVersion,
+			&p.DeviceID,
+			&p.Hostname,
+			&p.SerialNumber,
+			&p.IsSnapshotSuccessful,
+			&p.Status,
+			&p.InterfaceName,
+			&p.IsUp,
+			&p.IP,
+			&p.MTU,
+			&p.Description,
+			&p.LastChange,
+			&p.NeighborHostname,
+			&p.NeighborInterface,
+		); err != nil {
+			return model.Snapshot{}, err
+		}
+		parts = append(parts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return model.Snapshot{}, err
+	}
+	if len(parts) == 0 {
+		return model.Snapshot{}, nil
+	}
+
+	warningRows, err := s.db.QueryContext(ctx, selectWarningsQuery, sql.Named("id", id))
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+	defer warningRows.Close()
+
+	warnings := make([]dbWarning, 0)
+	for warningRows.Next() {
+		var w dbWarning
+		if err := warningRows.Scan(&w.DeviceID, &w.Command, &w.Field, &w.Message); err != nil {
+			return model.Snapshot{}, err
+		}
+		warnings = append(warnings, w)
+	}
+	if err := warningRows.Err(); err != nil {
+		return model.Snapshot{}, err
+	}
+
+	configRows, err := s.db.QueryContext(ctx, selectConfigsQuery, sql.Named("id", id))
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+	defer configRows.Close()
+
+	configs := make([]dbConfig, 0)
+	for configRows.Next() {
+		var c dbConfig
+		if err := configRows.Scan(&c.DeviceID, &c.RunningConfig); err != nil {
+			return model.Snapshot{}, err
+		}
+		configs = append(configs, c)
+	}
+	if err := configRows.Err(); err != nil {
+		return model.Snapshot{}, err
+	}
+
+	return toSnapshotFromDB(parts, warnings, configs)
+}
+
+// GetDeviceHistory implements the [Repository] interface.
+// The queries run in a single transaction, so snapshots and device states are consistent.
+func (s *sqLite) GetDeviceHistory(ctx context.Context, hostname string, from, to time.Time) ([]model.Snapshot, error) {
+	s.logger.Sugar().Infof("Getting the history of %s from the database", hostname)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	histories, err := getDevicesHistory(ctx, tx, hostname, from, to)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, rollbackErr
+		}
+		return nil, err
+	}
+
+	history, ok := histories[hostname]
+	if !ok {
+		history = []model.Snapshot{}
+	}
+
+	return history, tx.Commit()
+}
+
+// GetDevicesHistory implements the [Repository] interface.
+// The queries run in a single transaction, so snapshots and device states are consistent.
+func (s *sqLite) GetDevicesHistory(ctx context.Context, from, to time.Time) (map[string][]model.Snapshot, error) {
+	s.logger.Info("Getting the history of all devices from the database")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	histories, err := getDevicesHistory(ctx, tx, "", from, to)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return nil, rollbackErr
+		}
+		return nil, err
+	}
+
+	return histories, tx.Commit()
+}
+
+// getDevicesHistory selects snapshots with devices whose current hostname is [hostname], or with any device if it is empty,
+// and the device states they refer to within the transaction. Histories are returned by current hostname.
+func getDevicesHistory(ctx context.Context, tx *sql.Tx, hostname string, from, to time.Time) (map[string][]model.Snapshot, error) {
+	args := []any{
+		sql.Named("hostname", hostname),
+		sql.Named("from", toDBFromTime(from)),
+		sql.Named("to", toDBFromTime(to)),
+	}
+
+	rows, err := tx.QueryContext(ctx, selectDeviceSnapshotsQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := make([]dbDeviceSnapshot, 0)
+	for rows.Next() {
+		var ds dbDeviceSnapshot
+		if err := rows.Scan(&ds.ID, &ds.Timestamp, &ds.DeviceStateID, &ds.Hostname); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, ds)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return map[string][]model.Snapshot{}, nil
+	}
+
+	partRows, err := tx.QueryContext(ctx, selectDeviceStatesQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer partRows.Close()
+
+	parts := make([]dbDeviceStatePart, 0)
+	for partRows.Next() {
+		var p dbDeviceStatePart
+		if err := partRows.Scan(
+			&p.DeviceStateID,
+			&p.VendorName,
+			&p.OSName,
+			&p.OSVersion,
+			&p.Hostname,
+			&p.SerialNumber,
+			&p.IsSnapshotSuccessful,
+			&p.Status,
+			&p.InterfaceName,
+			&p.IsUp,
+			&p.IP,
+			&p.MTU,
+			&p.Description,
+			&p.LastChange,
+			&p.NeighborHostname,
+			&p.NeighborInterface,
+		); err != nil {
+			return nil, err
+		}
+		parts = append(parts, p)
+	}
+	if err := partRows.Err(); err != nil {
+		return nil, err
+	}
+
+	warningRows, err := tx.QueryContext(ctx, selectDeviceStateWarningsQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer warningRows.Close()
+
+	warnings := make([]dbDeviceStateWarning, 0)
+	for warningRows.Next() {
+		var w dbDeviceStateWarning
+		if err := warningRows.Scan(&w.DeviceStateID, &w.Command, &w.Field, &w.Message); err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, w)
+	}
+	if err := warningRows.Err(); err != nil {
+		return nil, err
+	}
+
+	configRows, err := tx.QueryContext(ctx, selectDeviceStateConfigsQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer configRows.Close()
+
+	configs := make([]dbDeviceStateConfig, 0)
+	for configRows.Next() {
+		var c dbDeviceStateConfig
+		if err := configRows.Scan(&c.DeviceStateID, &c.RunningConfig); err != nil {
+			return nil, err
+		}
+		configs = append(configs, c)
+	}
+	if err := configRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return toDeviceHistoryFromDB(snapshots, parts, warnings, configs)
+}
+
+// ListDevices implements the [Repository] interface.
+func (s *sqLite) ListDevices(ctx context.Context) ([]repository.StoredDevice, error) {
+	s.logger.Info("Listing devices in the database")
+
+	rows, err := s.db.QueryContext(ctx, selectDevicesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := make([]repository.StoredDevice, 0)
+	for rows.Next() {
+		var d dbDevice
+		if err := rows.Scan(
+			&d.ID,
+			&d.Hostname,
+			&d.SerialNumber,
+			&d.VendorName,
+			&d.OSName,
+			&d.OSVersion,
+			&d.FirstSeen,
+			&d.LastSeen,
+			&d.Snapshots,
+		); err != nil {
+			return nil, err
+		}
+		devices = append(devices, toStoredDeviceFromDB(d))
+	}
+
+	return devices, rows.Err()
+}
+
+// ListAddresses implements the [Repository] interface.
+func (s *sqLite) ListAddresses(ctx context.Context) ([]repository.StoredAddress, error) {
+	s.logger.Info("Listing addresses in the database")
+
+	rows, err := s.db.QueryContext(ctx, selectAddressesQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := make([]repository.StoredAddress, 0)
+	for rows.Next() {
+		var a dbAddress
+		if err := rows.Scan(
+			&a.DeviceID,
+			&a.Hostname,
+			&a.InterfaceName,
+			&a.IP,
+			&a.FirstSeen,
+			&a.LastSeen,
+		); err != nil {
+			return nil, err
+		}
+
+		address, err := toStoredAddressFromDB(a)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+
+	return addresses, rows.Err()
+}
+
+// RenameDevice implements the [Repository] interface.
+func (s *sqLite) RenameDevice(ctx context.Context, id int, hostname string) error {
+	s.logger.Sugar().Infof("Renaming device %d to %s in the database", id, hostname)
+
+	result, err := s.db.ExecContext(ctx, renameDeviceQuery,
+		sql.Named("id", id),
+		sql.Named("hostname", hostname),
+	)
+	if err != nil {
+		return err
+	}
+
+	renamed, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if renamed == 0 {
+		return repository.ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+// MergeDevices implements the [Repository] interface.
+func (s *sqLite) MergeDevices(ctx context.Context, from, into int) error {
+	s.logger.Sugar().Infof("Merging device %d into %d in the database", from, into)
+
+	if from == into {
+		return repository.ErrSameDevice
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := mergeDevices(ctx, tx, from, into); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// mergeDevices merges device [from] into device [into] within the transaction.
+func mergeDevices(ctx context.Context, tx *sql.Tx, from, into int) error {
+	args := []any{
+		sql.Named("from", from),
+		sql.Named("into", into),
+	}
+
+	var found int
+	if err := tx.QueryRowContext(ctx, countMergedDevicesQuery, args...).Scan(&found); err != nil {
+		return err
+	}
+	if found != 2 {
+		return repository.ErrDeviceNotFound
+	}
+
+	var overlapping int
+	if err := tx.QueryRowContext(ctx, countOverlappingSnapshotsQuery, args...).Scan(&overlapping); err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return repository.ErrDevicesOverlap
+	}
+
+	for _, query := range []string{
+		remapDuplicateDeviceStatesQuery,
+		deleteDuplicateDeviceStatesQuery,
+		remapDuplicateInterfacesQuery,
+		deleteDuplicateInterfacesQuery,
+		moveInterfacesQuery,
+		moveDeviceStatesQuery,
+		moveSnapshotDevicesQuery,
+		updateMergedDeviceQuery,
+		deleteMergedDeviceQuery,
+	} {
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// StoreFindings implements the [Repository] interface.
+func (s *sqLite) StoreFindings(ctx context.Context, timestamp time.Time, analysis string, findings []repository.Finding) error {
+	s.logger.Sugar().Infof("Storing %d findings of %s in the database", len(findings), analysis)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := storeFindings(ctx, tx, timestamp, analysis, findings); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// storeFindings replaces the findings of the analysis of the snapshot within the transaction.
+func storeFindings(ctx context.Context, tx *sql.Tx, timestamp time.Time, analysis string, findings []repository.Finding) error {
+	var snapshotID int
+	err := tx.QueryRowContext(ctx, selectSnapshotIDByTimestampQuery, sql.Named("timestamp", toDBFromTime(timestamp))).Scan(&snapshotID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrSnapshotNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteFindingsQuery,
+		sql.Named("snapshot_id", snapshotID),
+		sql.Named("analysis", analysis),
+	); err != nil {
+		return err
+	}
+
+	for _, finding := range findings {
+		if _, err := tx.ExecContext(ctx, insertFindingQuery,
+			sql.Named("snapshot_id", snapshotID),
+			sql.Named("analysis", analysis),
+			sql.Named("hostname", finding.Hostname),
+			sql.Named("interface_name", finding.Interface),
+			sql.Named("check_name", finding.Check),
+			sql.Named("severity", finding.Severity),
+			sql.Named("message", finding.Message),
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetFindings implements the [Repository] interface.
+func (s *sqLite) GetFindings(ctx context.Context, filter repository.FindingFilter) ([]repository.Finding, error) {
+	s.logger.Info("Getting findings from the database")
+
+	from, to := int64(math.MinInt64), int64(math.MaxInt64)
+	if !filter.From.IsZero() {
+		from = toDBFromTime(filter.From)
+	}
+	if !filter.To.IsZero() {
+		to = toDBFromTime(filter.To)
+	}
+
+	rows, err := s.db.QueryContext(ctx, selectFindingsQuery,
+		sql.Named("snapshot_id", filter.SnapshotID),
+		sql.Named("analysis", filter.Analysis),
+		sql.Named("hostname", filter.Hostname),
+		sql.Named("from", from),
+		sql.Named("to", to),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	findings := make([]repository.Finding, 0)
+	for rows.Next() {
+		var f dbFinding
+		if err := rows.Scan(
+			&f.SnapshotID,
+			&f.Timestamp,
+			&f.Analysis,
+			&f.Hostname,
+			&f.InterfaceName,
+			&f.CheckName,
+			&f.Severity,
+			&f.Message,
+		); err != nil {
+			return nil, err
+		}
+		findings = append(findings, toFindingFromDB(f))
+	}
+
+	return findings, rows.Err()
+}
+
+// DeleteSnapshot implements the [Repository] interface.
+// Device states no longer referenced by any snapshot are deleted too.
+func (s *sqLite) DeleteSnapshot(ctx context.Context, id int) error {
+	s.logger.Info("Deleting a snapshot from the database")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := deleteSnapshot(ctx, tx, id); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// deleteSnapshot deletes the snapshot and device states used only by it within the transaction.
+func deleteSnapshot(ctx context.Context, tx *sql.Tx, id int) error {
+	rows, err := tx.QueryContext(ctx, selectSnapshotDeviceStatesQuery, sql.Named("id", id))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	deviceStateIDs := make([]int, 0)
+	for rows.Next() {
+		var deviceStateID int
+		if err := rows.Scan(&deviceStateID); err != nil {
+			return err
+		}
+		deviceStateIDs = append(deviceStateIDs, deviceStateID)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteSnapshotQuery, sql.Named("id", id)); err != nil {
+		return err
+	}
+
+	// SQLite has no arrays, so ids are passed as a JSON array.
+	idsJSON, err := json.Marshal(deviceStateIDs)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteUnusedDeviceStatesQuery, sql.Named("ids", string(idsJSON))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PruneSnapshots implements the [Repository] interface.
+// A dry run deletes rows within a transaction that is rolled back.
+func (s *sqLite) PruneSnapshots(ctx context.Context, ids []int, dryRun bool) (repository.PruneResult, error) {
+	s.logger.Sugar().Infof("Pruning %d snapshots from the database", len(ids))
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return repository.PruneResult{}, err
+	}
+
+	result, err := pruneSnapshots(ctx, tx, ids)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return repository.PruneResult{}, rollbackErr
+		}
+		return repository.PruneResult{}, err
+	}
+
+	if dryRun {
+		return result, tx.Rollback()
+	}
+
+	return result, tx.Commit()
+}
+
+// pruneSnapshots deletes snapshots and orphaned rows within the transaction.
+// Interfaces are deleted before devices, so they are not deleted by cascade and are counted.
+func pruneSnapshots(ctx context.Context, tx *sql.Tx, ids []int) (repository.PruneResult, error) {
+	var result repository.PruneResult
+
+	// SQLite has no arrays, so ids are passed as a JSON array.
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return repository.PruneResult{}, err
+	}
+
+	queries := []struct {
+		query string
+		count *int
+	}{
+		{deleteSnapshotsQuery, &result.Snapshots},
+		{deleteOrphanedDeviceStatesQuery, &result.DeviceStates},
+		{deleteOrphanedInterfacesQuery, &result.Interfaces},
+		{deleteOrphanedDevicesQuery, &result.Devices},
+		{deleteOrphanedVendorsQuery, &result.Vendors},
+		{deleteOrphanedOperatingSystemsQuery, &result.OperatingSystems},
+	}
+
+	for _, q := range queries {
+		res, err := tx.ExecContext(ctx, q.query, sql.Named("ids", string(idsJSON)))
+		if err != nil {
+			return repository.PruneResult{}, err
+		}
+
+		count, err := res.RowsAffected()
+		if err != nil {
+			return repository.PruneResult{}, err
+		}
+		*q.count = int(count)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/migrations"
+	"github.com/sudeeya/net-monitor/internal/server/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T, identity repository.Identity) repository.Repository {
+		s, err := NewSQLite(zap.NewNop(), "file:"+filepath.Join(t.TempDir(), "net-monitor.db"), identity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			if err := s.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+
+		if err := s.Migrator().Up(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		return s
+	})
+}
+
+func BenchmarkStoreSnapshot(b *testing.B) {
+	for _, devices := range []int{100, 1000} {
+		b.Run(fmt.Sprintf("devices=%d", devices), func(b *testing.B) {
+			s, err := NewSQLite(zap.NewNop(), "file:"+filepath.Join(b.TempDir(), "net-monitor.db"), repository.DefaultIdentity())
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer s.Close()
+
+			if err := s.Migrator().Up(context.Background()); err != nil {
+				b.Fatal(err)
+			}
+
+			if err := repositorytest.BenchmarkStoreSnapshot(b, s, devices, 48, 0.05); err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestMigrationsRollBack(t *testing.T) {
+	s, err := NewSQLite(zap.NewNop(), "file:"+filepath.Join(t.TempDir(), "net-monitor.db"), repository.DefaultIdentity())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	if err := s.Migrator().Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := s.Migrator().Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Migrator().Down(ctx, len(statuses)); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err = s.Migrator().Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.Applied {
+			t.Errorf("migration %d is still applied", status.Version)
+		}
+	}
+
+	// The schema is recreated after rolling back every migration.
+	if err := s.Migrator().Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrationFiles(t *testing.T) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := migrations.Load(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) == 0 {
+		t.Fatal("no migrations are embedded")
+	}
+
+	// Embedded migrations are numbered without gaps, so a missing file is noticed.
+	for i, m := range loaded {
+		if m.Version != i+1 {
+			t.Fatalf("migration %d_%s, want version %d", m.Version, m.Name, i+1)
+		}
+	}
+}
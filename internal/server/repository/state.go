@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+// deviceState is the part of a device that may change between snapshots.
+type deviceState struct {
+	Hostname             string
+	Vendor               string
+	OSName               string
+	OSVersion            string
+	Serial               string
+	IsSnapshotSuccessful bool
+	Status               model.DeviceStatus
+	Warnings             []model.Warning
+	Interfaces           []model.Interface
+
+	// Omitted when empty, so states of devices without a collected configuration keep their hashes.
+	RunningConfig string `json:",omitempty"`
+}
+
+// StateHash returns a hash of the device state, which includes the device attributes.
+// Repositories store a device state once and share it between snapshots in which the hash is the same.
+// Interfaces and warnings in a different order give different hashes, because the order is stored.
+func StateHash(device model.Device) string {
+	state := deviceState{
+		Hostname:             device.Hostname,
+		Vendor:               device.Vendor,
+		OSName:               device.OSName,
+		OSVersion:            device.OSVersion,
+		Serial:               device.Serial,
+		IsSnapshotSuccessful: device.IsSnapshotSuccessful,
+		Status:               device.Status,
+		Warnings:             device.Warnings,
+		Interfaces:           device.Interfaces,
+		RunningConfig:        device.RunningConfig,
+	}
+
+	// Nil and empty slices are stored the same way.
+	if len(state.Warnings) == 0 {
+		state.Warnings = nil
+	}
+	if len(state.Interfaces) == 0 {
+		state.Interfaces = nil
+	}
+
+	// Marshaling cannot fail: the state consists of strings, numbers, booleans and IP prefixes.
+	data, _ := json.Marshal(state)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
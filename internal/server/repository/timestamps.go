@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+// ClosestTimestamp returns the timestamp closest to [at] from the candidates.
+// If two timestamps are equally close, the earlier one is returned.
+// Returns an empty snapshot if there are no candidates.
+func ClosestTimestamp(candidates []model.Snapshot, at time.Time) model.Snapshot {
+	var (
+		closest  model.Snapshot
+		distance time.Duration
+	)
+	for i, candidate := range candidates {
+		d := candidate.Timestamp.Sub(at).Abs()
+		if i == 0 || d < distance || d == distance && candidate.Timestamp.Before(closest.Timestamp) {
+			closest = candidate
+			distance = d
+		}
+	}
+
+	return closest
+}
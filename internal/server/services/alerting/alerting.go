@@ -0,0 +1,197 @@
+// Package alerting defines service that evaluates alerting rules against saved snapshots.
+package alerting
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+var _ services.AlertsService = (*alerting)(nil)
+
+// alertKey identifies an alert of a rule on a device or one of its interfaces.
+type alertKey struct {
+	rule     string
+	hostname string
+	iface    string
+}
+
+// alertState describes the state of an alert between snapshots.
+type alertState struct {
+	// Number of consecutive snapshots in which the condition held.
+	count int
+
+	ruleType RuleType
+	alert    services.Alert
+}
+
+// alerting implements the [AlertsService] interface.
+// Alert states are kept in memory, so alerts that were firing before a restart fire again.
+type alerting struct {
+	logger        *zap.Logger
+	rules         []Rule
+	notifications services.NotificationsService
+
+	// Number of recent interface states kept for flapping rules.
+	window int
+
+	mu     sync.Mutex
+	states map[alertKey]*alertState
+
+	// Recent states of interfaces by hostname and interface name, from oldest to newest.
+	interfaces map[string]map[string][]interfaceState
+}
+
+// interfaceState describes an interface as it was in the snapshot taken at the time.
+type interfaceState struct {
+	at    time.Time
+	iface model.Interface
+}
+
+// NewAlerting returns alerting object that evaluates [rules] and sends alerts to [notifications].
+// If [notifications] is nil, alerts are only logged.
+func NewAlerting(logger *zap.Logger, rules []Rule, notifications services.NotificationsService) *alerting {
+	window := 0
+	for _, rule := range rules {
+		if rule.Type == RuleInterfaceFlapping {
+			window = max(window, rule.Window)
+		}
+	}
+
+	return &alerting{
+		logger:        logger,
+		rules:         rules,
+		notifications: notifications,
+		window:        window,
+		states:        make(map[alertKey]*alertState),
+		interfaces:    make(map[string]map[string][]interfaceState),
+	}
+}
+
+// Evaluate implements the [AlertsService] interface.
+// An alert whose condition no longer holds is resolved, including alerts of devices missing from the snapshot.
+// A device that was unreachable does not interrupt the consecutive snapshots of its other alerts.
+func (a *alerting) Evaluate(_ context.Context, snapshot model.Snapshot) []services.Alert {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.recordInterfaces(snapshot)
+
+	alerts := make([]services.Alert, 0)
+	seen := make(map[alertKey]bool)
+
+	for ruleIdx := range a.rules {
+		rule := &a.rules[ruleIdx]
+		for _, device := range snapshot.Devices {
+			for _, c := range rule.conditions(device, a.interfaces[device.Hostname]) {
+				key := alertKey{rule: rule.Name, hostname: c.hostname, iface: c.iface}
+				seen[key] = true
+
+				state, ok := a.states[key]
+				if !ok {
+					state = &alertState{
+						ruleType: rule.Type,
+						alert: services.Alert{
+							Rule:      rule.Name,
+							Severity:  rule.Severity,
+							Hostname:  c.hostname,
+							Interface: c.iface,
+						},
+					}
+					a.states[key] = state
+				}
+				state.count++
+				state.alert.Message = c.message
+
+				if state.alert.State != services.AlertFiring && state.count >= rule.For {
+					state.alert.State = services.AlertFiring
+					state.alert.StartsAt = snapshot.Timestamp
+					alerts = append(alerts, state.alert)
+				}
+			}
+		}
+	}
+
+	// Interfaces and versions of unreachable devices are unknown, so their alerts are kept as they are.
+	unreachable := make(map[string]bool)
+	for _, device := range snapshot.Devices {
+		if device.Status == model.StatusFailure {
+			unreachable[device.Hostname] = true
+		}
+	}
+
+	resolved := make([]services.Alert, 0)
+	for key, state := range a.states {
+		if seen[key] || unreachable[key.hostname] && state.ruleType != RuleDeviceUnreachable {
+			continue
+		}
+
+		if state.alert.State == services.AlertFiring {
+			state.alert.State = services.AlertResolved
+			state.alert.EndsAt = snapshot.Timestamp
+			resolved = append(resolved, state.alert)
+		}
+		delete(a.states, key)
+	}
+	sort.Slice(resolved, func(i, j int) bool {
+		if resolved[i].Rule != resolved[j].Rule {
+			return resolved[i].Rule < resolved[j].Rule
+		}
+		if resolved[i].Hostname != resolved[j].Hostname {
+			return resolved[i].Hostname < resolved[j].Hostname
+		}
+		return resolved[i].Interface < resolved[j].Interface
+	})
+	alerts = append(alerts, resolved...)
+
+	for _, alert := range alerts {
+		if alert.State == services.AlertFiring {
+			a.logger.Sugar().Warnf("Alert %s is firing: %s", alert.Rule, alert.Message)
+		} else {
+			a.logger.Sugar().Infof("Alert %s is resolved: %s", alert.Rule, alert.Message)
+		}
+	}
+
+	if a.notifications != nil && len(alerts) > 0 {
+		a.notifications.Notify(alerts)
+	}
+
+	return alerts
+}
+
+// recordInterfaces appends the interface states of the snapshot to the recent states.
+// States of unreachable devices are kept as they are; states of devices and interfaces missing from the snapshot are forgotten.
+func (a *alerting) recordInterfaces(snapshot model.Snapshot) {
+	if a.window == 0 {
+		return
+	}
+
+	interfaces := make(map[string]map[string][]interfaceState, len(snapshot.Devices))
+	for _, device := range snapshot.Devices {
+		prev := a.interfaces[device.Hostname]
+		if device.Status == model.StatusFailure {
+			if prev != nil {
+				interfaces[device.Hostname] = prev
+			}
+			continue
+		}
+
+		states := make(map[string][]interfaceState, len(device.Interfaces))
+		for _, iface := range device.Interfaces {
+			recent := append(prev[iface.Name], interfaceState{at: snapshot.Timestamp, iface: iface})
+			if len(recent) > a.window {
+				recent = recent[len(recent)-a.window:]
+			}
+			states[iface.Name] = recent
+		}
+		interfaces[device.Hostname] = states
+	}
+
+	a.interfaces = interfaces
+}
@@ -0,0 +1,255 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// RuleType describes the condition checked by a rule.
+type RuleType string
+
+// Possible rule types.
+const (
+	// RuleDeviceUnreachable fires for a device that could not be reached.
+	RuleDeviceUnreachable RuleType = "device_unreachable"
+
+	// RuleInterfaceDown fires for an interface that is down.
+	RuleInterfaceDown RuleType = "interface_down"
+
+	// RuleInterfaceFlapping fires for an interface that went down at least Flaps times in the last Window snapshots.
+	// An interface whose last change moved while its state stayed the same went down between snapshots.
+	RuleInterfaceFlapping RuleType = "interface_flapping"
+
+	// RuleOSVersionNotAllowed fires for a device running an OS version that is not in the allowed list.
+	RuleOSVersionNotAllowed RuleType = "os_version_not_allowed"
+)
+
+// Defaults of rule settings.
+const (
+	defaultSeverity = "warning"
+	defaultWindow   = 10
+	defaultFlaps    = 3
+)
+
+// Rule describes an alerting rule.
+type Rule struct {
+	// Name identifies the rule in alerts and must be unique.
+	Name     string   `json:"name"`
+	Type     RuleType `json:"type"`
+	Severity string   `json:"severity"`
+
+	// Regular expressions that hostnames and, for interface rules, interface names and descriptions must match.
+	// An empty expression matches everything, including interfaces without a description.
+	Hostname    string `json:"hostname"`
+	Interface   string `json:"interface"`
+	Description string `json:"description"`
+
+	// Allowed OS versions of the [RuleOSVersionNotAllowed] rule.
+	Allowed []string `json:"allowed"`
+
+	// Number of snapshots and of times an interface went down in them that make the [RuleInterfaceFlapping] rule fire,
+	// 10 and 3 by default.
+	Window int `json:"window"`
+	Flaps  int `json:"flaps"`
+
+	// Number of consecutive snapshots in which the condition must hold before the rule fires, 1 by default.
+	For int `json:"for"`
+
+	hostname    *regexp.Regexp
+	iface       *regexp.Regexp
+	description *regexp.Regexp
+}
+
+// rulesFile describes the file with alerting rules.
+type rulesFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+// LoadRules reads alerting rules from a json file at [path] and validates them.
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rf rulesFile
+	if err := json.Unmarshal(data, &rf); err != nil {
+		return nil, fmt.Errorf("alerting rules %s: %w", path, err)
+	}
+
+	names := make(map[string]bool, len(rf.Rules))
+	for ruleIdx := range rf.Rules {
+		rule := &rf.Rules[ruleIdx]
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("alerting rule %q: %w", rule.Name, err)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("alerting rule %q is declared twice", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+
+	return rf.Rules, nil
+}
+
+// compile validates the rule, sets defaults and compiles its expressions.
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is not set")
+	}
+
+	switch r.Type {
+	case RuleDeviceUnreachable:
+	case RuleInterfaceDown:
+	case RuleInterfaceFlapping:
+		if r.Window == 0 {
+			r.Window = defaultWindow
+		}
+		if r.Flaps == 0 {
+			r.Flaps = defaultFlaps
+		}
+		if r.Window < 2 || r.Flaps < 0 || r.Flaps >= r.Window {
+			return fmt.Errorf("flaps must be positive and less than window")
+		}
+	case RuleOSVersionNotAllowed:
+		if len(r.Allowed) == 0 {
+			return fmt.Errorf("allowed OS versions are not set")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", r.Type)
+	}
+
+	if r.Interface != "" && r.Type != RuleInterfaceDown && r.Type != RuleInterfaceFlapping {
+		return fmt.Errorf("interface is only supported by interface rules")
+	}
+	if r.Description != "" && r.Type != RuleInterfaceDown && r.Type != RuleInterfaceFlapping {
+		return fmt.Errorf("description is only supported by interface rules")
+	}
+
+	if r.Severity == "" {
+		r.Severity = defaultSeverity
+	}
+
+	if r.For < 0 {
+		return fmt.Errorf("for must not be negative")
+	}
+	if r.For == 0 {
+		r.For = 1
+	}
+
+	var err error
+	if r.hostname, err = regexp.Compile(r.Hostname); err != nil {
+		return fmt.Errorf("hostname: %w", err)
+	}
+	if r.iface, err = regexp.Compile(r.Interface); err != nil {
+		return fmt.Errorf("interface: %w", err)
+	}
+	if r.description, err = regexp.Compile(r.Description); err != nil {
+		return fmt.Errorf("description: %w", err)
+	}
+
+	return nil
+}
+
+// condition describes a rule condition holding for a device or one of its interfaces.
+type condition struct {
+	hostname string
+	iface    string
+	message  string
+}
+
+// conditions returns the conditions of the rule holding for the device.
+// [states] holds the recent states of the device interfaces, from oldest to newest, including the current snapshot.
+func (r *Rule) conditions(device model.Device, states map[string][]interfaceState) []condition {
+	if !r.hostname.MatchString(device.Hostname) {
+		return nil
+	}
+
+	switch r.Type {
+	case RuleDeviceUnreachable:
+		if device.Status != model.StatusFailure {
+			return nil
+		}
+
+		return []condition{{
+			hostname: device.Hostname,
+			message:  fmt.Sprintf("device %s is unreachable", device.Hostname),
+		}}
+	case RuleInterfaceDown:
+		conditions := make([]condition, 0)
+		for _, iface := range device.Interfaces {
+			if iface.IsUp || !r.matchInterface(iface) {
+				continue
+			}
+
+			conditions = append(conditions, condition{
+				hostname: device.Hostname,
+				iface:    iface.Name,
+				message:  fmt.Sprintf("interface %s of %s is down", describe(iface), device.Hostname),
+			})
+		}
+
+		return conditions
+	case RuleInterfaceFlapping:
+		conditions := make([]condition, 0)
+		for _, iface := range device.Interfaces {
+			if !r.matchInterface(iface) {
+				continue
+			}
+
+			recent := states[iface.Name]
+			if len(recent) > r.Window {
+				recent = recent[len(recent)-r.Window:]
+			}
+			downs := 0
+			for i := 1; i < len(recent); i++ {
+				if services.WentDown(recent[i-1].iface, recent[i-1].at, recent[i].iface) {
+					downs++
+				}
+			}
+			if downs < r.Flaps {
+				continue
+			}
+
+			conditions = append(conditions, condition{
+				hostname: device.Hostname,
+				iface:    iface.Name,
+				message:  fmt.Sprintf("interface %s of %s went down %d times in %d snapshots", describe(iface), device.Hostname, downs, len(recent)),
+			})
+		}
+
+		return conditions
+	case RuleOSVersionNotAllowed:
+		// Versions of unreachable devices are unknown.
+		if device.Status == model.StatusFailure || slices.Contains(r.Allowed, device.OSVersion) {
+			return nil
+		}
+
+		return []condition{{
+			hostname: device.Hostname,
+			message:  fmt.Sprintf("%s runs %s %s, which is not allowed", device.Hostname, device.OSName, device.OSVersion),
+		}}
+	default:
+		return nil
+	}
+}
+
+// matchInterface reports whether the name and the description of the interface match the expressions of the rule.
+func (r *Rule) matchInterface(iface model.Interface) bool {
+	return r.iface.MatchString(iface.Name) && r.description.MatchString(iface.Description)
+}
+
+// describe returns the name of the interface followed by its description in parentheses, if it has one.
+func describe(iface model.Interface) string {
+	if iface.Description == "" {
+		return iface.Name
+	}
+
+	return fmt.Sprintf("%s (%s)", iface.Name, iface.Description)
+}
@@ -0,0 +1,112 @@
+package alerting
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+// loadRules writes the rules to a file and loads them.
+func loadRules(t *testing.T, rules string) ([]Rule, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "alert_rules.json")
+	if err := os.WriteFile(path, []byte(rules), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return LoadRules(path)
+}
+
+func TestInterfaceDownMatchesDescription(t *testing.T) {
+	rules, err := loadRules(t, `{"rules": [
+		{"name": "uplink-down", "type": "interface_down", "description": "(?i)uplink"}
+	]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := model.Snapshot{
+		Timestamp: time.Date(2024, time.October, 1, 3, 0, 0, 0, time.UTC),
+		Devices: []model.Device{{
+			Hostname: "leaf1",
+			Status:   model.StatusSuccess,
+			Interfaces: []model.Interface{
+				{Name: "ethernet-1/1", Description: "Uplink to spine1"},
+				{Name: "ethernet-1/2", Description: "server rack 4"},
+				{Name: "ethernet-1/3"},
+				{Name: "ethernet-1/4", IsUp: true, Description: "uplink to spine2"},
+			},
+		}},
+	}
+
+	alerts := NewAlerting(zap.NewNop(), rules, nil).Evaluate(context.Background(), snapshot)
+	if len(alerts) != 1 {
+		t.Fatalf("alerts = %+v, want one", alerts)
+	}
+	if alerts[0].Interface != "ethernet-1/1" {
+		t.Errorf("interface = %s, want ethernet-1/1", alerts[0].Interface)
+	}
+	if want := "interface ethernet-1/1 (Uplink to spine1) of leaf1 is down"; alerts[0].Message != want {
+		t.Errorf("message = %q, want %q", alerts[0].Message, want)
+	}
+}
+
+func TestLoadRulesRejectsInvalidDescription(t *testing.T) {
+	for name, rules := range map[string]string{
+		"invalid expression": `{"rules": [{"name": "x", "type": "interface_down", "description": "("}]}`,
+		"device rule":        `{"rules": [{"name": "x", "type": "device_unreachable", "description": "uplink"}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadRules(t, rules); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestInterfaceFlappingCountsLastChanges(t *testing.T) {
+	rules, err := loadRules(t, `{"rules": [
+		{"name": "flapping", "type": "interface_flapping", "window": 3, "flaps": 2}
+	]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAlerting(zap.NewNop(), rules, nil)
+
+	start := time.Date(2024, time.October, 1, 3, 0, 0, 0, time.UTC)
+	snapshot := func(minutes int, lastChange time.Time) model.Snapshot {
+		return model.Snapshot{
+			Timestamp: start.Add(time.Duration(minutes) * time.Minute),
+			Devices: []model.Device{{
+				Hostname:   "leaf1",
+				Status:     model.StatusSuccess,
+				Interfaces: []model.Interface{{Name: "ethernet-1/1", IsUp: true, LastChange: lastChange}},
+			}},
+		}
+	}
+
+	// The interface is up in every snapshot, but it went down and back up before each of the last two.
+	for _, s := range []model.Snapshot{
+		snapshot(0, start.Add(-time.Hour)),
+		snapshot(10, start.Add(5*time.Minute)),
+	} {
+		if alerts := a.Evaluate(context.Background(), s); len(alerts) != 0 {
+			t.Fatalf("alerts = %+v, want none", alerts)
+		}
+	}
+
+	alerts := a.Evaluate(context.Background(), snapshot(20, start.Add(15*time.Minute)))
+	if len(alerts) != 1 {
+		t.Fatalf("alerts = %+v, want one", alerts)
+	}
+	if want := "interface ethernet-1/1 of leaf1 went down 2 times in 3 snapshots"; alerts[0].Message != want {
+		t.Errorf("message = %q, want %q", alerts[0].Message, want)
+	}
+}
@@ -0,0 +1,190 @@
+// Package archive defines service that exports snapshots to archives and imports them into a [Repository] object.
+//
+// An archive is a gzip-compressed JSON Lines file. The first line is a header describing the archive,
+// and each following line is a snapshot in the JSON format of [model.Snapshot], from oldest to newest.
+package archive
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// Format and version written to the archive header.
+// The version is increased on incompatible changes of the snapshot format.
+const (
+	format  = "net-monitor-snapshots"
+	version = 1
+)
+
+// header is the first line of an archive.
+type header struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+}
+
+// timestampPrecision is the coarsest precision of timestamps stored by repositories.
+// Timestamps closer than it are considered equal on import.
+const timestampPrecision = time.Microsecond
+
+var _ services.ArchiveService = (*archive)(nil)
+
+// archive implements the [ArchiveService] interface.
+type archive struct {
+	logger *zap.Logger
+	repo   repository.Repository
+
+	analyzers []services.SnapshotAnalyzer
+}
+
+// NewArchive returns archive object that reads and stores snapshots using a [Repository] object.
+// [analyzers] are run on every imported snapshot, as they are on every saved one.
+// Alerting rules are not evaluated on import: the snapshots are history, and their alerts would be sent as new.
+func NewArchive(logger *zap.Logger, repo repository.Repository, analyzers ...services.SnapshotAnalyzer) *archive {
+	return &archive{
+		logger:    logger,
+		repo:      repo,
+		analyzers: analyzers,
+	}
+}
+
+// Export implements the [ArchiveService] interface.
+func (a *archive) Export(ctx context.Context, w io.Writer, from, to time.Time) (int, error) {
+	a.logger.Sugar().Infof("Exporting snapshots from %s to %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	timestamps, err := a.repo.GetTimestampsBetween(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+
+	h := header{
+		Format:     format,
+		Version:    version,
+		ExportedAt: time.Now().UTC(),
+		From:       from.UTC(),
+		To:         to.UTC(),
+	}
+	if err := enc.Encode(h); err != nil {
+		return 0, err
+	}
+
+	exported := 0
+	for i := len(timestamps) - 1; i >= 0; i-- {
+		snapshot, err := a.repo.GetSnapshot(ctx, timestamps[i].ID)
+		if err != nil {
+			return exported, err
+		}
+		// The snapshot was deleted after the timestamps were listed.
+		if snapshot.ID == 0 {
+			continue
+		}
+
+		if err := enc.Encode(snapshot); err != nil {
+			return exported, err
+		}
+		exported++
+	}
+
+	if err := zw.Close(); err != nil {
+		return exported, err
+	}
+
+	a.logger.Sugar().Infof("Exported %d snapshots", exported)
+
+	return exported, nil
+}
+
+// Import implements the [ArchiveService] interface.
+func (a *archive) Import(ctx context.Context, r io.Reader) (services.ImportResult, error) {
+	a.logger.Info("Importing snapshots")
+	var result services.ImportResult
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return result, fmt.Errorf("%w: %w", services.ErrInvalidArchive, err)
+	}
+	defer zr.Close()
+
+	dec := json.NewDecoder(zr)
+
+	var h header
+	if err := dec.Decode(&h); err != nil {
+		return result, fmt.Errorf("%w: header: %w", services.ErrInvalidArchive, err)
+	}
+	if h.Format != format {
+		return result, fmt.Errorf("%w: unknown format %q", services.ErrInvalidArchive, h.Format)
+	}
+	if h.Version != version {
+		return result, fmt.Errorf("%w: unsupported version %d", services.ErrInvalidArchive, h.Version)
+	}
+
+	for {
+		var snapshot model.Snapshot
+		err := dec.Decode(&snapshot)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return result, fmt.Errorf("%w: snapshot %d: %w", services.ErrInvalidArchive, result.Imported+result.Skipped+1, err)
+		}
+		if snapshot.Timestamp.IsZero() {
+			return result, fmt.Errorf("%w: snapshot %d has no timestamp", services.ErrInvalidArchive, result.Imported+result.Skipped+1)
+		}
+
+		stored, err := a.isStored(ctx, snapshot.Timestamp)
+		if err != nil {
+			return result, err
+		}
+		if stored {
+			result.Skipped++
+			continue
+		}
+
+		if err := a.repo.StoreSnapshot(ctx, snapshot); err != nil {
+			return result, err
+		}
+		result.Imported++
+
+		// The snapshot is already imported, so failed analyses are only logged.
+		for _, analyzer := range a.analyzers {
+			if err := analyzer.Analyze(ctx, snapshot); err != nil {
+				a.logger.Sugar().Errorf("Failed to analyze the snapshot taken at %s: %v", snapshot.Timestamp.Format(time.RFC3339), err)
+			}
+		}
+	}
+
+	a.logger.Sugar().Infof("Imported %d snapshots, skipped %d already stored", result.Imported, result.Skipped)
+
+	return result, nil
+}
+
+// isStored reports whether a snapshot with the timestamp is already stored.
+func (a *archive) isStored(ctx context.Context, timestamp time.Time) (bool, error) {
+	timestamps, err := a.repo.GetTimestampsBetween(ctx, timestamp.Add(-timestampPrecision), timestamp.Add(timestampPrecision))
+	if err != nil {
+		return false, err
+	}
+
+	for _, t := range timestamps {
+		if d := t.Timestamp.Sub(timestamp); d > -timestampPrecision && d < timestampPrecision {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,64 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/memory"
+)
+
+// fakeAnalyzer records the timestamps of analyzed snapshots.
+type fakeAnalyzer struct {
+	analyzed []time.Time
+}
+
+func (a *fakeAnalyzer) Analyze(_ context.Context, snapshot model.Snapshot) error {
+	a.analyzed = append(a.analyzed, snapshot.Timestamp)
+	return nil
+}
+
+func TestImportRunsAnalyzers(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, time.October, 1, 3, 0, 0, 0, time.UTC)
+
+	src := memory.NewMemory(zap.NewNop(), repository.DefaultIdentity())
+	for i := range 3 {
+		snapshot := model.Snapshot{
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Devices:   []model.Device{{Hostname: "leaf1", Serial: "SN1", IsSnapshotSuccessful: true, Status: model.StatusSuccess}},
+		}
+		if err := src.StoreSnapshot(ctx, snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := NewArchive(zap.NewNop(), src).Export(ctx, &buf, base, base.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := memory.NewMemory(zap.NewNop(), repository.DefaultIdentity())
+	// The second snapshot is already stored, so it is neither imported nor analyzed.
+	if err := dst.StoreSnapshot(ctx, model.Snapshot{Timestamp: base.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+
+	analyzer := &fakeAnalyzer{}
+	result, err := NewArchive(zap.NewNop(), dst, analyzer).Import(ctx, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Imported != 2 || result.Skipped != 1 {
+		t.Errorf("result = %+v, want 2 imported and 1 skipped", result)
+	}
+	if len(analyzer.analyzed) != 2 || !analyzer.analyzed[0].Equal(base) || !analyzer.analyzed[1].Equal(base.Add(2*time.Minute)) {
+		t.Errorf("analyzed = %v, want the first and the third snapshot", analyzer.analyzed)
+	}
+}
@@ -0,0 +1,156 @@
+// Package compliance defines service that checks saved snapshots against compliance policies
+// and keeps per-device reports.
+package compliance
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// Analysis is the name under which failed checks are stored as findings.
+const Analysis = "compliance"
+
+var _ services.ComplianceService = (*compliance)(nil)
+
+// compliance implements the [ComplianceService] interface.
+type compliance struct {
+	logger   *zap.Logger
+	repo     repository.Repository
+	policies []Policy
+}
+
+// NewCompliance returns compliance object that checks snapshots against [policies] loaded by [LoadPolicies].
+func NewCompliance(logger *zap.Logger, repo repository.Repository, policies []Policy) *compliance {
+	return &compliance{
+		logger:   logger,
+		repo:     repo,
+		policies: policies,
+	}
+}
+
+// Analyze implements the [ComplianceService] interface.
+func (c *compliance) Analyze(ctx context.Context, snapshot model.Snapshot) error {
+	c.logger.Sugar().Infof("Checking %d compliance policies", len(c.policies))
+
+	findings := make([]repository.Finding, 0)
+	for _, device := range snapshot.Devices {
+		for policyIdx := range c.policies {
+			policy := &c.policies[policyIdx]
+			for _, v := range policy.violations(device) {
+				findings = append(findings, repository.Finding{
+					Hostname:  device.Hostname,
+					Interface: v.iface,
+					Check:     policy.Name,
+					Severity:  policy.Severity,
+					Message:   v.message,
+				})
+			}
+		}
+	}
+
+	return c.repo.StoreFindings(ctx, snapshot.Timestamp, Analysis, findings)
+}
+
+// GetReport implements the [ComplianceService] interface.
+// Snapshots saved before the policies were configured are reported as passing.
+func (c *compliance) GetReport(ctx context.Context, id int) (services.ComplianceReport, error) {
+	c.logger.Info("Getting a compliance report")
+	if id == 0 {
+		latest, err := c.repo.GetNTimestamps(ctx, 1)
+		if err != nil {
+			return services.ComplianceReport{}, err
+		}
+		if len(latest) == 0 {
+			return services.ComplianceReport{}, nil
+		}
+		id = latest[0].ID
+	}
+
+	snapshot, err := c.repo.GetSnapshot(ctx, id)
+	if err != nil {
+		return services.ComplianceReport{}, err
+	}
+
+	findings, err := c.repo.GetFindings(ctx, repository.FindingFilter{
+		SnapshotID: id,
+		Analysis:   Analysis,
+	})
+	if err != nil {
+		return services.ComplianceReport{}, err
+	}
+
+	failures := make(map[string][]repository.Finding)
+	for _, finding := range findings {
+		failures[finding.Hostname] = append(failures[finding.Hostname], finding)
+	}
+
+	report := services.ComplianceReport{
+		SnapshotID: snapshot.ID,
+		Timestamp:  snapshot.Timestamp,
+		Devices:    make([]services.DeviceCompliance, len(snapshot.Devices)),
+	}
+	for deviceIdx, device := range snapshot.Devices {
+		report.Devices[deviceIdx] = services.DeviceCompliance{
+			SnapshotID: snapshot.ID,
+			Timestamp:  snapshot.Timestamp,
+			Hostname:   device.Hostname,
+			Failures:   failures[device.Hostname],
+		}
+	}
+	sort.SliceStable(report.Devices, func(i, j int) bool {
+		return report.Devices[i].Hostname < report.Devices[j].Hostname
+	})
+
+	return report, nil
+}
+
+// GetDeviceReports implements the [ComplianceService] interface.
+// Snapshots taken before the device was renamed are matched by the hostname it had in them.
+func (c *compliance) GetDeviceReports(ctx context.Context, hostname string, from, to time.Time) ([]services.DeviceCompliance, error) {
+	c.logger.Sugar().Infof("Getting compliance reports of %s", hostname)
+	snapshots, err := c.repo.GetDeviceHistory(ctx, hostname, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	findings, err := c.repo.GetFindings(ctx, repository.FindingFilter{
+		Analysis: Analysis,
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		snapshotID int
+		hostname   string
+	}
+	failures := make(map[key][]repository.Finding)
+	for _, finding := range findings {
+		k := key{finding.SnapshotID, finding.Hostname}
+		failures[k] = append(failures[k], finding)
+	}
+
+	reports := make([]services.DeviceCompliance, 0, len(snapshots))
+	for snapshotIdx := len(snapshots) - 1; snapshotIdx >= 0; snapshotIdx-- {
+		snapshot := snapshots[snapshotIdx]
+		for _, device := range snapshot.Devices {
+			reports = append(reports, services.DeviceCompliance{
+				SnapshotID: snapshot.ID,
+				Timestamp:  snapshot.Timestamp,
+				Hostname:   device.Hostname,
+				Failures:   failures[key{snapshot.ID, device.Hostname}],
+			})
+		}
+	}
+
+	return reports, nil
+}
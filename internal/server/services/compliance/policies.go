@@ -0,0 +1,323 @@
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+// Fields checked by policies.
+const (
+	fieldHostname  = "hostname"
+	fieldVendor    = "vendor"
+	fieldOSName    = "os_name"
+	fieldOSVersion = "os_version"
+	fieldSerial    = "serial_number"
+	fieldStatus    = "status"
+
+	fieldName  = "name"
+	fieldState = "state"
+	fieldIP    = "ip"
+	fieldMTU   = "mtu"
+)
+
+// Default severity of failed checks.
+const defaultSeverity = "warning"
+
+// Policy describes a compliance policy: a condition that a field of every selected device
+// or, if Interface is set, of every selected interface must satisfy.
+// Config conditions check the running configuration of every selected device instead of a field.
+type Policy struct {
+	// Name identifies the policy in findings and must be unique.
+	Name     string `json:"name"`
+	Severity string `json:"severity"`
+
+	// Regular expressions that select the devices the policy applies to.
+	// An empty expression matches everything.
+	Hostname string `json:"hostname"`
+	Vendor   string `json:"vendor"`
+	OSName   string `json:"os_name"`
+
+	// Regular expression that selects interfaces. If it is set, the policy checks interface fields.
+	Interface string `json:"interface"`
+
+	// Field is one of hostname, vendor, os_name, os_version, serial_number and status for devices
+	// and name, state (up or down), ip and mtu for interfaces.
+	Field string `json:"field"`
+
+	// Condition on the field value; exactly one of them or of the config conditions must be set.
+	Equals  *string  `json:"equals"`
+	Matches string   `json:"matches"`
+	OneOf   []string `json:"one_of"`
+
+	// Config conditions: a multiline regular expression the running configuration must match
+	// or a line it must contain, leading and trailing spaces ignored.
+	// They are used without Field and Interface.
+	ConfigMatches string `json:"config_matches"`
+	ConfigLine    string `json:"config_line"`
+
+	hostname      *regexp.Regexp
+	vendor        *regexp.Regexp
+	osName        *regexp.Regexp
+	iface         *regexp.Regexp
+	matches       *regexp.Regexp
+	configMatches *regexp.Regexp
+}
+
+// policiesFile describes the file with compliance policies.
+type policiesFile struct {
+	Policies []Policy `json:"policies"`
+}
+
+// LoadPolicies reads compliance policies from a json file at [path] and validates them.
+func LoadPolicies(path string) ([]Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pf policiesFile
+	if err := json.Unmarshal(data, &pf); err != nil {
+		return nil, fmt.Errorf("compliance policies %s: %w", path, err)
+	}
+
+	names := make(map[string]bool, len(pf.Policies))
+	for policyIdx := range pf.Policies {
+		policy := &pf.Policies[policyIdx]
+		if err := policy.compile(); err != nil {
+			return nil, fmt.Errorf("compliance policy %q: %w", policy.Name, err)
+		}
+		if names[policy.Name] {
+			return nil, fmt.Errorf("compliance policy %q is declared twice", policy.Name)
+		}
+		names[policy.Name] = true
+	}
+
+	return pf.Policies, nil
+}
+
+// compile validates the policy, sets defaults and compiles its expressions.
+func (p *Policy) compile() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is not set")
+	}
+
+	configConditions := 0
+	if p.ConfigMatches != "" {
+		configConditions++
+	}
+	if p.ConfigLine != "" {
+		configConditions++
+	}
+
+	switch p.Field {
+	case "":
+		if configConditions == 0 {
+			return fmt.Errorf("field is not set")
+		}
+		if p.Interface != "" {
+			return fmt.Errorf("config conditions do not apply to interfaces")
+		}
+	case fieldHostname, fieldVendor, fieldOSName, fieldOSVersion, fieldSerial, fieldStatus:
+		if p.Interface != "" {
+			return fmt.Errorf("field %s is not an interface field", p.Field)
+		}
+	case fieldName, fieldState, fieldIP, fieldMTU:
+		if p.Interface == "" {
+			return fmt.Errorf("field %s requires interface", p.Field)
+		}
+	default:
+		return fmt.Errorf("unknown field %q", p.Field)
+	}
+
+	if p.Field != "" && configConditions > 0 {
+		return fmt.Errorf("config conditions do not apply to field %s", p.Field)
+	}
+
+	conditions := configConditions
+	if p.Equals != nil {
+		conditions++
+	}
+	if p.Matches != "" {
+		conditions++
+	}
+	if len(p.OneOf) > 0 {
+		conditions++
+	}
+	if conditions != 1 {
+		return fmt.Errorf("exactly one of equals, matches, one_of, config_matches and config_line must be set")
+	}
+
+	if p.Severity == "" {
+		p.Severity = defaultSeverity
+	}
+
+	var err error
+	if p.hostname, err = regexp.Compile(p.Hostname); err != nil {
+		return fmt.Errorf("hostname: %w", err)
+	}
+	if p.vendor, err = regexp.Compile(p.Vendor); err != nil {
+		return fmt.Errorf("vendor: %w", err)
+	}
+	if p.osName, err = regexp.Compile(p.OSName); err != nil {
+		return fmt.Errorf("os_name: %w", err)
+	}
+	if p.iface, err = regexp.Compile(p.Interface); err != nil {
+		return fmt.Errorf("interface: %w", err)
+	}
+	if p.matches, err = regexp.Compile(p.Matches); err != nil {
+		return fmt.Errorf("matches: %w", err)
+	}
+	if p.configMatches, err = regexp.Compile("(?m)" + p.ConfigMatches); err != nil {
+		return fmt.Errorf("config_matches: %w", err)
+	}
+
+	return nil
+}
+
+// violation describes a device or interface that does not satisfy a policy.
+type violation struct {
+	iface   string
+	message string
+}
+
+// violations returns the violations of the policy by the device.
+// Unreachable devices and, by config conditions, devices without a running configuration
+// are not checked, since their data is unknown.
+func (p *Policy) violations(device model.Device) []violation {
+	if device.Status == model.StatusFailure ||
+		!p.hostname.MatchString(device.Hostname) ||
+		!p.vendor.MatchString(device.Vendor) ||
+		!p.osName.MatchString(device.OSName) {
+		return nil
+	}
+
+	if p.Field == "" {
+		if device.RunningConfig == "" {
+			return nil
+		}
+
+		switch {
+		case p.ConfigLine != "" && !hasLine(device.RunningConfig, p.ConfigLine):
+			return []violation{{
+				message: fmt.Sprintf("running config of %s has no line %q", device.Hostname, p.ConfigLine),
+			}}
+		case p.ConfigMatches != "" && !p.configMatches.MatchString(device.RunningConfig):
+			return []violation{{
+				message: fmt.Sprintf("running config of %s does not match %q", device.Hostname, p.ConfigMatches),
+			}}
+		}
+
+		return nil
+	}
+
+	if p.Interface == "" {
+		value := deviceField(device, p.Field)
+		if p.satisfied(value) {
+			return nil
+		}
+
+		return []violation{{
+			message: fmt.Sprintf("%s of %s is %q, %s", p.Field, device.Hostname, value, p.expected()),
+		}}
+	}
+
+	violations := make([]violation, 0)
+	for _, iface := range device.Interfaces {
+		if !p.iface.MatchString(iface.Name) {
+			continue
+		}
+
+		value := interfaceField(iface, p.Field)
+		if p.satisfied(value) {
+			continue
+		}
+
+		violations = append(violations, violation{
+			iface:   iface.Name,
+			message: fmt.Sprintf("%s of interface %s of %s is %q, %s", p.Field, iface.Name, device.Hostname, value, p.expected()),
+		})
+	}
+
+	return violations
+}
+
+// satisfied reports whether the value satisfies the policy condition.
+func (p *Policy) satisfied(value string) bool {
+	switch {
+	case p.Equals != nil:
+		return value == *p.Equals
+	case len(p.OneOf) > 0:
+		return slices.Contains(p.OneOf, value)
+	default:
+		return p.matches.MatchString(value)
+	}
+}
+
+// expected describes the policy condition.
+func (p *Policy) expected() string {
+	switch {
+	case p.Equals != nil:
+		return fmt.Sprintf("expected %q", *p.Equals)
+	case len(p.OneOf) > 0:
+		return fmt.Sprintf("expected one of %q", p.OneOf)
+	default:
+		return fmt.Sprintf("expected to match %q", p.Matches)
+	}
+}
+
+// hasLine reports whether the configuration contains the line, leading and trailing spaces ignored.
+func hasLine(config, line string) bool {
+	line = strings.TrimSpace(line)
+	for _, configLine := range strings.Split(config, "\n") {
+		if strings.TrimSpace(configLine) == line {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deviceField returns the value of the device field.
+func deviceField(device model.Device, field string) string {
+	switch field {
+	case fieldHostname:
+		return device.Hostname
+	case fieldVendor:
+		return device.Vendor
+	case fieldOSName:
+		return device.OSName
+	case fieldOSVersion:
+		return device.OSVersion
+	case fieldSerial:
+		return device.Serial
+	default:
+		return string(device.Status)
+	}
+}
+
+// interfaceField returns the value of the interface field.
+func interfaceField(iface model.Interface, field string) string {
+	switch field {
+	case fieldName:
+		return iface.Name
+	case fieldState:
+		if iface.IsUp {
+			return "up"
+		}
+		return "down"
+	case fieldIP:
+		if !iface.IP.IsValid() {
+			return ""
+		}
+		return iface.IP.String()
+	default:
+		return strconv.FormatInt(iface.MTU, 10)
+	}
+}
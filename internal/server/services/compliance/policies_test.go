@@ -0,0 +1,90 @@
+package compliance
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+const runningConfig = `set / system ntp server 10.0.0.1 admin-state enable
+set / system logging remote-server 10.0.0.2
+set / system ssh-server mgmt admin-state enable
+`
+
+// loadPolicies writes the policies to a file and loads them.
+func loadPolicies(t *testing.T, policies string) ([]Policy, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "policies.json")
+	if err := os.WriteFile(path, []byte(policies), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return LoadPolicies(path)
+}
+
+func TestConfigConditions(t *testing.T) {
+	policies, err := loadPolicies(t, `{"policies": [
+		{"name": "ntp", "config_matches": "^set / system ntp server 10\\.0\\.0\\.\\d+ "},
+		{"name": "syslog", "config_line": "  set / system logging remote-server 10.0.0.2  "},
+		{"name": "banner", "severity": "critical", "config_line": "set / system banner login-banner \"Authorized access only\""}
+	]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	device := model.Device{Hostname: "srl1", Status: model.StatusSuccess, RunningConfig: runningConfig}
+	got := make(map[string][]violation)
+	for _, policy := range policies {
+		if v := policy.violations(device); len(v) > 0 {
+			got[policy.Name] = v
+		}
+	}
+
+	want := map[string][]violation{
+		"banner": {{message: `running config of srl1 has no line "set / system banner login-banner \"Authorized access only\""`}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %+v, want %+v", got, want)
+	}
+	if policies[2].Severity != "critical" || policies[0].Severity != defaultSeverity {
+		t.Errorf("severities = %q, %q", policies[2].Severity, policies[0].Severity)
+	}
+
+	// Devices without a running configuration are not checked.
+	device.RunningConfig = ""
+	for _, policy := range policies {
+		if v := policy.violations(device); len(v) > 0 {
+			t.Errorf("policy %s: violations = %+v, want none without a running config", policy.Name, v)
+		}
+	}
+
+	device.RunningConfig = "set / system ntp server 192.0.2.1 admin-state enable\n"
+	want0 := []violation{{message: `running config of srl1 does not match "^set / system ntp server 10\\.0\\.0\\.\\d+ "`}}
+	if v := policies[0].violations(device); !reflect.DeepEqual(v, want0) {
+		t.Errorf("violations = %+v, want %+v", v, want0)
+	}
+}
+
+func TestLoadPoliciesRejectsInvalidConfigConditions(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies string
+	}{
+		{"with field", `{"policies": [{"name": "p", "field": "vendor", "config_line": "x"}]}`},
+		{"with interface", `{"policies": [{"name": "p", "interface": "eth", "config_line": "x"}]}`},
+		{"both", `{"policies": [{"name": "p", "config_line": "x", "config_matches": "x"}]}`},
+		{"invalid expression", `{"policies": [{"name": "p", "config_matches": "("}]}`},
+		{"no condition", `{"policies": [{"name": "p"}]}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := loadPolicies(t, test.policies); err == nil {
+				t.Error("want an error")
+			}
+		})
+	}
+}
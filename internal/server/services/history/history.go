@@ -0,0 +1,85 @@
+// Package history defines service that builds the history of devices and interfaces from stored snapshots.
+package history
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+var _ services.HistoryService = (*history)(nil)
+
+// history implements the [HistoryService] interface.
+type history struct {
+	logger *zap.Logger
+	repo   repository.Repository
+}
+
+// NewHistory returns history object that reads snapshots from a [Repository] object.
+func NewHistory(logger *zap.Logger, repo repository.Repository) *history {
+	return &history{
+		logger: logger,
+		repo:   repo,
+	}
+}
+
+// GetDeviceHistory implements the [HistoryService] interface.
+func (h *history) GetDeviceHistory(ctx context.Context, hostname string, from, to time.Time) (services.DeviceHistory, error) {
+	h.logger.Sugar().Infof("Getting the history of %s", hostname)
+	snapshots, err := h.repo.GetDeviceHistory(ctx, hostname, from, to)
+	if err != nil {
+		return services.DeviceHistory{}, err
+	}
+
+	return deviceHistory(hostname, snapshots), nil
+}
+
+// GetInterfaceHistory implements the [HistoryService] interface.
+func (h *history) GetInterfaceHistory(ctx context.Context, hostname, name string, from, to time.Time) (services.InterfaceHistory, error) {
+	h.logger.Sugar().Infof("Getting the history of %s on %s", name, hostname)
+	snapshots, err := h.repo.GetDeviceHistory(ctx, hostname, from, to)
+	if err != nil {
+		return services.InterfaceHistory{}, err
+	}
+
+	return interfaceHistory(hostname, name, snapshots), nil
+}
+
+// GetFlappingInterfaces implements the [HistoryService] interface.
+// The histories of all devices are read at once, so the time range should be kept short for large fleets.
+func (h *history) GetFlappingInterfaces(ctx context.Context, from, to time.Time, minDowns int) ([]services.FlappingInterface, error) {
+	h.logger.Sugar().Infof("Getting interfaces that went down at least %d times", minDowns)
+	histories, err := h.repo.GetDevicesHistory(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	flapping := make([]services.FlappingInterface, 0)
+	for hostname, snapshots := range histories {
+		for _, summary := range deviceHistory(hostname, snapshots).Interfaces {
+			if summary.Downs >= minDowns && summary.Downs > 0 {
+				flapping = append(flapping, services.FlappingInterface{
+					Hostname:         hostname,
+					InterfaceSummary: summary,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(flapping, func(i, j int) bool {
+		if flapping[i].Downs != flapping[j].Downs {
+			return flapping[i].Downs > flapping[j].Downs
+		}
+		if flapping[i].Hostname != flapping[j].Hostname {
+			return flapping[i].Hostname < flapping[j].Hostname
+		}
+		return flapping[i].Name < flapping[j].Name
+	})
+
+	return flapping, nil
+}
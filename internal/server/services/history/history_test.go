@@ -0,0 +1,115 @@
+package history
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/memory"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+var start = time.Date(2024, time.October, 1, 3, 0, 0, 0, time.UTC)
+
+// snapshot returns a snapshot taken [minutes] after the start with a device of each of the interface lists.
+func snapshot(minutes int, ifaces map[string][]model.Interface) model.Snapshot {
+	s := model.Snapshot{Timestamp: start.Add(time.Duration(minutes) * time.Minute)}
+	for _, hostname := range []string{"leaf1", "leaf2"} {
+		s.Devices = append(s.Devices, model.Device{
+			Hostname:             hostname,
+			Serial:               hostname,
+			IsSnapshotSuccessful: true,
+			Status:               model.StatusSuccess,
+			Interfaces:           ifaces[hostname],
+		})
+	}
+
+	return s
+}
+
+func TestGetFlappingInterfaces(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemory(zap.NewNop(), repository.DefaultIdentity())
+
+	before := start.Add(-time.Hour)
+	snapshots := []model.Snapshot{
+		snapshot(0, map[string][]model.Interface{
+			"leaf1": {{Name: "ethernet-1/1", IsUp: true, LastChange: before}, {Name: "ethernet-1/2", IsUp: true, LastChange: before}},
+			"leaf2": {{Name: "ethernet-1/1", IsUp: true}},
+		}),
+		// The first interface of leaf1 flapped unseen, the interface of leaf2 went down.
+		snapshot(10, map[string][]model.Interface{
+			"leaf1": {{Name: "ethernet-1/1", IsUp: true, LastChange: start.Add(5 * time.Minute)}, {Name: "ethernet-1/2", IsUp: true, LastChange: before}},
+			"leaf2": {{Name: "ethernet-1/1", IsUp: false}},
+		}),
+		// The first interface of leaf1 went down.
+		snapshot(20, map[string][]model.Interface{
+			"leaf1": {{Name: "ethernet-1/1", IsUp: false, LastChange: start.Add(15 * time.Minute)}, {Name: "ethernet-1/2", IsUp: true, LastChange: before}},
+			"leaf2": {{Name: "ethernet-1/1", IsUp: false}},
+		}),
+	}
+	for _, s := range snapshots {
+		if err := repo.StoreSnapshot(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := NewHistory(zap.NewNop(), repo)
+	flapping, err := h.GetFlappingInterfaces(ctx, start, start.Add(time.Hour), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]int, len(flapping))
+	for _, f := range flapping {
+		got[f.Hostname+" "+f.Name] = f.Downs
+	}
+	want := map[string]int{"leaf1 ethernet-1/1": 2, "leaf2 ethernet-1/1": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("downs = %v, want %v", got, want)
+	}
+	if len(flapping) > 0 && flapping[0].Hostname != "leaf1" {
+		t.Errorf("want the most flapping interface first, got %+v", flapping[0])
+	}
+}
+
+func TestInterfaceHistoryShowsUnseenFlaps(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemory(zap.NewNop(), repository.DefaultIdentity())
+
+	for _, s := range []model.Snapshot{
+		snapshot(0, map[string][]model.Interface{"leaf1": {{Name: "ethernet-1/1", IsUp: true, LastChange: start.Add(-time.Hour)}}}),
+		snapshot(10, map[string][]model.Interface{"leaf1": {{Name: "ethernet-1/1", IsUp: true, LastChange: start.Add(5 * time.Minute)}}}),
+	} {
+		if err := repo.StoreSnapshot(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := NewHistory(zap.NewNop(), repo).GetInterfaceHistory(ctx, "leaf1", "ethernet-1/1", start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if history.Downs != 1 {
+		t.Errorf("downs = %d, want 1", history.Downs)
+	}
+	if len(history.Changes) != 1 {
+		t.Fatalf("changes = %+v, want one", history.Changes)
+	}
+	want := []services.Change{{
+		SnapshotID: history.Changes[0].SnapshotID,
+		Timestamp:  start.Add(10 * time.Minute),
+		Attribute:  services.AttributeLastChange,
+		Old:        "2024-10-01T02:00:00Z",
+		New:        "2024-10-01T03:05:00Z",
+	}}
+	if !reflect.DeepEqual(history.Changes, want) {
+		t.Errorf("changes = %+v, want %+v", history.Changes, want)
+	}
+}
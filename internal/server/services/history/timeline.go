@@ -0,0 +1,188 @@
+package history
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// Interface states as they are shown in changes.
+const (
+	stateUp   = "up"
+	stateDown = "down"
+)
+
+// attribute describes the values of an attribute in two snapshots.
+type attribute struct {
+	name string
+	old  string
+	new  string
+}
+
+// deviceHistory builds the history of a device from snapshots with this device only, sorted from oldest to newest.
+func deviceHistory(hostname string, snapshots []model.Snapshot) services.DeviceHistory {
+	history := services.DeviceHistory{
+		Hostname:   hostname,
+		Snapshots:  len(snapshots),
+		Changes:    []services.Change{},
+		Interfaces: []services.InterfaceSummary{},
+	}
+	if len(snapshots) == 0 {
+		return history
+	}
+
+	history.FirstSeen = snapshots[0].Timestamp
+	history.LastSeen = snapshots[len(snapshots)-1].Timestamp
+	history.Last = snapshots[len(snapshots)-1].Devices[0]
+
+	for i := 1; i < len(snapshots); i++ {
+		history.Changes = append(history.Changes, deviceChanges(snapshots[i], snapshots[i-1].Devices[0])...)
+	}
+
+	for _, iface := range interfaceHistories(hostname, snapshots, func(string) bool { return true }) {
+		summary := services.InterfaceSummary{
+			Name:    iface.Name,
+			Last:    iface.Last,
+			Changes: len(iface.Changes),
+			Downs:   iface.Downs,
+		}
+		if len(iface.Changes) > 0 {
+			summary.LastChange = iface.Changes[len(iface.Changes)-1].Timestamp
+		}
+		history.Interfaces = append(history.Interfaces, summary)
+	}
+
+	return history
+}
+
+// interfaceHistory builds the history of an interface from snapshots with its device only, sorted from oldest to newest.
+func interfaceHistory(hostname, name string, snapshots []model.Snapshot) services.InterfaceHistory {
+	histories := interfaceHistories(hostname, snapshots, func(n string) bool { return n == name })
+	if len(histories) == 0 {
+		return services.InterfaceHistory{
+			Hostname: hostname,
+			Name:     name,
+			Changes:  []services.Change{},
+		}
+	}
+
+	return *histories[0]
+}
+
+// interfaceHistories builds histories of interfaces whose names match, in the order they first appear.
+// An interface missing from a snapshot, for example because the device was unreachable,
+// keeps its last known state, so its absence is not a change.
+func interfaceHistories(hostname string, snapshots []model.Snapshot, match func(name string) bool) []*services.InterfaceHistory {
+	histories := make([]*services.InterfaceHistory, 0)
+	byName := make(map[string]*services.InterfaceHistory)
+	for _, snapshot := range snapshots {
+		for _, iface := range snapshot.Devices[0].Interfaces {
+			if !match(iface.Name) {
+				continue
+			}
+
+			history, ok := byName[iface.Name]
+			if !ok {
+				history = &services.InterfaceHistory{
+					Hostname:  hostname,
+					Name:      iface.Name,
+					FirstSeen: snapshot.Timestamp,
+					Changes:   []services.Change{},
+				}
+				byName[iface.Name] = history
+				histories = append(histories, history)
+			} else {
+				if services.WentDown(history.Last, history.LastSeen, iface) {
+					history.Downs++
+				}
+				history.Changes = append(history.Changes, interfaceChanges(snapshot, history.LastSeen, history.Last, iface)...)
+			}
+
+			history.Snapshots++
+			history.LastSeen = snapshot.Timestamp
+			history.Last = iface
+		}
+	}
+
+	return histories
+}
+
+// deviceChanges returns changes of the device attributes from [prev] to the device of the snapshot.
+func deviceChanges(snapshot model.Snapshot, prev model.Device) []services.Change {
+	device := snapshot.Devices[0]
+
+	attributes := []attribute{
+		{services.AttributeHostname, prev.Hostname, device.Hostname},
+		{services.AttributeVendor, prev.Vendor, device.Vendor},
+		{services.AttributeOSName, prev.OSName, device.OSName},
+		{services.AttributeOSVersion, prev.OSVersion, device.OSVersion},
+		{services.AttributeSerial, prev.Serial, device.Serial},
+		{services.AttributeStatus, string(prev.Status), string(device.Status)},
+	}
+
+	return changes(snapshot, attributes)
+}
+
+// interfaceChanges returns changes of the interface attributes from [prev], seen in the snapshot taken at [prevAt], to [iface].
+// The last change is shown only if the state changed after [prevAt] but is the same, since it changes with every state change.
+func interfaceChanges(snapshot model.Snapshot, prevAt time.Time, prev, iface model.Interface) []services.Change {
+	attributes := []attribute{
+		{services.AttributeState, interfaceState(prev), interfaceState(iface)},
+		{services.AttributeIP, interfaceIP(prev), interfaceIP(iface)},
+		{services.AttributeMTU, strconv.FormatInt(prev.MTU, 10), strconv.FormatInt(iface.MTU, 10)},
+	}
+	if prev.IsUp == iface.IsUp && iface.LastChange.After(prevAt) {
+		attributes = append(attributes, attribute{services.AttributeLastChange, interfaceLastChange(prev), interfaceLastChange(iface)})
+	}
+
+	return changes(snapshot, attributes)
+}
+
+// changes returns a change for every attribute whose value differs.
+func changes(snapshot model.Snapshot, attributes []attribute) []services.Change {
+	changes := make([]services.Change, 0)
+	for _, a := range attributes {
+		if a.old == a.new {
+			continue
+		}
+
+		changes = append(changes, services.Change{
+			SnapshotID: snapshot.ID,
+			Timestamp:  snapshot.Timestamp,
+			Attribute:  a.name,
+			Old:        a.old,
+			New:        a.new,
+		})
+	}
+
+	return changes
+}
+
+// interfaceState returns the interface state as it is shown in changes.
+func interfaceState(iface model.Interface) string {
+	if iface.IsUp {
+		return stateUp
+	}
+
+	return stateDown
+}
+
+// interfaceLastChange returns the last change of the interface as it is shown in changes.
+func interfaceLastChange(iface model.Interface) string {
+	if iface.LastChange.IsZero() {
+		return "unknown"
+	}
+
+	return iface.LastChange.UTC().Format(time.RFC3339)
+}
+
+// interfaceIP returns the interface IP as it is shown in changes.
+func interfaceIP(iface model.Interface) string {
+	if !iface.IP.IsValid() {
+		return "none"
+	}
+
+	return iface.IP.String()
+}
@@ -0,0 +1,210 @@
+// Package ipam defines service that builds a tree of the subnets in use from the interfaces of snapshots.
+package ipam
+
+import (
+	"context"
+	"math/big"
+	"net/netip"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+var _ services.IPAMService = (*ipam)(nil)
+
+// ipam implements the [IPAMService] interface.
+type ipam struct {
+	logger *zap.Logger
+	repo   repository.Repository
+}
+
+// NewIPAM returns ipam object that builds prefix trees from the snapshots of [repo].
+func NewIPAM(logger *zap.Logger, repo repository.Repository) *ipam {
+	return &ipam{
+		logger: logger,
+		repo:   repo,
+	}
+}
+
+// GetPrefixTree implements the [IPAMService] interface.
+// Link-local addresses are skipped, since every IPv6 interface has one.
+func (i *ipam) GetPrefixTree(ctx context.Context) (services.PrefixTree, error) {
+	i.logger.Info("Getting the prefix tree")
+	latest, err := i.repo.GetNTimestamps(ctx, 1)
+	if err != nil {
+		return services.PrefixTree{}, err
+	}
+	if len(latest) == 0 {
+		return services.PrefixTree{}, nil
+	}
+
+	snapshot, err := i.repo.GetSnapshot(ctx, latest[0].ID)
+	if err != nil {
+		return services.PrefixTree{}, err
+	}
+
+	stored, err := i.repo.ListAddresses(ctx)
+	if err != nil {
+		return services.PrefixTree{}, err
+	}
+
+	return services.PrefixTree{
+		SnapshotID: latest[0].ID,
+		Timestamp:  latest[0].Timestamp,
+		Prefixes:   buildTree(usages(snapshot, stored)),
+	}, nil
+}
+
+// usage describes an address of an interface of the snapshot.
+type usage struct {
+	prefix netip.Prefix
+	services.AddressUsage
+}
+
+// usages returns the addresses of the interfaces of the snapshot with the times they were seen.
+func usages(snapshot model.Snapshot, stored []repository.StoredAddress) []usage {
+	type key struct {
+		hostname string
+		iface    string
+		ip       netip.Prefix
+	}
+	seen := make(map[key]repository.StoredAddress, len(stored))
+	for _, a := range stored {
+		seen[key{a.Hostname, a.Interface, a.IP}] = a
+	}
+
+	usages := make([]usage, 0)
+	for _, device := range snapshot.Devices {
+		for _, iface := range device.Interfaces {
+			if !iface.IP.IsValid() || iface.IP.Addr().IsLinkLocalUnicast() {
+				continue
+			}
+
+			u := usage{
+				prefix: iface.IP,
+				AddressUsage: services.AddressUsage{
+					Address:   iface.IP.Addr(),
+					Hostname:  device.Hostname,
+					Interface: iface.Name,
+					FirstSeen: snapshot.Timestamp,
+					LastSeen:  snapshot.Timestamp,
+				},
+			}
+			// Stored addresses have current hostnames, so devices renamed since the snapshot are not matched.
+			if a, ok := seen[key{device.Hostname, iface.Name, iface.IP}]; ok {
+				u.FirstSeen, u.LastSeen = a.FirstSeen, a.LastSeen
+			}
+
+			usages = append(usages, u)
+		}
+	}
+
+	return usages
+}
+
+// node describes a subnet while the tree is built.
+type node struct {
+	prefix    netip.Prefix
+	addresses []services.AddressUsage
+	children  []*node
+}
+
+// buildTree returns the subnets of the addresses nested by containment.
+func buildTree(usages []usage) []services.PrefixNode {
+	byPrefix := make(map[netip.Prefix]*node)
+	nodes := make([]*node, 0)
+	for _, u := range usages {
+		prefix := u.prefix.Masked()
+		n, ok := byPrefix[prefix]
+		if !ok {
+			n = &node{prefix: prefix}
+			byPrefix[prefix] = n
+			nodes = append(nodes, n)
+		}
+		n.addresses = append(n.addresses, u.AddressUsage)
+	}
+
+	// Enclosing subnets come before the subnets inside them.
+	sort.Slice(nodes, func(a, b int) bool {
+		if c := nodes[a].prefix.Addr().Compare(nodes[b].prefix.Addr()); c != 0 {
+			return c < 0
+		}
+		return nodes[a].prefix.Bits() < nodes[b].prefix.Bits()
+	})
+
+	roots := make([]*node, 0)
+	enclosing := make([]*node, 0)
+	for _, n := range nodes {
+		for len(enclosing) > 0 && !enclosing[len(enclosing)-1].prefix.Contains(n.prefix.Addr()) {
+			enclosing = enclosing[:len(enclosing)-1]
+		}
+
+		if len(enclosing) == 0 {
+			roots = append(roots, n)
+		} else {
+			parent := enclosing[len(enclosing)-1]
+			parent.children = append(parent.children, n)
+		}
+
+		enclosing = append(enclosing, n)
+	}
+
+	tree := make([]services.PrefixNode, len(roots))
+	for rootIdx, root := range roots {
+		tree[rootIdx], _ = toPrefixNode(root)
+	}
+
+	return tree
+}
+
+// toPrefixNode converts the node and returns it with the distinct addresses in use in it.
+func toPrefixNode(n *node) (services.PrefixNode, map[netip.Addr]struct{}) {
+	used := make(map[netip.Addr]struct{})
+	for _, a := range n.addresses {
+		used[a.Address] = struct{}{}
+	}
+
+	children := make([]services.PrefixNode, len(n.children))
+	for childIdx, child := range n.children {
+		var childUsed map[netip.Addr]struct{}
+		children[childIdx], childUsed = toPrefixNode(child)
+		for addr := range childUsed {
+			used[addr] = struct{}{}
+		}
+	}
+
+	sort.SliceStable(n.addresses, func(a, b int) bool {
+		return n.addresses[a].Address.Less(n.addresses[b].Address)
+	})
+
+	usable := size(n.prefix)
+	utilization, _ := new(big.Float).Quo(
+		new(big.Float).SetInt64(int64(len(used))*100),
+		new(big.Float).SetInt(usable),
+	).Float64()
+
+	return services.PrefixNode{
+		Prefix:      n.prefix,
+		Size:        usable.String(),
+		Used:        len(used),
+		Utilization: utilization,
+		Addresses:   n.addresses,
+		Children:    children,
+	}, used
+}
+
+// size returns the number of addresses of the subnet usable by hosts.
+// Network and broadcast addresses of IPv4 subnets are not usable, except in /31 and /32 subnets.
+func size(prefix netip.Prefix) *big.Int {
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	usable := new(big.Int).Lsh(big.NewInt(1), uint(hostBits))
+	if prefix.Addr().Is4() && hostBits >= 2 {
+		usable.Sub(usable, big.NewInt(2))
+	}
+
+	return usable
+}
@@ -0,0 +1,251 @@
+package ipam
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/memory"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// testUsages returns usages of the addresses, given as hostname, interface and prefix.
+func testUsages(values ...string) []usage {
+	usages := make([]usage, 0)
+	for i := 0; i+2 < len(values); i += 3 {
+		prefix := netip.MustParsePrefix(values[i+2])
+		usages = append(usages, usage{
+			prefix: prefix,
+			AddressUsage: services.AddressUsage{
+				Address:   prefix.Addr(),
+				Hostname:  values[i],
+				Interface: values[i+1],
+			},
+		})
+	}
+
+	return usages
+}
+
+// summary returns the nodes of the tree as lines indented by depth.
+func summary(tree []services.PrefixNode) []string {
+	lines := make([]string, 0)
+	var walk func(nodes []services.PrefixNode, depth int)
+	walk = func(nodes []services.PrefixNode, depth int) {
+		for _, n := range nodes {
+			addresses := make([]string, 0, len(n.Addresses))
+			for _, a := range n.Addresses {
+				addresses = append(addresses, a.Address.String())
+			}
+			lines = append(lines, fmt.Sprintf("%s%s size=%s used=%d utilization=%.4g addresses=%v",
+				strings.Repeat("  ", depth), n.Prefix, n.Size, n.Used, n.Utilization, addresses))
+			walk(n.Children, depth+1)
+		}
+	}
+	walk(tree, 0)
+
+	return lines
+}
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{prefix: "10.0.0.0/8", want: "16777214"},
+		{prefix: "10.0.0.0/24", want: "254"},
+		{prefix: "10.0.0.0/30", want: "2"},
+		{prefix: "10.0.0.0/31", want: "2"},
+		{prefix: "10.0.0.1/32", want: "1"},
+		{prefix: "0.0.0.0/0", want: "4294967294"},
+		{prefix: "2001:db8::/48", want: "1208925819614629174706176"},
+		{prefix: "2001:db8::/64", want: "18446744073709551616"},
+		{prefix: "2001:db8::/126", want: "4"},
+		{prefix: "2001:db8::/127", want: "2"},
+		{prefix: "2001:db8::1/128", want: "1"},
+		{prefix: "::/0", want: "340282366920938463463374607431768211456"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if got := size(netip.MustParsePrefix(tt.prefix)).String(); got != tt.want {
+				t.Errorf("size = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	tests := []struct {
+		name   string
+		usages []usage
+		want   []string
+	}{
+		{
+			name: "nested IPv4 subnets",
+			usages: testUsages(
+				"leaf4", "irb0", "10.0.2.1/24",
+				"leaf1", "irb0", "10.0.1.1/24",
+				"leaf1", "ethernet-1/1", "10.0.1.5/30",
+				"spine1", "ethernet-1/3", "10.0.1.6/30",
+				"leaf3", "irb1", "10.0.0.1/16",
+				"leaf2", "irb0", "10.0.1.2/24",
+				// The address of leaf1 is also assigned to leaf2, but it is used once.
+				"leaf2", "irb1", "10.0.1.1/24",
+				"leaf5", "ethernet-1/1", "192.168.0.1/31",
+			),
+			want: []string{
+				"10.0.0.0/16 size=65534 used=6 utilization=0.009156 addresses=[10.0.0.1]",
+				"  10.0.1.0/24 size=254 used=4 utilization=1.575 addresses=[10.0.1.1 10.0.1.1 10.0.1.2]",
+				"    10.0.1.4/30 size=2 used=2 utilization=100 addresses=[10.0.1.5 10.0.1.6]",
+				"  10.0.2.0/24 size=254 used=1 utilization=0.3937 addresses=[10.0.2.1]",
+				"192.168.0.0/31 size=2 used=1 utilization=50 addresses=[192.168.0.1]",
+			},
+		},
+		{
+			name: "full subnets",
+			usages: testUsages(
+				"leaf1", "ethernet-1/1", "10.0.0.0/31",
+				"spine1", "ethernet-1/3", "10.0.0.1/31",
+				"leaf1", "lo0", "10.255.0.1/32",
+				"leaf1", "ethernet-1/2", "2001:db8::/127",
+				"spine2", "ethernet-1/3", "2001:db8::1/127",
+			),
+			want: []string{
+				"10.0.0.0/31 size=2 used=2 utilization=100 addresses=[10.0.0.0 10.0.0.1]",
+				"10.255.0.1/32 size=1 used=1 utilization=100 addresses=[10.255.0.1]",
+				"2001:db8::/127 size=2 used=2 utilization=100 addresses=[2001:db8:: 2001:db8::1]",
+			},
+		},
+		{
+			name: "IPv6 /64 inside a /48",
+			usages: testUsages(
+				"leaf1", "irb0", "2001:db8:0:1::1/64",
+				"core1", "irb0", "2001:db8::1/48",
+				"leaf2", "irb0", "2001:db8:0:1::2/64",
+			),
+			want: []string{
+				"2001:db8::/48 size=1208925819614629174706176 used=3 utilization=2.482e-22 addresses=[2001:db8::1]",
+				"  2001:db8:0:1::/64 size=18446744073709551616 used=2 utilization=1.084e-17 addresses=[2001:db8:0:1::1 2001:db8:0:1::2]",
+			},
+		},
+		{
+			name: "IPv4 and IPv6 subnets are not nested",
+			usages: testUsages(
+				"leaf1", "irb0", "::ffff:10.0.0.1/96",
+				"leaf1", "irb1", "10.0.0.1/24",
+			),
+			want: []string{
+				"10.0.0.0/24 size=254 used=1 utilization=0.3937 addresses=[10.0.0.1]",
+				"::ffff:0.0.0.0/96 size=4294967296 used=1 utilization=2.328e-08 addresses=[::ffff:10.0.0.1]",
+			},
+		},
+		{name: "no addresses", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summary(buildTree(tt.usages)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestUsages(t *testing.T) {
+	firstSeen := time.Date(2024, time.September, 1, 12, 0, 0, 0, time.UTC)
+	timestamp := time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := model.Snapshot{
+		Timestamp: timestamp,
+		Devices: []model.Device{{
+			Hostname: "leaf1",
+			Interfaces: []model.Interface{
+				{Name: "ethernet-1/1", IP: netip.MustParsePrefix("10.0.0.0/31")},
+				{Name: "ethernet-1/2", IP: netip.MustParsePrefix("10.0.0.2/31")},
+				{Name: "ethernet-1/3", IP: netip.MustParsePrefix("fe80::1/64")},
+				{Name: "mgmt0"},
+			},
+		}},
+	}
+	stored := []repository.StoredAddress{
+		{Hostname: "leaf1", Interface: "ethernet-1/1", IP: netip.MustParsePrefix("10.0.0.0/31"), FirstSeen: firstSeen, LastSeen: timestamp},
+		// The interface had another address before.
+		{Hostname: "leaf1", Interface: "ethernet-1/2", IP: netip.MustParsePrefix("10.0.1.2/31"), FirstSeen: firstSeen, LastSeen: firstSeen},
+	}
+
+	want := []usage{
+		{
+			prefix: netip.MustParsePrefix("10.0.0.0/31"),
+			AddressUsage: services.AddressUsage{
+				Address:   netip.MustParseAddr("10.0.0.0"),
+				Hostname:  "leaf1",
+				Interface: "ethernet-1/1",
+				FirstSeen: firstSeen,
+				LastSeen:  timestamp,
+			},
+		},
+		{
+			prefix: netip.MustParsePrefix("10.0.0.2/31"),
+			AddressUsage: services.AddressUsage{
+				Address:   netip.MustParseAddr("10.0.0.2"),
+				Hostname:  "leaf1",
+				Interface: "ethernet-1/2",
+				FirstSeen: timestamp,
+				LastSeen:  timestamp,
+			},
+		},
+	}
+	if got := usages(snapshot, stored); !reflect.DeepEqual(got, want) {
+		t.Errorf("usages = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetPrefixTree(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemory(zap.NewNop(), repository.DefaultIdentity())
+	i := NewIPAM(zap.NewNop(), repo)
+
+	tree, err := i.GetPrefixTree(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tree, services.PrefixTree{}) {
+		t.Errorf("tree without snapshots = %+v, want an empty tree", tree)
+	}
+
+	timestamp := time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := model.Snapshot{
+		Timestamp: timestamp,
+		Devices: []model.Device{{
+			Hostname: "leaf1",
+			Status:   model.StatusSuccess,
+			Interfaces: []model.Interface{
+				{Name: "irb0", IsUp: true, IP: netip.MustParsePrefix("10.0.0.1/24")},
+				{Name: "mgmt0", IsUp: true},
+			},
+		}},
+	}
+	if err := repo.StoreSnapshot(ctx, snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err = i.GetPrefixTree(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"10.0.0.0/24 size=254 used=1 utilization=0.3937 addresses=[10.0.0.1]"}
+	if tree.SnapshotID == 0 || !tree.Timestamp.Equal(timestamp) || !reflect.DeepEqual(summary(tree.Prefixes), want) {
+		t.Errorf("tree = %+v, want %v in the snapshot", tree, want)
+	}
+	if a := tree.Prefixes[0].Addresses[0]; !a.FirstSeen.Equal(timestamp) || !a.LastSeen.Equal(timestamp) {
+		t.Errorf("address = %+v, want it seen in the snapshot", a)
+	}
+}
@@ -0,0 +1,232 @@
+package ipconflicts
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+)
+
+// Checks of the analysis.
+const (
+	// CheckDuplicateAddress is found for interfaces with the same address.
+	CheckDuplicateAddress = "duplicate_address"
+
+	// CheckP2PSubnetMismatch is found for ends of a point-to-point link configured with different subnets:
+	// interfaces of two devices whose subnets overlap, where the enclosing subnet is a /30 or /31 (/126 or /127 for IPv6)
+	// or the enclosed one is and contains the address of the other end.
+	CheckP2PSubnetMismatch = "p2p_subnet_mismatch"
+
+	// CheckOverlappingSubnet is found for interfaces whose subnet is inside the subnet of another interface.
+	CheckOverlappingSubnet = "overlapping_subnet"
+)
+
+// Severities of the checks.
+const (
+	SeverityCritical = "critical"
+	SeverityMajor    = "major"
+	SeverityMinor    = "minor"
+)
+
+// severityRank orders severities from the most to the least severe.
+var severityRank = map[string]int{
+	SeverityCritical: 0,
+	SeverityMajor:    1,
+	SeverityMinor:    2,
+}
+
+// address describes an address assigned to an interface.
+type address struct {
+	hostname string
+	iface    string
+	prefix   netip.Prefix
+}
+
+// String returns the interface and device of the address.
+func (a address) String() string {
+	return fmt.Sprintf("%s on %s", a.iface, a.hostname)
+}
+
+// subnet describes the interfaces with addresses in the same subnet.
+type subnet struct {
+	prefix  netip.Prefix
+	members []address
+}
+
+// detect returns conflicts among the addresses of the devices.
+// Addresses in the ignored prefixes are skipped, as are link-local, loopback and multicast addresses.
+func detect(devices []model.Device, ignored []netip.Prefix) []repository.Finding {
+	addresses := collect(devices, ignored)
+
+	findings := duplicates(addresses)
+	findings = append(findings, overlaps(addresses)...)
+
+	return findings
+}
+
+// collect returns the checked addresses of the devices.
+func collect(devices []model.Device, ignored []netip.Prefix) []address {
+	addresses := make([]address, 0)
+	for _, device := range devices {
+		for _, iface := range device.Interfaces {
+			if !iface.IP.IsValid() || !checked(iface.IP.Addr(), ignored) {
+				continue
+			}
+
+			addresses = append(addresses, address{
+				hostname: device.Hostname,
+				iface:    iface.Name,
+				prefix:   iface.IP,
+			})
+		}
+	}
+
+	return addresses
+}
+
+// checked reports whether conflicts of the address are looked for.
+func checked(addr netip.Addr, ignored []netip.Prefix) bool {
+	if addr.IsUnspecified() || addr.IsLoopback() || addr.IsMulticast() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() {
+		return false
+	}
+
+	for _, prefix := range ignored {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// duplicates returns a finding for every interface whose address is assigned to other interfaces too.
+func duplicates(addresses []address) []repository.Finding {
+	byAddr := make(map[netip.Addr][]address)
+	order := make([]netip.Addr, 0)
+	for _, a := range addresses {
+		if _, ok := byAddr[a.prefix.Addr()]; !ok {
+			order = append(order, a.prefix.Addr())
+		}
+		byAddr[a.prefix.Addr()] = append(byAddr[a.prefix.Addr()], a)
+	}
+
+	findings := make([]repository.Finding, 0)
+	for _, addr := range order {
+		holders := byAddr[addr]
+		if len(holders) < 2 {
+			continue
+		}
+
+		for holderIdx, holder := range holders {
+			others := make([]string, 0, len(holders)-1)
+			for otherIdx, other := range holders {
+				if otherIdx != holderIdx {
+					others = append(others, other.String())
+				}
+			}
+
+			findings = append(findings, finding(holder, CheckDuplicateAddress, SeverityCritical,
+				fmt.Sprintf("address %s of %s is also assigned to %s", addr, holder, strings.Join(others, ", "))))
+		}
+	}
+
+	return findings
+}
+
+// overlaps returns findings for interfaces whose subnet is inside the subnet of another interface.
+// Only the smallest enclosing subnet is reported.
+func overlaps(addresses []address) []repository.Finding {
+	bySubnet := make(map[netip.Prefix]*subnet)
+	subnets := make([]*subnet, 0)
+	for _, a := range addresses {
+		prefix := a.prefix.Masked()
+		s, ok := bySubnet[prefix]
+		if !ok {
+			s = &subnet{prefix: prefix}
+			bySubnet[prefix] = s
+			subnets = append(subnets, s)
+		}
+		s.members = append(s.members, a)
+	}
+
+	// Enclosing subnets come before the subnets inside them.
+	sort.Slice(subnets, func(i, j int) bool {
+		if c := subnets[i].prefix.Addr().Compare(subnets[j].prefix.Addr()); c != 0 {
+			return c < 0
+		}
+		return subnets[i].prefix.Bits() < subnets[j].prefix.Bits()
+	})
+
+	findings := make([]repository.Finding, 0)
+	enclosing := make([]*subnet, 0)
+	for _, s := range subnets {
+		for len(enclosing) > 0 && !enclosing[len(enclosing)-1].prefix.Contains(s.prefix.Addr()) {
+			enclosing = enclosing[:len(enclosing)-1]
+		}
+
+		if len(enclosing) > 0 {
+			findings = append(findings, overlap(enclosing[len(enclosing)-1], s)...)
+		}
+
+		enclosing = append(enclosing, s)
+	}
+
+	return findings
+}
+
+// overlap returns findings for the interfaces of the inner subnet, which is inside the outer one.
+func overlap(outer, inner *subnet) []repository.Finding {
+	findings := make([]repository.Finding, 0)
+	for _, a := range inner.members {
+		if peer, ok := p2pPeer(a, outer); ok {
+			message := fmt.Sprintf("point-to-point link between %s (%s) and %s (%s) has ends in different subnets",
+				a, a.prefix, peer, peer.prefix)
+			findings = append(findings,
+				finding(a, CheckP2PSubnetMismatch, SeverityMajor, message),
+				finding(peer, CheckP2PSubnetMismatch, SeverityMajor, message),
+			)
+			continue
+		}
+
+		findings = append(findings, finding(a, CheckOverlappingSubnet, SeverityMinor,
+			fmt.Sprintf("subnet %s of %s overlaps subnet %s of %s", inner.prefix, a, outer.prefix, outer.members[0])))
+	}
+
+	return findings
+}
+
+// p2pPeer returns the interface of another device in the outer subnet that is the other end
+// of a point-to-point link with the address.
+func p2pPeer(a address, outer *subnet) (address, bool) {
+	for _, peer := range outer.members {
+		if peer.hostname == a.hostname || peer.prefix.Addr() == a.prefix.Addr() {
+			continue
+		}
+		if pointToPoint(peer.prefix) || pointToPoint(a.prefix) && a.prefix.Contains(peer.prefix.Addr()) {
+			return peer, true
+		}
+	}
+
+	return address{}, false
+}
+
+// pointToPoint reports whether the prefix is used for point-to-point links.
+func pointToPoint(prefix netip.Prefix) bool {
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	return hostBits == 1 || hostBits == 2
+}
+
+// finding returns a finding of the check on the interface with the address.
+func finding(a address, check, severity, message string) repository.Finding {
+	return repository.Finding{
+		Hostname:  a.hostname,
+		Interface: a.iface,
+		Check:     check,
+		Severity:  severity,
+		Message:   message,
+	}
+}
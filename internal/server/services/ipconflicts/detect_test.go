@@ -0,0 +1,250 @@
+package ipconflicts
+
+import (
+	"fmt"
+	"net/netip"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+)
+
+// addresses returns devices with interfaces configured with the addresses, given as hostname, interface and prefix.
+func addresses(values ...string) []model.Device {
+	devices := make([]model.Device, 0)
+	byHostname := make(map[string]int)
+	for i := 0; i+2 < len(values); i += 3 {
+		deviceIdx, ok := byHostname[values[i]]
+		if !ok {
+			deviceIdx = len(devices)
+			byHostname[values[i]] = deviceIdx
+			devices = append(devices, model.Device{Hostname: values[i], Status: model.StatusSuccess})
+		}
+
+		devices[deviceIdx].Interfaces = append(devices[deviceIdx].Interfaces, model.Interface{
+			Name: values[i+1],
+			IsUp: true,
+			IP:   netip.MustParsePrefix(values[i+2]),
+		})
+	}
+
+	return devices
+}
+
+// summary returns the findings as sorted "hostname interface check severity" strings.
+func summary(findings []repository.Finding) []string {
+	s := make([]string, 0, len(findings))
+	for _, f := range findings {
+		s = append(s, fmt.Sprintf("%s %s %s %s", f.Hostname, f.Interface, f.Check, f.Severity))
+	}
+	sort.Strings(s)
+
+	return s
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		devices []model.Device
+		ignored []netip.Prefix
+		want    []string
+		// Messages of the findings in the order they are returned, if checked.
+		wantMessages []string
+	}{
+		{
+			name:    "duplicate address",
+			devices: addresses("leaf1", "irb0", "10.0.0.1/24", "leaf2", "irb0", "10.0.0.1/24"),
+			want: []string{
+				"leaf1 irb0 duplicate_address critical",
+				"leaf2 irb0 duplicate_address critical",
+			},
+			wantMessages: []string{
+				"address 10.0.0.1 of irb0 on leaf1 is also assigned to irb0 on leaf2",
+				"address 10.0.0.1 of irb0 on leaf2 is also assigned to irb0 on leaf1",
+			},
+		},
+		{
+			name:    "address assigned three times",
+			devices: addresses("leaf1", "irb0", "10.0.0.1/24", "leaf2", "irb0", "10.0.0.1/24", "leaf3", "irb0", "10.0.0.1/24"),
+			want: []string{
+				"leaf1 irb0 duplicate_address critical",
+				"leaf2 irb0 duplicate_address critical",
+				"leaf3 irb0 duplicate_address critical",
+			},
+			wantMessages: []string{
+				"address 10.0.0.1 of irb0 on leaf1 is also assigned to irb0 on leaf2, irb0 on leaf3",
+				"address 10.0.0.1 of irb0 on leaf2 is also assigned to irb0 on leaf1, irb0 on leaf3",
+				"address 10.0.0.1 of irb0 on leaf3 is also assigned to irb0 on leaf1, irb0 on leaf2",
+			},
+		},
+		{
+			name:    "duplicate address with different masks",
+			devices: addresses("leaf1", "irb0", "10.0.0.1/24", "leaf2", "irb0", "10.0.0.1/25"),
+			want: []string{
+				"leaf1 irb0 duplicate_address critical",
+				"leaf2 irb0 duplicate_address critical",
+				"leaf2 irb0 overlapping_subnet minor",
+			},
+		},
+		{
+			name:    "duplicate IPv6 address",
+			devices: addresses("leaf1", "irb0", "2001:db8::1/64", "leaf2", "irb0", "2001:db8::1/64"),
+			want: []string{
+				"leaf1 irb0 duplicate_address critical",
+				"leaf2 irb0 duplicate_address critical",
+			},
+		},
+		{
+			name:    "overlapping subnet",
+			devices: addresses("leaf1", "irb0", "10.1.0.1/16", "leaf2", "irb0", "10.1.2.1/24"),
+			want:    []string{"leaf2 irb0 overlapping_subnet minor"},
+			wantMessages: []string{
+				"subnet 10.1.2.0/24 of irb0 on leaf2 overlaps subnet 10.1.0.0/16 of irb0 on leaf1",
+			},
+		},
+		{
+			name: "only the smallest enclosing subnet is reported",
+			devices: addresses(
+				"leaf1", "irb0", "10.1.0.1/16",
+				"leaf2", "irb0", "10.1.2.1/24",
+				"leaf3", "irb0", "10.1.2.17/28",
+			),
+			want: []string{
+				"leaf2 irb0 overlapping_subnet minor",
+				"leaf3 irb0 overlapping_subnet minor",
+			},
+			wantMessages: []string{
+				"subnet 10.1.2.0/24 of irb0 on leaf2 overlaps subnet 10.1.0.0/16 of irb0 on leaf1",
+				"subnet 10.1.2.16/28 of irb0 on leaf3 overlaps subnet 10.1.2.0/24 of irb0 on leaf2",
+			},
+		},
+		{
+			name:    "overlapping subnets of the same device",
+			devices: addresses("leaf1", "irb0", "10.0.0.1/24", "leaf1", "ethernet-1/1", "10.0.0.5/30"),
+			want:    []string{"leaf1 ethernet-1/1 overlapping_subnet minor"},
+		},
+		{
+			name:    "adjacent subnets",
+			devices: addresses("leaf1", "irb0", "10.0.0.1/24", "leaf2", "irb0", "10.0.1.1/24", "leaf3", "irb0", "10.0.2.1/23"),
+			want:    []string{},
+		},
+		{
+			name:    "point-to-point link",
+			devices: addresses("leaf1", "ethernet-1/1", "10.0.0.0/31", "spine1", "ethernet-1/3", "10.0.0.1/31"),
+			want:    []string{},
+		},
+		{
+			name:    "point-to-point ends in /31 and /30",
+			devices: addresses("leaf1", "ethernet-1/1", "10.0.0.0/31", "spine1", "ethernet-1/3", "10.0.0.1/30"),
+			want: []string{
+				"leaf1 ethernet-1/1 p2p_subnet_mismatch major",
+				"spine1 ethernet-1/3 p2p_subnet_mismatch major",
+			},
+			wantMessages: []string{
+				"point-to-point link between ethernet-1/1 on leaf1 (10.0.0.0/31) and ethernet-1/3 on spine1 (10.0.0.1/30) has ends in different subnets",
+				"point-to-point link between ethernet-1/1 on leaf1 (10.0.0.0/31) and ethernet-1/3 on spine1 (10.0.0.1/30) has ends in different subnets",
+			},
+		},
+		{
+			name:    "point-to-point end inside a larger subnet that contains the other end",
+			devices: addresses("leaf1", "ethernet-1/1", "10.0.0.0/31", "spine1", "ethernet-1/3", "10.0.0.1/24"),
+			want: []string{
+				"leaf1 ethernet-1/1 p2p_subnet_mismatch major",
+				"spine1 ethernet-1/3 p2p_subnet_mismatch major",
+			},
+		},
+		{
+			name:    "point-to-point subnet inside a larger subnet without the other end",
+			devices: addresses("leaf1", "ethernet-1/1", "10.0.0.2/31", "spine1", "irb0", "10.0.0.1/24"),
+			want:    []string{"leaf1 ethernet-1/1 overlapping_subnet minor"},
+		},
+		{
+			name:    "IPv6 point-to-point ends in /127 and /126",
+			devices: addresses("leaf1", "ethernet-1/1", "2001:db8::/127", "spine1", "ethernet-1/3", "2001:db8::1/126"),
+			want: []string{
+				"leaf1 ethernet-1/1 p2p_subnet_mismatch major",
+				"spine1 ethernet-1/3 p2p_subnet_mismatch major",
+			},
+		},
+		{
+			name: "ignored, link-local and loopback addresses",
+			devices: addresses(
+				"leaf1", "irb0", "10.255.0.1/24", "leaf2", "irb0", "10.255.0.1/24",
+				"leaf1", "ethernet-1/1", "fe80::1/64", "leaf2", "ethernet-1/1", "fe80::1/64",
+				"leaf1", "lo0", "127.0.0.1/8", "leaf2", "lo0", "127.0.0.1/8",
+			),
+			ignored: []netip.Prefix{netip.MustParsePrefix("10.255.0.0/16")},
+			want:    []string{},
+		},
+		{
+			name: "interfaces without addresses",
+			devices: []model.Device{
+				{Hostname: "leaf1", Interfaces: []model.Interface{{Name: "ethernet-1/1"}}},
+				{Hostname: "leaf2", Interfaces: []model.Interface{{Name: "ethernet-1/1"}}},
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := detect(tt.devices, tt.ignored)
+			if got := summary(findings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %q, want %q", got, tt.want)
+			}
+
+			if tt.wantMessages == nil {
+				return
+			}
+			messages := make([]string, 0, len(findings))
+			for _, f := range findings {
+				messages = append(messages, f.Message)
+			}
+			if !reflect.DeepEqual(messages, tt.wantMessages) {
+				t.Errorf("messages = %q, want %q", messages, tt.wantMessages)
+			}
+		})
+	}
+}
+
+func TestParsePrefixes(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []netip.Prefix
+		wantErr bool
+	}{
+		{
+			name:   "prefixes and addresses",
+			values: []string{"10.0.0.1/8", "", "192.0.2.1", "2001:db8::1"},
+			want: []netip.Prefix{
+				netip.MustParsePrefix("10.0.0.0/8"),
+				netip.MustParsePrefix("192.0.2.1/32"),
+				netip.MustParsePrefix("2001:db8::1/128"),
+			},
+		},
+		{name: "none", want: []netip.Prefix{}},
+		{name: "invalid", values: []string{"10.0.0.0/33"}, wantErr: true},
+		{name: "hostname", values: []string{"leaf1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePrefixes(tt.values)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("prefixes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
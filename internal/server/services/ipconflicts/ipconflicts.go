@@ -0,0 +1,122 @@
+// Package ipconflicts defines service that finds duplicate addresses, overlapping subnets
+// and mismatched point-to-point links among the interfaces of saved snapshots.
+package ipconflicts
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"slices"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// Analysis is the name under which conflicts are stored as findings.
+const Analysis = "ip"
+
+var _ services.IPConflictsService = (*ipConflicts)(nil)
+
+// ipConflicts implements the [IPConflictsService] interface.
+type ipConflicts struct {
+	logger  *zap.Logger
+	repo    repository.Repository
+	ignored []netip.Prefix
+}
+
+// NewIPConflicts returns ipConflicts object that skips addresses in the [ignored] prefixes,
+// such as anycast gateways shared by several devices.
+func NewIPConflicts(logger *zap.Logger, repo repository.Repository, ignored []netip.Prefix) *ipConflicts {
+	return &ipConflicts{
+		logger:  logger,
+		repo:    repo,
+		ignored: ignored,
+	}
+}
+
+// ParsePrefixes parses prefixes such as 10.0.0.0/8 or addresses, which are treated as single-address prefixes.
+func ParsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(value)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid prefix %q", value)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+// Analyze implements the [IPConflictsService] interface.
+func (c *ipConflicts) Analyze(ctx context.Context, snapshot model.Snapshot) error {
+	c.logger.Info("Looking for IP conflicts")
+	findings := detect(snapshot.Devices, c.ignored)
+	if len(findings) > 0 {
+		c.logger.Sugar().Infof("Found %d IP conflicts", len(findings))
+	}
+
+	return c.repo.StoreFindings(ctx, snapshot.Timestamp, Analysis, findings)
+}
+
+// GetConflicts implements the [IPConflictsService] interface.
+// An empty report is returned for a snapshot that does not exist.
+func (c *ipConflicts) GetConflicts(ctx context.Context, id int, severity string) (services.IPConflictsReport, error) {
+	c.logger.Info("Getting IP conflicts")
+	var snapshot model.Snapshot
+	if id == 0 {
+		latest, err := c.repo.GetNTimestamps(ctx, 1)
+		if err != nil {
+			return services.IPConflictsReport{}, err
+		}
+		if len(latest) == 0 {
+			return services.IPConflictsReport{}, nil
+		}
+		snapshot = latest[0]
+	} else {
+		var err error
+		snapshot, err = c.repo.GetSnapshot(ctx, id)
+		if err != nil {
+			return services.IPConflictsReport{}, err
+		}
+		if snapshot.ID == 0 {
+			return services.IPConflictsReport{}, nil
+		}
+	}
+
+	findings, err := c.repo.GetFindings(ctx, repository.FindingFilter{
+		SnapshotID: snapshot.ID,
+		Analysis:   Analysis,
+	})
+	if err != nil {
+		return services.IPConflictsReport{}, err
+	}
+
+	if severity != "" {
+		findings = slices.DeleteFunc(findings, func(f repository.Finding) bool {
+			return f.Severity != severity
+		})
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank[findings[i].Severity] < severityRank[findings[j].Severity]
+	})
+
+	return services.IPConflictsReport{
+		SnapshotID: snapshot.ID,
+		Timestamp:  snapshot.Timestamp,
+		Conflicts:  findings,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package ipconflicts
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/memory"
+)
+
+func TestGetConflictsBySeverity(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewMemory(zap.NewNop(), repository.DefaultIdentity())
+
+	// Overlaps are detected in the order of their subnets, so the minor overlap comes before the major mismatch.
+	snapshot := model.Snapshot{
+		Timestamp: time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC),
+		Devices: addresses(
+			"leaf1", "irb0", "10.1.0.1/16", "leaf2", "irb0", "10.1.2.1/24",
+			"leaf1", "ethernet-1/1", "10.2.0.0/31", "spine1", "ethernet-1/3", "10.2.0.1/30",
+			"leaf3", "irb0", "192.0.2.1/24", "leaf4", "irb0", "192.0.2.1/24",
+		),
+	}
+	if err := repo.StoreSnapshot(ctx, snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewIPConflicts(zap.NewNop(), repo, nil)
+	if err := c.Analyze(ctx, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	detected := detect(snapshot.Devices, nil)
+	if detected[2].Severity != SeverityMinor {
+		t.Fatalf("detected %+v, want the minor overlap before the major mismatch", detected)
+	}
+
+	tests := []struct {
+		severity string
+		want     []string
+	}{
+		{
+			severity: "",
+			want: []string{
+				SeverityCritical, SeverityCritical,
+				SeverityMajor, SeverityMajor,
+				SeverityMinor,
+			},
+		},
+		{severity: SeverityCritical, want: []string{SeverityCritical, SeverityCritical}},
+		{severity: SeverityMajor, want: []string{SeverityMajor, SeverityMajor}},
+		{severity: SeverityMinor, want: []string{SeverityMinor}},
+		{severity: "warning", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run("severity="+tt.severity, func(t *testing.T) {
+			report, err := c.GetConflicts(ctx, 0, tt.severity)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			severities := make([]string, 0, len(report.Conflicts))
+			for _, conflict := range report.Conflicts {
+				severities = append(severities, conflict.Severity)
+			}
+			if !reflect.DeepEqual(severities, tt.want) {
+				t.Errorf("severities = %v, want %v", severities, tt.want)
+			}
+		})
+	}
+}
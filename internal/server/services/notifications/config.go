@@ -0,0 +1,189 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/server/notifier"
+	"github.com/sudeeya/net-monitor/internal/server/notifier/chat"
+	"github.com/sudeeya/net-monitor/internal/server/notifier/email"
+	"github.com/sudeeya/net-monitor/internal/server/notifier/webhook"
+)
+
+// Possible channel types.
+const (
+	webhookType = "webhook"
+	emailType   = "email"
+	slackType   = "slack"
+	teamsType   = "teams"
+)
+
+// Defaults of channel delivery settings.
+const (
+	defaultRetries           = 3
+	defaultRetryDelaySeconds = 5
+)
+
+// ChannelConfig describes a notification channel and how alerts are delivered to it.
+type ChannelConfig struct {
+	// Type is one of webhook, email, slack or teams.
+	Type string `json:"type"`
+
+	// URL of webhook, slack and teams channels.
+	URL string `json:"url"`
+
+	// Secret used to sign webhook requests. Requests are not signed if it is empty.
+	Secret string `json:"secret"`
+
+	// SMTP settings of email channels.
+	SMTPAddr string   `json:"smtp_addr"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+
+	// Number of retries of a failed notification and the delay before the first one, which doubles with each retry.
+	// Nil means the default; zero retries disables them.
+	Retries           *int `json:"retries"`
+	RetryDelaySeconds int  `json:"retry_delay_seconds"`
+
+	// Maximum number of notifications per minute; alerts queued meanwhile are sent together.
+	// Zero means no limit.
+	MaxPerMinute int `json:"max_per_minute"`
+}
+
+// Route describes which alerts are sent to which channels.
+// An alert matches a route if it matches all the set conditions.
+type Route struct {
+	Channels []string `json:"channels"`
+
+	Severities []string `json:"severities"`
+
+	// Regular expressions that rule names and hostnames must match.
+	Rule     string `json:"rule"`
+	Hostname string `json:"hostname"`
+
+	rule     *regexp.Regexp
+	hostname *regexp.Regexp
+}
+
+// Config describes notification channels and routes.
+// If there are no routes, every alert is sent to every channel.
+type Config struct {
+	Channels map[string]ChannelConfig `json:"channels"`
+	Routes   []Route                  `json:"routes"`
+}
+
+// LoadConfig reads notification channels and routes from a json file at [path] and validates them.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("notifications %s: %w", path, err)
+	}
+
+	for name, channel := range cfg.Channels {
+		if err := channel.validate(); err != nil {
+			return Config{}, fmt.Errorf("notification channel %s: %w", name, err)
+		}
+	}
+
+	for routeIdx := range cfg.Routes {
+		route := &cfg.Routes[routeIdx]
+		if err := route.compile(cfg.Channels); err != nil {
+			return Config{}, fmt.Errorf("notification route %d: %w", routeIdx+1, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// validate checks that the settings required by the channel type are set.
+func (c ChannelConfig) validate() error {
+	switch c.Type {
+	case webhookType, slackType, teamsType:
+		if c.URL == "" {
+			return fmt.Errorf("url is not set")
+		}
+	case emailType:
+		if c.SMTPAddr == "" || c.From == "" || len(c.To) == 0 {
+			return fmt.Errorf("smtp_addr, from and to must be set")
+		}
+	default:
+		return fmt.Errorf("unknown type %q", c.Type)
+	}
+
+	if c.Retries != nil && *c.Retries < 0 || c.RetryDelaySeconds < 0 || c.MaxPerMinute < 0 {
+		return fmt.Errorf("retries, retry_delay_seconds and max_per_minute must not be negative")
+	}
+
+	return nil
+}
+
+// newChannel returns the channel described by the config.
+func (c ChannelConfig) newChannel() notifier.Channel {
+	switch c.Type {
+	case emailType:
+		return email.NewEmail(c.SMTPAddr, c.Username, c.Password, c.From, c.To)
+	case slackType:
+		return chat.NewSlack(c.URL)
+	case teamsType:
+		return chat.NewTeams(c.URL)
+	default:
+		return webhook.NewWebhook(c.URL, c.Secret)
+	}
+}
+
+// retries returns the number of retries and the delay before the first one.
+func (c ChannelConfig) retries() (int, time.Duration) {
+	retries := defaultRetries
+	if c.Retries != nil {
+		retries = *c.Retries
+	}
+
+	delay := defaultRetryDelaySeconds * time.Second
+	if c.RetryDelaySeconds > 0 {
+		delay = time.Duration(c.RetryDelaySeconds) * time.Second
+	}
+
+	return retries, delay
+}
+
+// compile validates the route and compiles its expressions.
+func (r *Route) compile(channels map[string]ChannelConfig) error {
+	if len(r.Channels) == 0 {
+		return fmt.Errorf("channels are not set")
+	}
+	for _, name := range r.Channels {
+		if _, ok := channels[name]; !ok {
+			return fmt.Errorf("unknown channel %s", name)
+		}
+	}
+
+	var err error
+	if r.rule, err = regexp.Compile(r.Rule); err != nil {
+		return fmt.Errorf("rule: %w", err)
+	}
+	if r.hostname, err = regexp.Compile(r.Hostname); err != nil {
+		return fmt.Errorf("hostname: %w", err)
+	}
+
+	return nil
+}
+
+// matches reports whether the alert with the rule, severity and hostname matches the route.
+func (r *Route) matches(rule, severity, hostname string) bool {
+	if len(r.Severities) > 0 && !slices.Contains(r.Severities, severity) {
+		return false
+	}
+
+	return r.rule.MatchString(rule) && r.hostname.MatchString(hostname)
+}
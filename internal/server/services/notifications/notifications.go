@@ -0,0 +1,213 @@
+// Package notifications defines service that routes alerts to notification channels
+// and delivers them with retries and rate limiting.
+package notifications
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/notifier"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// Limits of queued notifications per channel.
+const (
+	// queueSize is the number of batches of alerts waiting to be sent; newer batches are dropped.
+	queueSize = 100
+
+	// maxBatchSize limits how many queued alerts are merged into a single notification.
+	maxBatchSize = 100
+)
+
+var _ services.NotificationsService = (*notifications)(nil)
+
+// channel describes a notification channel with its delivery settings and queue.
+type channel struct {
+	name string
+	notifier.Channel
+
+	retries    int
+	retryDelay time.Duration
+
+	// Minimum interval between notifications, zero if they are not limited.
+	interval time.Duration
+
+	queue chan []services.Alert
+}
+
+// notifications implements the [NotificationsService] interface.
+type notifications struct {
+	logger   *zap.Logger
+	channels map[string]*channel
+	routes   []Route
+}
+
+// NewNotifications returns notifications object that sends alerts to the channels of [cfg].
+func NewNotifications(logger *zap.Logger, cfg Config) *notifications {
+	channels := make(map[string]*channel, len(cfg.Channels))
+	for name, c := range cfg.Channels {
+		retries, retryDelay := c.retries()
+
+		var interval time.Duration
+		if c.MaxPerMinute > 0 {
+			interval = time.Minute / time.Duration(c.MaxPerMinute)
+		}
+
+		channels[name] = &channel{
+			name:       name,
+			Channel:    c.newChannel(),
+			retries:    retries,
+			retryDelay: retryDelay,
+			interval:   interval,
+			queue:      make(chan []services.Alert, queueSize),
+		}
+	}
+
+	return &notifications{
+		logger:   logger,
+		channels: channels,
+		routes:   cfg.Routes,
+	}
+}
+
+// Notify implements the [NotificationsService] interface.
+// If the queue of a channel is full, the alerts are dropped for it.
+func (n *notifications) Notify(alerts []services.Alert) {
+	batches := make(map[string][]services.Alert)
+	for _, alert := range alerts {
+		for _, name := range n.route(alert) {
+			batches[name] = append(batches[name], alert)
+		}
+	}
+
+	for name, batch := range batches {
+		select {
+		case n.channels[name].queue <- batch:
+		default:
+			n.logger.Sugar().Errorf("Dropped %d alerts for channel %s: queue is full", len(batch), name)
+		}
+	}
+}
+
+// route returns names of the channels to which the alert is sent.
+func (n *notifications) route(alert services.Alert) []string {
+	names := make([]string, 0)
+	if len(n.routes) == 0 {
+		for name := range n.channels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return names
+	}
+
+	seen := make(map[string]bool)
+	for routeIdx := range n.routes {
+		route := &n.routes[routeIdx]
+		if !route.matches(alert.Rule, alert.Severity, alert.Hostname) {
+			continue
+		}
+
+		for _, name := range route.Channels {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// Run implements the [NotificationsService] interface.
+// Each channel is served separately, so a slow channel does not delay the others.
+func (n *notifications) Run(ctx context.Context) {
+	n.logger.Sugar().Infof("Sending notifications to %d channels", len(n.channels))
+
+	var wg sync.WaitGroup
+	for _, c := range n.channels {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n.serve(ctx, c)
+		}()
+	}
+	wg.Wait()
+}
+
+// serve sends queued alerts to the channel until the context is done.
+// Alerts queued while the channel is rate limited are sent together.
+func (n *notifications) serve(ctx context.Context, c *channel) {
+	var next time.Time
+	for {
+		var batch []services.Alert
+		select {
+		case <-ctx.Done():
+			return
+		case batch = <-c.queue:
+		}
+
+		if c.interval > 0 {
+			if !sleep(ctx, time.Until(next)) {
+				return
+			}
+			next = time.Now().Add(c.interval)
+		}
+
+	drain:
+		for len(batch) < maxBatchSize {
+			select {
+			case more := <-c.queue:
+				batch = append(batch, more...)
+			default:
+				break drain
+			}
+		}
+
+		n.send(ctx, c, batch)
+	}
+}
+
+// send sends alerts to the channel, retrying with exponential backoff.
+func (n *notifications) send(ctx context.Context, c *channel, alerts []services.Alert) {
+	delay := c.retryDelay
+	for attempt := 0; ; attempt++ {
+		err := c.Send(ctx, alerts)
+		if err == nil {
+			n.logger.Sugar().Infof("Sent %d alerts to channel %s", len(alerts), c.name)
+			return
+		}
+
+		if attempt == c.retries {
+			n.logger.Sugar().Errorf("Failed to send %d alerts to channel %s: %v", len(alerts), c.name, err)
+			return
+		}
+
+		n.logger.Sugar().Errorf("Failed to send alerts to channel %s, retrying in %s: %v", c.name, delay, err)
+		if !sleep(ctx, delay) {
+			return
+		}
+		delay *= 2
+	}
+}
+
+// sleep waits for [d] and reports whether the context is still active.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
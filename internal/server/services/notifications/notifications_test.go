@@ -0,0 +1,225 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// fakeChannel fails the first sends and records the time and alerts of every send.
+type fakeChannel struct {
+	mu       sync.Mutex
+	failures int
+	sends    []time.Time
+	batches  [][]services.Alert
+	sent     chan struct{}
+}
+
+func newFakeChannel(failures int) *fakeChannel {
+	return &fakeChannel{failures: failures, sent: make(chan struct{}, 10)}
+}
+
+func (c *fakeChannel) Send(_ context.Context, alerts []services.Alert) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sends = append(c.sends, time.Now())
+	if len(c.sends) <= c.failures {
+		return errors.New("unavailable")
+	}
+
+	c.batches = append(c.batches, alerts)
+	c.sent <- struct{}{}
+
+	return nil
+}
+
+// newChannel returns a channel sending to the fake channel.
+func newChannel(name string, fake *fakeChannel, retries int, retryDelay, interval time.Duration) *channel {
+	return &channel{
+		name:       name,
+		Channel:    fake,
+		retries:    retries,
+		retryDelay: retryDelay,
+		interval:   interval,
+		queue:      make(chan []services.Alert, queueSize),
+	}
+}
+
+func TestSendRetriesWithBackoff(t *testing.T) {
+	fake := newFakeChannel(2)
+	n := &notifications{logger: zap.NewNop()}
+
+	n.send(context.Background(), newChannel("webhook", fake, 3, 20*time.Millisecond, 0), []services.Alert{{Rule: "r"}})
+
+	if len(fake.sends) != 3 || len(fake.batches) != 1 {
+		t.Fatalf("sent %d times and delivered %d batches, want 3 and 1", len(fake.sends), len(fake.batches))
+	}
+	// The delay doubles after every failed attempt.
+	if d := fake.sends[1].Sub(fake.sends[0]); d < 20*time.Millisecond {
+		t.Errorf("first retry after %s, want at least 20ms", d)
+	}
+	if d := fake.sends[2].Sub(fake.sends[1]); d < 40*time.Millisecond {
+		t.Errorf("second retry after %s, want at least 40ms", d)
+	}
+}
+
+func TestSendGivesUpAfterRetries(t *testing.T) {
+	fake := newFakeChannel(10)
+	n := &notifications{logger: zap.NewNop()}
+
+	n.send(context.Background(), newChannel("webhook", fake, 2, time.Millisecond, 0), []services.Alert{{Rule: "r"}})
+
+	if len(fake.sends) != 3 {
+		t.Errorf("sent %d times, want 3", len(fake.sends))
+	}
+}
+
+func TestSendStopsWhenCanceled(t *testing.T) {
+	fake := newFakeChannel(10)
+	n := &notifications{logger: zap.NewNop()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	n.send(ctx, newChannel("webhook", fake, 3, time.Hour, 0), []services.Alert{{Rule: "r"}})
+
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("send returned after %s", d)
+	}
+	if len(fake.sends) != 1 {
+		t.Errorf("sent %d times, want 1", len(fake.sends))
+	}
+}
+
+func TestRateLimitMergesQueuedAlerts(t *testing.T) {
+	const interval = 200 * time.Millisecond
+	fake := newFakeChannel(0)
+	n := &notifications{
+		logger:   zap.NewNop(),
+		channels: map[string]*channel{"chat": newChannel("chat", fake, 0, 0, interval)},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Run(ctx)
+
+	n.Notify([]services.Alert{{Rule: "first"}})
+	waitSent(t, fake)
+
+	// Both alerts arrive while the channel is limited and are sent together.
+	n.Notify([]services.Alert{{Rule: "second"}})
+	n.Notify([]services.Alert{{Rule: "third"}})
+	waitSent(t, fake)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	want := [][]services.Alert{{{Rule: "first"}}, {{Rule: "second"}, {Rule: "third"}}}
+	if !reflect.DeepEqual(fake.batches, want) {
+		t.Errorf("batches = %+v, want %+v", fake.batches, want)
+	}
+	if d := fake.sends[1].Sub(fake.sends[0]); d < interval {
+		t.Errorf("second notification after %s, want at least %s", d, interval)
+	}
+}
+
+// waitSent waits until the fake channel delivers a batch.
+func waitSent(t *testing.T, fake *fakeChannel) {
+	t.Helper()
+
+	select {
+	case <-fake.sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification sent")
+	}
+}
+
+func TestRoute(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.json")
+	if err := os.WriteFile(path, []byte(`{
+		"channels": {
+			"oncall": {"type": "webhook", "url": "http://localhost/oncall"},
+			"core": {"type": "slack", "url": "http://localhost/core"},
+			"all": {"type": "teams", "url": "http://localhost/all"}
+		},
+		"routes": [
+			{"channels": ["oncall"], "severities": ["critical"]},
+			{"channels": ["core", "oncall"], "hostname": "^core-", "rule": "down$"},
+			{"channels": ["all"]}
+		]
+	}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := NewNotifications(zap.NewNop(), cfg)
+
+	tests := []struct {
+		name  string
+		alert services.Alert
+		want  []string
+	}{
+		{
+			name:  "critical on core",
+			alert: services.Alert{Rule: "uplink-down", Severity: "critical", Hostname: "core-1"},
+			want:  []string{"oncall", "core", "all"},
+		},
+		{
+			name:  "warning on core",
+			alert: services.Alert{Rule: "uplink-down", Severity: "warning", Hostname: "core-1"},
+			want:  []string{"core", "oncall", "all"},
+		},
+		{
+			name:  "other rule",
+			alert: services.Alert{Rule: "os-version", Severity: "warning", Hostname: "core-1"},
+			want:  []string{"all"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := n.route(tt.alert); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("route = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Without routes, every alert is sent to every channel.
+	n.routes = nil
+	if got, want := n.route(services.Alert{Rule: "os-version"}), []string{"all", "core", "oncall"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("route = %v, want %v", got, want)
+	}
+}
+
+func TestLoadConfigRejectsInvalidRoutes(t *testing.T) {
+	for name, cfg := range map[string]string{
+		"unknown channel":    `{"channels": {}, "routes": [{"channels": ["oncall"]}]}`,
+		"no channels":        `{"channels": {"oncall": {"type": "webhook", "url": "http://localhost"}}, "routes": [{}]}`,
+		"invalid expression": `{"channels": {"oncall": {"type": "webhook", "url": "http://localhost"}}, "routes": [{"channels": ["oncall"], "rule": "("}]}`,
+		"missing smtp":       `{"channels": {"mail": {"type": "email", "to": ["noc@example.com"]}}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notifications.json")
+			if err := os.WriteFile(path, []byte(cfg), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadConfig(path); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+// Special values of policy durations.
+const (
+	forever = "forever"
+	all     = "all"
+)
+
+// Policy describes how densely snapshots younger than MaxAge are kept.
+type Policy struct {
+	// Snapshots older than MaxAge are handled by the next policy. Zero means no limit.
+	MaxAge time.Duration
+
+	// A single snapshot is kept per Resolution interval. Zero means every snapshot is kept.
+	Resolution time.Duration
+}
+
+// ParsePolicies parses a list of "age:resolution" pairs separated by commas,
+// for example "7d:all,90d:1h,forever:1d".
+// Ages must increase, and "forever" may only be the last age.
+// Snapshots older than the last age are deleted.
+// Durations are accepted in [time.ParseDuration] format or as a number of days ("7d") or weeks ("2w").
+func ParsePolicies(s string) ([]Policy, error) {
+	policies := make([]Policy, 0)
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		age, resolution, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid retention policy: %s", pair)
+		}
+
+		var (
+			p   Policy
+			err error
+		)
+
+		if age = strings.TrimSpace(age); age != forever {
+			if p.MaxAge, err = parseDuration(age); err != nil {
+				return nil, fmt.Errorf("invalid retention policy %s: %w", pair, err)
+			}
+		}
+
+		if resolution = strings.TrimSpace(resolution); resolution != all {
+			if p.Resolution, err = parseDuration(resolution); err != nil {
+				return nil, fmt.Errorf("invalid retention policy %s: %w", pair, err)
+			}
+		}
+
+		if len(policies) > 0 {
+			last := policies[len(policies)-1]
+			if last.MaxAge == 0 {
+				return nil, fmt.Errorf("retention policy %s follows a policy without age limit", pair)
+			}
+			if p.MaxAge != 0 && p.MaxAge <= last.MaxAge {
+				return nil, fmt.Errorf("retention policy %s must have a greater age than the previous one", pair)
+			}
+		}
+
+		policies = append(policies, p)
+	}
+
+	return policies, nil
+}
+
+// parseDuration parses a positive duration, additionally accepting days and weeks.
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("duration is not specified")
+	}
+
+	var (
+		d   time.Duration
+		err error
+	)
+
+	switch unit := s[len(s)-1:]; unit {
+	case "d", "w":
+		var n int
+		n, err = strconv.Atoi(s[:len(s)-1])
+		d = time.Duration(n) * 24 * time.Hour
+		if unit == "w" {
+			d *= 7
+		}
+	default:
+		d, err = time.ParseDuration(s)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration %q must be positive", s)
+	}
+
+	return d, nil
+}
+
+// bucket identifies an interval in which a single snapshot is kept.
+type bucket struct {
+	policyIdx int
+	start     time.Time
+}
+
+// expired returns snapshots not kept by the policies at the time [now].
+// The oldest snapshot of every resolution interval is kept, so kept snapshots do not change
+// as they move to coarser policies. Intervals are aligned to UTC.
+func expired(timestamps []model.Snapshot, policies []Policy, now time.Time) []model.Snapshot {
+	sorted := append([]model.Snapshot(nil), timestamps...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	kept := make(map[bucket]struct{})
+	expired := make([]model.Snapshot, 0)
+
+	for _, snapshot := range sorted {
+		policyIdx := findPolicy(policies, now.Sub(snapshot.Timestamp))
+		if policyIdx < 0 {
+			expired = append(expired, snapshot)
+			continue
+		}
+
+		p := policies[policyIdx]
+		if p.Resolution == 0 {
+			continue
+		}
+
+		b := bucket{
+			policyIdx: policyIdx,
+			start:     snapshot.Timestamp.UTC().Truncate(p.Resolution),
+		}
+		if _, ok := kept[b]; ok {
+			expired = append(expired, snapshot)
+			continue
+		}
+		kept[b] = struct{}{}
+	}
+
+	return expired
+}
+
+// findPolicy returns the index of the policy covering snapshots of the given age or -1 if there is none.
+func findPolicy(policies []Policy, age time.Duration) int {
+	for policyIdx, p := range policies {
+		if p.MaxAge == 0 || age < p.MaxAge {
+			return policyIdx
+		}
+	}
+
+	return -1
+}
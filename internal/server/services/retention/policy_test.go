@@ -0,0 +1,190 @@
+package retention
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+)
+
+const day = 24 * time.Hour
+
+func TestParsePolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []Policy
+		wantErr bool
+	}{
+		{
+			name: "tiers",
+			s:    "7d:all,90d:1h,forever:1d",
+			want: []Policy{
+				{MaxAge: 7 * day},
+				{MaxAge: 90 * day, Resolution: time.Hour},
+				{Resolution: day},
+			},
+		},
+		{
+			name: "weeks, durations and spaces",
+			s:    " 2w : 30m , 720h:all ",
+			want: []Policy{
+				{MaxAge: 14 * day, Resolution: 30 * time.Minute},
+				{MaxAge: 30 * day},
+			},
+		},
+		{name: "empty", s: "", want: []Policy{}},
+		{name: "bad unit", s: "7x:all", wantErr: true},
+		{name: "bad days", s: "7.5d:all", wantErr: true},
+		{name: "bad resolution", s: "7d:hourly", wantErr: true},
+		{name: "zero age", s: "0d:all", wantErr: true},
+		{name: "negative resolution", s: "7d:-1h", wantErr: true},
+		{name: "missing resolution", s: "7d", wantErr: true},
+		{name: "unordered ages", s: "90d:1h,7d:all", wantErr: true},
+		{name: "duplicate ages", s: "7d:all,7d:1h", wantErr: true},
+		{name: "equal ages in different units", s: "1w:all,7d:1h", wantErr: true},
+		{name: "forever not last", s: "forever:1d,90d:1h", wantErr: true},
+		{name: "forever twice", s: "forever:1h,forever:1d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePolicies(tt.s)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("want an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("policies = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2024, time.October, 10, 12, 30, 0, 0, time.UTC)
+	moscow := time.FixedZone("UTC+3", 3*60*60)
+
+	tests := []struct {
+		name     string
+		policies string
+		// Timestamps of snapshots with ids 1, 2 and so on.
+		timestamps []time.Time
+		want       []int
+	}{
+		{
+			name:       "all kept while young",
+			policies:   "7d:all,90d:1h",
+			timestamps: []time.Time{now.Add(-time.Minute), now.Add(-2 * time.Minute), now.Add(-7*day + time.Second)},
+			want:       []int{},
+		},
+		{
+			name:     "exactly 7d falls under the hourly policy",
+			policies: "7d:all,90d:1h",
+			timestamps: []time.Time{
+				now.Add(-7 * day),                    // 12:30, the second of the 12:00 bucket
+				now.Add(-7*day - 10*time.Minute),     // 12:20, the oldest of the 12:00 bucket
+				now.Add(-7*day + time.Second),        // younger than 7d, kept by the first policy
+				now.Add(-7*day - 30*time.Minute - 1), // 11:59:59, the 11:00 bucket
+			},
+			want: []int{1},
+		},
+		{
+			name:     "oldest snapshot per hour is kept",
+			policies: "7d:all,90d:1h",
+			timestamps: []time.Time{
+				time.Date(2024, time.September, 1, 10, 59, 0, 0, time.UTC),
+				time.Date(2024, time.September, 1, 10, 0, 0, 0, time.UTC),
+				time.Date(2024, time.September, 1, 10, 30, 0, 0, time.UTC),
+				time.Date(2024, time.September, 1, 11, 0, 0, 0, time.UTC),
+			},
+			want: []int{1, 3},
+		},
+		{
+			name:     "exactly 90d is deleted without a further policy",
+			policies: "7d:all,90d:1h",
+			timestamps: []time.Time{
+				now.Add(-90 * day),
+				now.Add(-90*day + time.Second),
+				now.Add(-365 * day),
+			},
+			want: []int{1, 3},
+		},
+		{
+			name:     "exactly 90d falls under the daily policy",
+			policies: "7d:all,90d:1h,forever:1d",
+			timestamps: []time.Time{
+				now.Add(-90 * day),                  // 12:30 of the day
+				now.Add(-90*day - 12*time.Hour),     // 00:30 of the same day
+				now.Add(-90*day + time.Second),      // younger than 90d, its own hourly bucket
+				now.Add(-90*day - 12*time.Hour - 1), // just before 00:30, the oldest of the day
+			},
+			want: []int{1, 2},
+		},
+		{
+			name:     "days are aligned to UTC",
+			policies: "7d:all,forever:1d",
+			timestamps: []time.Time{
+				// 2024-09-01 22:00 and 23:30 UTC, although 2024-09-02 in UTC+3.
+				time.Date(2024, time.September, 2, 1, 0, 0, 0, moscow),
+				time.Date(2024, time.September, 2, 2, 30, 0, 0, moscow),
+				// 2024-09-02 00:30 UTC, the same day as the previous one in UTC+3.
+				time.Date(2024, time.September, 2, 3, 30, 0, 0, moscow),
+			},
+			want: []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policies, err := ParsePolicies(tt.policies)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			timestamps := make([]model.Snapshot, len(tt.timestamps))
+			for tsIdx, ts := range tt.timestamps {
+				timestamps[tsIdx] = model.Snapshot{ID: tsIdx + 1, Timestamp: ts}
+			}
+
+			got := make([]int, 0)
+			for _, snapshot := range expired(timestamps, policies, now) {
+				got = append(got, snapshot.ID)
+			}
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expired = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPolicy(t *testing.T) {
+	policies, err := ParsePolicies("7d:all,90d:1h")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		age  time.Duration
+		want int
+	}{
+		{0, 0},
+		{7*day - 1, 0},
+		{7 * day, 1},
+		{90*day - 1, 1},
+		{90 * day, -1},
+	}
+	for _, tt := range tests {
+		if got := findPolicy(policies, tt.age); got != tt.want {
+			t.Errorf("findPolicy(%s) = %d, want %d", tt.age, got, tt.want)
+		}
+	}
+}
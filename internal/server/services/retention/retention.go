@@ -0,0 +1,102 @@
+// Package retention defines service that deletes snapshots according to retention policies.
+package retention
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+const limitInSeconds = 300
+
+var _ services.RetentionService = (*retention)(nil)
+
+// retention implements the [RetentionService] interface.
+type retention struct {
+	logger   *zap.Logger
+	repo     repository.Repository
+	policies []Policy
+	interval time.Duration
+	dryRun   bool
+}
+
+// NewRetention returns retention object that prunes snapshots stored in a [Repository] object.
+// When run, it prunes snapshots every [interval]; if [dryRun] is set, it only reports what would be deleted.
+func NewRetention(logger *zap.Logger, repo repository.Repository, policies []Policy, interval time.Duration, dryRun bool) *retention {
+	return &retention{
+		logger:   logger,
+		repo:     repo,
+		policies: policies,
+		interval: interval,
+		dryRun:   dryRun,
+	}
+}
+
+// Prune implements the [RetentionService] interface.
+// Orphaned rows are deleted even if no snapshot has expired, for example after deleting a snapshot by its id.
+func (r *retention) Prune(ctx context.Context, dryRun bool) (repository.PruneResult, error) {
+	start := time.Now()
+
+	timestamps, err := r.repo.GetNTimestamps(ctx, math.MaxInt32)
+	if err != nil {
+		return repository.PruneResult{}, err
+	}
+
+	expired := expired(timestamps, r.policies, start)
+
+	ids := make([]int, len(expired))
+	for snapshotIdx, snapshot := range expired {
+		ids[snapshotIdx] = snapshot.ID
+	}
+
+	result, err := r.repo.PruneSnapshots(ctx, ids, dryRun)
+	if err != nil {
+		return repository.PruneResult{}, err
+	}
+
+	action := "Retention deleted"
+	if dryRun {
+		action = "Retention dry run would delete"
+	}
+	r.logger.Sugar().Infof(
+		"%s %d of %d snapshots, %d devices, %d interfaces, %d vendors and %d operating systems in %s",
+		action, result.Snapshots, len(timestamps), result.Devices, result.Interfaces,
+		result.Vendors, result.OperatingSystems, time.Since(start).Round(time.Millisecond),
+	)
+	if len(expired) > 0 {
+		r.logger.Sugar().Infof(
+			"%s snapshots taken from %s to %s",
+			action, expired[0].Timestamp.Format(time.DateTime), expired[len(expired)-1].Timestamp.Format(time.DateTime),
+		)
+	}
+
+	return result, nil
+}
+
+// Run implements the [RetentionService] interface.
+// Snapshots are pruned on start and then every interval.
+func (r *retention) Run(ctx context.Context) {
+	r.logger.Sugar().Infof("Pruning snapshots every %s", r.interval)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		pruneCtx, cancel := context.WithTimeout(ctx, limitInSeconds*time.Second)
+		if _, err := r.Prune(pruneCtx, r.dryRun); err != nil {
+			r.logger.Sugar().Errorf("Failed to prune snapshots: %s", err.Error())
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
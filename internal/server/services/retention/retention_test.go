@@ -0,0 +1,108 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/repository/memory"
+	"github.com/sudeeya/net-monitor/internal/server/repository/sqlite"
+)
+
+func TestPruneDryRun(t *testing.T) {
+	backends := map[string]func(t *testing.T) repository.Repository{
+		"memory": func(t *testing.T) repository.Repository {
+			return memory.NewMemory(zap.NewNop(), repository.DefaultIdentity())
+		},
+		"sqlite": func(t *testing.T) repository.Repository {
+			s, err := sqlite.NewSQLite(zap.NewNop(), "file:"+filepath.Join(t.TempDir(), "net-monitor.db"), repository.DefaultIdentity())
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = s.Close() })
+			if err := s.Migrator().Up(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	}
+
+	for name, newRepo := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newRepo(t)
+
+			// Three snapshots of the same UTC day, each with its own device, so all but the oldest expire
+			// together with their devices, interfaces, vendors and operating systems.
+			start := time.Now().UTC().Truncate(day).Add(-3 * day)
+			for n := range 3 {
+				snapshot := model.Snapshot{
+					Timestamp: start.Add(time.Duration(n+1) * time.Hour),
+					Devices: []model.Device{{
+						Hostname:             fmt.Sprintf("leaf%d", n),
+						Vendor:               fmt.Sprintf("Vendor %d", n),
+						OSName:               "OS",
+						OSVersion:            fmt.Sprintf("v%d", n),
+						Serial:               fmt.Sprintf("serial%d", n),
+						IsSnapshotSuccessful: true,
+						Status:               model.StatusSuccess,
+						Interfaces: []model.Interface{
+							{Name: "ethernet-1/1", IsUp: true, IP: netip.MustParsePrefix(fmt.Sprintf("10.0.%d.1/31", n)), MTU: 9214},
+						},
+					}},
+				}
+				if err := repo.StoreSnapshot(ctx, snapshot); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			policies, err := ParsePolicies("1h:all,forever:1d")
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := NewRetention(zap.NewNop(), repo, policies, time.Hour, false)
+
+			dryRun, err := r.Prune(ctx, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := repository.PruneResult{
+				Snapshots:        2,
+				DeviceStates:     2,
+				Devices:          2,
+				Interfaces:       2,
+				Vendors:          2,
+				OperatingSystems: 2,
+			}
+			if dryRun != want {
+				t.Errorf("dry run = %+v, want %+v", dryRun, want)
+			}
+			if timestamps, err := repo.GetNTimestamps(ctx, math.MaxInt32); err != nil || len(timestamps) != 3 {
+				t.Fatalf("after dry run: %d snapshots, %v, want 3", len(timestamps), err)
+			}
+
+			result, err := r.Prune(ctx, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != dryRun {
+				t.Errorf("prune = %+v, want the dry run result %+v", result, dryRun)
+			}
+			timestamps, err := repo.GetNTimestamps(ctx, math.MaxInt32)
+			if err != nil || len(timestamps) != 1 {
+				t.Fatalf("after prune: %d snapshots, %v, want 1", len(timestamps), err)
+			}
+			if !timestamps[0].Timestamp.Equal(start.Add(time.Hour)) {
+				t.Errorf("kept snapshot taken at %s, want the oldest one at %s", timestamps[0].Timestamp, start.Add(time.Hour))
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
-// Package services defines service for interacting with snapshots.
+// Package services defines services for interacting with snapshots.
 package services
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net/netip"
+	"time"
 
 	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
 )
 
 // SnapshotsService describes the service for interacting with snapshots.
@@ -20,6 +25,388 @@ type SnapshotsService interface {
 	// GetNTimestamps returns the last n snapshot ids and timestamps.
 	GetNTimestamps(ctx context.Context, n int) ([]model.Snapshot, error)
 
+	// GetTimestampsPage returns up to limit snapshot ids and timestamps, from newest to oldest,
+	// starting after the cursor of the previous page. An empty cursor starts from the latest snapshot.
+	// Returns [ErrInvalidCursor] if the cursor was not returned by this method.
+	GetTimestampsPage(ctx context.Context, cursor string, limit int) (TimestampsPage, error)
+
+	// GetTimestampsBetween returns ids and timestamps of snapshots taken from [from] to [to] inclusive.
+	GetTimestampsBetween(ctx context.Context, from, to time.Time) ([]model.Snapshot, error)
+
+	// GetClosestSnapshot returns the snapshot taken closest to [at] with the devices matching the filter.
+	GetClosestSnapshot(ctx context.Context, at time.Time, filter repository.DeviceFilter) (model.Snapshot, error)
+
+	// GetFilteredSnapshot returns a snapshot by its id with the devices matching the filter.
+	GetFilteredSnapshot(ctx context.Context, id int, filter repository.DeviceFilter) (model.Snapshot, error)
+
 	// DeleteSnapshot deletes a snapshot by its id.
 	DeleteSnapshot(ctx context.Context, id int) error
 }
+
+// ErrInvalidCursor is returned if a page cursor is malformed.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TimestampsPage describes a page of snapshot ids and timestamps.
+// Next is the cursor of the following page; it is empty on the last page.
+type TimestampsPage struct {
+	Timestamps []model.Snapshot
+	Next       string
+}
+
+// HistoryService describes the service for getting the history of devices and interfaces across snapshots.
+type HistoryService interface {
+	// GetDeviceHistory returns changes of the device with the current hostname and a summary of its interfaces
+	// in snapshots taken from [from] to [to] inclusive, including snapshots taken before it was renamed.
+	GetDeviceHistory(ctx context.Context, hostname string, from, to time.Time) (DeviceHistory, error)
+
+	// GetInterfaceHistory returns changes of the interface of the device
+	// in snapshots taken from [from] to [to] inclusive.
+	GetInterfaceHistory(ctx context.Context, hostname, name string, from, to time.Time) (InterfaceHistory, error)
+
+	// GetFlappingInterfaces returns interfaces of all devices that went down at least minDowns times
+	// in snapshots taken from [from] to [to] inclusive, from the most to the least flapping.
+	GetFlappingInterfaces(ctx context.Context, from, to time.Time, minDowns int) ([]FlappingInterface, error)
+}
+
+// Attributes whose changes are tracked by the [HistoryService].
+const (
+	AttributeHostname   = "hostname"
+	AttributeVendor     = "vendor"
+	AttributeOSName     = "os_name"
+	AttributeOSVersion  = "os_version"
+	AttributeSerial     = "serial_number"
+	AttributeStatus     = "status"
+	AttributeState      = "state"
+	AttributeIP         = "ip"
+	AttributeMTU        = "mtu"
+	AttributeLastChange = "last_change"
+)
+
+// Change describes a change of an attribute between two snapshots.
+type Change struct {
+	// Snapshot in which the new value was first seen.
+	SnapshotID int
+	Timestamp  time.Time
+
+	Attribute string
+	Old       string
+	New       string
+}
+
+// DeviceHistory describes the history of a device.
+// Device attributes and reachability are tracked as device changes.
+type DeviceHistory struct {
+	Hostname string
+
+	// Number of snapshots with the device and the times of the first and last of them.
+	Snapshots int
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	// The device in the last snapshot.
+	Last model.Device
+
+	Changes    []Change
+	Interfaces []InterfaceSummary
+}
+
+// InterfaceSummary describes the history of an interface in short.
+type InterfaceSummary struct {
+	Name string
+
+	// The interface in the last snapshot in which it was present.
+	Last model.Interface
+
+	// Number of changes and of transitions from up to down.
+	Changes int
+	Downs   int
+
+	// Time of the last change, zero if the interface did not change.
+	LastChange time.Time
+}
+
+// FlappingInterface describes the history of an interface that went down repeatedly.
+type FlappingInterface struct {
+	Hostname string
+	InterfaceSummary
+}
+
+// WentDown reports whether the interface went down between the snapshot taken at [prevAt], in which it was [prev],
+// and the next one, in which it is [iface].
+// An interface in the same state whose last change is after [prevAt] went down and back up, or up and back down.
+func WentDown(prev model.Interface, prevAt time.Time, iface model.Interface) bool {
+	if prev.IsUp && !iface.IsUp {
+		return true
+	}
+
+	return prev.IsUp == iface.IsUp && iface.LastChange.After(prevAt)
+}
+
+// InterfaceHistory describes the history of an interface.
+// State, IP and MTU are tracked as interface changes, and so is the last change of an interface that flapped unseen.
+type InterfaceHistory struct {
+	Hostname string
+	Name     string
+
+	// Number of snapshots with the interface and the times of the first and last of them.
+	Snapshots int
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	// The interface in the last snapshot in which it was present.
+	Last model.Interface
+
+	// Number of transitions from up to down.
+	Downs int
+
+	Changes []Change
+}
+
+// RetentionService describes the service for deleting snapshots according to retention policies.
+type RetentionService interface {
+	// Prune deletes snapshots not kept by retention policies
+	// together with devices, interfaces, vendors and operating systems no longer referenced by any snapshot.
+	// If dryRun is set, nothing is deleted. Returns the number of rows deleted or that would be deleted.
+	Prune(ctx context.Context, dryRun bool) (repository.PruneResult, error)
+
+	// Run prunes snapshots periodically until the context is done.
+	Run(ctx context.Context)
+}
+
+// ArchiveService describes the service for moving snapshots between repositories through archives.
+type ArchiveService interface {
+	// Export writes snapshots taken from [from] to [to] inclusive to w as an archive, from oldest to newest.
+	// Returns the number of exported snapshots.
+	Export(ctx context.Context, w io.Writer, from, to time.Time) (int, error)
+
+	// Import stores snapshots read from an archive. Snapshots get new ids;
+	// a snapshot is skipped if a snapshot with the same timestamp is already stored,
+	// so an archive can be imported again after a partial failure.
+	// Returns [ErrInvalidArchive] if r is not an archive written by Export.
+	Import(ctx context.Context, r io.Reader) (ImportResult, error)
+}
+
+// ErrInvalidArchive is returned if an archive is malformed or has an unsupported format.
+var ErrInvalidArchive = errors.New("invalid archive")
+
+// ImportResult describes the number of imported snapshots and of snapshots skipped as already stored.
+type ImportResult struct {
+	Imported int
+	Skipped  int
+}
+
+// AlertsService describes the service for evaluating alerting rules against saved snapshots.
+type AlertsService interface {
+	// Evaluate evaluates the rules against the snapshot and returns alerts that started firing or resolved.
+	// Alerts that keep firing are not returned again.
+	Evaluate(ctx context.Context, snapshot model.Snapshot) []Alert
+}
+
+// AlertState describes whether an alert is firing.
+type AlertState string
+
+// Possible alert states.
+const (
+	AlertFiring   AlertState = "firing"
+	AlertResolved AlertState = "resolved"
+)
+
+// Alert describes a condition found by an alerting rule on a device or one of its interfaces.
+type Alert struct {
+	Rule     string
+	Severity string
+	State    AlertState
+
+	Hostname string
+	// Interface is empty for alerts on the whole device.
+	Interface string
+	Message   string
+
+	// Times of the snapshots in which the alert started firing and, if it is resolved, stopped.
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// NotificationsService describes the service for sending alerts to notification channels.
+type NotificationsService interface {
+	// Notify queues alerts for the channels selected by routes and returns without waiting for them to be sent.
+	Notify(alerts []Alert)
+
+	// Run sends queued alerts until the context is done.
+	Run(ctx context.Context)
+}
+
+// SnapshotAnalyzer describes an analysis run on every saved snapshot whose results are stored as findings.
+type SnapshotAnalyzer interface {
+	// Analyze analyzes the saved snapshot and stores its findings.
+	Analyze(ctx context.Context, snapshot model.Snapshot) error
+}
+
+// ComplianceService describes the service for checking snapshots against compliance policies.
+type ComplianceService interface {
+	// Analyze evaluates the policies against the saved snapshot and stores the failed checks.
+	SnapshotAnalyzer
+
+	// GetReport returns the results of every device of the snapshot with the id, or of the latest snapshot if id is 0.
+	// Returns an empty report if there are no snapshots.
+	GetReport(ctx context.Context, id int) (ComplianceReport, error)
+
+	// GetDeviceReports returns the results of the device with the current hostname
+	// in snapshots taken from [from] to [to] inclusive, from newest to oldest.
+	GetDeviceReports(ctx context.Context, hostname string, from, to time.Time) ([]DeviceCompliance, error)
+}
+
+// ComplianceReport describes the results of compliance policies for the devices of a snapshot.
+type ComplianceReport struct {
+	SnapshotID int
+	Timestamp  time.Time
+
+	// Devices sorted by hostname.
+	Devices []DeviceCompliance
+}
+
+// DeviceCompliance describes the results of compliance policies for a device in a snapshot.
+// A device passes if none of its checks failed.
+type DeviceCompliance struct {
+	SnapshotID int
+	Timestamp  time.Time
+	Hostname   string
+
+	Failures []repository.Finding
+}
+
+// Passed reports whether the device passed all compliance policies.
+func (d DeviceCompliance) Passed() bool {
+	return len(d.Failures) == 0
+}
+
+// IPConflictsService describes the service for finding duplicate addresses and overlapping subnets
+// among the interfaces of saved snapshots.
+type IPConflictsService interface {
+	// Analyze finds conflicts among the interfaces of the saved snapshot and stores them.
+	SnapshotAnalyzer
+
+	// GetConflicts returns the conflicts of the snapshot with the id, or of the latest snapshot if id is 0,
+	// with the severity if it is set. Returns an empty report if there are no snapshots.
+	GetConflicts(ctx context.Context, id int, severity string) (IPConflictsReport, error)
+}
+
+// IPConflictsReport describes the conflicts found in a snapshot, from the most to the least severe.
+type IPConflictsReport struct {
+	SnapshotID int                  `json:"snapshot_id"`
+	Timestamp  time.Time            `json:"timestamp"`
+	Conflicts  []repository.Finding `json:"conflicts"`
+}
+
+// IPAMService describes the service for building a view of the subnets in use from snapshots.
+type IPAMService interface {
+	// GetPrefixTree returns the subnets of the interfaces of the latest snapshot nested by containment.
+	// Returns an empty tree if there are no snapshots.
+	GetPrefixTree(ctx context.Context) (PrefixTree, error)
+}
+
+// PrefixTree describes the subnets in use in a snapshot.
+type PrefixTree struct {
+	SnapshotID int       `json:"snapshot_id"`
+	Timestamp  time.Time `json:"timestamp"`
+
+	// Subnets that are not inside other subnets, sorted by prefix.
+	Prefixes []PrefixNode `json:"prefixes"`
+}
+
+// PrefixNode describes a subnet and the subnets inside it.
+type PrefixNode struct {
+	Prefix netip.Prefix `json:"prefix"`
+
+	// Number of addresses usable by hosts, as a decimal string, since IPv6 subnets may not fit in an integer.
+	Size string `json:"size"`
+
+	// Number of distinct addresses in use in the subnet, including the subnets inside it,
+	// and their share of the usable addresses in percent.
+	Used        int     `json:"used"`
+	Utilization float64 `json:"utilization"`
+
+	// Addresses of interfaces configured with this subnet, sorted by address.
+	Addresses []AddressUsage `json:"addresses"`
+
+	Children []PrefixNode `json:"children"`
+}
+
+// AddressUsage describes an address of an interface with the times of the first and last snapshots in which it was seen.
+type AddressUsage struct {
+	Address   netip.Addr `json:"address"`
+	Hostname  string     `json:"hostname"`
+	Interface string     `json:"interface"`
+	FirstSeen time.Time  `json:"first_seen"`
+	LastSeen  time.Time  `json:"last_seen"`
+}
+
+// TopologyService describes the service for building the graph of devices and links of snapshots.
+type TopologyService interface {
+	// GetTopology returns the topology of the snapshot with the id, or of the latest snapshot if id is 0.
+	// Returns an empty topology if there is no such snapshot.
+	GetTopology(ctx context.Context, id int) (Topology, error)
+
+	// GetTopologyDiff returns the links that appeared and disappeared between the snapshots with the ids.
+	GetTopologyDiff(ctx context.Context, fromID, toID int) (TopologyDiff, error)
+}
+
+// Topology describes the devices of a snapshot and the links between them.
+type Topology struct {
+	SnapshotID int       `json:"snapshot_id"`
+	Timestamp  time.Time `json:"timestamp"`
+
+	// Nodes sorted by hostname.
+	Nodes []TopologyNode `json:"nodes"`
+
+	// Links sorted by their ends.
+	Links []Link `json:"links"`
+}
+
+// TopologyNode describes a device in a topology.
+type TopologyNode struct {
+	Hostname string             `json:"hostname"`
+	Status   model.DeviceStatus `json:"status"`
+}
+
+// Link describes a link between interfaces of two devices.
+type Link struct {
+	A LinkEnd `json:"a"`
+	B LinkEnd `json:"b"`
+
+	// Source of the link, LinkSourceLLDP or LinkSourceSubnet.
+	Source string `json:"source"`
+
+	// Subnet shared by the ends, invalid if they do not share a point-to-point subnet.
+	Subnet netip.Prefix `json:"subnet"`
+}
+
+// Sources of links.
+const (
+	// The link was reported by an LLDP neighbor of either end.
+	LinkSourceLLDP = "lldp"
+
+	// Neither end has an LLDP neighbor, and the link is inferred from the point-to-point subnet of the ends.
+	LinkSourceSubnet = "subnet"
+)
+
+// Up reports whether both ends of the link are up.
+func (l Link) Up() bool {
+	return l.A.IsUp && l.B.IsUp
+}
+
+// LinkEnd describes an interface at an end of a link.
+type LinkEnd struct {
+	Hostname  string `json:"hostname"`
+	Interface string `json:"interface"`
+	IsUp      bool   `json:"is_up"`
+}
+
+// TopologyDiff describes the links that appeared and disappeared between two snapshots.
+type TopologyDiff struct {
+	From Topology `json:"from"`
+	To   Topology `json:"to"`
+
+	Added   []Link `json:"added"`
+	Removed []Link `json:"removed"`
+}
@@ -3,7 +3,14 @@ package snapshots
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/sudeeya/net-monitor/internal/pkg/model"
@@ -11,19 +18,34 @@ import (
 	"github.com/sudeeya/net-monitor/internal/server/services"
 )
 
+// tracer starts spans of saved snapshots.
+var tracer = otel.Tracer("github.com/sudeeya/net-monitor/internal/server/services/snapshots")
+
 var _ services.SnapshotsService = (*snapshots)(nil)
 
 // snapshots implements the [SnapshotsService] interface.
 type snapshots struct {
 	logger *zap.Logger
 	repo   repository.Repository
+	alerts services.AlertsService
+
+	analyzers []services.SnapshotAnalyzer
 }
 
 // NewSnapshots returns snapshots object to interact with a [Repository] object.
-func NewSnapshots(logger *zap.Logger, repo repository.Repository) *snapshots {
+// If [alerts] is nil, alerting rules are not evaluated.
+// [analyzers] are run on every saved snapshot.
+func NewSnapshots(
+	logger *zap.Logger,
+	repo repository.Repository,
+	alerts services.AlertsService,
+	analyzers ...services.SnapshotAnalyzer,
+) *snapshots {
 	return &snapshots{
-		logger: logger,
-		repo:   repo,
+		logger:    logger,
+		repo:      repo,
+		alerts:    alerts,
+		analyzers: analyzers,
 	}
 }
 
@@ -59,12 +81,134 @@ func (s *snapshots) GetNTimestamps(ctx context.Context, n int) ([]model.Snapshot
 	return timestamps, nil
 }
 
+// GetTimestampsPage implements the [SnapshotsService] interface.
+// The cursor encodes the timestamp of the last snapshot of the previous page,
+// so pages stay consistent while new snapshots are saved.
+func (s *snapshots) GetTimestampsPage(ctx context.Context, cursor string, limit int) (services.TimestampsPage, error) {
+	s.logger.Sugar().Infof("Getting a page of %d timestamps", limit)
+	if limit <= 0 {
+		return services.TimestampsPage{}, fmt.Errorf("page limit must be positive")
+	}
+
+	// One more timestamp is requested to know whether there is a next page.
+	var (
+		timestamps []model.Snapshot
+		err        error
+	)
+	if cursor == "" {
+		timestamps, err = s.repo.GetNTimestamps(ctx, limit+1)
+	} else {
+		before, decodeErr := decodeCursor(cursor)
+		if decodeErr != nil {
+			return services.TimestampsPage{}, decodeErr
+		}
+		timestamps, err = s.repo.GetTimestampsBefore(ctx, before, limit+1)
+	}
+	if err != nil {
+		return services.TimestampsPage{}, err
+	}
+
+	page := services.TimestampsPage{
+		Timestamps: timestamps,
+	}
+	if len(timestamps) > limit {
+		page.Timestamps = timestamps[:limit]
+		page.Next = encodeCursor(timestamps[limit-1].Timestamp)
+	}
+
+	return page, nil
+}
+
+// encodeCursor returns an opaque cursor pointing after the timestamp.
+func encodeCursor(timestamp time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(timestamp.Format(time.RFC3339Nano)))
+}
+
+// decodeCursor returns the timestamp encoded by [encodeCursor].
+func decodeCursor(cursor string) (time.Time, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, services.ErrInvalidCursor
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, string(decoded))
+	if err != nil {
+		return time.Time{}, services.ErrInvalidCursor
+	}
+
+	return timestamp, nil
+}
+
+// GetTimestampsBetween implements the [SnapshotsService] interface.
+func (s *snapshots) GetTimestampsBetween(ctx context.Context, from, to time.Time) ([]model.Snapshot, error) {
+	s.logger.Sugar().Infof("Getting timestamps from %s to %s", from, to)
+	timestamps, err := s.repo.GetTimestampsBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return timestamps, nil
+}
+
+// GetClosestSnapshot implements the [SnapshotsService] interface.
+func (s *snapshots) GetClosestSnapshot(ctx context.Context, at time.Time, filter repository.DeviceFilter) (model.Snapshot, error) {
+	s.logger.Sugar().Infof("Getting the snapshot closest to %s", at)
+	closest, err := s.repo.GetClosestTimestamp(ctx, at)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+	if closest.ID == 0 {
+		return model.Snapshot{}, nil
+	}
+
+	snapshot, err := s.repo.GetFilteredSnapshot(ctx, closest.ID, filter)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
+// GetFilteredSnapshot implements the [SnapshotsService] interface.
+func (s *snapshots) GetFilteredSnapshot(ctx context.Context, id int, filter repository.DeviceFilter) (model.Snapshot, error) {
+	s.logger.Info("Getting a filtered snapshot")
+	snapshot, err := s.repo.GetFilteredSnapshot(ctx, id, filter)
+	if err != nil {
+		return model.Snapshot{}, err
+	}
+
+	return snapshot, nil
+}
+
 // SaveSnapshot implements the [SnapshotsService] interface.
 func (s *snapshots) SaveSnapshot(ctx context.Context, snapshot model.Snapshot) error {
 	s.logger.Info("Saving a snapshot")
+	ctx, span := tracer.Start(ctx, "SaveSnapshot", trace.WithAttributes(
+		attribute.Int("snapshot.devices", len(snapshot.Devices)),
+	))
+	defer span.End()
+
 	if err := s.repo.StoreSnapshot(ctx, snapshot); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
 
+	if s.alerts != nil {
+		_, alertsSpan := tracer.Start(ctx, "EvaluateAlerts")
+		s.alerts.Evaluate(ctx, snapshot)
+		alertsSpan.End()
+	}
+
+	// The snapshot is already saved, so failed analyses are only logged.
+	for _, analyzer := range s.analyzers {
+		analyzeCtx, analyzeSpan := tracer.Start(ctx, "Analyze")
+		if err := analyzer.Analyze(analyzeCtx, snapshot); err != nil {
+			s.logger.Sugar().Errorf("Failed to analyze the snapshot: %v", err)
+			analyzeSpan.RecordError(err)
+		}
+		analyzeSpan.End()
+	}
+
 	return nil
 }
@@ -0,0 +1,257 @@
+// Package topology defines service that builds the graph of devices and links of snapshots.
+//
+// Links are taken from the neighbors that clients discover by LLDP. Interfaces without neighbors,
+// for example of devices whose clients do not collect them, are linked by addresses as a fallback:
+// two interfaces of different devices with the same /30 or /31 (/126 or /127 for IPv6) subnet are linked.
+package topology
+
+import (
+	"context"
+	"net/netip"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/repository"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+var _ services.TopologyService = (*topology)(nil)
+
+// topology implements the [TopologyService] interface.
+type topology struct {
+	logger *zap.Logger
+	repo   repository.Repository
+}
+
+// NewTopology returns topology object that builds topologies from the snapshots of [repo].
+func NewTopology(logger *zap.Logger, repo repository.Repository) *topology {
+	return &topology{
+		logger: logger,
+		repo:   repo,
+	}
+}
+
+// GetTopology implements the [TopologyService] interface.
+func (t *topology) GetTopology(ctx context.Context, id int) (services.Topology, error) {
+	t.logger.Info("Getting a topology")
+	if id == 0 {
+		latest, err := t.repo.GetNTimestamps(ctx, 1)
+		if err != nil {
+			return services.Topology{}, err
+		}
+		if len(latest) == 0 {
+			return services.Topology{}, nil
+		}
+		id = latest[0].ID
+	}
+
+	snapshot, err := t.repo.GetSnapshot(ctx, id)
+	if err != nil {
+		return services.Topology{}, err
+	}
+
+	return build(snapshot), nil
+}
+
+// GetTopologyDiff implements the [TopologyService] interface.
+func (t *topology) GetTopologyDiff(ctx context.Context, fromID, toID int) (services.TopologyDiff, error) {
+	t.logger.Sugar().Infof("Getting the topology diff of snapshots %d and %d", fromID, toID)
+	from, err := t.repo.GetSnapshot(ctx, fromID)
+	if err != nil {
+		return services.TopologyDiff{}, err
+	}
+
+	to, err := t.repo.GetSnapshot(ctx, toID)
+	if err != nil {
+		return services.TopologyDiff{}, err
+	}
+
+	diff := services.TopologyDiff{
+		From: build(from),
+		To:   build(to),
+	}
+	diff.Added = subtract(diff.To.Links, diff.From.Links)
+	diff.Removed = subtract(diff.From.Links, diff.To.Links)
+
+	return diff, nil
+}
+
+// build returns the topology of the snapshot.
+// Links are taken from LLDP neighbors; interfaces without neighbors are linked by their point-to-point subnets.
+func build(snapshot model.Snapshot) services.Topology {
+	topology := services.Topology{
+		SnapshotID: snapshot.ID,
+		Timestamp:  snapshot.Timestamp,
+		Nodes:      make([]services.TopologyNode, len(snapshot.Devices)),
+		Links:      make([]services.Link, 0),
+	}
+
+	ends := make(map[services.LinkEnd]model.Interface)
+	for deviceIdx, device := range snapshot.Devices {
+		topology.Nodes[deviceIdx] = services.TopologyNode{
+			Hostname: device.Hostname,
+			Status:   device.Status,
+		}
+
+		for _, iface := range device.Interfaces {
+			ends[services.LinkEnd{Hostname: device.Hostname, Interface: iface.Name}] = iface
+		}
+	}
+
+	linked := addNeighborLinks(&topology, snapshot, ends)
+	addSubnetLinks(&topology, snapshot, linked)
+
+	sort.Slice(topology.Nodes, func(i, j int) bool {
+		return topology.Nodes[i].Hostname < topology.Nodes[j].Hostname
+	})
+	sort.Slice(topology.Links, func(i, j int) bool {
+		if topology.Links[i].A != topology.Links[j].A {
+			return less(topology.Links[i].A, topology.Links[j].A)
+		}
+		return less(topology.Links[i].B, topology.Links[j].B)
+	})
+
+	return topology
+}
+
+// addNeighborLinks adds links reported by LLDP neighbors and returns their ends without state.
+// A link reported by both ends is added once. Neighbors that are not devices of the snapshot are skipped,
+// and a neighbor interface missing from the snapshot, for example because the device failed, is taken as down.
+func addNeighborLinks(topology *services.Topology, snapshot model.Snapshot, ends map[services.LinkEnd]model.Interface) map[services.LinkEnd]bool {
+	devices := make(map[string]bool, len(snapshot.Devices))
+	for _, device := range snapshot.Devices {
+		devices[device.Hostname] = true
+	}
+
+	linked := make(map[services.LinkEnd]bool)
+	added := make(map[[2]services.LinkEnd]bool)
+	for _, device := range snapshot.Devices {
+		for _, iface := range device.Interfaces {
+			if iface.NeighborHostname == "" || iface.NeighborHostname == device.Hostname || !devices[iface.NeighborHostname] {
+				continue
+			}
+
+			a := services.LinkEnd{Hostname: device.Hostname, Interface: iface.Name}
+			b := services.LinkEnd{Hostname: iface.NeighborHostname, Interface: iface.NeighborInterface}
+			neighbor := ends[b]
+			link := services.Link{
+				A:      services.LinkEnd{Hostname: a.Hostname, Interface: a.Interface, IsUp: iface.IsUp},
+				B:      services.LinkEnd{Hostname: b.Hostname, Interface: b.Interface, IsUp: neighbor.IsUp},
+				Source: services.LinkSourceLLDP,
+			}
+			if less(b, a) {
+				a, b = b, a
+				link.A, link.B = link.B, link.A
+			}
+			if added[[2]services.LinkEnd{a, b}] {
+				continue
+			}
+			added[[2]services.LinkEnd{a, b}] = true
+			linked[a], linked[b] = true, true
+
+			if subnet, ok := sharedSubnet(iface, neighbor); ok {
+				link.Subnet = subnet
+			}
+			topology.Links = append(topology.Links, link)
+		}
+	}
+
+	return linked
+}
+
+// addSubnetLinks links interfaces of different devices with the same /30 or /31 (/126 or /127 for IPv6) subnet.
+// Interfaces with an LLDP neighbor or in [linked] are skipped, since LLDP shows what they are connected to.
+func addSubnetLinks(topology *services.Topology, snapshot model.Snapshot, linked map[services.LinkEnd]bool) {
+	ends := make(map[netip.Prefix][]services.LinkEnd)
+	subnets := make([]netip.Prefix, 0)
+	for _, device := range snapshot.Devices {
+		for _, iface := range device.Interfaces {
+			if !iface.IP.IsValid() || !pointToPoint(iface.IP) || iface.NeighborHostname != "" {
+				continue
+			}
+			if linked[services.LinkEnd{Hostname: device.Hostname, Interface: iface.Name}] {
+				continue
+			}
+
+			subnet := iface.IP.Masked()
+			if _, ok := ends[subnet]; !ok {
+				subnets = append(subnets, subnet)
+			}
+			ends[subnet] = append(ends[subnet], services.LinkEnd{
+				Hostname:  device.Hostname,
+				Interface: iface.Name,
+				IsUp:      iface.IsUp,
+			})
+		}
+	}
+
+	// A subnet with more than two interfaces is misconfigured and does not describe a link.
+	for _, subnet := range subnets {
+		e := ends[subnet]
+		if len(e) != 2 || e[0].Hostname == e[1].Hostname {
+			continue
+		}
+
+		if less(e[1], e[0]) {
+			e[0], e[1] = e[1], e[0]
+		}
+		topology.Links = append(topology.Links, services.Link{
+			A:      e[0],
+			B:      e[1],
+			Source: services.LinkSourceSubnet,
+			Subnet: subnet,
+		})
+	}
+}
+
+// sharedSubnet returns the point-to-point subnet of both interfaces, if they have the same one.
+func sharedSubnet(a, b model.Interface) (netip.Prefix, bool) {
+	if !a.IP.IsValid() || !b.IP.IsValid() || !pointToPoint(a.IP) {
+		return netip.Prefix{}, false
+	}
+
+	subnet := a.IP.Masked()
+	return subnet, subnet == b.IP.Masked()
+}
+
+// subtract returns the links of [links] whose ends are not linked in [other].
+func subtract(links, other []services.Link) []services.Link {
+	type key struct {
+		a, b services.LinkEnd
+	}
+	linked := make(map[key]bool, len(other))
+	for _, link := range other {
+		linked[key{end(link.A), end(link.B)}] = true
+	}
+
+	diff := make([]services.Link, 0)
+	for _, link := range links {
+		if !linked[key{end(link.A), end(link.B)}] {
+			diff = append(diff, link)
+		}
+	}
+
+	return diff
+}
+
+// end returns the interface of the link end without its state.
+func end(e services.LinkEnd) services.LinkEnd {
+	e.IsUp = false
+	return e
+}
+
+// less orders link ends by hostname and interface name.
+func less(a, b services.LinkEnd) bool {
+	if a.Hostname != b.Hostname {
+		return a.Hostname < b.Hostname
+	}
+	return a.Interface < b.Interface
+}
+
+// pointToPoint reports whether the prefix is used for point-to-point links.
+func pointToPoint(prefix netip.Prefix) bool {
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	return hostBits == 1 || hostBits == 2
+}
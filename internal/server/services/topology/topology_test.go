@@ -0,0 +1,251 @@
+package topology
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/sudeeya/net-monitor/internal/pkg/model"
+	"github.com/sudeeya/net-monitor/internal/server/services"
+)
+
+// device returns a successfully snapped device with the interfaces.
+func device(hostname string, ifaces ...model.Interface) model.Device {
+	return model.Device{Hostname: hostname, Status: model.StatusSuccess, Interfaces: ifaces}
+}
+
+// iface returns an interface that is up with the address, if any.
+func iface(name, ip string) model.Interface {
+	i := model.Interface{Name: name, IsUp: true}
+	if ip != "" {
+		i.IP = netip.MustParsePrefix(ip)
+	}
+	return i
+}
+
+// withNeighbor returns the interface with the LLDP neighbor.
+func withNeighbor(i model.Interface, hostname, name string) model.Interface {
+	i.NeighborHostname = hostname
+	i.NeighborInterface = name
+	return i
+}
+
+// linkEnd returns an end of a link that is up.
+func linkEnd(hostname, name string) services.LinkEnd {
+	return services.LinkEnd{Hostname: hostname, Interface: name, IsUp: true}
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		devices []model.Device
+		want    []services.Link
+	}{
+		{
+			name: "neighbor reported by both ends is linked once",
+			devices: []model.Device{
+				device("leaf1", withNeighbor(iface("ethernet-1/1", "10.0.0.1/31"), "spine1", "ethernet-1/3")),
+				device("spine1", withNeighbor(iface("ethernet-1/3", "10.0.0.0/31"), "leaf1", "ethernet-1/1")),
+			},
+			want: []services.Link{{
+				A:      linkEnd("leaf1", "ethernet-1/1"),
+				B:      linkEnd("spine1", "ethernet-1/3"),
+				Source: services.LinkSourceLLDP,
+				Subnet: netip.MustParsePrefix("10.0.0.0/31"),
+			}},
+		},
+		{
+			name: "neighbor reported by one end",
+			devices: []model.Device{
+				device("spine1", iface("ethernet-1/3", "")),
+				device("leaf1", withNeighbor(iface("ethernet-1/1", ""), "spine1", "ethernet-1/3")),
+			},
+			want: []services.Link{{
+				A:      linkEnd("leaf1", "ethernet-1/1"),
+				B:      linkEnd("spine1", "ethernet-1/3"),
+				Source: services.LinkSourceLLDP,
+			}},
+		},
+		{
+			name: "neighbor in a disjoint subnet",
+			devices: []model.Device{
+				device("leaf1", withNeighbor(iface("ethernet-1/1", "10.0.0.1/31"), "spine1", "ethernet-1/3")),
+				device("spine1", iface("ethernet-1/3", "10.0.1.0/31")),
+			},
+			want: []services.Link{{
+				A:      linkEnd("leaf1", "ethernet-1/1"),
+				B:      linkEnd("spine1", "ethernet-1/3"),
+				Source: services.LinkSourceLLDP,
+			}},
+		},
+		{
+			name: "neighbor interface missing from the snapshot is down",
+			devices: []model.Device{
+				device("leaf1", withNeighbor(iface("ethernet-1/1", ""), "spine1", "ethernet-1/3")),
+				{Hostname: "spine1", Status: model.StatusFailure},
+			},
+			want: []services.Link{{
+				A:      linkEnd("leaf1", "ethernet-1/1"),
+				B:      services.LinkEnd{Hostname: "spine1", Interface: "ethernet-1/3"},
+				Source: services.LinkSourceLLDP,
+			}},
+		},
+		{
+			name: "neighbors outside the snapshot and of the device itself are skipped",
+			devices: []model.Device{
+				device("leaf1",
+					withNeighbor(iface("mgmt0", ""), "oob-switch", "Gi0/12"),
+					withNeighbor(iface("ethernet-1/5", ""), "leaf1", "ethernet-1/6"),
+				),
+			},
+			want: []services.Link{},
+		},
+		{
+			name: "subnets link interfaces without neighbors",
+			devices: []model.Device{
+				device("leaf1", iface("ethernet-1/1", "10.0.0.1/31"), iface("ethernet-1/2", "10.0.0.5/30")),
+				device("spine1", iface("ethernet-1/3", "10.0.0.0/31")),
+				device("spine2", iface("ethernet-1/3", "10.0.0.6/30")),
+			},
+			want: []services.Link{
+				{
+					A:      linkEnd("leaf1", "ethernet-1/1"),
+					B:      linkEnd("spine1", "ethernet-1/3"),
+					Source: services.LinkSourceSubnet,
+					Subnet: netip.MustParsePrefix("10.0.0.0/31"),
+				},
+				{
+					A:      linkEnd("leaf1", "ethernet-1/2"),
+					B:      linkEnd("spine2", "ethernet-1/3"),
+					Source: services.LinkSourceSubnet,
+					Subnet: netip.MustParsePrefix("10.0.0.4/30"),
+				},
+			},
+		},
+		{
+			name: "interfaces with neighbors are not linked by subnets",
+			devices: []model.Device{
+				// LLDP shows that leaf1 is cabled to spine2, although it shares a subnet with spine1.
+				device("leaf1", withNeighbor(iface("ethernet-1/1", "10.0.0.1/31"), "spine2", "ethernet-1/3")),
+				device("spine1", iface("ethernet-1/3", "10.0.0.0/31")),
+				device("spine2", iface("ethernet-1/3", "")),
+				// The neighbor is not a device of the snapshot, but it is still what the interface is connected to.
+				device("leaf2", withNeighbor(iface("ethernet-1/1", "10.0.1.1/31"), "firewall", "eth1")),
+				device("spine3", iface("ethernet-1/3", "10.0.1.0/31")),
+			},
+			want: []services.Link{{
+				A:      linkEnd("leaf1", "ethernet-1/1"),
+				B:      linkEnd("spine2", "ethernet-1/3"),
+				Source: services.LinkSourceLLDP,
+			}},
+		},
+		{
+			name: "subnet ends without neighbors are not linked twice",
+			devices: []model.Device{
+				device("leaf1", iface("ethernet-1/1", "10.0.0.1/31")),
+				device("spine1", withNeighbor(iface("ethernet-1/3", "10.0.0.0/31"), "leaf1", "ethernet-1/1")),
+			},
+			want: []services.Link{{
+				A:      linkEnd("leaf1", "ethernet-1/1"),
+				B:      linkEnd("spine1", "ethernet-1/3"),
+				Source: services.LinkSourceLLDP,
+				Subnet: netip.MustParsePrefix("10.0.0.0/31"),
+			}},
+		},
+		{
+			name: "subnets that are not point-to-point or shared by more than two interfaces",
+			devices: []model.Device{
+				device("leaf1", iface("ethernet-1/1", "10.0.0.1/24"), iface("ethernet-1/2", "10.0.1.1/30")),
+				device("leaf2", iface("ethernet-1/1", "10.0.0.2/24"), iface("ethernet-1/2", "10.0.1.2/30")),
+				device("leaf3", iface("ethernet-1/2", "10.0.1.3/30")),
+				device("leaf4", iface("ethernet-1/1", "10.0.2.1/31"), iface("ethernet-1/2", "10.0.2.0/31")),
+			},
+			want: []services.Link{},
+		},
+		{
+			name: "IPv6 subnets",
+			devices: []model.Device{
+				device("leaf1", iface("ethernet-1/1", "2001:db8::1/127")),
+				device("spine1", iface("ethernet-1/3", "2001:db8::/127")),
+			},
+			want: []services.Link{{
+				A:      linkEnd("leaf1", "ethernet-1/1"),
+				B:      linkEnd("spine1", "ethernet-1/3"),
+				Source: services.LinkSourceSubnet,
+				Subnet: netip.MustParsePrefix("2001:db8::/127"),
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topology := build(model.Snapshot{ID: 1, Devices: tt.devices})
+			if !reflect.DeepEqual(topology.Links, tt.want) {
+				t.Errorf("links = %+v, want %+v", topology.Links, tt.want)
+			}
+			for i := 1; i < len(topology.Nodes); i++ {
+				if topology.Nodes[i-1].Hostname >= topology.Nodes[i].Hostname {
+					t.Errorf("nodes are not sorted by hostname: %+v", topology.Nodes)
+				}
+			}
+			if len(topology.Nodes) != len(tt.devices) {
+				t.Errorf("nodes = %+v, want one per device", topology.Nodes)
+			}
+		})
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	link := func(a, b string, aUp bool) services.Link {
+		return services.Link{
+			A:      services.LinkEnd{Hostname: a, Interface: "ethernet-1/1", IsUp: aUp},
+			B:      services.LinkEnd{Hostname: b, Interface: "ethernet-1/1", IsUp: true},
+			Source: services.LinkSourceLLDP,
+		}
+	}
+
+	tests := []struct {
+		name  string
+		links []services.Link
+		other []services.Link
+		want  []services.Link
+	}{
+		{
+			name:  "removed link",
+			links: []services.Link{link("leaf1", "spine1", true), link("leaf2", "spine1", true)},
+			other: []services.Link{link("leaf1", "spine1", true)},
+			want:  []services.Link{link("leaf2", "spine1", true)},
+		},
+		{
+			name:  "link that went down is still linked",
+			links: []services.Link{link("leaf1", "spine1", true)},
+			other: []services.Link{link("leaf1", "spine1", false)},
+			want:  []services.Link{},
+		},
+		{
+			name:  "link found by another source is still linked",
+			links: []services.Link{link("leaf1", "spine1", true)},
+			other: []services.Link{func() services.Link {
+				l := link("leaf1", "spine1", true)
+				l.Source = services.LinkSourceSubnet
+				l.Subnet = netip.MustParsePrefix("10.0.0.0/31")
+				return l
+			}()},
+			want: []services.Link{},
+		},
+		{
+			name:  "nothing to subtract",
+			links: []services.Link{link("leaf1", "spine1", true)},
+			want:  []services.Link{link("leaf1", "spine1", true)},
+		},
+		{name: "no links", other: []services.Link{link("leaf1", "spine1", true)}, want: []services.Link{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subtract(tt.links, tt.other); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("subtract = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}